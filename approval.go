@@ -0,0 +1,185 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// mountApprovals records, per script hash, the host paths outside the repo root the user approved.
+type mountApprovals map[string][]string
+
+// approveMounts asks the user to approve mounts which escape the current git repo (or the current directory,
+// outside of a repo) the first time a script is run. Approvals are remembered per script content hash,
+// so any change to the script asks again.
+func approveMounts(stdin io.Reader, stderr io.Writer, scriptPath string, scriptData []byte, mounts []Mount) error {
+	escaping, err := escapingMountPaths(mounts)
+	if err != nil {
+		return err
+	}
+	if len(escaping) == 0 {
+		return nil
+	}
+
+	scriptHash := sha256.Sum256(scriptData)
+	key := hex.EncodeToString(scriptHash[:])
+
+	approvals, err := loadMountApprovals()
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, p := range escaping {
+		if !contains(approvals[key], p) {
+			pending = append(pending, p)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if os.Getenv("CLIX_APPROVE_MOUNTS") != "" {
+		log(1, "Mounts approved by CLIX_APPROVE_MOUNTS: %v", pending)
+		return nil
+	}
+	if !isTerminal(stdin) {
+		return fmt.Errorf("script %s mounts paths outside the repository (%s); run it interactively to approve them, or set CLIX_APPROVE_MOUNTS=1", scriptPath, strings.Join(pending, ", "))
+	}
+
+	fmt.Fprintf(stderr, "%s requests access to paths outside the repository:\n", scriptPath)
+	for _, p := range pending {
+		fmt.Fprintf(stderr, "  %s\n", p)
+	}
+	fmt.Fprintf(stderr, "Allow access? [y/N] ")
+	answer, _ := bufio.NewReader(stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		return fmt.Errorf("access to mounts not approved")
+	}
+
+	approvals[key] = append(approvals[key], pending...)
+	return saveMountApprovals(approvals)
+}
+
+// escapingMountPaths returns the resolved host paths of mounts which are outside the repo root.
+// Mounts of the clix cache directory and named volumes are managed by clix, and are not included.
+func escapingMountPaths(mounts []Mount) ([]string, error) {
+	var userMounts []Mount
+	for _, m := range mounts {
+		if !usesCacheDir([]Mount{m}) && m.Volume == "" {
+			userMounts = append(userMounts, m)
+		}
+	}
+	if len(userMounts) == 0 {
+		return nil, nil
+	}
+
+	resolved, err := resolveMounts(userMounts, "")
+	if err != nil {
+		return nil, fmt.Errorf("error resolving mounts: %w", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	root, err := findGitRoot(cwd)
+	if err != nil {
+		root = cwd
+	}
+
+	var escaping []string
+	for _, m := range resolved {
+		if m.masked {
+			continue
+		}
+		hostPath, err := filepath.Abs(m.HostPath)
+		if err != nil {
+			return nil, err
+		}
+		if !isWithin(root, hostPath) {
+			escaping = append(escaping, hostPath)
+		}
+	}
+	return escaping, nil
+}
+
+// isWithin reports whether p is dir or a path beneath dir.
+func isWithin(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func mountApprovalsPath() (string, error) {
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mount-approvals.json"), nil
+}
+
+func loadMountApprovals() (mountApprovals, error) {
+	path, err := mountApprovalsPath()
+	if err != nil {
+		return nil, err
+	}
+	approvals := mountApprovals{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return approvals, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading mount approvals: %w", err)
+	}
+	if err := json.Unmarshal(data, &approvals); err != nil {
+		return nil, fmt.Errorf("error parsing mount approvals %s: %w", path, err)
+	}
+	return approvals, nil
+}
+
+func saveMountApprovals(approvals mountApprovals) error {
+	path, err := mountApprovalsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state dir: %w", err)
+	}
+	data, err := json.MarshalIndent(approvals, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
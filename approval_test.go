@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestIsWithin(t *testing.T) {
+	tests := []struct {
+		dir, path string
+		expected  bool
+	}{
+		{"/repo", "/repo", true},
+		{"/repo", "/repo/sub/dir", true},
+		{"/repo", "/repo-other", false},
+		{"/repo", "/", false},
+		{"/repo", "/home/me", false},
+	}
+	for _, tt := range tests {
+		if got := isWithin(tt.dir, tt.path); got != tt.expected {
+			t.Errorf("isWithin(%q, %q) = %v, want %v", tt.dir, tt.path, got, tt.expected)
+		}
+	}
+}
+
+func TestApproveMounts(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("CLIX_APPROVE_MOUNTS", "")
+
+	scriptData := []byte("image: alpine\n")
+	outside := t.TempDir()
+	mounts := []Mount{
+		{HostPath: "git.repoRoot(cwd)"},
+		{HostPath: "${cacheDir}/data"},
+		{HostPath: outside},
+	}
+
+	// Mounts within the repo (and cache mounts) never need approval
+	if err := approveMounts(strings.NewReader(""), io.Discard, "tool.yaml", scriptData, mounts[:2]); err != nil {
+		t.Fatalf("Expected mounts within the repo to be allowed, got %v", err)
+	}
+
+	// Without a terminal we can't prompt, so the run is refused
+	err := approveMounts(strings.NewReader("y\n"), io.Discard, "tool.yaml", scriptData, mounts)
+	if err == nil || !strings.Contains(err.Error(), outside) {
+		t.Fatalf("Expected error mentioning %s, got %v", outside, err)
+	}
+
+	// Previously approved paths are remembered for the same script content
+	hash := sha256.Sum256(scriptData)
+	if err := saveMountApprovals(mountApprovals{hex.EncodeToString(hash[:]): {outside}}); err != nil {
+		t.Fatalf("saveMountApprovals failed: %v", err)
+	}
+	if err := approveMounts(strings.NewReader(""), io.Discard, "tool.yaml", scriptData, mounts); err != nil {
+		t.Errorf("Expected approved mount to be allowed, got %v", err)
+	}
+
+	// Changing the script invalidates the approval
+	if err := approveMounts(strings.NewReader(""), io.Discard, "tool.yaml", []byte("image: evil\n"), mounts); err == nil {
+		t.Errorf("Expected changed script to require approval again")
+	}
+
+	t.Setenv("CLIX_APPROVE_MOUNTS", "1")
+	if err := approveMounts(strings.NewReader(""), io.Discard, "tool.yaml", []byte("image: evil\n"), mounts); err != nil {
+		t.Errorf("Expected CLIX_APPROVE_MOUNTS to approve mounts, got %v", err)
+	}
+}
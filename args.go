@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ArgsConfig declares the arguments passed to the tool around those given by the user.
+type ArgsConfig struct {
+	// Positional names the leading user arguments, which are then only passed where prepend or append
+	// reference them as ${args.NAME}
+	Positional []string `json:"positional,omitempty"`
+	// Prepend are passed before the user arguments, e.g. [--config, "${cwd}/tool.yaml"]
+	Prepend []string `json:"prepend,omitempty"`
+	// Append are passed after the user arguments
+	Append []string `json:"append,omitempty"`
+	// Defaults are passed in place of the user arguments when there are none
+	Defaults []string `json:"defaults,omitempty"`
+}
+
+// argReference matches ${cwd}, ${args.NAME} and ${args.NAME:-default} in prepend and append.
+var argReference = regexp.MustCompile(`\$\{(cwd|args\.([A-Za-z_][A-Za-z0-9_-]*))(:-([^}]*))?\}`)
+
+// expand returns the arguments for the tool, given the user arguments.
+func (c *ArgsConfig) expand(userArgs []string) ([]string, error) {
+	if c == nil {
+		return userArgs, nil
+	}
+
+	named := map[string]string{}
+	for i, name := range c.Positional {
+		if i >= len(userArgs) {
+			break
+		}
+		named[name] = userArgs[i]
+	}
+	rest := userArgs[min(len(c.Positional), len(userArgs)):]
+	if len(userArgs) == 0 {
+		rest = c.Defaults
+	}
+
+	var err error
+	template := func(args []string) []string {
+		var out []string
+		for _, arg := range args {
+			out = append(out, argReference.ReplaceAllStringFunc(arg, func(ref string) string {
+				m := argReference.FindStringSubmatch(ref)
+				if m[1] == "cwd" {
+					cwd, cwdErr := os.Getwd()
+					if cwdErr != nil && err == nil {
+						err = cwdErr
+					}
+					return cwd
+				}
+				if v, ok := named[m[2]]; ok {
+					return v
+				}
+				if m[3] == "" && err == nil {
+					err = fmt.Errorf("missing argument %s (use ${args.%s:-default} for a default)", m[2], m[2])
+				}
+				return m[4]
+			}))
+		}
+		return out
+	}
+
+	args := template(c.Prepend)
+	args = append(args, rest...)
+	args = append(args, template(c.Append)...)
+	return args, err
+}
+
+// expandCommand replaces ${cwd} and ${scriptDir} in the script's entrypoint and command, and returns the
+// arguments for the tool, splicing the user arguments into the command at ${args}. A command becomes the
+// entrypoint and leading arguments, so sandboxes only see an entrypoint.
+func expandCommand(script *Script, scriptPath string, userArgs []string) ([]string, error) {
+	if script.Command != nil && script.Entrypoint != "" {
+		return nil, fmt.Errorf("a script may set entrypoint or command, not both")
+	}
+	if script.Command == nil && !strings.Contains(script.Entrypoint, "${") {
+		return userArgs, nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	scriptDir, err := scriptDirectory(scriptPath)
+	if err != nil {
+		return nil, err
+	}
+	replacer := strings.NewReplacer("${cwd}", cwd, "${scriptDir}", scriptDir)
+	script.Entrypoint = replacer.Replace(script.Entrypoint)
+	if script.Command == nil {
+		return userArgs, nil
+	}
+
+	var command []string
+	spliced := false
+	for _, arg := range script.Command {
+		if arg == "${args}" {
+			command = append(command, userArgs...)
+			spliced = true
+			continue
+		}
+		command = append(command, replacer.Replace(arg))
+	}
+	if !spliced {
+		command = append(command, userArgs...)
+	}
+	if len(command) == 0 {
+		return nil, fmt.Errorf("command is empty")
+	}
+	script.Entrypoint, script.Command = command[0], nil
+	return command[1:], nil
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandArgs(t *testing.T) {
+	cwd, _ := os.Getwd()
+	config := &ArgsConfig{
+		Positional: []string{"project"},
+		Prepend:    []string{"--config", "${cwd}/tool.yaml", "--project=${args.project}"},
+		Append:     []string{"--region=${args.region:-us-central1}"},
+		Defaults:   []string{"--help"},
+	}
+
+	for _, tc := range []struct {
+		in   []string
+		want []string
+	}{
+		{[]string{"my-project", "list", "-v"}, []string{"--config", cwd + "/tool.yaml", "--project=my-project", "list", "-v", "--region=us-central1"}},
+		{[]string{"my-project"}, []string{"--config", cwd + "/tool.yaml", "--project=my-project", "--region=us-central1"}},
+	} {
+		got, err := config.expand(tc.in)
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("expand(%q) = %q, %v; want %q", tc.in, got, err, tc.want)
+		}
+	}
+
+	// Defaults are only used without user arguments, and a missing positional argument is an error
+	if _, err := config.expand(nil); err == nil {
+		t.Errorf("Expected an error for the missing project argument")
+	}
+	config.Prepend = []string{"--config", "${cwd}/tool.yaml"}
+	if got, err := config.expand(nil); err != nil || !reflect.DeepEqual(got, []string{"--config", cwd + "/tool.yaml", "--help", "--region=us-central1"}) {
+		t.Errorf("expand() = %q, %v; want the defaults", got, err)
+	}
+
+	var none *ArgsConfig
+	if got, err := none.expand([]string{"a"}); err != nil || !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("expand without args config = %q, %v", got, err)
+	}
+}
+
+func TestExpandCommand(t *testing.T) {
+	cwd, _ := os.Getwd()
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "tool.yaml")
+
+	script := Script{Command: []string{"tool", "--config", "${scriptDir}/tool.yaml", "${args}", "--out", "${cwd}/out", "report"}}
+	args, err := expandCommand(&script, scriptPath, []string{"-v", "--since=1d"})
+	if err != nil {
+		t.Fatalf("expandCommand failed: %v", err)
+	}
+	if want := []string{"--config", dir + "/tool.yaml", "-v", "--since=1d", "--out", cwd + "/out", "report"}; script.Entrypoint != "tool" || !reflect.DeepEqual(args, want) {
+		t.Errorf("expandCommand() = %q %q; want tool %q", script.Entrypoint, args, want)
+	}
+
+	// Without ${args}, the user arguments are appended
+	script = Script{Command: []string{"tool", "run"}}
+	if args, err := expandCommand(&script, scriptPath, []string{"x"}); err != nil || script.Entrypoint != "tool" || !reflect.DeepEqual(args, []string{"run", "x"}) {
+		t.Errorf("expandCommand() = %q %q, %v", script.Entrypoint, args, err)
+	}
+
+	script = Script{Entrypoint: "${scriptDir}/bin/tool"}
+	if args, err := expandCommand(&script, scriptPath, []string{"x"}); err != nil || script.Entrypoint != dir+"/bin/tool" || !reflect.DeepEqual(args, []string{"x"}) {
+		t.Errorf("expandCommand() = %q %q, %v", script.Entrypoint, args, err)
+	}
+
+	script = Script{Entrypoint: "tool", Command: []string{"tool"}}
+	if _, err := expandCommand(&script, scriptPath, nil); err == nil {
+		t.Errorf("Expected an error for both entrypoint and command")
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// awsSecret reads the string value of an AWS Secrets Manager secret, named by its name or ARN, with the
+// host's aws CLI and credentials.
+func awsSecret(id string) (string, error) {
+	args := []string{"secretsmanager", "get-secret-value", "--secret-id", id, "--query", "SecretString", "--output", "text"}
+	// ARNs are in a region, which may not be the default one
+	if parts := strings.Split(id, ":"); len(parts) > 3 && parts[0] == "arn" && parts[3] != "" {
+		args = append(args, "--region", parts[3])
+	}
+	log(1, "Reading secret %s with aws", id)
+	return awsOutput(args...)
+}
+
+// ssmParameter reads an AWS Systems Manager parameter, decrypting SecureString parameters, with the
+// host's aws CLI and credentials.
+func ssmParameter(name string) (string, error) {
+	if !strings.HasPrefix(name, "/") && !strings.HasPrefix(name, "arn:") {
+		return "", fmt.Errorf("ssmParameter must be a parameter path such as /tools/token, not %q", name)
+	}
+	log(1, "Reading parameter %s with aws", name)
+	return awsOutput("ssm", "get-parameter", "--name", name, "--with-decryption", "--query", "Parameter.Value", "--output", "text")
+}
+
+func awsOutput(args ...string) (string, error) {
+	out, err := hostCommandOutput("aws", args...)
+	if err != nil {
+		return "", err
+	}
+	// The text output ends with a newline
+	return strings.TrimSuffix(strings.TrimSuffix(string(out), "\n"), "\r"), nil
+}
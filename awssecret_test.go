@@ -0,0 +1,51 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAwsSecrets(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("MOCK_CALLS", calls)
+
+	if got, err := awsSecret("arn:aws:secretsmanager:eu-west-1:123456789012:secret:tools/token-AbCdEf"); err != nil || got != "aws-mock-secret" {
+		t.Errorf("Expected the secret, got %q, %v", got, err)
+	}
+	if got, err := ssmParameter("/tools/token"); err != nil || got != "aws-mock-secret" {
+		t.Errorf("Expected the parameter, got %q, %v", got, err)
+	}
+	data, _ := os.ReadFile(calls)
+	want := "aws secretsmanager get-secret-value --secret-id arn:aws:secretsmanager:eu-west-1:123456789012:secret:tools/token-AbCdEf --query SecretString --output text --region eu-west-1\n" +
+		"aws ssm get-parameter --name /tools/token --with-decryption --query Parameter.Value --output text\n"
+	if string(data) != want {
+		t.Errorf("Expected %q, got %q", want, data)
+	}
+
+	if _, err := ssmParameter("tools/token"); err == nil {
+		t.Errorf("Expected an error for a parameter which is not a path")
+	}
+	t.Setenv("MOCK_BEHAVIOR", "aws_no_credentials")
+	if _, err := awsSecret("tools/token"); err == nil || !strings.Contains(err.Error(), "Unable to locate credentials") {
+		t.Errorf("Expected the aws CLI's error, got %v", err)
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const cacheUsage = "usage: clix cache <ls|info|gc> [flags]"
+
+// The limits of clix cache gc, unless the configuration or its flags set others.
+const (
+	defaultCacheMaxSize = "10GiB"
+	defaultCacheMaxAge  = "30d"
+)
+
+// CachePolicy limits the clix cache directory; it is applied by clix cache gc.
+type CachePolicy struct {
+	// MaxSize is the total size of the cache, e.g. 10GiB; the least recently used entries are removed first
+	MaxSize string `json:"maxSize,omitempty"`
+	// MaxAge is how long an entry is kept after it was last used, e.g. 30d or 72h
+	MaxAge string `json:"maxAge,omitempty"`
+	// Images also removes the clix-built images which a newer build of the same script has replaced
+	Images bool `json:"images,omitempty"`
+}
+
+// runCache implements `clix cache <ls|info|gc>`, which inspect and manage the caches that clix creates.
+func runCache(stdout, stderr io.Writer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(cacheUsage)
+	}
+	cli := containerCLI()
+	switch args[0] {
+	case "ls":
+		return runCacheLs(stdout, stderr, cli, args[1:])
+	case "info":
+		return runCacheInfo(stdout, stderr, cli, args[1:])
+	case "gc":
+		return runCacheGC(stdout, stderr, cli, args[1:])
+	}
+	return fmt.Errorf("unknown cache command %q; %s", args[0], cacheUsage)
+}
+
+// runCacheGC implements `clix cache gc`: it prunes the cache directory by age and then by size, removes
+// clix volumes which no container is using, and with --images, the clix-built images which are replaced.
+func runCacheGC(stdout, stderr io.Writer, cli []string, args []string) error {
+	policy, err := loadCachePolicy()
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("cache gc", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&policy.MaxSize, "max-size", policy.MaxSize, "the total size of the cache directory, e.g. 10GiB (0 for no limit)")
+	flags.StringVar(&policy.MaxAge, "max-age", policy.MaxAge, "remove cache entries unused for longer, e.g. 30d or 72h (0 for no limit)")
+	flags.BoolVar(&policy.Images, "images", policy.Images, "also remove clix-built images replaced by a newer build")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("usage: clix cache gc [--max-size SIZE] [--max-age AGE] [--images]")
+	}
+	maxSize, maxAge, err := policy.limits()
+	if err != nil {
+		return err
+	}
+
+	entries, err := cacheEntries()
+	if err != nil {
+		return err
+	}
+	for _, e := range pruneCache(entries, maxSize, maxAge, time.Now()) {
+		if err := os.RemoveAll(e.Path); err != nil {
+			fmt.Fprintf(stderr, "Warning: failed to remove %s: %v\n", e.Path, err)
+			continue
+		}
+		fmt.Fprintf(stdout, "Removed %s (%s, last used %s)\n", e.Path, formatBytes(e.Size), e.LastUsed.Format(time.DateOnly))
+	}
+
+	volumes, err := listVolumes(cli)
+	if err != nil {
+		return err
+	}
+	for _, v := range volumes {
+		cmd := execCommand(cli[0], append(cli[1:], "volume", "rm", v)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			// Volumes used by a container can't be removed
+			fmt.Fprintf(stderr, "Warning: failed to remove volume %s: %s\n", v, bytes.TrimSpace(out))
+			continue
+		}
+		fmt.Fprintf(stdout, "Removed volume %s\n", v)
+	}
+
+	if policy.Images {
+		return removeReplacedImages(stdout, stderr, cli)
+	}
+	return nil
+}
+
+// loadCachePolicy returns the configured limits of the cache, or the defaults.
+func loadCachePolicy() (CachePolicy, error) {
+	config, err := loadUserConfig()
+	if err != nil {
+		return CachePolicy{}, err
+	}
+	policy := config.Cache
+	if policy.MaxSize == "" {
+		policy.MaxSize = defaultCacheMaxSize
+	}
+	if policy.MaxAge == "" {
+		policy.MaxAge = defaultCacheMaxAge
+	}
+	return policy, nil
+}
+
+// limits parses the policy's maximum size and age.
+func (p CachePolicy) limits() (int64, time.Duration, error) {
+	maxSize, err := parseSize(p.MaxSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid cache maxSize: %w", err)
+	}
+	maxAge, err := parseAge(p.MaxAge)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid cache maxAge: %w", err)
+	}
+	return maxSize, maxAge, nil
+}
+
+// cacheEntry is a directory of the clix cache which gc keeps or removes as a whole: the cache of an image
+// (${cacheDir}), or a host cache of a runtime, such as maven or binaries.
+type cacheEntry struct {
+	Kind     string
+	Path     string
+	Size     int64
+	LastUsed time.Time
+}
+
+// cacheEntries lists the entries of the clix cache directory. Prompt values, which expire on their own, and
+// the empty mask paths are not entries.
+func cacheEntries() ([]cacheEntry, error) {
+	root, err := clixCacheRoot()
+	if err != nil {
+		return nil, err
+	}
+	var entries []cacheEntry
+	dirs, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading cache directory: %w", err)
+	}
+	for _, d := range dirs {
+		switch name := d.Name(); {
+		case !d.IsDir(), name == "prompts", name == "mask":
+		case name == "cache":
+			images, err := os.ReadDir(filepath.Join(root, name))
+			if err != nil {
+				return nil, fmt.Errorf("error reading cache directory: %w", err)
+			}
+			for _, image := range images {
+				if image.IsDir() {
+					entries = append(entries, newCacheEntry("image", filepath.Join(root, name, image.Name())))
+				}
+			}
+		default:
+			entries = append(entries, newCacheEntry("runtime", filepath.Join(root, name)))
+		}
+	}
+	return entries, nil
+}
+
+// newCacheEntry measures the directory p. Its last use is its modification time, which clix updates
+// whenever a run uses the directory.
+func newCacheEntry(kind, p string) cacheEntry {
+	e := cacheEntry{Kind: kind, Path: p}
+	if info, err := os.Stat(p); err == nil {
+		e.LastUsed = info.ModTime()
+	}
+	filepath.WalkDir(p, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				e.Size += info.Size()
+			}
+		}
+		return nil
+	})
+	return e
+}
+
+// pruneCache returns the entries to remove: those unused for longer than maxAge, and then the least recently
+// used until the rest fit in maxSize. A zero limit is no limit.
+func pruneCache(entries []cacheEntry, maxSize int64, maxAge time.Duration, now time.Time) []cacheEntry {
+	entries = append([]cacheEntry{}, entries...)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].LastUsed.Before(entries[j].LastUsed) })
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+	var removed []cacheEntry
+	for _, e := range entries {
+		expired := maxAge > 0 && now.Sub(e.LastUsed) > maxAge
+		if !expired && (maxSize <= 0 || total <= maxSize) {
+			break
+		}
+		removed = append(removed, e)
+		total -= e.Size
+	}
+	return removed
+}
+
+// touchCacheDir marks a cache directory as used, for gc; it does nothing if the directory doesn't exist yet.
+func touchCacheDir(dir string) {
+	now := time.Now()
+	os.Chtimes(dir, now, now)
+}
+
+// removeReplacedImages removes the images built for build: scripts which a newer build of the same script
+// and repository has replaced. The engine lists images newest first, and each repository is one script.
+func removeReplacedImages(stdout, stderr io.Writer, cli []string) error {
+	images, err := listClixImages(cli, stderr)
+	if err != nil {
+		return err
+	}
+	latest := map[string]bool{}
+	for _, item := range images {
+		image := item.Name
+		repository, _, _ := strings.Cut(image, ":")
+		if !latest[repository] {
+			latest[repository] = true
+			continue
+		}
+		if out, err := execCommand(cli[0], append(cli[1:], "rmi", image)...).CombinedOutput(); err != nil {
+			fmt.Fprintf(stderr, "Warning: failed to remove image %s: %s\n", image, bytes.TrimSpace(out))
+			continue
+		}
+		fmt.Fprintf(stdout, "Removed image %s\n", image)
+	}
+	return nil
+}
+
+var sizePattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([KMGT]?)(?:I?B)?$`)
+
+// parseSize parses a size such as 500MiB or 10G; units are powers of 1024.
+func parseSize(s string) (int64, error) {
+	m := sizePattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+	if m == nil {
+		return 0, fmt.Errorf("%q is not a size, such as 10GiB", s)
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, err
+	}
+	exp := 0
+	if m[2] != "" {
+		exp = strings.Index("KMGT", m[2]) + 1
+	}
+	return int64(n * float64(int64(1)<<(10*exp))), nil
+}
+
+// parseAge parses a duration such as 72h, also accepting days, e.g. 30d.
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not an age, such as 30d or 72h", s)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an age, such as 30d or 72h", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunCache(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("CLIX_SANDBOX", "docker")
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	if err := runCache(&stdout, &stderr, []string{"ls"}); err != nil {
+		t.Fatalf("cache ls failed: %v", err)
+	}
+	for _, want := range []string{"volume  clix-terraform-plugins", "volume  clix-go-mod"} {
+		if got := stdout.String(); !strings.Contains(got, want) {
+			t.Errorf("Expected cache ls output to contain %q, got %q", want, got)
+		}
+	}
+
+	// Volumes in use are skipped
+	stdout.Reset()
+	if err := runCache(&stdout, &stderr, []string{"gc"}); err != nil {
+		t.Fatalf("cache gc failed: %v", err)
+	}
+	if got := stdout.String(); got != "Removed volume clix-terraform-plugins\n" {
+		t.Errorf("Unexpected cache gc output %q", got)
+	}
+	if !strings.Contains(stderr.String(), "failed to remove volume clix-go-mod: volume is in use") {
+		t.Errorf("Expected a warning for the volume in use, got %q", stderr.String())
+	}
+
+	if err := runCache(&stdout, &stderr, []string{"purge"}); err == nil {
+		t.Errorf("Expected an error for an unknown cache command")
+	}
+}
+
+func TestCacheGC(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("CLIX_SANDBOX", "docker")
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	// The configured policy applies unless a flag overrides it
+	os.MkdirAll(filepath.Join(configHome, "clix"), 0755)
+	os.WriteFile(filepath.Join(configHome, "clix", "config.yaml"), []byte("cache:\n  maxSize: 1KiB\n  maxAge: 7d\n"), 0644)
+
+	now := time.Now()
+	for _, dir := range []struct {
+		path string
+		size int
+		age  time.Duration
+	}{
+		{"cache/old", 10, 10 * 24 * time.Hour},
+		{"cache/big", 1000, 2 * time.Hour},
+		{"cache/recent", 500, time.Hour},
+		{"maven", 100, 0},
+		{"prompts", 10, 100 * 24 * time.Hour},
+	} {
+		p := filepath.Join(cacheHome, "clix", dir.path)
+		os.MkdirAll(p, 0755)
+		os.WriteFile(filepath.Join(p, "data"), make([]byte, dir.size), 0644)
+		os.Chtimes(p, now.Add(-dir.age), now.Add(-dir.age))
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := runCache(&stdout, &stderr, []string{"gc", "--images"}); err != nil {
+		t.Fatalf("cache gc failed: %v", err)
+	}
+	for _, dir := range []string{"cache/old", "cache/big"} {
+		if _, err := os.Stat(filepath.Join(cacheHome, "clix", dir)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", dir)
+		}
+	}
+	for _, dir := range []string{"cache/recent", "maven", "prompts"} {
+		if _, err := os.Stat(filepath.Join(cacheHome, "clix", dir)); err != nil {
+			t.Errorf("Expected %s to be kept: %v", dir, err)
+		}
+	}
+	output := stdout.String()
+	if !strings.Contains(output, "Removed image clix-lint-0a1b2c3d-4e5f6a7b:c1\n") || strings.Count(output, "Removed image") != 1 {
+		t.Errorf("Expected only the replaced image to be removed, got %q", output)
+	}
+
+	if err := runCache(&stdout, &stderr, []string{"gc", "--max-age", "soon"}); err == nil || !strings.Contains(err.Error(), "invalid cache maxAge") {
+		t.Errorf("Expected an invalid age error, got %v", err)
+	}
+}
+
+func TestPruneCache(t *testing.T) {
+	now := time.Now()
+	entries := []cacheEntry{
+		{Path: "a", Size: 300, LastUsed: now.Add(-time.Hour)},
+		{Path: "b", Size: 300, LastUsed: now.Add(-3 * time.Hour)},
+		{Path: "c", Size: 300, LastUsed: now.Add(-2 * time.Hour)},
+	}
+	var got []string
+	for _, e := range pruneCache(entries, 500, 0, now) {
+		got = append(got, e.Path)
+	}
+	if strings.Join(got, ",") != "b,c" {
+		t.Errorf("Expected the least recently used entries to be removed, got %v", got)
+	}
+	if removed := pruneCache(entries, 0, 0, now); len(removed) != 0 {
+		t.Errorf("Expected no limits to remove nothing, got %v", removed)
+	}
+}
+
+func TestParseSizeAndAge(t *testing.T) {
+	for s, want := range map[string]int64{"0": 0, "512": 512, "10GiB": 10 << 30, "1.5M": 3 << 19, "2kb": 2048} {
+		if got, err := parseSize(s); err != nil || got != want {
+			t.Errorf("parseSize(%q) = %d, %v, want %d", s, got, err, want)
+		}
+	}
+	if _, err := parseSize("lots"); err == nil {
+		t.Errorf("Expected an error for an invalid size")
+	}
+	for s, want := range map[string]time.Duration{"30d": 30 * 24 * time.Hour, "72h": 72 * time.Hour, "0": 0} {
+		if got, err := parseAge(s); err != nil || got != want {
+			t.Errorf("parseAge(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// cacheItem is something clix keeps between runs: an entry of the cache directory, a rootfs extraction
+// left by an interrupted run, an image built for a build: script, or a volume.
+type cacheItem struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	Path string `json:"path,omitempty"`
+	Size int64  `json:"size"`
+	// LastUsed is when the item was last used; for images, when they were built
+	LastUsed time.Time `json:"lastUsed,omitzero"`
+}
+
+// runCacheLs implements `clix cache ls [--json]`, which lists the cache items with their sizes and last use.
+func runCacheLs(stdout, stderr io.Writer, cli []string, args []string) error {
+	flags := flag.NewFlagSet("cache ls", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	jsonOutput := flags.Bool("json", false, "print the items as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	items, err := listCacheItems(stderr, cli)
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		return writeJSON(stdout, items)
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tSIZE\tLAST USED")
+	for _, item := range items {
+		size, lastUsed := "-", "-"
+		if item.Kind != "volume" {
+			size = formatBytes(item.Size)
+		}
+		if !item.LastUsed.IsZero() {
+			lastUsed = item.LastUsed.Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", item.Kind, item.Name, size, lastUsed)
+	}
+	return w.Flush()
+}
+
+// cacheInfo is the summary printed by clix cache info.
+type cacheInfo struct {
+	CacheDir string                   `json:"cacheDir"`
+	Kinds    map[string]cacheKindInfo `json:"kinds"`
+	Policy   CachePolicy              `json:"policy"`
+	// GCRemoves are the paths which clix cache gc would remove with the policy
+	GCRemoves []string `json:"gcRemoves"`
+	GCFrees   int64    `json:"gcFrees"`
+}
+
+type cacheKindInfo struct {
+	Count int   `json:"count"`
+	Size  int64 `json:"size"`
+}
+
+// runCacheInfo implements `clix cache info [--json]`, which totals the cache items by kind, and shows what
+// clix cache gc would remove from the cache directory.
+func runCacheInfo(stdout, stderr io.Writer, cli []string, args []string) error {
+	flags := flag.NewFlagSet("cache info", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	jsonOutput := flags.Bool("json", false, "print the summary as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	root, err := clixCacheRoot()
+	if err != nil {
+		return err
+	}
+	policy, err := loadCachePolicy()
+	if err != nil {
+		return err
+	}
+	maxSize, maxAge, err := policy.limits()
+	if err != nil {
+		return err
+	}
+	items, err := listCacheItems(stderr, cli)
+	if err != nil {
+		return err
+	}
+	entries, err := cacheEntries()
+	if err != nil {
+		return err
+	}
+
+	info := cacheInfo{CacheDir: root, Kinds: map[string]cacheKindInfo{}, Policy: policy, GCRemoves: []string{}}
+	for _, item := range items {
+		k := info.Kinds[item.Kind]
+		k.Count++
+		k.Size += item.Size
+		info.Kinds[item.Kind] = k
+	}
+	for _, e := range pruneCache(entries, maxSize, maxAge, time.Now()) {
+		info.GCRemoves = append(info.GCRemoves, e.Path)
+		info.GCFrees += e.Size
+	}
+	if *jsonOutput {
+		return writeJSON(stdout, info)
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Cache directory:\t%s\n", info.CacheDir)
+	for _, kind := range []struct{ kind, label string }{
+		{"image", "Image caches"},
+		{"runtime", "Runtime caches"},
+		{"rootfs", "Rootfs extractions"},
+		{"build", "Built images"},
+		{"volume", "Volumes"},
+	} {
+		k := info.Kinds[kind.kind]
+		if kind.kind == "volume" {
+			fmt.Fprintf(w, "%s:\t%d\n", kind.label, k.Count)
+		} else {
+			fmt.Fprintf(w, "%s:\t%d (%s)\n", kind.label, k.Count, formatBytes(k.Size))
+		}
+	}
+	fmt.Fprintf(w, "GC policy:\tmaxSize %s, maxAge %s\n", policy.MaxSize, policy.MaxAge)
+	fmt.Fprintf(w, "GC would remove:\t%d entries (%s)\n", len(info.GCRemoves), formatBytes(info.GCFrees))
+	return w.Flush()
+}
+
+// listCacheItems lists the cache directory entries, then rootfs extractions, built images and volumes. The
+// container engine's items are skipped with a warning if it can't be reached.
+func listCacheItems(stderr io.Writer, cli []string) ([]cacheItem, error) {
+	entries, err := cacheEntries()
+	if err != nil {
+		return nil, err
+	}
+	// Rootfs extractions are removed after each run, so those in the temp directory were interrupted
+	extractions, _ := filepath.Glob(filepath.Join(os.TempDir(), "clix-chroot-*"))
+	for _, p := range extractions {
+		entries = append(entries, newCacheEntry("rootfs", p))
+	}
+	var items []cacheItem
+	for _, e := range entries {
+		items = append(items, cacheItem{Kind: e.Kind, Name: filepath.Base(e.Path), Path: e.Path, Size: e.Size, LastUsed: e.LastUsed})
+	}
+
+	images, err := listClixImages(cli, io.Discard)
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: %v\n", err)
+	}
+	items = append(items, images...)
+	volumes, err := listVolumes(cli)
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: %v\n", err)
+	}
+	for _, v := range volumes {
+		items = append(items, cacheItem{Kind: "volume", Name: v})
+	}
+	return items, nil
+}
+
+// listClixImages lists the images built for build: scripts, newest first, as the engine lists them.
+func listClixImages(cli []string, stderr io.Writer) ([]cacheItem, error) {
+	cmd := execCommand(cli[0], append(cli[1:], "images", "--filter", "reference=clix-*", "--format", "{{.Repository}}:{{.Tag}}\t{{.CreatedAt}}\t{{.Size}}")...)
+	cmd.Stderr = stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing images: %w", err)
+	}
+	var images []cacheItem
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+		item := cacheItem{Kind: "build", Name: fields[0], Size: parseEngineSize(fields[2])}
+		item.LastUsed, _ = time.Parse("2006-01-02 15:04:05 -0700 MST", fields[1])
+		images = append(images, item)
+	}
+	return images, nil
+}
+
+var engineSizePattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([kKMGT]?)B$`)
+
+// parseEngineSize parses an image size as the container engines print it, e.g. 1.2GB, in powers of 1000.
+func parseEngineSize(s string) int64 {
+	m := engineSizePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0
+	}
+	n, _ := strconv.ParseFloat(m[1], 64)
+	if m[2] != "" {
+		for range strings.Index("KMGT", strings.ToUpper(m[2])) + 1 {
+			n *= 1000
+		}
+	}
+	return int64(n)
+}
+
+func writeJSON(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
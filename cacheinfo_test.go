@@ -0,0 +1,78 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheLsAndInfo(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("CLIX_SANDBOX", "docker")
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("TMPDIR", t.TempDir())
+
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	image := filepath.Join(cacheHome, "clix", "cache", "3f2a")
+	os.MkdirAll(image, 0755)
+	os.WriteFile(filepath.Join(image, "data"), make([]byte, 2048), 0644)
+	os.Chtimes(image, old, old)
+	os.MkdirAll(filepath.Join(cacheHome, "clix", "maven"), 0755)
+	os.MkdirAll(filepath.Join(os.TempDir(), "clix-chroot-123"), 0755)
+
+	var stdout, stderr bytes.Buffer
+	if err := runCache(&stdout, &stderr, []string{"ls", "--json"}); err != nil {
+		t.Fatalf("cache ls failed: %v", err)
+	}
+	var items []cacheItem
+	if err := json.Unmarshal(stdout.Bytes(), &items); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", stdout.String(), err)
+	}
+	var kinds []string
+	for _, item := range items {
+		kinds = append(kinds, item.Kind+":"+item.Name)
+	}
+	want := "image:3f2a runtime:maven rootfs:clix-chroot-123 build:clix-lint-0a1b2c3d-4e5f6a7b:c2 build:clix-lint-0a1b2c3d-4e5f6a7b:c1 build:clix-fmt-1a2b3c4d-5e6f7a8b:c1 volume:clix-terraform-plugins volume:clix-go-mod"
+	if got := strings.Join(kinds, " "); got != want {
+		t.Errorf("cache ls items = %s, want %s", got, want)
+	}
+	if items[0].Size != 2048 || !items[0].LastUsed.Equal(old) {
+		t.Errorf("Expected the image cache's size and last use, got %+v", items[0])
+	}
+	if items[3].Size != 1200000000 {
+		t.Errorf("Expected the engine's image size, got %d", items[3].Size)
+	}
+
+	stdout.Reset()
+	if err := runCache(&stdout, &stderr, []string{"info"}); err != nil {
+		t.Fatalf("cache info failed: %v", err)
+	}
+	output := strings.Join(strings.Fields(stdout.String()), " ")
+	for _, want := range []string{"Image caches: 1 (2.0KiB)", "Built images: 3 (2.2GiB)", "Volumes: 2", "GC would remove: 1 entries (2.0KiB)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected cache info to contain %q, got %q", want, stdout.String())
+		}
+	}
+}
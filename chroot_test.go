@@ -57,3 +57,11 @@ entrypoint: /hello
 		t.Errorf("unexpected output: %q", stdout.String())
 	}
 }
+
+func TestChrootWorkdir(t *testing.T) {
+	script := Script{Image: "hello-world", Entrypoint: "/hello", Workdir: "/home/me/src"}
+	err := (&ChrootSandbox{}).Run(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, script, nil)
+	if err == nil || !strings.Contains(err.Error(), "workdir is not supported in chroot sandbox") {
+		t.Errorf("Expected the workdir to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+const cliUsage = `usage: clix [flags] <script>[:command] [args...]
+       clix run [flags] <script>[:command] [--] [args...]
+       clix explain <script>[:command] [args...]
+       clix shell [--shell SHELL] <script>[:command]
+       clix exec <script> [--] <command> [args...]
+       clix sign|validate|cache|secret|doctor|init|install|uninstall|list|lock|verify|upgrade|fmt|build|completion ...
+
+Flags before the script are clix's own; everything after it is passed to the tool.`
+
+// addRunFlags registers the flags of clix itself, which are accepted by clix run and before the script in
+// clix <script>. Each sets the environment variable which the rest of clix reads, so that it also applies
+// to the runs of --each.
+func addRunFlags(flags *flag.FlagSet) {
+	flags.Var(envFlag{env: "CLIX_SANDBOX"}, "sandbox", "the sandbox to run the tool in, or a comma separated list in order of preference")
+	flags.Var(verbosityFlag{}, "v", "log what clix does; repeat (-v -v) or set a level (-v=2) for more detail")
+	flags.Var(envFlag{env: "CLIX_PROFILE"}, "profile", "the script profile to use")
+	flags.Var(envFlag{env: "CLIX_YES", boolFlag: true}, "yes", "skip the script's confirmation prompt")
+	flags.Var(publishFlag{}, "publish", "publish a port to the host, e.g. 8080:8080 (repeatable)")
+	flags.Var(envFlag{env: "CLIX_NETWORK"}, "network", "the tool's network: none, host, bridge or a container engine network")
+	flags.Var(envFlag{env: "CLIX_OFFLINE", boolFlag: true}, "offline", "run without network, failing if anything would be downloaded")
+	flags.Var(envFlag{env: "CLIX_DOCKER_CONTEXT"}, "context", "the docker context to run the tool in")
+	flags.Var(envFlag{env: "CLIX_NO_SANDBOX", boolFlag: true}, "no-sandbox", "run go tools natively, ignoring the script's sandbox")
+	flags.Var(envFlag{env: "CLIX_APPROVE_MOUNTS", boolFlag: true}, "approve-mounts", "approve mounts outside the repository without prompting")
+	flags.Var(envFlag{env: "CLIX_TIMINGS", boolFlag: true}, "timings", "print the time and resources used by the run")
+	flags.Var(envFlag{env: "CLIX_DRY_RUN", boolFlag: true}, "dry-run", "print the resolved script and the command which would run the tool, without running it")
+}
+
+// parseLeadingFlags parses the clix flags before the script or subcommand, returning the remaining args.
+// The flag parser stops at the first non-flag, so the tool's own flags are never mistaken for clix's.
+func parseLeadingFlags(stderr io.Writer, args []string) ([]string, error) {
+	flags := flag.NewFlagSet("clix", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(stderr, "%s\n\nFlags:\n", cliUsage)
+		flags.PrintDefaults()
+	}
+	addRunFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	return flags.Args(), nil
+}
+
+// envFlag is a clix flag which sets an environment variable; bool flags set it to 1, or unset it when false.
+type envFlag struct {
+	env      string
+	boolFlag bool
+}
+
+func (f envFlag) String() string { return "" }
+
+func (f envFlag) IsBoolFlag() bool { return f.boolFlag }
+
+func (f envFlag) Set(value string) error {
+	if f.boolFlag {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		if !enabled {
+			return os.Unsetenv(f.env)
+		}
+		value = "1"
+	}
+	return os.Setenv(f.env, value)
+}
+
+// verbosityFlag is -v, which raises CLIX_LOG_VERBOSITY by one each time it is given, or sets it with -v=N.
+type verbosityFlag struct{}
+
+func (verbosityFlag) String() string { return "" }
+
+func (verbosityFlag) IsBoolFlag() bool { return true }
+
+func (verbosityFlag) Set(value string) error {
+	if value == "true" {
+		verbosity, _ := strconv.Atoi(os.Getenv("CLIX_LOG_VERBOSITY"))
+		value = strconv.Itoa(verbosity + 1)
+	} else if _, err := strconv.Atoi(value); err != nil {
+		return errors.New("verbosity must be a number")
+	}
+	return os.Setenv("CLIX_LOG_VERBOSITY", value)
+}
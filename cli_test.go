@@ -0,0 +1,65 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLeadingFlags(t *testing.T) {
+	for _, env := range []string{"CLIX_SANDBOX", "CLIX_LOG_VERBOSITY", "CLIX_YES", "CLIX_PUBLISH", "CLIX_OFFLINE"} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("CLIX_OFFLINE", "1")
+
+	rest, err := parseLeadingFlags(io.Discard, []string{"-v", "-v", "--sandbox=podman", "--yes", "--offline=false", "--publish", "8080:8080", "tool.yaml", "--sandbox", "--help"})
+	if err != nil {
+		t.Fatalf("parseLeadingFlags failed: %v", err)
+	}
+	if want := []string{"tool.yaml", "--sandbox", "--help"}; !reflect.DeepEqual(rest, want) {
+		t.Errorf("rest = %v, want %v; the tool's flags must be left alone", rest, want)
+	}
+	for env, want := range map[string]string{
+		"CLIX_LOG_VERBOSITY": "2",
+		"CLIX_SANDBOX":       "podman",
+		"CLIX_YES":           "1",
+		"CLIX_PUBLISH":       "8080:8080",
+		"CLIX_OFFLINE":       "",
+	} {
+		if got := os.Getenv(env); got != want {
+			t.Errorf("%s = %q, want %q", env, got, want)
+		}
+	}
+
+	if _, err := parseLeadingFlags(io.Discard, []string{"-v=high", "tool.yaml"}); err == nil {
+		t.Errorf("Expected an error for a non-numeric verbosity")
+	}
+}
+
+func TestRunUnknownLeadingFlag(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	err := run(strings.NewReader(""), &stdout, &stderr, []string{"clix", "--frobnicate", "tool.yaml"})
+	if err == nil || !strings.Contains(err.Error(), "flag provided but not defined: -frobnicate") {
+		t.Errorf("Expected an unknown flag error rather than reading the flag as the script, got %v", err)
+	}
+	if !strings.Contains(stderr.String(), "clix run [flags]") {
+		t.Errorf("Expected the usage on stderr, got %q", stderr.String())
+	}
+}
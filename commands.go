@@ -0,0 +1,87 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Command is one of several named tools defined by a script, e.g. gsutil and bq alongside gcloud,
+// sharing the script's image and mounts.
+type Command struct {
+	// Entrypoint replaces the script's entrypoint
+	Entrypoint string `json:"entrypoint,omitempty"`
+	Profile
+}
+
+// splitCommand splits a script path of the form file.yaml:command, returning the path unchanged if it
+// names a file or has no command.
+func splitCommand(scriptPath string) (string, string) {
+	if _, err := os.Stat(scriptPath); err == nil {
+		return scriptPath, ""
+	}
+	i := strings.LastIndex(scriptPath, ":")
+	if i < 0 || strings.ContainsAny(scriptPath[i+1:], `/\`) {
+		return scriptPath, ""
+	}
+	if _, err := os.Stat(scriptPath[:i]); err != nil {
+		return scriptPath, ""
+	}
+	return scriptPath[:i], scriptPath[i+1:]
+}
+
+// selectCommand merges the named command into the script. With no name, the command is chosen by
+// the name the script was run as, so that a symlink named bq to the script runs the bq command;
+// failing that, the script runs its own entrypoint.
+func selectCommand(script *Script, scriptPath, name string) error {
+	if len(script.Commands) == 0 {
+		if name != "" {
+			return fmt.Errorf("command %q not found: the script has no commands", name)
+		}
+		return nil
+	}
+	if name == "" {
+		base := filepath.Base(scriptPath)
+		if _, ok := script.Commands[strings.TrimSuffix(base, filepath.Ext(base))]; ok {
+			name = strings.TrimSuffix(base, filepath.Ext(base))
+		} else if script.Entrypoint != "" {
+			return nil
+		}
+	}
+	command, ok := script.Commands[name]
+	if !ok {
+		var names []string
+		for n := range script.Commands {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if name == "" {
+			return fmt.Errorf("the script has no entrypoint; run one of its commands with %s:<command> (available: %s)", scriptPath, strings.Join(names, ", "))
+		}
+		return fmt.Errorf("command %q not found (available: %s)", name, strings.Join(names, ", "))
+	}
+	log(1, "Using command %s", name)
+
+	if command.Entrypoint != "" {
+		script.Entrypoint = command.Entrypoint
+		script.Command = nil
+	}
+	mergeProfile(script, command.Profile)
+	return nil
+}
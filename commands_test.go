@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestSplitCommand(t *testing.T) {
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "gcloud.yaml")
+	if err := os.WriteFile(scriptPath, []byte("image: gcloud\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	colonPath := filepath.Join(dir, "odd:name")
+	if err := os.WriteFile(colonPath, []byte("image: odd\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		in, path, command string
+	}{
+		{scriptPath, scriptPath, ""},
+		{scriptPath + ":bq", scriptPath, "bq"},
+		{colonPath, colonPath, ""},
+		{filepath.Join(dir, "missing.yaml:bq"), filepath.Join(dir, "missing.yaml:bq"), ""},
+		{scriptPath + ":sub/dir", scriptPath + ":sub/dir", ""},
+	} {
+		path, command := splitCommand(tc.in)
+		if path != tc.path || command != tc.command {
+			t.Errorf("splitCommand(%q) = %q, %q; want %q, %q", tc.in, path, command, tc.path, tc.command)
+		}
+	}
+}
+
+func TestSelectCommand(t *testing.T) {
+	data := `
+image: gcloud:500.0.0
+entrypoint: gcloud
+mounts:
+- hostPath: ~/.config/gcloud
+  sandboxPath: /root/.config/gcloud
+commands:
+  bq:
+    entrypoint: bq
+  gsutil:
+    entrypoint: gsutil
+    env:
+    - name: BOTO_CONFIG
+      value: /dev/null
+`
+	load := func() Script {
+		var script Script
+		if err := yaml.UnmarshalStrict([]byte(data), &script); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		return script
+	}
+
+	script := load()
+	if err := selectCommand(&script, "gcloud.yaml", "gsutil"); err != nil {
+		t.Fatalf("selectCommand failed: %v", err)
+	}
+	if script.Entrypoint != "gsutil" || len(script.Env) != 1 || len(script.Mounts) != 1 {
+		t.Errorf("Unexpected script %+v", script)
+	}
+
+	// A symlink named after a command selects it
+	script = load()
+	if err := selectCommand(&script, "/usr/local/bin/bq", ""); err != nil || script.Entrypoint != "bq" {
+		t.Errorf("selectCommand(bq symlink) = %v, entrypoint %q", err, script.Entrypoint)
+	}
+
+	// Otherwise the script's own entrypoint is run
+	script = load()
+	if err := selectCommand(&script, "gcloud.yaml", ""); err != nil || script.Entrypoint != "gcloud" {
+		t.Errorf("selectCommand() = %v, entrypoint %q", err, script.Entrypoint)
+	}
+
+	script = load()
+	if err := selectCommand(&script, "gcloud.yaml", "kubectl"); err == nil || !strings.Contains(err.Error(), "available: bq, gsutil") {
+		t.Errorf("Expected an error listing the commands, got %v", err)
+	}
+
+	script = load()
+	script.Entrypoint = ""
+	if err := selectCommand(&script, "gcloud.yaml", ""); err == nil || !strings.Contains(err.Error(), "gcloud.yaml:<command>") {
+		t.Errorf("Expected an error explaining how to pick a command, got %v", err)
+	}
+
+	if err := selectCommand(&Script{}, "tool.yaml", "bq"); err == nil {
+		t.Errorf("Expected an error for a script without commands")
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// subcommands are the subcommands of clix, with their descriptions for shell completion.
+var subcommands = []struct{ name, description string }{
+	{"run", "run a script, or run it once per input with --each"},
+	{"explain", "print what a run would do, without running it"},
+	{"shell", "start a shell in the tool's sandbox"},
+	{"exec", "run a command in a script's running container"},
+	{"build", "prepare scripts without running them"},
+	{"init", "create a script for an image or go tool"},
+	{"install", "install a script as a command"},
+	{"uninstall", "remove an installed command"},
+	{"list", "list the installed commands"},
+	{"lock", "write a script's lockfile"},
+	{"verify", "check a script against its lockfile and signature"},
+	{"upgrade", "bump the versions scripts pin"},
+	{"fmt", "format scripts"},
+	{"validate", "check scripts against the schema"},
+	{"sign", "sign a script"},
+	{"cache", "list and clean up the clix cache"},
+	{"secret", "manage secrets in the keychain"},
+	{"doctor", "check the host for common problems"},
+	{"completion", "print the shell completion script"},
+}
+
+// completionFlag is a flag of clix, as offered by shell completion.
+type completionFlag struct {
+	name, usage string
+	boolFlag    bool
+}
+
+// spelling returns the flag as it is typed: -v, or --sandbox for longer names.
+func (f completionFlag) spelling() string {
+	if len(f.name) == 1 {
+		return "-" + f.name
+	}
+	return "--" + f.name
+}
+
+// runCompletionFlags returns the flags which clix run, and clix before the script, accept.
+func runCompletionFlags() []completionFlag {
+	flags := flag.NewFlagSet("clix", flag.ContinueOnError)
+	addRunFlags(flags)
+	var result []completionFlag
+	flags.VisitAll(func(f *flag.Flag) {
+		b, ok := f.Value.(interface{ IsBoolFlag() bool })
+		result = append(result, completionFlag{name: f.Name, usage: f.Usage, boolFlag: ok && b.IsBoolFlag()})
+	})
+	return result
+}
+
+// runCompletion implements `clix completion bash|zsh|fish`, which prints the completion script for the
+// shell. The scripts complete the subcommands, the run flags, script files, and the installed commands
+// for clix uninstall, which they list with the hidden `clix completion --installed`.
+func runCompletion(stdout io.Writer, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: clix completion bash|zsh|fish")
+	}
+	switch args[0] {
+	case "bash":
+		return writeBashCompletion(stdout)
+	case "zsh":
+		return writeZshCompletion(stdout)
+	case "fish":
+		return writeFishCompletion(stdout)
+	case "--installed":
+		records, err := installRecords()
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			fmt.Fprintln(stdout, record.Name)
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported shell %q: clix completion supports bash, zsh and fish", args[0])
+}
+
+func writeBashCompletion(w io.Writer) error {
+	var names, flagNames []string
+	for _, c := range subcommands {
+		names = append(names, c.name)
+	}
+	for _, f := range runCompletionFlags() {
+		flagNames = append(flagNames, f.spelling())
+	}
+	_, err := fmt.Fprintf(w, `# bash completion for clix; load it with: source <(clix completion bash)
+_clix() {
+  local cur=${COMP_WORDS[COMP_CWORD]} subcommand="" i
+  for ((i = 1; i < COMP_CWORD; i++)); do
+    if [[ ${COMP_WORDS[i]} != -* ]]; then
+      subcommand=${COMP_WORDS[i]}
+      break
+    fi
+  done
+  if [[ $cur == -* && ( -z $subcommand || $subcommand == run ) ]]; then
+    COMPREPLY=($(compgen -W %s -- "$cur"))
+    return
+  fi
+  case $subcommand in
+  "")
+    COMPREPLY=($(compgen -W %s -- "$cur") $(compgen -f -- "$cur"))
+    ;;
+  uninstall)
+    COMPREPLY=($(compgen -W "$(clix completion --installed 2>/dev/null)" -- "$cur"))
+    ;;
+  completion)
+    COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur"))
+    ;;
+  *)
+    COMPREPLY=($(compgen -f -- "$cur"))
+    ;;
+  esac
+}
+complete -o default -o filenames -F _clix clix
+`, shellQuote(strings.Join(flagNames, " ")), shellQuote(strings.Join(names, " ")))
+	return err
+}
+
+func writeZshCompletion(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("#compdef clix\n# zsh completion for clix; load it with: source <(clix completion zsh)\n_clix() {\n  local -a subcommands flags\n  subcommands=(\n")
+	for _, c := range subcommands {
+		fmt.Fprintf(&b, "    %s\n", shellQuote(c.name+":"+strings.ReplaceAll(c.description, ":", `\:`)))
+	}
+	b.WriteString("  )\n  flags=(\n")
+	for _, f := range runCompletionFlags() {
+		fmt.Fprintf(&b, "    %s\n", shellQuote(f.spelling()+":"+strings.ReplaceAll(f.usage, ":", `\:`)))
+	}
+	b.WriteString(`  )
+  local subcommand="" i
+  for ((i = 2; i < CURRENT; i++)); do
+    if [[ ${words[i]} != -* ]]; then
+      subcommand=${words[i]}
+      break
+    fi
+  done
+  if [[ $PREFIX == -* && ( -z $subcommand || $subcommand == run ) ]]; then
+    _describe 'flag' flags
+    return
+  fi
+  case $subcommand in
+  "")
+    _describe 'command' subcommands
+    _files
+    ;;
+  uninstall)
+    compadd -- ${(f)"$(clix completion --installed 2>/dev/null)"}
+    ;;
+  completion)
+    compadd bash zsh fish
+    ;;
+  *)
+    _files
+    ;;
+  esac
+}
+compdef _clix clix
+`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeFishCompletion(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# fish completion for clix; load it with: clix completion fish | source\n")
+	var names []string
+	for _, c := range subcommands {
+		names = append(names, c.name)
+		fmt.Fprintf(&b, "complete -c clix -n __fish_use_subcommand -a %s -d %s\n", c.name, shellQuote(c.description))
+	}
+	for _, f := range runCompletionFlags() {
+		option := "-l " + f.name
+		if len(f.name) == 1 {
+			option = "-s " + f.name
+		}
+		if !f.boolFlag {
+			option += " -r"
+		}
+		// The flags go before the script, or after clix run
+		fmt.Fprintf(&b, "complete -c clix -n '__fish_use_subcommand; or __fish_seen_subcommand_from run' %s -d %s\n", option, shellQuote(f.usage))
+	}
+	b.WriteString("complete -c clix -n '__fish_seen_subcommand_from uninstall' -f -a '(clix completion --installed 2>/dev/null)'\n")
+	b.WriteString("complete -c clix -n '__fish_seen_subcommand_from completion' -f -a 'bash zsh fish'\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
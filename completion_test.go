@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		t.Run(shell, func(t *testing.T) {
+			var stdout bytes.Buffer
+			if err := runCompletion(&stdout, []string{shell}); err != nil {
+				t.Fatalf("runCompletion failed: %v", err)
+			}
+			for _, want := range []string{"uninstall", "sandbox", "dry-run", "clix completion --installed"} {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("%s completion does not contain %q:\n%s", shell, want, stdout.String())
+				}
+			}
+		})
+	}
+
+	if err := runCompletion(&bytes.Buffer{}, []string{"powershell"}); err == nil || !strings.Contains(err.Error(), "unsupported shell") {
+		t.Errorf("expected an unsupported shell error, got %v", err)
+	}
+	if err := runCompletion(&bytes.Buffer{}, nil); err == nil || !strings.Contains(err.Error(), "usage") {
+		t.Errorf("expected a usage error, got %v", err)
+	}
+}
+
+func TestRunCompletionInstalled(t *testing.T) {
+	data := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", data)
+	scriptsDir := filepath.Join(data, "clix", "scripts")
+	if err := os.MkdirAll(scriptsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"shfmt", "jq"} {
+		if err := writeInstallRecord(scriptsDir, installRecord{Name: name, Source: "/tools/" + name + ".yaml"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var stdout bytes.Buffer
+	if err := runCompletion(&stdout, []string{"--installed"}); err != nil {
+		t.Fatalf("runCompletion failed: %v", err)
+	}
+	if got, want := stdout.String(), "jq\nshfmt\n"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRunCompletionFlags(t *testing.T) {
+	flags := map[string]completionFlag{}
+	for _, f := range runCompletionFlags() {
+		flags[f.spelling()] = f
+	}
+	if f, ok := flags["--sandbox"]; !ok || f.boolFlag {
+		t.Errorf("expected --sandbox to take a value, got %+v", f)
+	}
+	for _, name := range []string{"-v", "--yes", "--dry-run"} {
+		if f, ok := flags[name]; !ok || !f.boolFlag {
+			t.Errorf("expected %s to be a boolean flag, got %+v", name, f)
+		}
+	}
+}
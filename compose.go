@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// composeProjectLabel is the label docker compose sets on the networks of a project.
+const composeProjectLabel = "com.docker.compose.project"
+
+// isComposeNetwork reports whether a network mode attaches to a docker compose network: "compose" for
+// the project in the current directory, or "compose:NAME" for a project or network name.
+func isComposeNetwork(mode string) bool {
+	return mode == "compose" || strings.HasPrefix(mode, "compose:")
+}
+
+// resolveComposeNetwork replaces a compose network mode with the name of the network, so that the tool
+// can reach the services of a docker compose project.
+func resolveComposeNetwork(cli []string, script *Script) error {
+	mode := script.Network.Mode
+	if !isComposeNetwork(mode) {
+		return nil
+	}
+	name := strings.TrimPrefix(strings.TrimPrefix(mode, "compose"), ":")
+	if name == "" {
+		project, err := composeProjectInCwd(cli)
+		if err != nil {
+			return err
+		}
+		name = project
+	}
+	network, err := composeProjectNetwork(cli, name)
+	if err != nil {
+		return err
+	}
+	log(1, "Using the compose network %s", network)
+	script.Network.Mode = network
+	return nil
+}
+
+// composeProjectInCwd returns the running compose project whose compose file is in the current
+// directory or one of its parents.
+func composeProjectInCwd(cli []string) (string, error) {
+	out, err := execCommand(cli[0], append(cli[1:], "compose", "ls", "--format", "json")...).Output()
+	if err != nil {
+		return "", fmt.Errorf("listing compose projects: %w", err)
+	}
+	var projects []struct {
+		Name        string
+		ConfigFiles string
+	}
+	if err := json.Unmarshal(out, &projects); err != nil {
+		return "", fmt.Errorf("parsing compose projects: %w", err)
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for _, p := range projects {
+		for _, file := range strings.Split(p.ConfigFiles, ",") {
+			if dir := filepath.Dir(file); dir == cwd || strings.HasPrefix(cwd, dir+string(filepath.Separator)) {
+				return p.Name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("network: compose: no running compose project in %s; start it with docker compose up, or name it with compose:NAME", cwd)
+}
+
+// composeProjectNetwork returns the network of a compose project: its default network, or its only
+// network. A name which isn't a project with networks is used as a network name, e.g. myproject_default.
+func composeProjectNetwork(cli []string, name string) (string, error) {
+	out, err := execCommand(cli[0], append(cli[1:], "network", "ls", "--filter", "label="+composeProjectLabel+"="+name, "--format", "{{.Name}}")...).Output()
+	if err != nil {
+		return "", fmt.Errorf("listing compose networks: %w", err)
+	}
+	networks := strings.Fields(string(out))
+	switch len(networks) {
+	case 0:
+		return name, nil
+	case 1:
+		return networks[0], nil
+	}
+	for _, n := range networks {
+		if n == name+"_default" {
+			return n, nil
+		}
+	}
+	return "", fmt.Errorf("compose project %s has several networks (%s); name one with compose:NETWORK", name, strings.Join(networks, ", "))
+}
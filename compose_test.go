@@ -0,0 +1,60 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveComposeNetwork(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	dir := t.TempDir()
+	t.Setenv("MOCK_COMPOSE_DIR", dir)
+	sub := filepath.Join(dir, "migrations")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		cwd  string
+		mode string
+		want string
+	}{
+		{dir, "compose", "shop_default"},
+		{sub, "compose", "shop_default"},
+		{dir, "compose:blog", "blog_web"},
+		{dir, "compose:myproject_default", "myproject_default"},
+		{dir, "bridge", "bridge"},
+	} {
+		t.Chdir(tc.cwd)
+		script := Script{Network: NetworkPolicy{Mode: tc.mode}}
+		if err := resolveComposeNetwork([]string{"docker"}, &script); err != nil || script.Network.Mode != tc.want {
+			t.Errorf("resolveComposeNetwork(%q) in %s = %q, %v; want %q", tc.mode, tc.cwd, script.Network.Mode, err, tc.want)
+		}
+	}
+
+	t.Chdir(t.TempDir())
+	script := Script{Network: NetworkPolicy{Mode: "compose"}}
+	if err := resolveComposeNetwork([]string{"docker"}, &script); err == nil {
+		t.Errorf("Expected an error without a compose project")
+	}
+	if err := checkNetwork("nerdctl", NetworkPolicy{Mode: "compose:shop"}); err == nil {
+		t.Errorf("Expected an error for a compose network with nerdctl")
+	}
+}
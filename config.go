@@ -0,0 +1,175 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"sigs.k8s.io/yaml"
+)
+
+// UserConfig is the machine-specific configuration which applies to every script, read from
+// /etc/clix/config.yaml and then the user's ~/.config/clix/config.yaml.
+type UserConfig struct {
+	// Sandbox is the sandbox (or list of sandboxes) used by scripts which don't choose one
+	Sandbox SandboxList `json:"sandbox,omitempty"`
+	// PullPolicy is the pull policy of scripts which don't set one
+	PullPolicy string `json:"pullPolicy,omitempty"`
+	// DockerContext is the docker context of scripts which don't set one
+	DockerContext string `json:"dockerContext,omitempty"`
+	// Mounts are added to every script run in a container, e.g. for a corporate CA bundle,
+	// unless the script mounts something at the same sandbox path
+	Mounts []Mount `json:"mounts,omitempty"`
+	// Env is added to every script run in a container, unless the script sets the variable
+	Env []EnvVar `json:"env,omitempty"`
+	// Images override the env of scripts whose image matches
+	Images []ImageConfig `json:"images,omitempty"`
+	// AllowDockerSocket allows scripts with dockerSocket: true to access the container engine socket
+	AllowDockerSocket bool `json:"allowDockerSocket,omitempty"`
+	// Cache sets the limits which clix cache gc applies to the cache directory
+	Cache CachePolicy `json:"cache,omitzero"`
+}
+
+// ImageConfig is the configuration for images matching a pattern.
+type ImageConfig struct {
+	// Match is a glob matched against the image reference, e.g. gcr.io/my-project/*
+	Match string `json:"match"`
+	// Env replaces the script's variables of the same name
+	Env []EnvVar `json:"env,omitempty"`
+}
+
+// systemConfigPath is the machine-wide configuration, read before the user's configuration.
+var systemConfigPath = "/etc/clix/config.yaml"
+
+// loadUserConfig reads the system and user configuration; later files override earlier settings, and add
+// to their mounts, env and images.
+func loadUserConfig() (*UserConfig, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	config := &UserConfig{}
+	for _, p := range []string{systemConfigPath, filepath.Join(dir, "config.yaml")} {
+		data, err := os.ReadFile(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", p, err)
+		}
+		var c UserConfig
+		if err := yaml.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", p, err)
+		}
+		log(1, "Loaded configuration from %s", p)
+		if len(c.Sandbox) > 0 {
+			config.Sandbox = c.Sandbox
+		}
+		if c.PullPolicy != "" {
+			config.PullPolicy = c.PullPolicy
+		}
+		if c.DockerContext != "" {
+			config.DockerContext = c.DockerContext
+		}
+		config.Mounts = append(config.Mounts, c.Mounts...)
+		config.Env = append(config.Env, c.Env...)
+		config.Images = append(config.Images, c.Images...)
+		if c.AllowDockerSocket {
+			config.AllowDockerSocket = true
+		}
+		if c.Cache.MaxSize != "" {
+			config.Cache.MaxSize = c.Cache.MaxSize
+		}
+		if c.Cache.MaxAge != "" {
+			config.Cache.MaxAge = c.Cache.MaxAge
+		}
+		if c.Cache.Images {
+			config.Cache.Images = true
+		}
+	}
+	return config, nil
+}
+
+// applyDefaults sets the script's sandbox, pull policy and docker context from the configuration, if the
+// script doesn't.
+func (c *UserConfig) applyDefaults(script *Script) {
+	if len(script.Sandbox) == 0 {
+		script.Sandbox = c.Sandbox
+	}
+	if script.PullPolicy == "" {
+		script.PullPolicy = c.PullPolicy
+	}
+	if script.DockerContext == "" {
+		script.DockerContext = c.DockerContext
+	}
+}
+
+// apply merges the configured mounts and env into a script which is about to run in a container.
+func (c *UserConfig) apply(script Script) Script {
+	script.Mounts = append([]Mount{}, script.Mounts...)
+	script.Env = append([]EnvVar{}, script.Env...)
+	for _, m := range c.Mounts {
+		if !hasMountTarget(script.Mounts, mountTarget(m)) {
+			script.Mounts = append(script.Mounts, m)
+		}
+	}
+	for _, e := range c.Env {
+		if !hasEnvVar(script.Env, e.Name) {
+			script.Env = append(script.Env, e)
+		}
+	}
+	for _, image := range c.Images {
+		if matched, _ := path.Match(image.Match, script.Image); !matched {
+			continue
+		}
+		for _, e := range image.Env {
+			script.Env = mergeEnvVar(script.Env, e)
+		}
+	}
+	return script
+}
+
+func hasMountTarget(mounts []Mount, target string) bool {
+	for _, m := range mounts {
+		if mountTarget(m) == target {
+			return true
+		}
+	}
+	return false
+}
+
+func hasEnvVar(env []EnvVar, name string) bool {
+	for _, e := range env {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// configuredSandbox applies the user configuration to scripts run in a container sandbox. Scripts which
+// run on the host don't get the configured mounts, so that they are not forced into a container.
+type configuredSandbox struct {
+	Sandbox
+	config *UserConfig
+}
+
+func (s *configuredSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
+	return s.Sandbox.Run(stdin, stdout, stderr, s.config.apply(script), args)
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUserConfig(t *testing.T) {
+	dir := t.TempDir()
+	systemConfigPath = filepath.Join(dir, "etc", "config.yaml")
+	defer func() { systemConfigPath = "/etc/clix/config.yaml" }()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "home"))
+	for path, content := range map[string]string{
+		systemConfigPath: `
+sandbox: podman
+mounts:
+- hostPath: /etc/ssl/certs/corp-ca.pem
+  sandboxPath: /usr/local/share/ca-certificates/corp-ca.crt
+env:
+- name: HTTPS_PROXY
+  value: http://proxy.corp:3128
+`,
+		filepath.Join(dir, "home", "clix", "config.yaml"): `
+pullPolicy: always
+dockerContext: colima
+images:
+- match: gcr.io/my-project/*
+  env:
+  - name: PROJECT
+    value: my-project
+`,
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config, err := loadUserConfig()
+	if err != nil {
+		t.Fatalf("loadUserConfig failed: %v", err)
+	}
+
+	script := Script{
+		Image: "gcr.io/my-project/tool:1.0",
+		Env:   []EnvVar{{Name: "HTTPS_PROXY", Value: "http://other:8080"}, {Name: "PROJECT", Value: "default"}},
+	}
+	config.applyDefaults(&script)
+	if len(script.Sandbox) != 1 || script.Sandbox[0] != "podman" || script.PullPolicy != "always" || script.DockerContext != "colima" {
+		t.Errorf("Expected the configured defaults, got %v %q %q", script.Sandbox, script.PullPolicy, script.DockerContext)
+	}
+
+	// The script's own docker context wins over the configured one
+	own := Script{DockerContext: "remote"}
+	config.applyDefaults(&own)
+	if own.DockerContext != "remote" {
+		t.Errorf("Expected the script's docker context, got %q", own.DockerContext)
+	}
+
+	applied := config.apply(script)
+	if len(applied.Mounts) != 1 || applied.Mounts[0].HostPath != "/etc/ssl/certs/corp-ca.pem" {
+		t.Errorf("Expected the CA bundle to be mounted, got %+v", applied.Mounts)
+	}
+	if len(applied.Env) != 2 || applied.Env[0].Value != "http://other:8080" || applied.Env[1].Value != "my-project" {
+		t.Errorf("Expected the script's env with the image override, got %+v", applied.Env)
+	}
+	if len(script.Mounts) != 0 {
+		t.Errorf("Expected the script to be unchanged, got %+v", script.Mounts)
+	}
+
+	cmdArgs, err := buildContainerArgs([]string{"podman"}, applied, nil, false)
+	if err != nil {
+		t.Fatalf("buildContainerArgs failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(cmdArgs, " "), "--pull always") {
+		t.Errorf("Expected the pull policy, got %v", cmdArgs)
+	}
+}
+
+func TestDockerContextPrecedence(t *testing.T) {
+	t.Setenv("CLIX_SANDBOX", "docker")
+	t.Setenv("CLIX_DRY_RUN", "")
+	t.Setenv("CLIX_DOCKER_CONTEXT", "")
+	dir := t.TempDir()
+	systemConfigPath = filepath.Join(dir, "etc", "config.yaml")
+	defer func() { systemConfigPath = "/etc/clix/config.yaml" }()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	os.MkdirAll(filepath.Join(dir, "clix"), 0755)
+	os.WriteFile(filepath.Join(dir, "clix", "config.yaml"), []byte("dockerContext: colima\n"), 0644)
+	configured := filepath.Join(dir, "configured.yaml")
+	os.WriteFile(configured, []byte("image: alpine\nmountCwd: false\n"), 0644)
+	own := filepath.Join(dir, "own.yaml")
+	os.WriteFile(own, []byte("image: alpine\nmountCwd: false\ndockerContext: remote\n"), 0644)
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"clix", "--dry-run", configured}, "docker --context colima run "},
+		{[]string{"clix", "--dry-run", own}, "docker --context remote run "},
+		{[]string{"clix", "--dry-run", "--context", "laptop", own}, "docker --context laptop run "},
+	} {
+		var stdout bytes.Buffer
+		if err := run(strings.NewReader(""), &stdout, io.Discard, tc.args); err != nil {
+			t.Fatalf("%v failed: %v", tc.args, err)
+		}
+		if !strings.Contains(stdout.String(), tc.want) {
+			t.Errorf("%v: expected %q, got:\n%s", tc.args, tc.want, stdout.String())
+		}
+	}
+}
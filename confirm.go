@@ -0,0 +1,86 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// confirmRun asks the user to confirm the script's confirm message before the tool runs. The prompt is
+// skipped with --yes (CLIX_YES), or in CI (CI is set) when stdin is not a terminal.
+func confirmRun(stdin io.Reader, stderr io.Writer, script Script, args []string) error {
+	if script.Confirm == "" {
+		return nil
+	}
+	message, err := confirmMessage(script, args)
+	if err != nil {
+		return err
+	}
+	if os.Getenv("CLIX_YES") != "" {
+		log(1, "Confirmed by --yes: %s", message)
+		return nil
+	}
+	if !isTerminal(stdin) {
+		if os.Getenv("CI") != "" {
+			log(1, "Confirmation skipped in CI: %s", message)
+			return nil
+		}
+		return fmt.Errorf("%s\nthe script requires confirmation; run it interactively, or pass --yes", message)
+	}
+
+	fmt.Fprintf(stderr, "%s\nContinue? [y/N] ", message)
+	answer, _ := bufio.NewReader(stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		return fmt.Errorf("run not confirmed")
+	}
+	return nil
+}
+
+// confirmMessage returns the confirm message, with ${command} and ${mounts} replaced.
+func confirmMessage(script Script, args []string) (string, error) {
+	command := args
+	if script.Entrypoint != "" {
+		command = append([]string{script.Entrypoint}, args...)
+	}
+
+	var mounts []string
+	if strings.Contains(script.Confirm, "${mounts}") {
+		var userMounts []Mount
+		for _, m := range script.Mounts {
+			if !usesCacheDir([]Mount{m}) && m.Volume == "" {
+				userMounts = append(userMounts, m)
+			}
+		}
+		resolved, err := resolveMounts(userMounts, "")
+		if err != nil {
+			return "", fmt.Errorf("error resolving mounts: %w", err)
+		}
+		for _, m := range resolved {
+			if !m.masked {
+				mounts = append(mounts, m.HostPath)
+			}
+		}
+	}
+
+	return strings.NewReplacer(
+		"${command}", strings.Join(command, " "),
+		"${mounts}", strings.Join(mounts, ", "),
+	).Replace(script.Confirm), nil
+}
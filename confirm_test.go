@@ -0,0 +1,65 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestConfirmMessage(t *testing.T) {
+	dir := t.TempDir()
+	script := Script{
+		Entrypoint: "terraform",
+		Confirm:    "About to run ${command} with access to ${mounts}",
+		Mounts:     []Mount{{HostPath: dir}, {HostPath: "${cacheDir}/terraform"}},
+	}
+	got, err := confirmMessage(script, []string{"apply", "-auto-approve"})
+	if err != nil {
+		t.Fatalf("confirmMessage failed: %v", err)
+	}
+	if want := "About to run terraform apply -auto-approve with access to " + dir; got != want {
+		t.Errorf("confirmMessage() = %q, want %q", got, want)
+	}
+}
+
+func TestConfirmRun(t *testing.T) {
+	t.Setenv("CLIX_YES", "")
+	t.Setenv("CI", "")
+	script := Script{Entrypoint: "terraform", Confirm: "Apply changes?"}
+
+	// Without a terminal, the run needs --yes
+	err := confirmRun(strings.NewReader("y\n"), &bytes.Buffer{}, script, nil)
+	if err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("Expected an error suggesting --yes, got %v", err)
+	}
+
+	t.Setenv("CI", "true")
+	if err := confirmRun(strings.NewReader(""), &bytes.Buffer{}, script, nil); err != nil {
+		t.Errorf("Expected confirmation to be skipped in CI, got %v", err)
+	}
+
+	t.Setenv("CI", "")
+	t.Setenv("CLIX_YES", "1")
+	if err := confirmRun(strings.NewReader(""), &bytes.Buffer{}, script, nil); err != nil {
+		t.Errorf("Expected confirmation with --yes, got %v", err)
+	}
+
+	t.Setenv("CLIX_YES", "")
+	if err := confirmRun(strings.NewReader(""), &bytes.Buffer{}, Script{}, nil); err != nil {
+		t.Errorf("Expected no prompt without confirm, got %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// credentialPresets give the tool the user's credentials for a provider, as mounts and env. The
+// credentials are mounted read-only at the same paths as on the host, so that they work in every
+// sandbox, and those which don't exist are skipped.
+var credentialPresets = map[string]func(home string) ([]Mount, []EnvVar, error){
+	"gcloud": func(home string) ([]Mount, []EnvVar, error) {
+		config := os.Getenv("CLOUDSDK_CONFIG")
+		if config == "" {
+			config = filepath.Join(home, ".config", "gcloud")
+		}
+		if !pathExists(config) {
+			return nil, nil, nil
+		}
+		return []Mount{{HostPath: config, ReadOnly: true}}, []EnvVar{
+			{Name: "CLOUDSDK_CONFIG", Value: config},
+			// gcloud writes logs to its config directory, which is read-only
+			{Name: "CLOUDSDK_CORE_DISABLE_FILE_LOGGING", Value: "true"},
+		}, nil
+	},
+	"kube": func(home string) ([]Mount, []EnvVar, error) {
+		mounts, env, err := kubeconfigMounts()
+		for i := range mounts {
+			mounts[i].ReadOnly = true
+		}
+		return mounts, env, err
+	},
+	"git": func(home string) ([]Mount, []EnvVar, error) {
+		var mounts []Mount
+		for _, p := range []string{".gitconfig", ".config/git", ".git-credentials", ".ssh/known_hosts"} {
+			if p = filepath.Join(home, p); pathExists(p) {
+				mounts = append(mounts, Mount{HostPath: p, ReadOnly: true})
+			}
+		}
+		// The ssh agent holds the keys, so the keys themselves are not mounted
+		var env []EnvVar
+		if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" && pathExists(sock) {
+			mounts = append(mounts, Mount{HostPath: sock})
+			env = append(env, EnvVar{Name: "SSH_AUTH_SOCK", Value: sock})
+		}
+		return mounts, env, nil
+	},
+	"docker": func(home string) ([]Mount, []EnvVar, error) {
+		config := os.Getenv("DOCKER_CONFIG")
+		if config == "" {
+			config = filepath.Join(home, ".docker")
+		}
+		if !pathExists(config) {
+			return nil, nil, nil
+		}
+		return []Mount{{HostPath: config, ReadOnly: true}}, []EnvVar{{Name: "DOCKER_CONFIG", Value: config}}, nil
+	},
+}
+
+// credentialAliases are other names of the presets.
+var credentialAliases = map[string]string{"kubectl": "kube"}
+
+// applyCredentials expands the script's credentials into the mounts and env of each preset. The script's own
+// mounts and env take precedence.
+func applyCredentials(script *Script) error {
+	if len(script.Credentials) == 0 {
+		return nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get user home dir: %w", err)
+	}
+	for _, name := range script.Credentials {
+		if alias, ok := credentialAliases[name]; ok {
+			name = alias
+		}
+		preset, ok := credentialPresets[name]
+		if !ok {
+			var names []string
+			for n := range credentialPresets {
+				names = append(names, n)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown credentials %q (known credentials: %s)", name, strings.Join(names, ", "))
+		}
+		mounts, env, err := preset(home)
+		if err != nil {
+			return fmt.Errorf("credentials %s: %w", name, err)
+		}
+		if len(mounts) == 0 {
+			log(1, "No %s credentials found to mount", name)
+		}
+		for _, m := range mounts {
+			if !hasMountTarget(script.Mounts, mountTarget(m)) {
+				script.Mounts = append(script.Mounts, m)
+			}
+		}
+		for _, e := range env {
+			if !hasEnvVar(script.Env, e.Name) {
+				script.Env = append(script.Env, e)
+			}
+		}
+	}
+	return nil
+}
+
+// pathExists reports whether a file or directory exists at p.
+func pathExists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyCredentials(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, name := range []string{"CLOUDSDK_CONFIG", "KUBECONFIG", "DOCKER_CONFIG", "SSH_AUTH_SOCK"} {
+		t.Setenv(name, "")
+	}
+	for _, dir := range []string{".config/gcloud", ".kube"} {
+		if err := os.MkdirAll(filepath.Join(home, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{".kube/config", ".gitconfig"} {
+		if err := os.WriteFile(filepath.Join(home, file), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	script := Script{
+		Credentials: []string{"gcloud", "kubectl", "git", "docker"},
+		Env:         []EnvVar{{Name: "CLOUDSDK_CONFIG", Value: "/custom"}},
+	}
+	if err := applyCredentials(&script); err != nil {
+		t.Fatalf("applyCredentials failed: %v", err)
+	}
+
+	var mounts []string
+	for _, m := range script.Mounts {
+		if !m.ReadOnly {
+			t.Errorf("Expected %s to be mounted read-only", m.HostPath)
+		}
+		mounts = append(mounts, m.HostPath)
+	}
+	// Docker has no config, so nothing is mounted for it
+	want := []string{filepath.Join(home, ".config/gcloud"), filepath.Join(home, ".kube/config"), filepath.Join(home, ".gitconfig")}
+	if strings.Join(mounts, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected mounts %v, got %v", want, mounts)
+	}
+
+	env := map[string]string{}
+	for _, e := range script.Env {
+		env[e.Name] = e.Value
+	}
+	if env["CLOUDSDK_CONFIG"] != "/custom" {
+		t.Errorf("Expected the script's CLOUDSDK_CONFIG to take precedence, got %q", env["CLOUDSDK_CONFIG"])
+	}
+	if env["KUBECONFIG"] != filepath.Join(home, ".kube/config") {
+		t.Errorf("Expected KUBECONFIG to be set, got %q", env["KUBECONFIG"])
+	}
+	if _, ok := env["DOCKER_CONFIG"]; ok {
+		t.Errorf("Expected no DOCKER_CONFIG without a docker config")
+	}
+
+	err := applyCredentials(&Script{Credentials: []string{"aws"}})
+	if err == nil || !strings.Contains(err.Error(), "known credentials: docker, gcloud, git, kube") {
+		t.Errorf("Expected an error listing the presets, got %v", err)
+	}
+}
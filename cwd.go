@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// CwdMount is whether the current directory is mounted in the sandbox: "true" (the default), "false",
+// or "repoRoot" to mount the root of the git repository containing it. In a script it is a boolean or
+// "repoRoot".
+type CwdMount string
+
+func (c *CwdMount) UnmarshalJSON(data []byte) error {
+	var b bool
+	if err := json.Unmarshal(data, &b); err == nil {
+		*c = CwdMount(fmt.Sprint(b))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "true", "false", "repoRoot":
+		*c = CwdMount(s)
+		return nil
+	}
+	return fmt.Errorf("mountCwd must be true, false or repoRoot, not %q", s)
+}
+
+// mountCwd mounts the current directory, or its git repository root, at the same path in the sandbox,
+// so that the tool's working directory exists there. Nothing is added when a mount already contains it.
+// By default, only container images get the mount, including those built from a build: section, which
+// runs before the image is known: runtimes run on the host without mounts, and the chroot sandbox
+// doesn't support them.
+func mountCwd(script *Script) error {
+	if script.MountCwd == "false" {
+		return nil
+	}
+	if script.MountCwd == "" && ((script.Image == "" && script.Build == nil) || selectedSandbox(*script) == "chroot") {
+		return nil
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("error getting current working directory: %w", err)
+	}
+	dir := cwd
+	if script.MountCwd == "repoRoot" {
+		if root, err := findGitRoot(cwd); err == nil && root != "" {
+			dir = root
+		} else {
+			log(1, "Mounting %s: it is not in a git repository", cwd)
+		}
+	}
+
+	var userMounts []Mount
+	for _, m := range script.Mounts {
+		if !usesCacheDir([]Mount{m}) && m.Volume == "" {
+			userMounts = append(userMounts, m)
+		}
+	}
+	resolved, err := resolveMounts(userMounts, "")
+	if err != nil {
+		return fmt.Errorf("error resolving mounts: %w", err)
+	}
+	for _, m := range resolved {
+		if !m.masked && m.HostPath == m.SandboxPath && isWithin(m.HostPath, dir) {
+			return nil
+		}
+	}
+
+	log(1, "Mounting the working directory %s", dir)
+	script.Mounts = append(script.Mounts, Mount{HostPath: dir})
+	return nil
+}
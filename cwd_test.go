@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestCwdMountUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want CwdMount
+	}{
+		{`true`, "true"},
+		{`false`, "false"},
+		{`"repoRoot"`, "repoRoot"},
+	} {
+		var got CwdMount
+		if err := json.Unmarshal([]byte(tc.in), &got); err != nil || got != tc.want {
+			t.Errorf("Unmarshal(%s) = %q, %v; want %q", tc.in, got, err, tc.want)
+		}
+	}
+	var got CwdMount
+	if err := json.Unmarshal([]byte(`"parent"`), &got); err == nil {
+		t.Errorf("Expected an error for an unknown mountCwd")
+	}
+}
+
+func TestMountCwd(t *testing.T) {
+	cwd, _ := os.Getwd()
+	root, err := findGitRoot(cwd)
+	if err != nil {
+		t.Skipf("not in a git repository: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		script Script
+		want   []string
+	}{
+		{"image default", Script{Image: "tool:1.0"}, []string{cwd}},
+		{"built image default", Script{Build: &BuildConfig{Git: "https://github.com/example/tool"}}, []string{cwd}},
+		{"runtime default", Script{Go: &GoConfig{Run: "example.com/tool"}}, nil},
+		{"disabled", Script{Image: "tool:1.0", MountCwd: "false"}, nil},
+		{"enabled for a runtime", Script{Go: &GoConfig{Run: "example.com/tool"}, MountCwd: "true"}, []string{cwd}},
+		{"repository root", Script{Image: "tool:1.0", MountCwd: "repoRoot"}, []string{root}},
+		{"already mounted", Script{Image: "tool:1.0", Mounts: []Mount{{HostPath: root}}}, []string{root}},
+		{"mounted elsewhere", Script{Image: "tool:1.0", Mounts: []Mount{{HostPath: cwd, SandboxPath: "/src"}}}, []string{cwd, cwd}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			script := tc.script
+			if err := mountCwd(&script); err != nil {
+				t.Fatalf("mountCwd failed: %v", err)
+			}
+			var got []string
+			for _, m := range script.Mounts {
+				got = append(got, m.HostPath)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("Expected mounts %v, got %v", tc.want, got)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("Expected mounts %v, got %v", tc.want, got)
+				}
+			}
+		})
+	}
+}
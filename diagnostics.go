@@ -0,0 +1,187 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// diagnosticsOutputLines is the number of trailing lines of output kept in a diagnostics bundle.
+const diagnosticsOutputLines = 50
+
+// diagnosticsBundle is written to the clix state dir when a run fails, for inclusion in bug reports.
+type diagnosticsBundle struct {
+	Time     time.Time `json:"time"`
+	Args     []string  `json:"args"`
+	Error    string    `json:"error"`
+	ExitCode int       `json:"exitCode,omitempty"`
+	Sandbox  string    `json:"sandbox,omitempty"`
+	// Script is the run spec, after transformations (e.g. go scripts run in a golang image)
+	Script         *Script  `json:"script,omitempty"`
+	ToolArgs       []string `json:"toolArgs,omitempty"`
+	RuntimeVersion string   `json:"runtimeVersion,omitempty"`
+	ImageDigest    string   `json:"imageDigest,omitempty"`
+	Output         []string `json:"output,omitempty"`
+}
+
+// diagnostics records what clix ran, so it can be included in a diagnostics bundle.
+var diagnostics diagnosticsBundle
+
+func diagnosticsEnabled() bool {
+	return os.Getenv("CLIX_DIAGNOSTICS") != ""
+}
+
+// recordRun records the run spec which is about to be executed.
+func recordRun(sandboxType string, script Script, args []string) {
+	diagnostics.Sandbox = sandboxType
+	diagnostics.Script = &script
+	diagnostics.ToolArgs = args
+}
+
+// stateDir returns the directory where clix keeps its state, following the XDG base directory spec.
+func stateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "clix"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home dir: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "clix"), nil
+}
+
+// writeDiagnostics writes a diagnostics bundle for the failed run, returning its path.
+func writeDiagnostics(args []string, runErr error, output *outputTail) (string, error) {
+	bundle := diagnostics
+	bundle.Time = time.Now().UTC()
+	bundle.Args = args
+	bundle.Error = runErr.Error()
+	var exitErr *exitError
+	if errors.As(runErr, &exitErr) {
+		bundle.ExitCode = exitErr.code
+	}
+	if output != nil {
+		bundle.Output = output.Lines()
+	}
+	if bundle.Script != nil {
+		bundle.RuntimeVersion, bundle.ImageDigest = runtimeInfo(bundle.Sandbox, *bundle.Script)
+	}
+
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "diagnostics")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create diagnostics dir: %w", err)
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	// The script's env, the error and the output may contain secrets
+	data = []byte(redactSecrets(string(data)))
+	path := filepath.Join(dir, bundle.Time.Format("20060102T150405.000Z")+".json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write diagnostics: %w", err)
+	}
+	return path, nil
+}
+
+// runtimeInfo returns the version of the container runtime and the digest of the image, where available.
+func runtimeInfo(sandboxType string, script Script) (string, string) {
+	var cli []string
+	switch sandboxType {
+	case "docker":
+		cli = dockerCLI(script)
+	case "podman", "nerdctl":
+		cli = []string{sandboxType}
+	case "wsl":
+		cli = wslDockerCLI(os.Getenv("CLIX_WSL_DISTRO"))
+	default:
+		return "", ""
+	}
+
+	query := func(args ...string) string {
+		out, err := execCommand(cli[0], append(cli[1:], args...)...).Output()
+		if err != nil {
+			log(2, "Unable to get diagnostics info from %v: %v", args, err)
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	version := query("version", "--format", "{{.Client.Version}} (server {{.Server.Version}})")
+	digest := ""
+	if script.Image != "" {
+		digest = query("image", "inspect", "--format", "{{.Id}} {{.RepoDigests}}", script.Image)
+	}
+	return version, digest
+}
+
+// outputTail is a writer which keeps the last lines written to it.
+type outputTail struct {
+	mutex   sync.Mutex
+	max     int
+	lines   []string
+	partial []byte
+}
+
+func newOutputTail(max int) *outputTail {
+	return &outputTail{max: max}
+}
+
+func (t *outputTail) Write(p []byte) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	data := append(t.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		t.lines = append(t.lines, string(data[:i]))
+		data = data[i+1:]
+	}
+	if len(t.lines) > t.max {
+		t.lines = t.lines[len(t.lines)-t.max:]
+	}
+	t.partial = append([]byte(nil), data...)
+	return len(p), nil
+}
+
+// Lines returns the kept lines, including any trailing incomplete line.
+func (t *outputTail) Lines() []string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	lines := append([]string(nil), t.lines...)
+	if len(t.partial) > 0 {
+		lines = append(lines, string(t.partial))
+	}
+	if len(lines) > t.max {
+		lines = lines[len(lines)-t.max:]
+	}
+	return lines
+}
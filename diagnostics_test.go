@@ -0,0 +1,74 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOutputTail(t *testing.T) {
+	tail := newOutputTail(3)
+	fmt.Fprintf(tail, "one\ntwo\nthr")
+	fmt.Fprintf(tail, "ee\nfour\nfive")
+
+	got := tail.Lines()
+	expected := []string{"three", "four", "five"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Lines() = %v, want %v", got, expected)
+	}
+}
+
+func TestWriteDiagnostics(t *testing.T) {
+	stateHome := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", stateHome)
+
+	diagnostics = diagnosticsBundle{}
+	recordRun("chroot", Script{Image: "alpine", Entrypoint: "false"}, []string{"--flag"})
+
+	tail := newOutputTail(diagnosticsOutputLines)
+	fmt.Fprintln(tail, "something went wrong")
+
+	path, err := writeDiagnostics([]string{"clix", "tool.yaml"}, fmt.Errorf("error running chroot command: %w", &exitError{code: 2}), tail)
+	if err != nil {
+		t.Fatalf("writeDiagnostics failed: %v", err)
+	}
+	if !strings.HasPrefix(path, filepath.Join(stateHome, "clix", "diagnostics")) {
+		t.Errorf("Expected bundle in state dir, got %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read bundle: %v", err)
+	}
+	var bundle diagnosticsBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		t.Fatalf("failed to parse bundle: %v", err)
+	}
+	if bundle.ExitCode != 2 {
+		t.Errorf("Expected exit code 2, got %d", bundle.ExitCode)
+	}
+	if bundle.Script == nil || bundle.Script.Image != "alpine" {
+		t.Errorf("Expected run spec to be recorded, got %+v", bundle.Script)
+	}
+	if !reflect.DeepEqual(bundle.Output, []string{"something went wrong"}) {
+		t.Errorf("Expected output to be recorded, got %v", bundle.Output)
+	}
+}
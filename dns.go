@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// vpnInterface matches the names of the network interfaces of VPN clients (OpenVPN, WireGuard,
+// Tailscale, Cisco AnyConnect, GlobalProtect etc).
+var vpnInterface = regexp.MustCompile(`^(tun|tap|wg|ppp|tailscale|nordlynx|cscotun|gpd|ipsec|vpn)[0-9]*$`)
+
+// resolvectlLink matches a link line of resolvectl dns and resolvectl domain, e.g. "Link 5 (tun0): 10.8.0.1".
+var resolvectlLink = regexp.MustCompile(`^Link [0-9]+ \(([^)]+)\):(.*)$`)
+
+// hostVPNDNSFn returns the DNS servers and search domains of the host's VPN connections.
+var hostVPNDNSFn = hostVPNDNS
+
+// hostVPNDNS returns the DNS servers and search domains which systemd-resolved has for VPN interfaces.
+// Container engines copy the host's global resolvers, which don't resolve the VPN's internal names when
+// the VPN configures DNS per interface. It returns nothing where this isn't detectable.
+func hostVPNDNS() ([]string, []string) {
+	if runtime.GOOS != "linux" || !onHostPath("resolvectl") {
+		return nil, nil
+	}
+	servers := resolvectlVPNValues("dns")
+	if len(servers) == 0 {
+		return nil, nil
+	}
+	var domains []string
+	for _, d := range resolvectlVPNValues("domain") {
+		// ~ marks routing-only domains, and ~. routes every query to the link
+		if d = strings.TrimPrefix(d, "~"); d != "." && d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return servers, domains
+}
+
+// resolvectlVPNValues returns the values of VPN links in the output of resolvectl with the subcommand.
+func resolvectlVPNValues(subcommand string) []string {
+	out, err := execCommand("resolvectl", subcommand).Output()
+	if err != nil {
+		log(2, "resolvectl %s failed: %v", subcommand, err)
+		return nil
+	}
+	var values []string
+	for _, line := range strings.Split(string(out), "\n") {
+		m := resolvectlLink.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil || !vpnInterface.MatchString(m[1]) {
+			continue
+		}
+		values = append(values, strings.Fields(m[2])...)
+	}
+	return values
+}
+
+// dnsArgs returns the --dns and --dns-search arguments of docker-compatible CLIs for the script, importing
+// the host's VPN DNS settings when the script doesn't set dns.
+func dnsArgs(script Script) []string {
+	servers, search := script.DNS, script.DNSSearch
+	if len(servers) == 0 && script.Network.Mode != "none" && script.Network.Mode != "host" {
+		vpnServers, vpnSearch := hostVPNDNSFn()
+		if len(vpnServers) > 0 {
+			log(1, "Using the DNS servers of the host's VPN: %s", strings.Join(vpnServers, ", "))
+			servers = vpnServers
+			search = append(append([]string(nil), search...), vpnSearch...)
+		}
+	}
+	var args []string
+	for _, s := range servers {
+		args = append(args, "--dns", s)
+	}
+	for _, s := range search {
+		args = append(args, "--dns-search", s)
+	}
+	return args
+}
+
+// checkExtraHost checks that an extraHosts entry is name:ip, where ip may be host-gateway (the host, as
+// seen from the container).
+func checkExtraHost(entry string) error {
+	name, addr, ok := strings.Cut(entry, ":")
+	if !ok || name == "" {
+		return fmt.Errorf("extraHosts: %q must be name:ip", entry)
+	}
+	if addr != "host-gateway" && net.ParseIP(strings.Trim(addr, "[]")) == nil {
+		return fmt.Errorf("extraHosts: %q is not an IP address or host-gateway", addr)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolvectlVPNValues(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	if got, want := resolvectlVPNValues("dns"), []string{"10.8.0.1", "10.8.0.2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resolvectlVPNValues(dns) = %v, want %v", got, want)
+	}
+	if got, want := resolvectlVPNValues("domain"), []string{"corp.example.com", "~example.internal", "~."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("resolvectlVPNValues(domain) = %v, want %v", got, want)
+	}
+}
+
+func TestDNSArgs(t *testing.T) {
+	defer func() { hostVPNDNSFn = hostVPNDNS }()
+	hostVPNDNSFn = func() ([]string, []string) { return []string{"10.8.0.1"}, []string{"corp.example.com"} }
+
+	for _, tc := range []struct {
+		script Script
+		want   []string
+	}{
+		{Script{}, []string{"--dns", "10.8.0.1", "--dns-search", "corp.example.com"}},
+		{Script{DNS: []string{"1.1.1.1"}, DNSSearch: []string{"svc.local"}}, []string{"--dns", "1.1.1.1", "--dns-search", "svc.local"}},
+		{Script{Network: NetworkPolicy{Mode: "none"}}, nil},
+	} {
+		if got := dnsArgs(tc.script); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("dnsArgs(%+v) = %v, want %v", tc.script, got, tc.want)
+		}
+	}
+}
+
+func TestExtraHosts(t *testing.T) {
+	for _, h := range []string{"internal.registry:10.0.0.5", "host.local:host-gateway", "v6.local:::1"} {
+		if err := checkExtraHost(h); err != nil {
+			t.Errorf("checkExtraHost(%q) failed: %v", h, err)
+		}
+	}
+	for _, h := range []string{"10.0.0.5", ":10.0.0.5", "registry:registry.corp"} {
+		if err := checkExtraHost(h); err == nil {
+			t.Errorf("Expected an error for %q", h)
+		}
+	}
+
+	script := Script{Image: "tool", ExtraHosts: []string{"internal.registry:10.0.0.5", "host.local:host-gateway"}}
+	cmdArgs, err := buildContainerArgs([]string{"podman"}, script, nil, false)
+	if err != nil {
+		t.Fatalf("buildContainerArgs failed: %v", err)
+	}
+	if args := strings.Join(cmdArgs, " "); !strings.Contains(args, "--add-host internal.registry:10.0.0.5 --add-host host.local:host-gateway") {
+		t.Errorf("Expected the extra hosts, got %v", cmdArgs)
+	}
+}
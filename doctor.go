@@ -0,0 +1,211 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+var lookPathFn = exec.LookPath
+
+// doctorCheck is the result of a clix doctor check: ok, warn (some scripts or sandboxes won't work) or
+// fail (clix can't run scripts as configured), with a fix for anything but ok.
+type doctorCheck struct {
+	name   string
+	status string
+	detail string
+	fix    string
+}
+
+// runDoctor implements `clix doctor`, which checks the host for the problems that stop scripts running,
+// printing a fix for each. It fails if any check fails.
+func runDoctor(stdout io.Writer, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: clix doctor")
+	}
+	var checks []doctorCheck
+	checks = append(checks, checkEngines()...)
+	checks = append(checks, checkTool("git", "version", "install git; it is needed for build: scripts, ${git.*} expressions and finding the repository of mounts"))
+	checks = append(checks, checkTool("go", "version", "install Go from https://go.dev/dl; it is needed for go: scripts run without a container"))
+	checks = append(checks, checkSandbox())
+	if runtime.GOOS == "linux" {
+		checks = append(checks, checkUserNamespaces(), checkBinfmt())
+	}
+	checks = append(checks, checkCacheDir())
+
+	failed := 0
+	for _, c := range checks {
+		fmt.Fprintf(stdout, "%-6s %s: %s\n", "["+c.status+"]", c.name, c.detail)
+		if c.fix != "" {
+			fmt.Fprintf(stdout, "       fix: %s\n", c.fix)
+		}
+		if c.status == "fail" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// checkTool checks that a command is installed, reporting its version.
+func checkTool(name, versionArg, fix string) doctorCheck {
+	if _, err := lookPathFn(name); err != nil {
+		return doctorCheck{name: name, status: "warn", detail: "not found", fix: fix}
+	}
+	out, err := execCommand(name, versionArg).Output()
+	if err != nil {
+		return doctorCheck{name: name, status: "warn", detail: fmt.Sprintf("%s %s failed: %v", name, versionArg, err), fix: fix}
+	}
+	detail := firstLine(out)
+	if detail == "" {
+		detail = "installed"
+	}
+	return doctorCheck{name: name, status: "ok", detail: detail}
+}
+
+// checkEngines checks the docker and podman CLIs, and whether their daemons answer. Having neither is a
+// failure, as they are the default sandboxes.
+func checkEngines() []doctorCheck {
+	var checks []doctorCheck
+	found := false
+	for _, engine := range []struct {
+		cli []string
+		fix string
+	}{
+		{dockerCLI(Script{DockerContext: os.Getenv("CLIX_DOCKER_CONTEXT")}), dockerDaemonFix()},
+		{[]string{"podman"}, podmanDaemonFix()},
+	} {
+		name := engine.cli[0]
+		check := checkTool(name, "--version", "")
+		if check.status != "ok" {
+			check.status, check.detail = "info", "not installed"
+			checks = append(checks, check)
+			continue
+		}
+		found = true
+		checks = append(checks, check)
+
+		out, err := execCommand(name, append(engine.cli[1:], "version", "--format", "{{.Server.Version}}")...).CombinedOutput()
+		if err != nil {
+			checks = append(checks, doctorCheck{name: name + " daemon", status: "fail", detail: "not reachable: " + firstLine(out), fix: engine.fix})
+			continue
+		}
+		detail := "reachable"
+		if version := firstLine(out); version != "" {
+			detail += ", server " + version
+		}
+		checks = append(checks, doctorCheck{name: name + " daemon", status: "ok", detail: detail})
+	}
+	if !found {
+		checks = append(checks, doctorCheck{name: "container engine", status: "fail", detail: "neither docker nor podman is installed",
+			fix: "install Docker (https://docs.docker.com/get-docker/) or Podman (https://podman.io/docs/installation), or set CLIX_SANDBOX to another sandbox"})
+	}
+	return checks
+}
+
+func dockerDaemonFix() string {
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		return "start Docker Desktop, or check the docker context (docker context ls)"
+	}
+	return "start the daemon (sudo systemctl start docker), add yourself to the docker group (sudo usermod -aG docker $USER), or check DOCKER_HOST and the docker context"
+}
+
+func podmanDaemonFix() string {
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		return "start the podman machine (podman machine start), or create one first (podman machine init)"
+	}
+	return "check podman info for the error; rootless podman needs subuids (/etc/subuid) for your user"
+}
+
+// checkSandbox checks that the sandbox scripts run in by default, from CLIX_SANDBOX, the user
+// configuration or the default chain, is available.
+func checkSandbox() doctorCheck {
+	config, err := loadUserConfig()
+	if err != nil {
+		return doctorCheck{name: "configuration", status: "fail", detail: err.Error(), fix: "fix or remove the configuration file"}
+	}
+	name := selectedSandbox(Script{Sandbox: config.Sandbox})
+	if !sandboxAvailableFn(name) {
+		return doctorCheck{name: "sandbox", status: "fail", detail: name + " is not available",
+			fix: fmt.Sprintf("install %s, or choose an available sandbox with CLIX_SANDBOX or sandbox: in ~/.config/clix/config.yaml", name)}
+	}
+	return doctorCheck{name: "sandbox", status: "ok", detail: name}
+}
+
+// checkUserNamespaces checks that unprivileged user namespaces, which the namespace sandbox and rootless
+// podman need, are allowed.
+func checkUserNamespaces() doctorCheck {
+	check := doctorCheck{name: "user namespaces", status: "ok", detail: "enabled"}
+	if !userNamespacesEnabled() {
+		check.status, check.detail = "warn", "disabled"
+		check.fix = "sudo sysctl -w kernel.unprivileged_userns_clone=1 user.max_user_namespaces=15000"
+	} else if data, err := os.ReadFile("/proc/sys/kernel/apparmor_restrict_unprivileged_userns"); err == nil && strings.TrimSpace(string(data)) == "1" {
+		check.status, check.detail = "warn", "restricted by AppArmor"
+		check.fix = "sudo sysctl -w kernel.apparmor_restrict_unprivileged_userns=0, or use docker or podman"
+	}
+	return check
+}
+
+// checkBinfmt reports the foreign architectures which the kernel runs with qemu binfmt_misc handlers, as
+// used for images with another arch.
+func checkBinfmt() doctorCheck {
+	var arches []string
+	for arch, name := range qemuArch {
+		if arch != runtime.GOARCH && binfmtFixedHandler(name) {
+			arches = append(arches, arch)
+		}
+	}
+	if len(arches) == 0 {
+		return doctorCheck{name: "binfmt handlers", status: "warn", detail: "none; images for other architectures can't run",
+			fix: "install qemu-user-static (with binfmt-support), or docker run --privileged --rm tonistiigi/binfmt --install all"}
+	}
+	sort.Strings(arches)
+	return doctorCheck{name: "binfmt handlers", status: "ok", detail: strings.Join(arches, ", ")}
+}
+
+// checkCacheDir checks that the clix cache directory can be written.
+func checkCacheDir() doctorCheck {
+	root, err := clixCacheRoot()
+	if err != nil {
+		return doctorCheck{name: "cache directory", status: "fail", detail: err.Error(), fix: "set HOME or XDG_CACHE_HOME"}
+	}
+	fix := fmt.Sprintf("check the ownership and permissions of %s, or set XDG_CACHE_HOME", root)
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return doctorCheck{name: "cache directory", status: "fail", detail: err.Error(), fix: fix}
+	}
+	f, err := os.CreateTemp(root, ".doctor-*")
+	if err != nil {
+		return doctorCheck{name: "cache directory", status: "fail", detail: root + " is not writable", fix: fix}
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return doctorCheck{name: "cache directory", status: "ok", detail: root}
+}
+
+func firstLine(out []byte) string {
+	line, _, _ := bytes.Cut(bytes.TrimSpace(out), []byte("\n"))
+	return string(line)
+}
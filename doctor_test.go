@@ -0,0 +1,71 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+	execCommand = fakeExecCommand
+	lookPathFn = func(name string) (string, error) {
+		if name == "docker" || name == "git" {
+			return "/usr/bin/" + name, nil
+		}
+		return "", exec.ErrNotFound
+	}
+	sandboxAvailableFn = func(name string) bool { return name == "docker" }
+	defer func() {
+		execCommand = exec.Command
+		lookPathFn = exec.LookPath
+		sandboxAvailableFn = sandboxAvailable
+	}()
+	t.Setenv("CLIX_SANDBOX", "")
+	t.Setenv("CLIX_DOCKER_CONTEXT", "")
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	var stdout bytes.Buffer
+	if err := runDoctor(&stdout, nil); err != nil {
+		t.Fatalf("doctor failed: %v\n%s", err, stdout.String())
+	}
+	for _, want := range []string{
+		"[ok]   docker: Docker version 27.3.1, build ce12230\n",
+		"[ok]   docker daemon: reachable, server 27.3.1\n",
+		"[info] podman: not installed\n",
+		"[warn] go: not found\n       fix: install Go",
+		"[ok]   sandbox: docker\n",
+		"[ok]   cache directory: ",
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("Expected doctor output to contain %q, got:\n%s", want, stdout.String())
+		}
+	}
+
+	os.Setenv("MOCK_BEHAVIOR", "daemon_down")
+	defer os.Unsetenv("MOCK_BEHAVIOR")
+	stdout.Reset()
+	err := runDoctor(&stdout, nil)
+	if err == nil || !strings.Contains(err.Error(), "1 of") {
+		t.Errorf("Expected the unreachable daemon to fail a check, got %v", err)
+	}
+	if want := "[fail] docker daemon: not reachable: Cannot connect to the Docker daemon"; !strings.Contains(stdout.String(), want) {
+		t.Errorf("Expected doctor output to contain %q, got:\n%s", want, stdout.String())
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// dryRunEnabled reports whether --dry-run (CLIX_DRY_RUN) is set: the script is resolved as for a run, but
+// the tool, its hooks and image builds are printed rather than run.
+func dryRunEnabled() bool {
+	return os.Getenv("CLIX_DRY_RUN") != ""
+}
+
+// runExplain implements `clix explain <script> [args...]`, which is `clix --dry-run <script> [args...]`.
+func runExplain(stdin io.Reader, stdout, stderr io.Writer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: clix explain <script>[:command] [args...]")
+	}
+	os.Setenv("CLIX_DRY_RUN", "1")
+	return run(stdin, stdout, stderr, append([]string{"clix"}, args...))
+}
+
+// printResolvedScript prints the script as it will be run, after profiles, overrides, expressions and env
+// values are applied, and the tool's arguments. Secret values are redacted.
+func printResolvedScript(w io.Writer, script Script, args []string) error {
+	data, err := yaml.Marshal(script)
+	if err != nil {
+		return fmt.Errorf("error printing script: %w", err)
+	}
+	fmt.Fprintf(w, "# Resolved script\n%s", redactSecrets(string(data)))
+	if len(args) > 0 {
+		fmt.Fprintf(w, "# Arguments\n%s\n", redactSecrets(displayCommand(args)))
+	}
+	return nil
+}
+
+// printDryRunCommand prints the command which would run the tool, with the env it adds and its directory,
+// to the tool's stdout.
+func printDryRunCommand(cmd *exec.Cmd) error {
+	w := cmd.Stdout
+	if w == nil {
+		w = os.Stdout
+	}
+	host := map[string]bool{}
+	for _, e := range os.Environ() {
+		host[e] = true
+	}
+	var env []string
+	for _, e := range cmd.Env {
+		if !host[e] {
+			env = append(env, e)
+		}
+	}
+	line := displayCommand(append(env, cmd.Args...))
+	if cmd.Dir != "" {
+		line = "cd " + displayCommand([]string{cmd.Dir}) + " && " + line
+	}
+	_, err := fmt.Fprintf(w, "# Command\n%s\n", redactSecrets(line))
+	return err
+}
+
+var plainWord = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// displayCommand joins a command line for display, quoting the words which the shell would split.
+func displayCommand(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		if plainWord.MatchString(w) {
+			quoted[i] = w
+		} else {
+			quoted[i] = shellQuote(w)
+		}
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	t.Setenv("CLIX_DRY_RUN", "")
+	t.Setenv("CLIX_SANDBOX", "docker")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "tool.yaml")
+	os.WriteFile(scriptPath, []byte("go:\n  run: example.com/tool\n  version: v1.2.3\nenv:\n- name: MODE\n  value: ci\n"), 0644)
+
+	var stdout, stderr bytes.Buffer
+	if err := run(strings.NewReader(""), &stdout, &stderr, []string{"clix", "--dry-run", scriptPath, "a b"}); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	output := stdout.String()
+	for _, want := range []string{"# Resolved script\n", "  run: example.com/tool\n", "  value: ci\n", "# Command\ngo run example.com/tool@v1.2.3 'a b'\n"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected the dry run output to contain %q, got:\n%s", want, output)
+		}
+	}
+
+	// clix explain is the same as --dry-run
+	os.Unsetenv("CLIX_DRY_RUN")
+	imagePath := filepath.Join(dir, "jq.yaml")
+	os.WriteFile(imagePath, []byte("image: ghcr.io/jqlang/jq:1.7.1\nmountCwd: false\n"), 0644)
+	stdout.Reset()
+	if err := runExplain(strings.NewReader(""), &stdout, &stderr, []string{imagePath, ".name"}); err != nil {
+		t.Fatalf("explain failed: %v", err)
+	}
+	if want := "# Command\ndocker run -i "; !strings.Contains(stdout.String(), want) || !strings.Contains(stdout.String(), "ghcr.io/jqlang/jq:1.7.1 .name\n") {
+		t.Errorf("Expected the docker command, got:\n%s", stdout.String())
+	}
+}
+
+func TestPrintDryRunCommand(t *testing.T) {
+	registerSecret("s3cr3t-token")
+	var stdout bytes.Buffer
+	cmd := exec.Command("docker", "run", "-e", "TOKEN=s3cr3t-token", "alpine", "sh", "-c", "echo $TOKEN")
+	cmd.Env = append(os.Environ(), "EXTRA=1")
+	cmd.Dir = "/work dir"
+	cmd.Stdout = &stdout
+	if err := printDryRunCommand(cmd); err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Command\ncd '/work dir' && EXTRA=1 docker run -e TOKEN=**** alpine sh -c 'echo $TOKEN'\n"; stdout.String() != want {
+		t.Errorf("printDryRunCommand printed %q, want %q", stdout.String(), want)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// runRun implements `clix run [flags] <script> [--] [args...]`
+func runRun(stdin io.Reader, stdout, stderr io.Writer, args []string) error {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	each := flags.Bool("each", false, "run the script once per input item, replacing {} in the args with the item")
+	parallel := flags.Int("parallel", 1, "maximum number of concurrent runs with --each")
+	glob := flags.String("glob", "", "with --each, read input items from files matching the glob instead of stdin")
+	addRunFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	rest := flags.Args()
+	if len(rest) == 0 {
+		return fmt.Errorf("usage: clix run [flags] <script> [--] [args...]")
+	}
+	scriptPath, scriptArgs := rest[0], rest[1:]
+	if len(scriptArgs) > 0 && scriptArgs[0] == "--" {
+		scriptArgs = scriptArgs[1:]
+	}
+
+	if !*each {
+		return run(stdin, stdout, stderr, append([]string{"clix", scriptPath}, scriptArgs...))
+	}
+
+	var items []string
+	if *glob != "" {
+		matches, err := filepath.Glob(*glob)
+		if err != nil {
+			return fmt.Errorf("invalid glob %q: %w", *glob, err)
+		}
+		items = matches
+	} else {
+		scanner := bufio.NewScanner(stdin)
+		for scanner.Scan() {
+			if item := strings.TrimSpace(scanner.Text()); item != "" {
+				items = append(items, item)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("error reading items from stdin: %w", err)
+		}
+	}
+	return runEach(stdout, stderr, scriptPath, scriptArgs, items, *parallel)
+}
+
+// runEach runs the script once per item, with at most parallel runs at a time.
+// Each run is a separate clix process, with its output prefixed by the item.
+func runEach(stdout, stderr io.Writer, scriptPath string, scriptArgs []string, items []string, parallel int) error {
+	if parallel < 1 {
+		parallel = 1
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find clix executable: %w", err)
+	}
+
+	var outputMutex sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, parallel)
+	exitCodes := make([]int, len(items))
+	for i, item := range items {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			prefix := "[" + item + "] "
+			out := &prefixWriter{w: stdout, prefix: prefix, mutex: &outputMutex}
+			errOut := &prefixWriter{w: stderr, prefix: prefix, mutex: &outputMutex}
+
+			cmd := execCommand(self, append([]string{scriptPath}, substituteItem(scriptArgs, item)...)...)
+			cmd.Stdout = out
+			cmd.Stderr = errOut
+			err := cmd.Run()
+			out.Flush()
+			errOut.Flush()
+			if err != nil {
+				if exitErr, ok := err.(*exec.ExitError); ok {
+					exitCodes[i] = exitErr.ExitCode()
+				} else {
+					fmt.Fprintf(errOut, "%v\n", err)
+					errOut.Flush()
+					exitCodes[i] = 1
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var failed []string
+	for i, code := range exitCodes {
+		if code != 0 {
+			failed = append(failed, fmt.Sprintf("%s (exit %d)", items[i], code))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d runs failed: %s", len(failed), len(items), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// substituteItem replaces {} in args with item; if no arg contains {}, the item is appended.
+func substituteItem(args []string, item string) []string {
+	var result []string
+	found := false
+	for _, arg := range args {
+		if strings.Contains(arg, "{}") {
+			found = true
+			arg = strings.ReplaceAll(arg, "{}", item)
+		}
+		result = append(result, arg)
+	}
+	if !found {
+		result = append(result, item)
+	}
+	return result
+}
+
+// prefixWriter writes complete lines to w, each prefixed with prefix, so that concurrent output is not interleaved.
+type prefixWriter struct {
+	w       io.Writer
+	prefix  string
+	mutex   *sync.Mutex
+	partial []byte
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.partial = append(p.partial, data...)
+	for {
+		i := bytes.IndexByte(p.partial, '\n')
+		if i < 0 {
+			break
+		}
+		p.writeLine(p.partial[:i+1])
+		p.partial = p.partial[i+1:]
+	}
+	return len(data), nil
+}
+
+// Flush writes any trailing incomplete line.
+func (p *prefixWriter) Flush() {
+	if len(p.partial) > 0 {
+		p.writeLine(append(p.partial, '\n'))
+		p.partial = nil
+	}
+}
+
+func (p *prefixWriter) writeLine(line []byte) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	io.WriteString(p.w, p.prefix)
+	p.w.Write(line)
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubstituteItem(t *testing.T) {
+	got := substituteItem([]string{"lint", "--dir={}", "{}"}, "mod1")
+	if expected := []string{"lint", "--dir=mod1", "mod1"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("substituteItem = %v, want %v", got, expected)
+	}
+
+	got = substituteItem([]string{"lint"}, "mod1")
+	if expected := []string{"lint", "mod1"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("substituteItem = %v, want %v", got, expected)
+	}
+}
+
+func TestRunEach(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	os.Setenv("MOCK_BEHAVIOR", "echo_args")
+	defer os.Unsetenv("MOCK_BEHAVIOR")
+
+	var stdout, stderr bytes.Buffer
+	stdin := strings.NewReader("a\nfail\n\nc\n")
+	err := runRun(stdin, &stdout, &stderr, []string{"--each", "--parallel", "2", "lint.clix", "--", "check", "{}"})
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 runs failed: fail (exit 4)") {
+		t.Fatalf("Expected one failed run, got %v", err)
+	}
+
+	output := stdout.String()
+	for _, want := range []string{
+		"[a] args: [lint.clix check a]",
+		"[fail] args: [lint.clix check fail]",
+		"[c] args: [lint.clix check c]",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got %q", want, output)
+		}
+	}
+}
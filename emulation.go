@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// qemuArch maps GOARCH names, as used in image platforms, to qemu-user names.
+var qemuArch = map[string]string{
+	"amd64":   "x86_64",
+	"arm64":   "aarch64",
+	"arm":     "arm",
+	"386":     "i386",
+	"ppc64le": "ppc64le",
+	"s390x":   "s390x",
+	"riscv64": "riscv64",
+}
+
+// imageArch returns the architecture of the image to run: that of the script's platform or its arch,
+// defaulting to the host architecture.
+func imageArch(script Script) string {
+	if _, arch, ok := strings.Cut(script.Platform, "/"); ok {
+		arch, _, _ = strings.Cut(arch, "/")
+		return arch
+	}
+	if script.Arch != "" {
+		return script.Arch
+	}
+	return runtime.GOARCH
+}
+
+// imagePlatform returns the platform of the image to pull, for sandboxes which unpack images themselves.
+func imagePlatform(script Script) v1.Platform {
+	platform := v1.Platform{OS: "linux", Architecture: imageArch(script)}
+	if parts := strings.Split(script.Platform, "/"); len(parts) > 1 {
+		platform.OS = parts[0]
+		if len(parts) > 2 {
+			platform.Variant = parts[2]
+		}
+	}
+	return platform
+}
+
+// findEmulator returns the path of a qemu-user emulator for arch, if the host needs one to run arch binaries.
+// An empty path means no emulator needs to be invoked, because the host runs arch natively or the kernel
+// already runs arch binaries with a binfmt_misc handler.
+func findEmulator(arch string) (string, error) {
+	if arch == runtime.GOARCH {
+		return "", nil
+	}
+	name, ok := qemuArch[arch]
+	if !ok {
+		return "", fmt.Errorf("unsupported image architecture %q", arch)
+	}
+	if binfmtFixedHandler(name) {
+		log(1, "Using binfmt_misc handler to run %s binaries", arch)
+		return "", nil
+	}
+	for _, candidate := range []string{"qemu-" + name + "-static", "qemu-" + name} {
+		if path, err := exec.LookPath(candidate); err == nil {
+			log(1, "Using %s to emulate %s", path, arch)
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("image architecture %s differs from host architecture %s, and no emulator was found; install qemu-user-static (qemu-%s-static) or set arch: %s in the script", arch, runtime.GOARCH, name, runtime.GOARCH)
+}
+
+// binfmtFixedHandler reports whether a binfmt_misc handler for the qemu arch is enabled with the F (fix binary)
+// flag, in which case the kernel runs foreign binaries even inside a chroot.
+func binfmtFixedHandler(name string) bool {
+	data, err := os.ReadFile("/proc/sys/fs/binfmt_misc/qemu-" + name)
+	if err != nil {
+		return false
+	}
+	enabled, fixed := false, false
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "enabled" {
+			enabled = true
+		}
+		if flags, ok := strings.CutPrefix(line, "flags: "); ok && strings.Contains(flags, "F") {
+			fixed = true
+		}
+	}
+	return enabled && fixed
+}
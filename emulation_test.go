@@ -0,0 +1,98 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestImageArch(t *testing.T) {
+	if got := imageArch(Script{}); got != runtime.GOARCH {
+		t.Errorf("imageArch() = %q, want host arch %q", got, runtime.GOARCH)
+	}
+	if got := imageArch(Script{Arch: "riscv64"}); got != "riscv64" {
+		t.Errorf("imageArch() = %q, want %q", got, "riscv64")
+	}
+	if got := imageArch(Script{Arch: "riscv64", Platform: "linux/amd64"}); got != "amd64" {
+		t.Errorf("imageArch() = %q, want the platform's arch %q", got, "amd64")
+	}
+}
+
+func TestImagePlatform(t *testing.T) {
+	if got := imagePlatform(Script{Platform: "linux/arm/v7"}); got.OS != "linux" || got.Architecture != "arm" || got.Variant != "v7" {
+		t.Errorf("imagePlatform(linux/arm/v7) = %+v", got)
+	}
+	if got := imagePlatform(Script{Arch: "arm64"}); got.OS != "linux" || got.Architecture != "arm64" || got.Variant != "" {
+		t.Errorf("imagePlatform(arch arm64) = %+v", got)
+	}
+}
+
+func TestFindEmulator(t *testing.T) {
+	foreign := "arm64"
+	if runtime.GOARCH == "arm64" {
+		foreign = "amd64"
+	}
+	name := qemuArch[foreign]
+
+	t.Run("host arch", func(t *testing.T) {
+		emulator, err := findEmulator(runtime.GOARCH)
+		if err != nil || emulator != "" {
+			t.Errorf("findEmulator(host) = %q, %v; want no emulator", emulator, err)
+		}
+	})
+
+	t.Run("unsupported arch", func(t *testing.T) {
+		if _, err := findEmulator("vax"); err == nil {
+			t.Error("expected error for unsupported arch")
+		}
+	})
+
+	if binfmtFixedHandler(name) {
+		t.Skipf("host has a binfmt_misc handler for %s", name)
+	}
+
+	t.Run("emulator on path", func(t *testing.T) {
+		dir := t.TempDir()
+		qemu := filepath.Join(dir, "qemu-"+name+"-static")
+		if err := os.WriteFile(qemu, []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("PATH", dir)
+
+		emulator, err := findEmulator(foreign)
+		if err != nil {
+			t.Fatalf("findEmulator failed: %v", err)
+		}
+		if emulator != qemu {
+			t.Errorf("findEmulator() = %q, want %q", emulator, qemu)
+		}
+	})
+
+	t.Run("no emulator", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+
+		_, err := findEmulator(foreign)
+		if err == nil {
+			t.Fatal("expected error when no emulator is available")
+		}
+		if !strings.Contains(err.Error(), "qemu-"+name+"-static") {
+			t.Errorf("error %q should name the emulator to install", err)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// defaultEnvDenyList are glob patterns of host environment variables which are never forwarded into sandboxes,
+// unless the script explicitly sets them.
+var defaultEnvDenyList = []string{
+	"AWS_ACCESS_KEY_ID",
+	"AWS_SECRET_ACCESS_KEY",
+	"AWS_SESSION_TOKEN",
+	"AZURE_CLIENT_SECRET",
+	"GITHUB_TOKEN",
+	"GH_TOKEN",
+	"GITLAB_TOKEN",
+	"GOOGLE_APPLICATION_CREDENTIALS",
+	"CLOUDSDK_AUTH_ACCESS_TOKEN",
+	"NPM_TOKEN",
+	"VAULT_TOKEN",
+	"DOCKER_AUTH_CONFIG",
+	"SSH_AUTH_SOCK",
+	"*_TOKEN",
+	"*_SECRET",
+	"*_SECRET_KEY",
+	"*_PASSWORD",
+	"*_API_KEY",
+}
+
+// envDenyList returns the deny-list patterns, including any extra patterns from CLIX_ENV_DENY (comma separated).
+func envDenyList() []string {
+	denyList := append([]string(nil), defaultEnvDenyList...)
+	for _, pattern := range strings.Split(os.Getenv("CLIX_ENV_DENY"), ",") {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			denyList = append(denyList, pattern)
+		}
+	}
+	return denyList
+}
+
+// isEnvDenied reports whether the environment variable name matches a deny-list pattern.
+func isEnvDenied(name string, denyList []string) bool {
+	for _, pattern := range denyList {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// sandboxEnv returns the environment for a sandboxed process which inherits the host environment:
+// the host environment without denied variables, followed by the variables set by the script.
+func sandboxEnv(script Script) []string {
+	denyList := envDenyList()
+	explicit := make(map[string]bool)
+	for _, e := range script.Env {
+		explicit[e.Name] = true
+	}
+
+	var env []string
+	var scrubbed []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if explicit[name] {
+			continue
+		}
+		if isEnvDenied(name, denyList) {
+			scrubbed = append(scrubbed, name)
+			continue
+		}
+		env = append(env, kv)
+	}
+	if len(scrubbed) > 0 {
+		log(1, "Not forwarding sensitive host environment variables: %s", strings.Join(scrubbed, ", "))
+	}
+
+	for _, e := range script.Env {
+		env = append(env, fmt.Sprintf("%s=%s", e.Name, e.Value))
+	}
+	return env
+}
+
+// applyPassEnv copies the host environment variables matching the script's passEnv patterns into its
+// env, unless the script sets them. Variables on the deny list are only passed when a pattern names them
+// exactly, with a warning; a glob such as AWS_* skips them.
+func applyPassEnv(stderr io.Writer, script *Script) {
+	if len(script.PassEnv) == 0 {
+		return
+	}
+	denyList := envDenyList()
+	var skipped []string
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if hasEnvVar(script.Env, name) {
+			continue
+		}
+		exact, matched := false, false
+		for _, pattern := range script.PassEnv {
+			if pattern == name {
+				exact = true
+			}
+			if ok, _ := path.Match(pattern, name); ok {
+				matched = true
+			}
+		}
+		if !matched {
+			continue
+		}
+		if isEnvDenied(name, denyList) {
+			if !exact {
+				skipped = append(skipped, name)
+				continue
+			}
+			fmt.Fprintf(stderr, "Warning: passing the sensitive host environment variable %s to the tool\n", name)
+		}
+		script.Env = append(script.Env, EnvVar{Name: name, Value: value})
+	}
+	if len(skipped) > 0 {
+		sort.Strings(skipped)
+		fmt.Fprintf(stderr, "Warning: not passing sensitive host environment variables %s; name them exactly in passEnv to pass them\n", strings.Join(skipped, ", "))
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsEnvDenied(t *testing.T) {
+	denyList := defaultEnvDenyList
+	for _, name := range []string{"AWS_SECRET_ACCESS_KEY", "GITHUB_TOKEN", "SSH_AUTH_SOCK", "MY_SERVICE_TOKEN", "DB_PASSWORD"} {
+		if !isEnvDenied(name, denyList) {
+			t.Errorf("Expected %s to be denied", name)
+		}
+	}
+	for _, name := range []string{"HOME", "PATH", "AWS_PROFILE", "TERM", "TOKENIZER"} {
+		if isEnvDenied(name, denyList) {
+			t.Errorf("Expected %s to be allowed", name)
+		}
+	}
+}
+
+func TestSandboxEnv(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "secret")
+	t.Setenv("MY_SETTING", "value")
+	t.Setenv("EXTRA_DENIED", "value")
+	t.Setenv("CLIX_ENV_DENY", "EXTRA_*")
+	t.Setenv("NPM_TOKEN", "host-value")
+
+	env := sandboxEnv(Script{Env: []EnvVar{{Name: "NPM_TOKEN", Value: "explicit"}}})
+
+	values := make(map[string][]string)
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		values[name] = append(values[name], value)
+	}
+
+	if _, found := values["GITHUB_TOKEN"]; found {
+		t.Errorf("Expected GITHUB_TOKEN to be scrubbed")
+	}
+	if _, found := values["EXTRA_DENIED"]; found {
+		t.Errorf("Expected EXTRA_DENIED to be scrubbed by CLIX_ENV_DENY")
+	}
+	if got := values["MY_SETTING"]; len(got) != 1 || got[0] != "value" {
+		t.Errorf("Expected MY_SETTING to be forwarded, got %v", got)
+	}
+	// Explicitly set variables are passed, and replace the host value
+	if got := values["NPM_TOKEN"]; len(got) != 1 || got[0] != "explicit" {
+		t.Errorf("Expected NPM_TOKEN=explicit only, got %v", got)
+	}
+}
+
+func TestApplyPassEnv(t *testing.T) {
+	t.Setenv("AWS_PROFILE", "dev")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("GITHUB_TOKEN", "token")
+	t.Setenv("NO_COLOR", "1")
+
+	var stderr strings.Builder
+	script := Script{
+		PassEnv: []string{"AWS_*", "NO_COLOR", "GITHUB_TOKEN", "UNSET_*"},
+		Env:     []EnvVar{{Name: "AWS_REGION", Value: "europe-west1"}},
+	}
+	applyPassEnv(&stderr, &script)
+
+	values := make(map[string]string)
+	for _, e := range script.Env {
+		values[e.Name] = e.Value
+	}
+	if values["AWS_PROFILE"] != "dev" || values["NO_COLOR"] != "1" {
+		t.Errorf("Expected matching variables to be passed, got %v", values)
+	}
+	if values["AWS_REGION"] != "europe-west1" {
+		t.Errorf("Expected the script's AWS_REGION to take precedence, got %q", values["AWS_REGION"])
+	}
+	// A glob doesn't pass sensitive variables, but naming one does
+	if _, found := values["AWS_SECRET_ACCESS_KEY"]; found {
+		t.Errorf("Expected AWS_SECRET_ACCESS_KEY not to be passed by a glob")
+	}
+	if values["GITHUB_TOKEN"] != "token" {
+		t.Errorf("Expected the named GITHUB_TOKEN to be passed, got %q", values["GITHUB_TOKEN"])
+	}
+	for _, want := range []string{"not passing sensitive host environment variables", "AWS_SECRET_ACCESS_KEY", "passing the sensitive host environment variable GITHUB_TOKEN"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("Expected stderr to contain %q, got %q", want, stderr.String())
+		}
+	}
+}
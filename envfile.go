@@ -0,0 +1,193 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// envNamePattern matches the names of environment variables.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvFromSource is a source of several env vars.
+type EnvFromSource struct {
+	// File is a dotenv file of KEY=VALUE lines; a relative path is looked up in the current directory,
+	// then the script's directory
+	File string `json:"file,omitempty"`
+	// SopsFile is a SOPS-encrypted file of keys and values, decrypted on the host with the user's keys;
+	// the plaintext is never written to disk
+	SopsFile string `json:"sopsFile,omitempty"`
+	// Optional skips the source when the file doesn't exist, rather than failing
+	Optional bool `json:"optional,omitempty"`
+}
+
+// loadEnvFrom adds the variables of the script's envFrom sources to its env. Later sources take
+// precedence over earlier ones, and the script's env over all of them. The values are used as written,
+// without interpolation or expressions.
+func loadEnvFrom(script *Script, scriptPath string) error {
+	if len(script.EnvFrom) == 0 {
+		return nil
+	}
+	explicit := make(map[string]bool)
+	for _, e := range script.Env {
+		explicit[e.Name] = true
+	}
+
+	var loaded []EnvVar
+	for _, source := range script.EnvFrom {
+		file := source.File
+		if source.SopsFile != "" {
+			if file != "" {
+				return fmt.Errorf("envFrom %s: set file or sopsFile, not both", file)
+			}
+			file = source.SopsFile
+		}
+		path, err := findEnvFile(file, scriptPath)
+		if os.IsNotExist(err) && source.Optional {
+			log(1, "Skipping missing env file %s", file)
+			continue
+		} else if err != nil {
+			return fmt.Errorf("envFrom %s: %w", file, err)
+		}
+		var vars []EnvVar
+		if source.SopsFile != "" {
+			vars, err = decryptSopsEnv(path)
+		} else {
+			var data []byte
+			if data, err = os.ReadFile(path); err == nil {
+				vars, err = parseDotenv(data)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("envFrom %s: %w", path, err)
+		}
+		log(1, "Loaded %d env vars from %s", len(vars), path)
+		for _, e := range vars {
+			if !explicit[e.Name] {
+				loaded = mergeEnvVar(loaded, e)
+			}
+		}
+	}
+	script.Env = append(script.Env, loaded...)
+	return nil
+}
+
+// findEnvFile returns the path of an env file, looking up relative paths in the current directory and
+// then the script's directory.
+func findEnvFile(file, scriptPath string) (string, error) {
+	if file == "~" || strings.HasPrefix(file, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user home dir: %w", err)
+		}
+		file = filepath.Join(home, strings.TrimPrefix(file[1:], "/"))
+	}
+	candidates := []string{file}
+	if !filepath.IsAbs(file) {
+		dir, err := scriptDirectory(scriptPath)
+		if err != nil {
+			return "", err
+		}
+		candidates = append(candidates, filepath.Join(dir, file))
+	}
+	var err error
+	for _, p := range candidates {
+		if _, err = os.Stat(p); err == nil {
+			return filepath.Abs(p)
+		}
+	}
+	return "", err
+}
+
+// parseDotenv parses the KEY=VALUE lines of a dotenv file. Blank lines and # comments are ignored, and
+// lines may start with "export". Double-quoted values may contain escapes such as \n, single-quoted
+// values are literal, and unquoted values end at a " #" comment.
+func parseDotenv(data []byte) ([]EnvVar, error) {
+	var vars []EnvVar
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", n)
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(value, `"`):
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value for %s", n, name)
+			}
+			value = unquoted
+		case strings.HasPrefix(value, "'"):
+			if len(value) < 2 || !strings.HasSuffix(value, "'") {
+				return nil, fmt.Errorf("line %d: unterminated quoted value for %s", n, name)
+			}
+			value = value[1 : len(value)-1]
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		vars = append(vars, EnvVar{Name: name, Value: value})
+	}
+	return vars, scanner.Err()
+}
+
+// decryptSopsEnv decrypts a SOPS file with the host's sops, and returns its top-level keys as env vars.
+// The plaintext is only held in memory.
+func decryptSopsEnv(path string) ([]EnvVar, error) {
+	log(1, "Decrypting %s with sops", path)
+	out, err := hostCommandOutput("sops", "--decrypt", "--output-type", "json", path)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(out, &values); err != nil {
+		return nil, fmt.Errorf("parsing decrypted values: %w", err)
+	}
+	var vars []EnvVar
+	for name, v := range values {
+		if !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("key %q is not an environment variable name", name)
+		}
+		switch v := v.(type) {
+		case string:
+			registerSecret(v)
+			vars = append(vars, EnvVar{Name: name, Value: v})
+		case float64, bool:
+			vars = append(vars, EnvVar{Name: name, Value: fmt.Sprint(v)})
+		default:
+			return nil, fmt.Errorf("key %s has a nested value; env vars must be strings, numbers or booleans", name)
+		}
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Name < vars[j].Name })
+	return vars, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	vars, err := parseDotenv([]byte(`# Project settings
+PROJECT=my-project
+export REGION = us-central1
+GREETING="hello\nworld"
+PATTERN='$HOME/*'
+ZONE=us-central1-a # the default zone
+
+EMPTY=
+`))
+	if err != nil {
+		t.Fatalf("parseDotenv failed: %v", err)
+	}
+	want := []EnvVar{
+		{Name: "PROJECT", Value: "my-project"},
+		{Name: "REGION", Value: "us-central1"},
+		{Name: "GREETING", Value: "hello\nworld"},
+		{Name: "PATTERN", Value: "$HOME/*"},
+		{Name: "ZONE", Value: "us-central1-a"},
+		{Name: "EMPTY", Value: ""},
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("parseDotenv = %+v, want %+v", vars, want)
+	}
+
+	for _, bad := range []string{"NOVALUE", "1NAME=x", "QUOTED='x"} {
+		if _, err := parseDotenv([]byte(bad)); err == nil {
+			t.Errorf("Expected an error parsing %q", bad)
+		}
+	}
+}
+
+func TestLoadEnvFrom(t *testing.T) {
+	scriptDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(scriptDir, "defaults.env"), []byte("PROJECT=default\nREGION=us-central1\nZONE=a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cwd := t.TempDir()
+	t.Chdir(cwd)
+	if err := os.WriteFile(filepath.Join(cwd, ".env"), []byte("PROJECT=mine\nZONE=b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	script := Script{
+		// defaults.env is found next to the script, and .env in the current directory
+		EnvFrom: []EnvFromSource{{File: "defaults.env"}, {File: ".env"}, {File: "missing.env", Optional: true}},
+		Env:     []EnvVar{{Name: "ZONE", Value: "c"}},
+	}
+	if err := loadEnvFrom(&script, filepath.Join(scriptDir, "tool")); err != nil {
+		t.Fatalf("loadEnvFrom failed: %v", err)
+	}
+	want := []EnvVar{{Name: "ZONE", Value: "c"}, {Name: "PROJECT", Value: "mine"}, {Name: "REGION", Value: "us-central1"}}
+	if !reflect.DeepEqual(script.Env, want) {
+		t.Errorf("Expected env %+v, got %+v", want, script.Env)
+	}
+
+	script = Script{EnvFrom: []EnvFromSource{{File: "missing.env"}}}
+	if err := loadEnvFrom(&script, filepath.Join(scriptDir, "tool")); err == nil {
+		t.Errorf("Expected an error for a missing env file")
+	}
+}
+
+func TestLoadSopsEnv(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "secrets.enc.yaml"), []byte("API_TOKEN: ENC[AES256_GCM,data:...]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script := Script{EnvFrom: []EnvFromSource{{SopsFile: "secrets.enc.yaml"}}}
+	if err := loadEnvFrom(&script, filepath.Join(dir, "tool")); err != nil {
+		t.Fatalf("loadEnvFrom failed: %v", err)
+	}
+	want := []EnvVar{{Name: "API_TOKEN", Value: "decrypted-token"}, {Name: "DEBUG", Value: "false"}, {Name: "PORT", Value: "8080"}}
+	if !reflect.DeepEqual(script.Env, want) {
+		t.Errorf("Expected env %+v, got %+v", want, script.Env)
+	}
+
+	t.Setenv("MOCK_BEHAVIOR", "sops_no_key")
+	script = Script{EnvFrom: []EnvFromSource{{SopsFile: "secrets.enc.yaml"}}}
+	err := loadEnvFrom(&script, filepath.Join(dir, "tool"))
+	if err == nil || !strings.Contains(err.Error(), "data key") {
+		t.Errorf("Expected sops' error, got %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// EnvValueSource is where the value of an env var comes from, when it is computed at launch.
+type EnvValueSource struct {
+	// GcloudAccessToken is a short-lived access token of the user's gcloud credentials
+	GcloudAccessToken *GcloudAccessTokenSource `json:"gcloudAccessToken,omitempty"`
+	// OnePassword is a 1Password secret reference (op://vault/item/field), read with the op CLI
+	OnePassword string `json:"onePassword,omitempty"`
+	// ClixSecret is the name of a secret stored in the OS keychain with clix secret set
+	ClixSecret string `json:"clixSecret,omitempty"`
+	// Prompt asks the user for the value on the terminal
+	Prompt *PromptSource `json:"prompt,omitempty"`
+	// GcpSecret is a Google Cloud Secret Manager secret, accessed with the host's application default
+	// credentials
+	GcpSecret *GcpSecretSource `json:"gcpSecret,omitempty"`
+	// AwsSecret is the name or ARN of an AWS Secrets Manager secret, read with the host's AWS credentials
+	AwsSecret string `json:"awsSecret,omitempty"`
+	// SsmParameter is the path of an AWS Systems Manager parameter, read with the host's AWS credentials
+	SsmParameter string `json:"ssmParameter,omitempty"`
+	// Command is a host command which prints the value, e.g. gh auth token
+	Command CommandLine `json:"command,omitempty"`
+	// Timeout limits how long the command may run (e.g. 5s), defaulting to 30s
+	Timeout string `json:"timeout,omitempty"`
+	// OnFailure is what happens when the value can't be computed: "error" (the default) stops the run,
+	// "empty" sets the variable to "", and "skip" leaves it unset
+	OnFailure string `json:"onFailure,omitempty"`
+}
+
+// CommandLine is a command and its arguments. In a script it is a list, or a string which is split on
+// whitespace (without shell quoting).
+type CommandLine []string
+
+func (c *CommandLine) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*c = strings.Fields(s)
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*c = list
+	return nil
+}
+
+// defaultCommandTimeout is the timeout of valueFrom commands.
+const defaultCommandTimeout = 30 * time.Second
+
+// GcloudAccessTokenSource runs gcloud auth print-access-token on the host.
+type GcloudAccessTokenSource struct {
+	// Account is the gcloud account, defaulting to the active account
+	Account string `json:"account,omitempty"`
+	// ImpersonateServiceAccount is a service account whose token is printed instead
+	ImpersonateServiceAccount string `json:"impersonateServiceAccount,omitempty"`
+}
+
+// resolveEnvValues computes the values of the script's env vars which have a valueFrom, on the host.
+// They are resolved just before the tool is launched, so that short-lived credentials are fresh.
+func resolveEnvValues(stdin io.Reader, stderr io.Writer, script *Script) error {
+	env := script.Env[:0:0]
+	for _, e := range script.Env {
+		if e.ValueFrom == nil {
+			env = append(env, e)
+			continue
+		}
+		if e.Value != "" {
+			return fmt.Errorf("env %s: set value or valueFrom, not both", e.Name)
+		}
+		var value string
+		var err error
+		switch {
+		case e.ValueFrom.GcloudAccessToken != nil:
+			value, err = gcloudAccessToken(e.ValueFrom.GcloudAccessToken)
+		case e.ValueFrom.OnePassword != "":
+			value, err = onePasswordSecret(e.ValueFrom.OnePassword)
+		case e.ValueFrom.ClixSecret != "":
+			value, err = keychainGet(e.ValueFrom.ClixSecret)
+		case e.ValueFrom.Prompt != nil:
+			value, err = promptValue(stdin, stderr, e.Name, e.ValueFrom.Prompt)
+		case e.ValueFrom.GcpSecret != nil:
+			value, err = gcpSecret(e.ValueFrom.GcpSecret)
+		case e.ValueFrom.AwsSecret != "":
+			value, err = awsSecret(e.ValueFrom.AwsSecret)
+		case e.ValueFrom.SsmParameter != "":
+			value, err = ssmParameter(e.ValueFrom.SsmParameter)
+		case len(e.ValueFrom.Command) > 0:
+			value, err = commandValue(e.ValueFrom)
+		default:
+			err = fmt.Errorf("valueFrom has no source")
+		}
+		if err != nil {
+			switch e.ValueFrom.OnFailure {
+			case "", "error":
+				return fmt.Errorf("env %s: %w", e.Name, err)
+			case "empty":
+				fmt.Fprintf(stderr, "Warning: setting %s to \"\": %v\n", e.Name, err)
+			case "skip":
+				fmt.Fprintf(stderr, "Warning: not setting %s: %v\n", e.Name, err)
+				continue
+			default:
+				return fmt.Errorf("env %s: onFailure must be error, empty or skip, not %q", e.Name, e.ValueFrom.OnFailure)
+			}
+		}
+		registerSecret(value)
+		e.Value = value
+		env = append(env, e)
+	}
+	script.Env = env
+	return nil
+}
+
+// commandValue runs a host command, and returns its output without the trailing newline.
+func commandValue(source *EnvValueSource) (string, error) {
+	timeout := defaultCommandTimeout
+	if source.Timeout != "" {
+		d, err := time.ParseDuration(source.Timeout)
+		if err != nil || d <= 0 {
+			return "", fmt.Errorf("timeout must be a duration such as 5s, not %q", source.Timeout)
+		}
+		timeout = d
+	}
+	log(1, "Running %s for an env value", strings.Join(source.Command, " "))
+	out, err := hostCommandOutputTimeout(timeout, source.Command[0], source.Command[1:]...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// gcloudAccessToken prints an access token with the host's gcloud.
+func gcloudAccessToken(source *GcloudAccessTokenSource) (string, error) {
+	args := []string{"auth", "print-access-token"}
+	if source.Account != "" {
+		args = append(args, source.Account)
+	}
+	if source.ImpersonateServiceAccount != "" {
+		args = append(args, "--impersonate-service-account="+source.ImpersonateServiceAccount)
+	}
+	log(1, "Getting a gcloud access token: gcloud %s", strings.Join(args, " "))
+	out, err := hostCommandOutput("gcloud", args...)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", fmt.Errorf("gcloud auth print-access-token printed no token")
+	}
+	return token, nil
+}
+
+// onePasswordSecret reads a secret reference with the op CLI, which prompts for unlock (e.g. biometric)
+// when needed.
+func onePasswordSecret(ref string) (string, error) {
+	if !strings.HasPrefix(ref, "op://") {
+		return "", fmt.Errorf("onePassword must be a secret reference op://vault/item/field, not %q", ref)
+	}
+	log(1, "Reading %s with op", ref)
+	out, err := hostCommandOutput("op", "read", "--no-newline", ref)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// hostCommandOutput runs a command on the host and returns its output, or an error with its stderr.
+// The output is not logged, as it may be a secret.
+func hostCommandOutput(name string, args ...string) ([]byte, error) {
+	return hostCommandOutputTimeout(0, name, args...)
+}
+
+// hostCommandOutputTimeout is hostCommandOutput, killing the command if it runs for longer than the
+// timeout (unless it is 0).
+func hostCommandOutputTimeout(timeout time.Duration, name string, args ...string) ([]byte, error) {
+	cmd := execCommand(name, args...)
+	var stdout bytes.Buffer
+	var stderr strings.Builder
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w", name, err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	var err error
+	select {
+	case err = <-done:
+	case <-expired:
+		cmd.Process.Kill()
+		<-done
+		return nil, fmt.Errorf("%s timed out after %s", name, timeout)
+	}
+	out := stdout.Bytes()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s failed: %s", name, msg)
+		}
+		return nil, fmt.Errorf("%s failed: %w", name, err)
+	}
+	return out, nil
+}
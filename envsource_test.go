@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveEnvValues(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("MOCK_CALLS", calls)
+
+	script := Script{Env: []EnvVar{
+		{Name: "PROJECT", Value: "my-project"},
+		{Name: "CLOUDSDK_AUTH_ACCESS_TOKEN", ValueFrom: &EnvValueSource{GcloudAccessToken: &GcloudAccessTokenSource{
+			ImpersonateServiceAccount: "deployer@my-project.iam.gserviceaccount.com",
+		}}},
+	}}
+	if err := resolveEnvValues(nil, io.Discard, &script); err != nil {
+		t.Fatalf("resolveEnvValues failed: %v", err)
+	}
+	if got := script.Env[1].Value; got != "ya29.mock-token" {
+		t.Errorf("Expected the access token, got %q", got)
+	}
+	data, _ := os.ReadFile(calls)
+	if want := "gcloud auth print-access-token --impersonate-service-account=deployer@my-project.iam.gserviceaccount.com"; strings.TrimSpace(string(data)) != want {
+		t.Errorf("Expected %q, got %q", want, data)
+	}
+
+	t.Setenv("MOCK_BEHAVIOR", "gcloud_logged_out")
+	script = Script{Env: []EnvVar{{Name: "TOKEN", ValueFrom: &EnvValueSource{GcloudAccessToken: &GcloudAccessTokenSource{}}}}}
+	err := resolveEnvValues(nil, io.Discard, &script)
+	if err == nil || !strings.Contains(err.Error(), "env TOKEN") || !strings.Contains(err.Error(), "active account") {
+		t.Errorf("Expected gcloud's error, got %v", err)
+	}
+
+	script = Script{Env: []EnvVar{{Name: "TOKEN", Value: "x", ValueFrom: &EnvValueSource{}}}}
+	if err := resolveEnvValues(nil, io.Discard, &script); err == nil {
+		t.Errorf("Expected an error for both value and valueFrom")
+	}
+
+	script = Script{Env: []EnvVar{{Name: "GITHUB_TOKEN", ValueFrom: &EnvValueSource{OnePassword: "op://dev/github/token"}}}}
+	if err := resolveEnvValues(nil, io.Discard, &script); err != nil || script.Env[0].Value != "ghp_mock" {
+		t.Errorf("Expected the 1Password secret, got %q, %v", script.Env[0].Value, err)
+	}
+	script = Script{Env: []EnvVar{{Name: "GITHUB_TOKEN", ValueFrom: &EnvValueSource{OnePassword: "op://dev/missing/token"}}}}
+	if err := resolveEnvValues(nil, io.Discard, &script); err == nil || !strings.Contains(err.Error(), "could not find item") {
+		t.Errorf("Expected op's error, got %v", err)
+	}
+	script = Script{Env: []EnvVar{{Name: "GITHUB_TOKEN", ValueFrom: &EnvValueSource{OnePassword: "dev/github/token"}}}}
+	if err := resolveEnvValues(nil, io.Discard, &script); err == nil || !strings.Contains(err.Error(), "secret reference") {
+		t.Errorf("Expected an error for a value which is not a secret reference, got %v", err)
+	}
+}
+
+func TestCommandEnvValues(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	var source EnvValueSource
+	if err := json.Unmarshal([]byte(`{"command": "gh auth token", "timeout": "1s"}`), &source); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(source.Command, CommandLine{"gh", "auth", "token"}) {
+		t.Errorf("Expected the command to be split, got %q", source.Command)
+	}
+	script := Script{Env: []EnvVar{{Name: "GITHUB_TOKEN", ValueFrom: &source}}}
+	if err := resolveEnvValues(nil, io.Discard, &script); err != nil || script.Env[0].Value != "gho_mock" {
+		t.Errorf("Expected the command's output, got %q, %v", script.Env[0].Value, err)
+	}
+
+	t.Setenv("MOCK_BEHAVIOR", "gh_logged_out")
+	script = Script{Env: []EnvVar{{Name: "GITHUB_TOKEN", ValueFrom: &EnvValueSource{Command: CommandLine{"gh", "auth", "token"}}}}}
+	if err := resolveEnvValues(nil, io.Discard, &script); err == nil || !strings.Contains(err.Error(), "no oauth token") {
+		t.Errorf("Expected the command's error, got %v", err)
+	}
+
+	var stderr strings.Builder
+	script = Script{Env: []EnvVar{
+		{Name: "A", ValueFrom: &EnvValueSource{Command: CommandLine{"gh", "auth", "token"}, OnFailure: "empty"}},
+		{Name: "B", ValueFrom: &EnvValueSource{Command: CommandLine{"gh", "auth", "token"}, OnFailure: "skip"}},
+		{Name: "C", Value: "c"},
+	}}
+	if err := resolveEnvValues(nil, &stderr, &script); err != nil {
+		t.Fatalf("resolveEnvValues failed: %v", err)
+	}
+	if len(script.Env) != 2 || script.Env[0].Name != "A" || script.Env[0].Value != "" || script.Env[1].Name != "C" {
+		t.Errorf("Expected A to be empty and B to be skipped, got %+v", script.Env)
+	}
+	if !strings.Contains(stderr.String(), "not setting B") {
+		t.Errorf("Expected a warning, got %q", stderr.String())
+	}
+
+	t.Setenv("MOCK_BEHAVIOR", "gh_hangs")
+	script = Script{Env: []EnvVar{{Name: "GITHUB_TOKEN", ValueFrom: &EnvValueSource{Command: CommandLine{"gh", "auth", "token"}, Timeout: "100ms"}}}}
+	if err := resolveEnvValues(nil, io.Discard, &script); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// scriptLabel is the label of the containers which clix runs for a script, whose value is the script's
+// path, so that clix exec can find them.
+const scriptLabel = "clix.dev/script"
+
+// scriptLabelArg returns the label=value of the containers run for the script, by its real absolute path,
+// so that the script's links (such as installed tools) and relative paths find the same containers.
+func scriptLabelArg(scriptPath string) string {
+	if resolved, err := filepath.EvalSymlinks(scriptPath); err == nil {
+		scriptPath = resolved
+	}
+	if abs, err := filepath.Abs(scriptPath); err == nil {
+		scriptPath = abs
+	}
+	return scriptLabel + "=" + scriptPath
+}
+
+// runExec implements `clix exec <script>[:command] [--] <command> [args...]`, which runs a command in the
+// running container of the script, for tools which run long-lived servers. It is docker exec, with the container
+// and engine found through the script.
+func runExec(stdin io.Reader, stdout, stderr io.Writer, args []string) error {
+	if len(args) > 1 && args[1] == "--" {
+		args = append(args[:1:1], args[2:]...)
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("usage: clix exec <script>[:command] [--] <command> [args...]")
+	}
+	// The script is selected as for a run, so that the container is looked up on the engine it runs on
+	scriptPath, name := splitCommand(args[0])
+	command := args[1:]
+	script, err := readScript(scriptPath)
+	if err != nil {
+		return err
+	}
+	script.scriptPath = scriptPath
+	if err := selectCommand(&script, scriptPath, name); err != nil {
+		return err
+	}
+	if err := applyOverrides(&script); err != nil {
+		return err
+	}
+	if err := applyProfile(&script, selectedProfile()); err != nil {
+		return err
+	}
+	userConfig, err := loadUserConfig()
+	if err != nil {
+		return err
+	}
+	userConfig.applyDefaults(&script)
+	if dockerContext := os.Getenv("CLIX_DOCKER_CONTEXT"); dockerContext != "" {
+		script.DockerContext = dockerContext
+	}
+
+	var cli []string
+	switch sandbox := selectedSandbox(script); sandbox {
+	case "docker", "":
+		cli = dockerCLI(script)
+	case "podman", "nerdctl":
+		cli = []string{sandbox}
+	default:
+		return fmt.Errorf("clix exec needs a docker-compatible sandbox (docker, podman, nerdctl), not %s", sandbox)
+	}
+
+	container, err := runningContainer(cli, scriptPath)
+	if err != nil {
+		return err
+	}
+	execArgs := []string{"exec", "-i"}
+	if isTerminal(stdin) {
+		execArgs = append(execArgs, "-t")
+	}
+	execArgs = append(execArgs, container)
+	cmd := execCommand(cli[0], append(append(cli[1:], execArgs...), command...)...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := runTool(cmd); err != nil {
+		return fmt.Errorf("error running %s exec: %w", cli[0], err)
+	}
+	return nil
+}
+
+// runningContainer returns the ID of the script's running container. If the script has several, such
+// as servers started with different arguments, the most recently created is used.
+func runningContainer(cli []string, scriptPath string) (string, error) {
+	out, err := execCommand(cli[0], append(cli[1:], "ps", "--filter", "label="+scriptLabelArg(scriptPath), "--format", "{{.ID}}")...).Output()
+	if err != nil {
+		return "", fmt.Errorf("listing the containers of %s: %w", scriptPath, err)
+	}
+	containers := strings.Fields(string(out))
+	if len(containers) == 0 {
+		return "", fmt.Errorf("%s has no running container; start the tool with clix %s first", scriptPath, scriptPath)
+	}
+	if len(containers) > 1 {
+		log(1, "%s has %d running containers, using the newest, %s", scriptPath, len(containers), containers[0])
+	}
+	return containers[0], nil
+}
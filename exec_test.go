@@ -0,0 +1,96 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExec(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("CLIX_SANDBOX", "docker")
+	t.Setenv("CLIX_DOCKER_CONTEXT", "")
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("MOCK_CALLS", calls)
+
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "server.yaml")
+	os.WriteFile(scriptPath, []byte("image: example.com/server:1.0\n"), 0644)
+	var stdout, stderr bytes.Buffer
+	if err := run(strings.NewReader(""), &stdout, &stderr, []string{"clix", "exec", scriptPath, "--", "cat", "/etc/server.conf"}); err != nil {
+		t.Fatalf("exec failed: %v", err)
+	}
+	data, _ := os.ReadFile(calls)
+	want := "docker ps --filter label=" + scriptLabelArg(scriptPath) + " --format {{.ID}}\ndocker exec -i c0ffee cat /etc/server.conf\n"
+	if string(data) != want {
+		t.Errorf("Unexpected commands:\n%s\nwant:\n%s", data, want)
+	}
+
+	t.Setenv("MOCK_BEHAVIOR", "no_containers")
+	if err := runExec(strings.NewReader(""), &stdout, &stderr, []string{scriptPath, "sh"}); err == nil || !strings.Contains(err.Error(), "has no running container") {
+		t.Errorf("Expected an error without a running container, got %v", err)
+	}
+	t.Setenv("MOCK_BEHAVIOR", "")
+
+	// Scripts are selected as for a run: commands of the script share its containers, and a profile
+	// must exist
+	tools := filepath.Join(dir, "tools.yaml")
+	os.WriteFile(tools, []byte("image: google/cloud-sdk\ncommands:\n  bq:\n    entrypoint: bq\nprofiles:\n  ci:\n    image: google/cloud-sdk:slim\n"), 0644)
+	os.Remove(calls)
+	if err := runExec(strings.NewReader(""), &stdout, &stderr, []string{tools + ":bq", "--", "sh"}); err != nil {
+		t.Fatalf("exec of a command failed: %v", err)
+	}
+	if data, _ := os.ReadFile(calls); !strings.Contains(string(data), "label="+scriptLabelArg(tools)+" ") {
+		t.Errorf("Expected the containers of the script to be listed, got %q", data)
+	}
+	if err := runExec(strings.NewReader(""), &stdout, &stderr, []string{tools + ":gsutil", "sh"}); err == nil || !strings.Contains(err.Error(), `command "gsutil" not found`) {
+		t.Errorf("Expected an error for an unknown command, got %v", err)
+	}
+	t.Setenv("CLIX_PROFILE", "dev")
+	if err := runExec(strings.NewReader(""), &stdout, &stderr, []string{tools + ":bq", "sh"}); err == nil || !strings.Contains(err.Error(), `profile "dev" not found`) {
+		t.Errorf("Expected the profile to be applied, got %v", err)
+	}
+	t.Setenv("CLIX_PROFILE", "")
+
+	t.Setenv("CLIX_SANDBOX", "chroot")
+	if err := runExec(strings.NewReader(""), &stdout, &stderr, []string{scriptPath, "sh"}); err == nil || !strings.Contains(err.Error(), "docker-compatible sandbox") {
+		t.Errorf("Expected an error for a sandbox without containers, got %v", err)
+	}
+}
+
+func TestScriptLabel(t *testing.T) {
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "server.yaml")
+	os.WriteFile(scriptPath, []byte("image: alpine\n"), 0644)
+	link := filepath.Join(dir, "server")
+	if err := os.Symlink(scriptPath, link); err != nil {
+		t.Fatal(err)
+	}
+
+	// Containers run through a link are found from the script, and the other way round
+	args, err := buildDockerArgs(Script{Image: "alpine", scriptPath: link}, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(strings.Join(args, " "), "--label "+scriptLabelArg(scriptPath)+" ") {
+		t.Errorf("Expected the container to be labelled with the script, got %v", args)
+	}
+}
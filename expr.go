@@ -0,0 +1,373 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	celast "github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+)
+
+// Script fields which locate things on the host (mounts[].hostPath and sandboxPath, env[].value, entrypoint
+// and workdir) may be CEL expressions, evaluated with cel-go, for example `git.repoRoot(cwd)`,
+// `xdg.configDir("gcloud")` or `home() + "/.kube"`. A value is only treated as an expression if it parses
+// as CEL, uses only known functions and variables, and calls one of the functions below; anything else,
+// such as a literal path, is used as written.
+//
+// Env values may also embed expressions as ${expr}, for example `${git.branch()}-${git.headSha()}`.
+//
+// Override conditions (overrides[].when) are boolean expressions, for example
+// `os == "darwin" && arch == "arm64"`.
+
+// exprFunction implements an expression function of string arguments.
+type exprFunction struct {
+	// arities are the numbers of arguments the function accepts
+	arities []int
+	call    func(args []string) (string, error)
+}
+
+// exprFunctions are the functions available in expressions.
+var exprFunctions = map[string]exprFunction{
+	"git.repoRoot": {[]int{0, 1}, func(args []string) (string, error) {
+		dir, err := optionalArg(args, os.Getwd)
+		if err != nil {
+			return "", err
+		}
+		root, err := findGitRoot(dir)
+		if err != nil {
+			return "", fmt.Errorf("failed to find git root of %s: %w", dir, err)
+		}
+		return root, nil
+	}},
+	"git.headSha": gitFunction("rev-parse", "HEAD"),
+	"git.branch":  gitFunction("branch", "--show-current"),
+	"time.now":    noArgs(func() (string, error) { return exprNow().UTC().Format(time.RFC3339), nil }),
+	"time.unix":   noArgs(func() (string, error) { return strconv.FormatInt(exprNow().Unix(), 10), nil }),
+	"env": {[]int{1, 2}, func(args []string) (string, error) {
+		if v, ok := os.LookupEnv(args[0]); ok {
+			return v, nil
+		}
+		if len(args) == 2 {
+			return args[1], nil
+		}
+		return "", fmt.Errorf("environment variable %s is not set", args[0])
+	}},
+	"home":          noArgs(os.UserHomeDir),
+	"cwd":           noArgs(os.Getwd),
+	"scriptDir":     noArgs(func() (string, error) { return scriptDirectory(exprScriptPath) }),
+	"xdg.configDir": xdgFunction("XDG_CONFIG_HOME", ".config"),
+	"xdg.cacheDir":  xdgFunction("XDG_CACHE_HOME", ".cache"),
+	"xdg.dataDir":   xdgFunction("XDG_DATA_HOME", ".local/share"),
+}
+
+// exprNow is the clock of time.now() and time.unix().
+var exprNow = time.Now
+
+// exprScriptPath is the path of the script being run, for scriptDir().
+var exprScriptPath = "."
+
+// exprVariables are the variables available in expressions.
+var exprVariables = map[string]func() (string, error){
+	"cwd":  os.Getwd,
+	"os":   func() (string, error) { return runtime.GOOS, nil },
+	"arch": func() (string, error) { return runtime.GOARCH, nil },
+}
+
+func noArgs(f func() (string, error)) exprFunction {
+	return exprFunction{[]int{0}, func([]string) (string, error) { return f() }}
+}
+
+func optionalArg(args []string, def func() (string, error)) (string, error) {
+	if len(args) == 0 {
+		return def()
+	}
+	return args[0], nil
+}
+
+// gitFunction returns the function which runs git with args in a directory (the current working
+// directory by default), returning its output. git.branch() is empty for a detached HEAD.
+func gitFunction(args ...string) exprFunction {
+	return exprFunction{[]int{0, 1}, func(fnArgs []string) (string, error) {
+		dir, err := optionalArg(fnArgs, os.Getwd)
+		if err != nil {
+			return "", err
+		}
+		cmd := execCommand("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("git %s in %s: %w", strings.Join(args, " "), dir, err)
+		}
+		return strings.TrimSpace(string(out)), nil
+	}}
+}
+
+// xdgFunction returns the function for an XDG base directory, which takes an optional tool name to
+// return the tool's directory within it, e.g. xdg.configDir("gcloud").
+func xdgFunction(env, def string) exprFunction {
+	return exprFunction{[]int{0, 1}, func(args []string) (string, error) {
+		dir, err := xdgDir(env, def)
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(append([]string{dir}, args...)...), nil
+	}}
+}
+
+// xdgDir returns the XDG base directory named by env, or its default beneath home.
+func xdgDir(env, def string) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home dir: %w", err)
+	}
+	return filepath.Join(home, def), nil
+}
+
+// exprEnv is the CEL environment of expressions, with the standard library, exprFunctions and
+// exprVariables.
+var exprEnv = sync.OnceValues(func() (*cel.Env, error) {
+	var opts []cel.EnvOption
+	for name := range exprVariables {
+		opts = append(opts, cel.Variable(name, cel.StringType))
+	}
+	for name, f := range exprFunctions {
+		var overloads []cel.FunctionOpt
+		for _, arity := range f.arities {
+			argTypes := make([]*cel.Type, arity)
+			for i := range argTypes {
+				argTypes[i] = cel.StringType
+			}
+			id := fmt.Sprintf("%s_%d", strings.ReplaceAll(name, ".", "_"), arity)
+			overloads = append(overloads, cel.Overload(id, argTypes, cel.StringType, cel.FunctionBinding(exprBinding(name))))
+		}
+		opts = append(opts, cel.Function(name, overloads...))
+	}
+	return cel.NewEnv(opts...)
+})
+
+// exprBinding returns the CEL binding of the function name, which converts its arguments and result.
+func exprBinding(name string) func(args ...ref.Val) ref.Val {
+	return func(args ...ref.Val) ref.Val {
+		strs := make([]string, len(args))
+		for i, arg := range args {
+			strs[i] = string(arg.(types.String))
+		}
+		v, err := exprFunctions[name].call(strs)
+		if err != nil {
+			return types.WrapErr(err)
+		}
+		return types.String(v)
+	}
+}
+
+// compileExpression parses and checks s, reporting whether it is an expression: whether it parses,
+// uses only known functions and variables and, if needsCall, calls one of exprFunctions. Type errors,
+// such as the wrong number of arguments, are errors.
+func compileExpression(s string, needsCall bool) (*cel.Ast, bool, error) {
+	env, err := exprEnv()
+	if err != nil {
+		return nil, false, err
+	}
+	parsed, iss := env.Parse(s)
+	if iss.Err() != nil {
+		return nil, false, nil
+	}
+	known, call := exprNames(env, parsed.NativeRep().Expr())
+	if !known || (needsCall && !call) {
+		return nil, false, nil
+	}
+	checked, iss := env.Check(parsed)
+	if iss.Err() != nil {
+		return nil, true, iss.Err()
+	}
+	return checked, true, nil
+}
+
+// exprNames reports whether e uses only known functions and variables, and whether it calls one of
+// exprFunctions.
+func exprNames(env *cel.Env, e celast.Expr) (known, call bool) {
+	var children []celast.Expr
+	switch e.Kind() {
+	case celast.LiteralKind:
+		return true, false
+	case celast.IdentKind:
+		return exprVariables[e.AsIdent()] != nil, false
+	case celast.ListKind:
+		children = e.AsList().Elements()
+	case celast.CallKind:
+		c := e.AsCall()
+		name := c.FunctionName()
+		children = c.Args()
+		if c.IsMemberFunction() {
+			// Qualified functions, such as git.repoRoot(), parse as a call on the git identifier
+			if target := c.Target(); target.Kind() == celast.IdentKind && exprFunctions[target.AsIdent()+"."+name].call != nil {
+				name = target.AsIdent() + "." + name
+			} else {
+				children = append([]celast.Expr{target}, children...)
+			}
+		}
+		if exprFunctions[name].call != nil {
+			call = true
+		} else if _, isOperator := operators.FindReverse(name); !isOperator && !env.HasFunction(name) {
+			return false, false
+		}
+	default:
+		return false, false
+	}
+	for _, child := range children {
+		childKnown, childCall := exprNames(env, child)
+		if !childKnown {
+			return false, false
+		}
+		call = call || childCall
+	}
+	return true, call
+}
+
+// evalCompiled evaluates the checked expression, which must have the type want.
+func evalCompiled(checked *cel.Ast, want *cel.Type) (any, error) {
+	if !checked.OutputType().IsExactType(want) {
+		return nil, fmt.Errorf("expected %s, got %s", want, checked.OutputType())
+	}
+	env, err := exprEnv()
+	if err != nil {
+		return nil, err
+	}
+	program, err := env.Program(checked)
+	if err != nil {
+		return nil, err
+	}
+	vars := map[string]any{}
+	for name, f := range exprVariables {
+		vars[name] = func() ref.Val {
+			v, err := f()
+			if err != nil {
+				return types.WrapErr(err)
+			}
+			return types.String(v)
+		}
+	}
+	v, _, err := program.Eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return v.Value(), nil
+}
+
+// evalExpression evaluates s if it is an expression, reporting whether it was.
+func evalExpression(s string) (string, bool, error) {
+	checked, ok, err := compileExpression(s, true)
+	if !ok {
+		return s, false, nil
+	}
+	if err != nil {
+		return "", true, fmt.Errorf("evaluating %q: %w", s, err)
+	}
+	v, err := evalCompiled(checked, cel.StringType)
+	if err != nil {
+		return "", true, fmt.Errorf("evaluating %q: %w", s, err)
+	}
+	return v.(string), true, nil
+}
+
+// templateExpression matches the ${expr} expressions embedded in env values.
+var templateExpression = regexp.MustCompile(`\$\{([^{}]+)\}`)
+
+// evalTemplate evaluates s as an expression, or else the ${expr} expressions embedded in it. Embedded
+// expressions which don't parse or use unknown names, such as ${cacheDir}, are left as written.
+func evalTemplate(s string) (string, error) {
+	v, ok, err := evalExpression(s)
+	if ok || err != nil {
+		return v, err
+	}
+	s = templateExpression.ReplaceAllStringFunc(s, func(ref string) string {
+		if err != nil {
+			return ref
+		}
+		expr := templateExpression.FindStringSubmatch(ref)[1]
+		checked, ok, cerr := compileExpression(expr, false)
+		if !ok {
+			return ref
+		}
+		if cerr != nil {
+			err = fmt.Errorf("evaluating %q: %w", expr, cerr)
+			return ref
+		}
+		v, eerr := evalCompiled(checked, cel.StringType)
+		if eerr != nil {
+			err = fmt.Errorf("evaluating %q: %w", expr, eerr)
+			return ref
+		}
+		return v.(string)
+	})
+	return s, err
+}
+
+// evalCondition evaluates s as a boolean expression, such as `os == "darwin" && arch == "arm64"`.
+// Unlike evalExpression, s must be an expression.
+func evalCondition(s string) (bool, error) {
+	env, err := exprEnv()
+	if err != nil {
+		return false, err
+	}
+	if _, iss := env.Parse(s); iss.Err() != nil {
+		return false, fmt.Errorf("parsing %q: %w", s, iss.Err())
+	}
+	checked, ok, err := compileExpression(s, false)
+	if !ok {
+		return false, fmt.Errorf("%q uses an unknown function or variable", s)
+	}
+	if err != nil {
+		return false, fmt.Errorf("evaluating %q: %w", s, err)
+	}
+	v, err := evalCompiled(checked, cel.BoolType)
+	if err != nil {
+		return false, fmt.Errorf("evaluating %q: %w", s, err)
+	}
+	return v.(bool), nil
+}
+
+// evaluateScriptExpressions evaluates the expressions in the script's env values and entrypoint.
+// Mount host paths are evaluated by resolveMounts, as sandboxes resolve mounts themselves.
+func evaluateScriptExpressions(script *Script) error {
+	for i, e := range script.Env {
+		v, err := evalTemplate(e.Value)
+		if err != nil {
+			return fmt.Errorf("env %s: %w", e.Name, err)
+		}
+		script.Env[i].Value = v
+	}
+	v, _, err := evalExpression(script.Entrypoint)
+	if err != nil {
+		return fmt.Errorf("entrypoint: %w", err)
+	}
+	script.Entrypoint = v
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvalExpression(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("CLIX_TEST_PROJECT", "my-project")
+	cwd, _ := os.Getwd()
+	defer func(p string) { exprScriptPath = p }(exprScriptPath)
+	exprScriptPath = "/opt/scripts/tool.yaml"
+
+	for _, tc := range []struct {
+		in   string
+		want string
+	}{
+		{"home()", home},
+		{"xdg.configDir('gcloud')", filepath.Join(home, ".config", "gcloud")},
+		{"xdg.cacheDir('tool') + '/plugins'", filepath.Join(home, ".cache", "tool") + "/plugins"},
+		{"scriptDir()", "/opt/scripts"},
+		{`home() + "/.config/gcloud"`, home + "/.config/gcloud"},
+		{"xdg.configDir()", filepath.Join(home, ".config")},
+		{"env('CLIX_TEST_PROJECT')", "my-project"},
+		{"env('CLIX_TEST_UNSET', 'default')", "default"},
+		{"cwd() + '/' + ('a' + \"b\")", cwd + "/ab"},
+		// Expressions are CEL, with its standard functions and operators
+		{"home().endsWith('/') ? home() : home() + '/'", home + "/"},
+		{"env('CLIX_TEST_PROJECT').startsWith('my-') ? 'mine' : 'theirs'", "mine"},
+	} {
+		got, ok, err := evalExpression(tc.in)
+		if err != nil || !ok || got != tc.want {
+			t.Errorf("evalExpression(%q) = %q, %v, %v; want %q", tc.in, got, ok, err, tc.want)
+		}
+	}
+
+	// The fake git prints its arguments
+	t.Setenv("MOCK_BEHAVIOR", "echo_args")
+	if got, ok, err := evalExpression("git.repoRoot(cwd)"); err != nil || !ok || got != "args: [rev-parse --show-toplevel]" {
+		t.Errorf("evalExpression(git.repoRoot(cwd)) = %q, %v, %v", got, ok, err)
+	}
+
+	// Values which are not expressions are used as written
+	for _, in := range []string{"/tmp", "~/.config", "${cacheDir}/go", "data", "us-central1", "true", "unknown(1)", "cwd", ""} {
+		if got, ok, err := evalExpression(in); err != nil || ok || got != in {
+			t.Errorf("evalExpression(%q) = %q, %v, %v; want it unchanged", in, got, ok, err)
+		}
+	}
+
+	if _, _, err := evalExpression("env('CLIX_TEST_UNSET')"); err == nil {
+		t.Errorf("Expected an error for an unset variable")
+	}
+	if _, _, err := evalExpression("home('x')"); err == nil {
+		t.Errorf("Expected an error for an unexpected argument")
+	}
+	if _, _, err := evalExpression("xdg.dataDir('a', 'b')"); err == nil {
+		t.Errorf("Expected an error for too many arguments")
+	}
+	if _, _, err := evalExpression("size(home())"); err == nil || !strings.Contains(err.Error(), "expected string") {
+		t.Errorf("Expected an error for an expression which isn't a string, got %v", err)
+	}
+}
+
+func TestEvalTemplate(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	defer func(f func() time.Time) { exprNow = f }(exprNow)
+	exprNow = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+	t.Setenv("CLIX_TEST_PROJECT", "my-project")
+
+	for _, tc := range []struct {
+		in   string
+		want string
+	}{
+		{"git.headSha()", "0123456789abcdef0123456789abcdef01234567"},
+		{"${git.branch()}-${time.unix()}", "main-1772366400"},
+		{"built ${time.now()} for ${env('CLIX_TEST_PROJECT')}", "built 2026-03-01T12:00:00Z for my-project"},
+		{"${cacheDir}/go", "${cacheDir}/go"},
+		{"${unknown()} ${not an expression}", "${unknown()} ${not an expression}"},
+		{"plain", "plain"},
+	} {
+		got, err := evalTemplate(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("evalTemplate(%q) = %q, %v; want %q", tc.in, got, err, tc.want)
+		}
+	}
+
+	if _, err := evalTemplate("${env('CLIX_TEST_UNSET')}"); err == nil {
+		t.Errorf("Expected an error for an unset variable")
+	}
+}
+
+func TestEvalCondition(t *testing.T) {
+	t.Setenv("CLIX_TEST_PROJECT", "my-project")
+
+	for _, tc := range []struct {
+		in   string
+		want bool
+	}{
+		{"os == '" + runtime.GOOS + "'", true},
+		{"os != '" + runtime.GOOS + "'", false},
+		{"os == 'plan9' || arch == '" + runtime.GOARCH + "'", true},
+		{"os == '" + runtime.GOOS + "' && arch == 'mips'", false},
+		{"!(os == 'plan9')", true},
+		{"env('CLIX_TEST_PROJECT') == 'my-' + 'project'", true},
+		// The right-hand side is not evaluated when the result is already known
+		{"os == 'plan9' && env('CLIX_TEST_UNSET') == 'x'", false},
+	} {
+		got, err := evalCondition(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("evalCondition(%q) = %v, %v; want %v", tc.in, got, err, tc.want)
+		}
+	}
+
+	for _, in := range []string{"os", "os == ", "platform == 'linux'", "!os", "os == '" + runtime.GOOS + "' && 'x'"} {
+		if _, err := evalCondition(in); err == nil {
+			t.Errorf("evalCondition(%q): expected an error", in)
+		}
+	}
+}
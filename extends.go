@@ -0,0 +1,233 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// expandExtends merges the scripts named by the script's extends field (a path or URL, or a list of them)
+// into the script, returning the merged script as JSON. Bases are merged in order, each after its own
+// bases, and the script itself last, so later definitions win. Base scripts fetched from URLs must be
+// signed by a trusted key, whether or not CLIX_VERIFY_SIGNATURES is set.
+func expandExtends(scriptPath string, data []byte) ([]byte, error) {
+	// Scripts which don't extend others are returned unchanged, so that their approvals still match
+	var header struct {
+		Extends any `json:"extends"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil || header.Extends == nil {
+		return data, nil
+	}
+	merged, err := loadExtended(scriptPath, data, []string{scriptPath})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(merged)
+}
+
+func loadExtended(source string, data []byte, chain []string) (map[string]any, error) {
+	var script map[string]any
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", source, err)
+	}
+	if script == nil {
+		script = map[string]any{}
+	}
+	var bases []string
+	switch extends := script["extends"].(type) {
+	case nil:
+		return script, nil
+	case string:
+		bases = []string{extends}
+	case []any:
+		for _, base := range extends {
+			s, ok := base.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s: extends must be a path or URL, or a list of them", source)
+			}
+			bases = append(bases, s)
+		}
+	default:
+		return nil, fmt.Errorf("%s: extends must be a path or URL, or a list of them", source)
+	}
+	delete(script, "extends")
+
+	merged := map[string]any{}
+	for _, base := range bases {
+		location, err := resolveExtendsLocation(source, base)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range chain {
+			if s == location {
+				return nil, fmt.Errorf("extends cycle: %s -> %s", strings.Join(chain, " -> "), location)
+			}
+		}
+		baseData, err := readExtendsBase(location)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s (extended by %s): %w", location, source, err)
+		}
+		baseScript, err := loadExtended(location, baseData, append(chain, location))
+		if err != nil {
+			return nil, err
+		}
+		log(1, "Script %s extends %s", source, location)
+		merged = mergeScriptMaps(merged, baseScript)
+	}
+	return mergeScriptMaps(merged, script), nil
+}
+
+// resolveExtendsLocation resolves base relative to the script which extends it.
+func resolveExtendsLocation(source, base string) (string, error) {
+	if strings.Contains(source, "://") {
+		sourceURL, err := url.Parse(source)
+		if err != nil {
+			return "", err
+		}
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return "", fmt.Errorf("invalid extends %q: %w", base, err)
+		}
+		return sourceURL.ResolveReference(baseURL).String(), nil
+	}
+	if strings.Contains(base, "://") {
+		return base, nil
+	}
+	if strings.HasPrefix(base, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user home dir: %w", err)
+		}
+		return filepath.Join(home, base[2:]), nil
+	}
+	return resolveScriptPath(source, base)
+}
+
+// readExtendsBase reads a base script, verifying the signature of scripts fetched from URLs.
+func readExtendsBase(location string) ([]byte, error) {
+	if !strings.Contains(location, "://") {
+		data, err := os.ReadFile(location)
+		if err != nil {
+			return nil, err
+		}
+		if signatureRequired() {
+			if err := verifyScriptSignature(location, data); err != nil {
+				return nil, err
+			}
+		}
+		return data, nil
+	}
+
+	data, _, err := fetchSignedScript(location)
+	return data, err
+}
+
+// fetchSignedScript fetches the script at url and its signature, which must be from a trusted key.
+func fetchSignedScript(url string) ([]byte, []byte, error) {
+	data, err := fetchURL(url)
+	if err != nil {
+		return nil, nil, err
+	}
+	sigData, err := fetchURL(signaturePath(url))
+	if err != nil {
+		return nil, nil, fmt.Errorf("remote scripts must be signed: %w", err)
+	}
+	if err := verifySignature(url, data, sigData); err != nil {
+		return nil, nil, err
+	}
+	return data, sigData, nil
+}
+
+func fetchURL(url string) ([]byte, error) {
+	if err := checkOnline(url); err != nil {
+		return nil, err
+	}
+	log(1, "Fetching %s", url)
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// mergeScriptMaps merges override into base: maps are merged recursively, env and mounts lists are
+// merged by variable name and sandbox path, and other values are replaced.
+func mergeScriptMaps(base, override map[string]any) map[string]any {
+	merged := make(map[string]any, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		baseList, baseIsList := merged[k].([]any)
+		overrideList, overrideIsList := v.([]any)
+		baseMap, baseIsMap := merged[k].(map[string]any)
+		overrideMap, overrideIsMap := v.(map[string]any)
+		switch {
+		case k == "env" && baseIsList && overrideIsList:
+			merged[k] = mergeLists(baseList, overrideList, "name")
+		case k == "mounts" && baseIsList && overrideIsList:
+			merged[k] = mergeLists(baseList, overrideList, "sandboxPath", "hostPath")
+		case baseIsMap && overrideIsMap:
+			merged[k] = mergeScriptMaps(baseMap, overrideMap)
+		default:
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// mergeLists appends override to base, replacing base items with the same key: the first of keys set in the item.
+func mergeLists(base, override []any, keys ...string) []any {
+	key := func(item any) string {
+		if m, ok := item.(map[string]any); ok {
+			for _, k := range keys {
+				if s, ok := m[k].(string); ok && s != "" {
+					return s
+				}
+			}
+		}
+		return ""
+	}
+	merged := append([]any{}, base...)
+	for _, item := range override {
+		replaced := false
+		if k := key(item); k != "" {
+			for i := range merged {
+				if key(merged[i]) == k {
+					merged[i] = item
+					replaced = true
+					break
+				}
+			}
+		}
+		if !replaced {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
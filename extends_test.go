@@ -0,0 +1,113 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestExpandExtends(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	write("presets/credentials.yaml", `
+mounts:
+- hostPath: ~/.config/gcloud
+  sandboxPath: /root/.config/gcloud
+env:
+- name: CLOUDSDK_CORE_PROJECT
+  value: shared
+`)
+	write("presets/base.yaml", `
+extends: credentials.yaml
+network: none
+env:
+- name: REGION
+  value: us-central1
+`)
+	scriptPath := write("tool", `
+extends: [presets/base.yaml]
+image: tool:1.0
+network: ""
+env:
+- name: CLOUDSDK_CORE_PROJECT
+  value: mine
+mounts:
+- hostPath: git.repoRoot(cwd)
+`)
+	data, _ := os.ReadFile(scriptPath)
+	merged, err := expandExtends(scriptPath, data)
+	if err != nil {
+		t.Fatalf("expandExtends failed: %v", err)
+	}
+	var script Script
+	if err := yaml.Unmarshal(merged, &script); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if script.Image != "tool:1.0" || script.Network.Mode != "" {
+		t.Errorf("Expected the script to override its bases, got %+v", script)
+	}
+	if len(script.Env) != 2 || script.Env[0] != (EnvVar{Name: "CLOUDSDK_CORE_PROJECT", Value: "mine"}) || script.Env[1].Name != "REGION" {
+		t.Errorf("Unexpected env %+v", script.Env)
+	}
+	if len(script.Mounts) != 2 || script.Mounts[0].SandboxPath != "/root/.config/gcloud" || script.Mounts[1].HostPath != "git.repoRoot(cwd)" {
+		t.Errorf("Unexpected mounts %+v", script.Mounts)
+	}
+
+	// Scripts without extends are unchanged
+	plain := []byte("image: tool\n")
+	if got, err := expandExtends(scriptPath, plain); err != nil || string(got) != string(plain) {
+		t.Errorf("Expected the script to be unchanged, got %q, %v", got, err)
+	}
+
+	write("presets/a.yaml", "extends: b.yaml\n")
+	write("presets/b.yaml", "extends: a.yaml\n")
+	cyclic := write("cyclic", "extends: presets/a.yaml\n")
+	data, _ = os.ReadFile(cyclic)
+	if _, err := expandExtends(cyclic, data); err == nil || !strings.Contains(err.Error(), "extends cycle") {
+		t.Errorf("Expected a cycle error, got %v", err)
+	}
+}
+
+func TestExpandExtendsUnsignedURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".sig") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("image: tool\n"))
+	}))
+	defer server.Close()
+
+	data := []byte("extends: " + server.URL + "/base.yaml\n")
+	if _, err := expandExtends(filepath.Join(t.TempDir(), "tool"), data); err == nil || !strings.Contains(err.Error(), "must be signed") {
+		t.Errorf("Expected unsigned remote scripts to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	yamlv3 "go.yaml.in/yaml/v3"
+)
+
+// leadingFields are the fields which come first in a formatted script, followed by its section (image,
+// go etc) and then the other fields in the order they are declared in Script.
+var leadingFields = []string{"apiVersion", "kind", "extends"}
+
+// runFmt implements `clix fmt [--check] <script>...`, which rewrites scripts in the canonical format.
+// With --check, the scripts which are not formatted are listed and nothing is written.
+func runFmt(stdout, stderr io.Writer, args []string) error {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	check := flags.Bool("check", false, "list the scripts which are not formatted, failing if there are any, without rewriting them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: clix fmt [--check] <script>...")
+	}
+
+	unformatted := 0
+	for _, scriptPath := range flags.Args() {
+		data, err := os.ReadFile(scriptPath)
+		if err != nil {
+			return fmt.Errorf("error reading script file: %w", err)
+		}
+		formatted, err := formatScript(data)
+		if err != nil {
+			return fmt.Errorf("formatting %s: %w", scriptPath, err)
+		}
+		if bytes.Equal(data, formatted) {
+			continue
+		}
+		if *check {
+			fmt.Fprintln(stdout, scriptPath)
+			unformatted++
+			continue
+		}
+		if err := os.WriteFile(scriptPath, formatted, 0644); err != nil {
+			return err
+		}
+		if _, err := os.Stat(signaturePath(scriptPath)); err == nil {
+			fmt.Fprintf(stderr, "Warning: the signature of %s is no longer valid; sign it again with clix sign\n", scriptPath)
+		}
+	}
+	if unformatted > 0 {
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+// formatScript returns the script with its fields in the canonical order, indented by two spaces with
+// list items at the indentation of their field.
+// Comments, the shebang line and blank lines between top-level fields are kept.
+func formatScript(data []byte) ([]byte, error) {
+	var shebang []byte
+	if bytes.HasPrefix(data, []byte("#!")) {
+		end := bytes.IndexByte(data, '\n') + 1
+		if end == 0 {
+			end = len(data)
+		}
+		shebang, data = data[:end], data[end:]
+	}
+
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("script is empty")
+	}
+	root := doc.Content[0]
+	spaced := spacedKeys(root, strings.Split(string(data), "\n"))
+	if root.Kind == yamlv3.MappingNode && len(root.Content) > 0 {
+		// The comment and blank line at the top of the script belong to the script, not its first field
+		first := root.Content[0]
+		header, spacedTop := first.HeadComment, spaced[first.Value]
+		first.HeadComment, spaced[first.Value] = "", false
+		sortFields(root, reflect.TypeOf(Script{}))
+		first = root.Content[0]
+		first.HeadComment = strings.TrimSpace(header + "\n" + first.HeadComment)
+		spaced[first.Value] = spaced[first.Value] || spacedTop
+	}
+
+	var buf bytes.Buffer
+	enc := yamlv3.NewEncoder(&buf)
+	enc.SetIndent(2)
+	enc.CompactSeqIndent()
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	out.Write(shebang)
+	lines := strings.SplitAfter(buf.String(), "\n")
+	for i, line := range lines {
+		// A blank line goes before the comments of the field, as it did in the script
+		if key, _, ok := strings.Cut(line, ":"); ok && spaced[key] && !strings.HasPrefix(line, " ") {
+			start := out.Len()
+			for j := i - 1; j >= 0 && strings.HasPrefix(lines[j], "#"); j-- {
+				start -= len(lines[j])
+			}
+			if start > 0 && !bytes.HasSuffix(out.Bytes()[:start], []byte("\n\n")) {
+				rest := slices.Clone(out.Bytes()[start:])
+				out.Truncate(start)
+				out.WriteString("\n")
+				out.Write(rest)
+			}
+		}
+		out.WriteString(line)
+	}
+	return out.Bytes(), nil
+}
+
+// spacedKeys returns the top-level keys of the script which are preceded by a blank line, before their
+// comments.
+func spacedKeys(root *yamlv3.Node, lines []string) map[string]bool {
+	spaced := map[string]bool{}
+	if root.Kind != yamlv3.MappingNode {
+		return spaced
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i]
+		line := key.Line - 1
+		if key.HeadComment != "" {
+			line -= strings.Count(key.HeadComment, "\n") + 1
+		}
+		if line > 0 && line <= len(lines) && strings.TrimSpace(lines[line-1]) == "" {
+			spaced[key.Value] = true
+		}
+	}
+	return spaced
+}
+
+// sortFields sorts the fields of mappings in the node in the order they are declared in t, the type the
+// node is parsed into, keeping unknown fields in their order after the known ones. The keys of maps, such
+// as commands and profiles, are written as they are.
+func sortFields(node *yamlv3.Node, t reflect.Type) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case node.Kind == yamlv3.MappingNode && t.Kind() == reflect.Struct:
+		order, types := structFields(t)
+		if t == reflect.TypeOf(Script{}) {
+			order = slices.Concat(leadingFields, scriptSections, order)
+		}
+		index := func(key string) int {
+			if i := slices.Index(order, key); i >= 0 {
+				return i
+			}
+			return len(order)
+		}
+		pairs := make([][2]*yamlv3.Node, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			pairs = append(pairs, [2]*yamlv3.Node{node.Content[i], node.Content[i+1]})
+		}
+		slices.SortStableFunc(pairs, func(a, b [2]*yamlv3.Node) int {
+			return index(a[0].Value) - index(b[0].Value)
+		})
+		node.Content = node.Content[:0]
+		for _, pair := range pairs {
+			node.Content = append(node.Content, pair[0], pair[1])
+			if fieldType, ok := types[pair[0].Value]; ok {
+				sortFields(pair[1], fieldType)
+			}
+		}
+	case node.Kind == yamlv3.MappingNode && t.Kind() == reflect.Map:
+		for i := 1; i < len(node.Content); i += 2 {
+			sortFields(node.Content[i], t.Elem())
+		}
+	case node.Kind == yamlv3.SequenceNode && t.Kind() == reflect.Slice:
+		for _, item := range node.Content {
+			sortFields(item, t.Elem())
+		}
+	}
+}
+
+// structFields returns the json names of the struct's fields in order, flattening embedded structs as
+// encoding/json does, with the type of each.
+func structFields(t reflect.Type) ([]string, map[string]reflect.Type) {
+	var names []string
+	types := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" && field.Anonymous {
+			embedded, embeddedTypes := structFields(field.Type)
+			names = append(names, embedded...)
+			for n, ft := range embeddedTypes {
+				types[n] = ft
+			}
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+		types[name] = field.Type
+	}
+	return names, types
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatScript(t *testing.T) {
+	input := `#!/usr/bin/env clix
+# Lints the repository
+kind: Script
+apiVersion: clix.dev/v1alpha1
+
+mounts:
+    - sandboxPath: /src   # the repository
+      hostPath: ${git.repoRoot(cwd)}
+image: "golangci/golangci-lint:v1.59"
+
+# The tool's settings
+env:
+      - value: "1"
+        name: LINT_STRICT
+commands:
+  fix:
+    entrypoint: golangci-lint
+    image: other
+x-team: tools
+args: {prepend: [run]}
+`
+	want := `#!/usr/bin/env clix
+# Lints the repository
+apiVersion: clix.dev/v1alpha1
+kind: Script
+image: "golangci/golangci-lint:v1.59"
+
+mounts:
+- hostPath: ${git.repoRoot(cwd)}
+  sandboxPath: /src # the repository
+
+# The tool's settings
+env:
+- name: LINT_STRICT
+  value: "1"
+args: {prepend: [run]}
+commands:
+  fix:
+    entrypoint: golangci-lint
+    image: other
+x-team: tools
+`
+	got, err := formatScript([]byte(input))
+	if err != nil || string(got) != want {
+		t.Fatalf("formatScript = %v:\n%s\nwant:\n%s", err, got, want)
+	}
+	if again, err := formatScript(got); err != nil || !bytes.Equal(again, got) {
+		t.Errorf("Expected formatting to be idempotent, got %v:\n%s", err, again)
+	}
+}
+
+func TestRunFmt(t *testing.T) {
+	dir := t.TempDir()
+	formatted := filepath.Join(dir, "formatted")
+	os.WriteFile(formatted, []byte("apiVersion: clix.dev/v1alpha1\nkind: Script\nimage: alpine\n"), 0644)
+	unformatted := filepath.Join(dir, "unformatted")
+	os.WriteFile(unformatted, []byte("image: alpine\nkind: Script\napiVersion: clix.dev/v1alpha1\n"), 0755)
+
+	var stdout, stderr bytes.Buffer
+	err := run(nil, &stdout, &stderr, []string{"clix", "fmt", "--check", formatted, unformatted})
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) || stdout.String() != unformatted+"\n" {
+		t.Errorf("Expected --check to list the unformatted script, got %v: %s", err, stdout.String())
+	}
+
+	stdout.Reset()
+	if err := runFmt(&stdout, &stderr, []string{formatted, unformatted}); err != nil || stdout.Len() != 0 {
+		t.Fatalf("fmt failed: %v: %s", err, stdout.String())
+	}
+	if data, _ := os.ReadFile(unformatted); string(data) != "apiVersion: clix.dev/v1alpha1\nkind: Script\nimage: alpine\n" {
+		t.Errorf("Unexpected formatted script:\n%s", data)
+	}
+	if info, _ := os.Stat(unformatted); info.Mode().Perm() != 0755 {
+		t.Errorf("Expected the script to stay executable, got %v", info.Mode())
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// GcpSecretSource is a secret version in Google Cloud Secret Manager.
+type GcpSecretSource struct {
+	// Project is the project of the secret, defaulting to GOOGLE_CLOUD_PROJECT
+	Project string `json:"project,omitempty"`
+	// Name is the name of the secret
+	Name string `json:"name"`
+	// Version is the version of the secret, defaulting to latest
+	Version string `json:"version,omitempty"`
+}
+
+// secretManagerURL is the endpoint of the Secret Manager API.
+var secretManagerURL = "https://secretmanager.googleapis.com/v1"
+
+// gcpSecret accesses a Secret Manager secret version with the host's application default credentials.
+func gcpSecret(source *GcpSecretSource) (string, error) {
+	project := source.Project
+	if project == "" {
+		project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	if project == "" || source.Name == "" {
+		return "", fmt.Errorf("gcpSecret needs a name and a project (or GOOGLE_CLOUD_PROJECT)")
+	}
+	version := source.Version
+	if version == "" {
+		version = "latest"
+	}
+	resource := fmt.Sprintf("projects/%s/secrets/%s/versions/%s", project, source.Name, version)
+
+	log(1, "Accessing secret %s with application default credentials", resource)
+	out, err := hostCommandOutput("gcloud", "auth", "application-default", "print-access-token")
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest("GET", secretManagerURL+"/"+resource+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(out)))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("accessing %s: %w", resource, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("accessing %s: %s", resource, resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("accessing %s: %s: %s", resource, resp.Status, body.Error.Message)
+	}
+	data, err := base64.StdEncoding.DecodeString(body.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("accessing %s: decoding payload: %w", resource, err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestGcpSecret(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer ya29.mock-adc-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": {"message": "unauthenticated"}}`))
+			return
+		}
+		if r.URL.Path != "/projects/platform/secrets/tool-token/versions/latest:access" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"message": "Secret [projects/123/secrets/other] not found or has no versions."}}`))
+			return
+		}
+		w.Write([]byte(`{"name": "projects/123/secrets/tool-token/versions/3", "payload": {"data": "czNjcjN0"}}`))
+	}))
+	defer server.Close()
+	defer func(u string) { secretManagerURL = u }(secretManagerURL)
+	secretManagerURL = server.URL
+
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	got, err := gcpSecret(&GcpSecretSource{Project: "platform", Name: "tool-token"})
+	if err != nil || got != "s3cr3t" {
+		t.Errorf("Expected the secret, got %q, %v", got, err)
+	}
+
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "platform")
+	if _, err := gcpSecret(&GcpSecretSource{Name: "other", Version: "2"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected Secret Manager's error, got %v", err)
+	}
+
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	if _, err := gcpSecret(&GcpSecretSource{Name: "tool-token"}); err == nil {
+		t.Errorf("Expected an error without a project")
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// HooksConfig declares commands run before and after the tool.
+type HooksConfig struct {
+	// PreRun are run in order before the tool; if one fails, the tool is not run
+	PreRun []Hook `json:"preRun,omitempty"`
+	// PostRun are run in order after the tool, even if it failed
+	PostRun []Hook `json:"postRun,omitempty"`
+}
+
+// Hook is a command run before or after the tool, e.g. to refresh credentials.
+type Hook struct {
+	// Command is the command and its arguments
+	Command []string `json:"command"`
+	// Sandbox runs the command in the script's image, with its mounts, rather than on the host
+	Sandbox bool `json:"sandbox,omitempty"`
+}
+
+// executeWithHooks runs the script's preRun hooks, the tool and then its postRun hooks.
+func executeWithHooks(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
+	if script.Hooks == nil {
+		return execute(stdin, stdout, stderr, script, args)
+	}
+	if err := runHooks(stderr, script, "preRun", script.Hooks.PreRun); err != nil {
+		return err
+	}
+	runErr := execute(stdin, stdout, stderr, script, args)
+	if err := runHooks(stderr, script, "postRun", script.Hooks.PostRun); err != nil {
+		if runErr != nil {
+			return fmt.Errorf("%w (and %v)", runErr, err)
+		}
+		return err
+	}
+	return runErr
+}
+
+// runHooks runs the hooks in order, stopping at the first failure. Hooks do not read the tool's stdin,
+// and write their output to stderr, so that they don't interfere with the tool's output.
+func runHooks(stderr io.Writer, script Script, phase string, hooks []Hook) error {
+	for i, hook := range hooks {
+		if len(hook.Command) == 0 {
+			return fmt.Errorf("%s hook %d has no command", phase, i)
+		}
+		log(1, "Running %s hook: %s", phase, strings.Join(hook.Command, " "))
+		if dryRunEnabled() && !hook.Sandbox {
+			fmt.Fprintf(stderr, "# %s hook\n%s\n", phase, redactSecrets(displayCommand(hook.Command)))
+			continue
+		}
+
+		var err error
+		if hook.Sandbox {
+			if script.Image == "" {
+				return fmt.Errorf("%s hook %s: sandbox hooks require the script to have an image", phase, hook.Command[0])
+			}
+			hookScript := script
+			hookScript.Entrypoint = ""
+			hookScript.Hooks = nil
+			err = execute(strings.NewReader(""), stderr, stderr, hookScript, hook.Command)
+		} else {
+			cmd := execCommand(hook.Command[0], hook.Command[1:]...)
+			cmd.Stdout = stderr
+			cmd.Stderr = stderr
+			err = cmd.Run()
+		}
+		if err != nil {
+			return fmt.Errorf("%s hook %s failed: %w", phase, hook.Command[0], err)
+		}
+	}
+	return nil
+}
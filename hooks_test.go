@@ -0,0 +1,69 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecuteWithHooks(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("CLIX_SANDBOX", "docker")
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("MOCK_CALLS", calls)
+
+	script := Script{
+		Image:      "tool:1.0",
+		Entrypoint: "tool",
+		Hooks: &HooksConfig{
+			PreRun:  []Hook{{Command: []string{"refresh-credentials", "--quiet"}}},
+			PostRun: []Hook{{Command: []string{"rm", "-rf", "/tmp/out"}, Sandbox: true}},
+		},
+	}
+	if err := executeWithHooks(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, script, []string{"build"}); err != nil {
+		t.Fatalf("executeWithHooks failed: %v", err)
+	}
+	data, _ := os.ReadFile(calls)
+	got := string(data)
+	pre := strings.Index(got, "refresh-credentials --quiet")
+	tool := strings.Index(got, "--entrypoint tool tool:1.0 build")
+	post := strings.Index(got, "tool:1.0 rm -rf /tmp/out")
+	if pre < 0 || tool < pre || post < tool {
+		t.Errorf("Expected the preRun hook, the tool and then the postRun hook, got:\n%s", got)
+	}
+
+	// A failing preRun hook stops the run
+	os.Remove(calls)
+	t.Setenv("MOCK_BEHAVIOR", "exit_3")
+	err := executeWithHooks(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, script, []string{"build"})
+	if err == nil || !strings.Contains(err.Error(), "preRun hook refresh-credentials failed") {
+		t.Errorf("Expected the preRun hook to fail, got %v", err)
+	}
+	data, _ = os.ReadFile(calls)
+	if strings.Contains(string(data), "tool:1.0") {
+		t.Errorf("Expected the tool not to run, got:\n%s", data)
+	}
+
+	script.Image = ""
+	if err := runHooks(&bytes.Buffer{}, script, "postRun", script.Hooks.PostRun); err == nil {
+		t.Errorf("Expected an error for a sandbox hook without an image")
+	}
+}
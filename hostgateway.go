@@ -0,0 +1,74 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// hostGatewayAlias is the name by which containers reach the host.
+const hostGatewayAlias = "host.docker.internal"
+
+// appleContainerGateway is the host's address on apple/container's default network.
+const appleContainerGateway = "192.168.64.1"
+
+// hostGateway returns the address by which tools in the sandbox reach services on the host: the
+// container engines' alias, or the loopback address for sandboxes which share the host's network.
+func hostGateway(sandboxType string) string {
+	switch sandboxType {
+	case "chroot", "proot", "namespace", "nsjail", "runc", "seatbelt", "landlock":
+		return "127.0.0.1"
+	case "apple-container":
+		return appleContainerGateway
+	}
+	return hostGatewayAlias
+}
+
+// expandHostGateway replaces ${hostGateway} in the script's env values, command, entrypoint and args
+// with the host's address in the selected sandbox.
+func expandHostGateway(script *Script) {
+	gateway := hostGateway(selectedSandbox(*script))
+	expand := func(s *string) {
+		if strings.Contains(*s, "${hostGateway}") {
+			*s = strings.ReplaceAll(*s, "${hostGateway}", gateway)
+			script.usesHostGateway = true
+		}
+	}
+	for i := range script.Env {
+		expand(&script.Env[i].Value)
+	}
+	for i := range script.Command {
+		expand(&script.Command[i])
+	}
+	expand(&script.Entrypoint)
+	if script.Args != nil {
+		for i := range script.Args.Prepend {
+			expand(&script.Args.Prepend[i])
+		}
+		for i := range script.Args.Append {
+			expand(&script.Args.Append[i])
+		}
+	}
+}
+
+// hostGatewayArgs returns the arguments which make host.docker.internal resolve to the host, for
+// container engines which don't always define it (docker on Linux, nerdctl); podman always does.
+func hostGatewayArgs(cli []string) []string {
+	if filepath.Base(cli[len(cli)-1]) == "podman" {
+		return nil
+	}
+	return []string{"--add-host", hostGatewayAlias + ":host-gateway"}
+}
@@ -0,0 +1,55 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandHostGateway(t *testing.T) {
+	for _, tc := range []struct {
+		sandbox string
+		want    string
+	}{
+		{"docker", "host.docker.internal"},
+		{"podman", "host.docker.internal"},
+		{"apple-container", "192.168.64.1"},
+		{"namespace", "127.0.0.1"},
+	} {
+		t.Setenv("CLIX_SANDBOX", tc.sandbox)
+		script := Script{Image: "migrate", Env: []EnvVar{{Name: "DATABASE_URL", Value: "postgres://${hostGateway}:5432/app"}}}
+		expandHostGateway(&script)
+		if got := script.Env[0].Value; got != "postgres://"+tc.want+":5432/app" || !script.usesHostGateway {
+			t.Errorf("With %s, got %q", tc.sandbox, got)
+		}
+	}
+
+	t.Setenv("CLIX_SANDBOX", "docker")
+	script := Script{Image: "migrate", Args: &ArgsConfig{Append: []string{"--host=${hostGateway}"}}}
+	expandHostGateway(&script)
+	for _, tc := range []struct {
+		cli  string
+		want bool
+	}{{"docker", true}, {"podman", false}} {
+		cmdArgs, err := buildContainerArgs([]string{tc.cli}, script, nil, false)
+		if err != nil {
+			t.Fatalf("buildContainerArgs failed: %v", err)
+		}
+		if got := strings.Contains(strings.Join(cmdArgs, " "), "--add-host host.docker.internal:host-gateway"); got != tc.want {
+			t.Errorf("With %s, expected the host gateway to be mapped: %v, got %v", tc.cli, tc.want, cmdArgs)
+		}
+	}
+}
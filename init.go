@@ -0,0 +1,122 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// initComments are the commented-out options which clix init adds to every script, as a starting point.
+const initComments = `# Options which many tools need; uncomment and adjust them.
+# mounts:
+# - hostPath: ~/.config/TOOL       # configuration or credentials which the tool reads
+#   sandboxPath: /root/.config/TOOL
+#   readOnly: true
+# env:
+# - name: TOOL_OPTION
+#   value: "1"
+# passEnv: [TERM]                  # host environment variables to pass through
+# network: none                    # for tools which don't need the network
+# args:
+#   prepend: [--config, "${scriptDir}/TOOL.yaml"]
+`
+
+var majorVersionElement = regexp.MustCompile(`^v[0-9]+$`)
+
+// runInit implements `clix init (--image IMAGE [--entrypoint CMD] | --go MODULE[@VERSION]) [file]`, which
+// writes a script ready to run, with the shebang, a mount of the working directory, and comments for
+// common options.
+func runInit(stdout, stderr io.Writer, args []string) error {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	image := flags.String("image", "", "the container image of the tool")
+	entrypoint := flags.String("entrypoint", "", "with --image, the command to run in the image")
+	goModule := flags.String("go", "", "the go package of the tool, with an optional @version")
+	force := flags.Bool("force", false, "overwrite an existing file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	*entrypoint = strings.TrimSpace(*entrypoint)
+	if flags.NArg() > 1 || (*image == "") == (*goModule == "") || (*entrypoint != "" && *image == "") {
+		return fmt.Errorf("usage: clix init (--image IMAGE [--entrypoint CMD] | --go MODULE[@VERSION]) [--force] [file]")
+	}
+
+	var sb strings.Builder
+	sb.WriteString("#!/usr/bin/env clix\napiVersion: clix.dev/v1alpha1\nkind: Script\n")
+	var name string
+	if *image != "" {
+		name = imageToolName(*image, *entrypoint)
+		fmt.Fprintf(&sb, "image: %s\n", *image)
+		if *entrypoint != "" {
+			fmt.Fprintf(&sb, "entrypoint: %q\n", *entrypoint)
+		}
+		sb.WriteString("# The working directory is mounted by default; mountCwd: repoRoot mounts its git repository\n")
+		sb.WriteString("# instead, and mountCwd: false mounts neither.\n")
+	} else {
+		pkg, version, ok := strings.Cut(*goModule, "@")
+		if !ok {
+			version = "latest"
+		}
+		name = goToolName(pkg)
+		fmt.Fprintf(&sb, "go:\n  run: %s\n  version: %s\n", pkg, version)
+		sb.WriteString("# Mount the git repository containing the working directory, so that the tool can read it\n")
+		sb.WriteString("mounts:\n- hostPath: git.repoRoot(cwd)\n")
+	}
+	sb.WriteString(strings.ReplaceAll(initComments, "TOOL", name))
+
+	scriptPath := flags.Arg(0)
+	if scriptPath == "" {
+		scriptPath = name
+	}
+	if _, err := parseScript([]byte(sb.String())); err != nil {
+		return fmt.Errorf("generated script is invalid: %w", err)
+	}
+	if _, err := os.Stat(scriptPath); err == nil && !*force {
+		return fmt.Errorf("%s already exists; use --force to overwrite it", scriptPath)
+	}
+	if err := os.WriteFile(scriptPath, []byte(sb.String()), 0755); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Wrote %s; run it directly, or make it a command with clix install %s\n", scriptPath, scriptPath)
+	return nil
+}
+
+// imageToolName names a tool after its entrypoint, or else the image's repository, e.g. jq for
+// ghcr.io/jqlang/jq:1.7.1.
+func imageToolName(image, entrypoint string) string {
+	if entrypoint != "" {
+		return path.Base(strings.Fields(entrypoint)[0])
+	}
+	image, _, _ = strings.Cut(image, "@")
+	name := path.Base(image)
+	name, _, _ = strings.Cut(name, ":")
+	return name
+}
+
+// goToolName names a tool after the last element of its package path, skipping a major version suffix.
+func goToolName(pkg string) string {
+	elements := strings.Split(strings.TrimSuffix(pkg, "/"), "/")
+	name := elements[len(elements)-1]
+	if majorVersionElement.MatchString(name) && len(elements) > 1 {
+		name = elements[len(elements)-2]
+	}
+	return name
+}
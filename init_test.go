@@ -0,0 +1,73 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInit(t *testing.T) {
+	dir := t.TempDir()
+	var stdout, stderr bytes.Buffer
+	jq := filepath.Join(dir, "jq")
+	if err := runInit(&stdout, &stderr, []string{"--image", "ghcr.io/jqlang/jq:1.7.1", jq}); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	data, err := os.ReadFile(jq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "#!/usr/bin/env clix\n") || !strings.Contains(string(data), "#   sandboxPath: /root/.config/jq\n") {
+		t.Errorf("Unexpected script:\n%s", data)
+	}
+	script, err := parseScript(data)
+	if err != nil || script.Image != "ghcr.io/jqlang/jq:1.7.1" {
+		t.Errorf("Expected a valid image script, got %+v, %v", script, err)
+	}
+	if info, _ := os.Stat(jq); info.Mode()&0100 == 0 {
+		t.Errorf("Expected the script to be executable, got %v", info.Mode())
+	}
+	if err := runInit(&stdout, &stderr, []string{"--image", "alpine", jq}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected an error for an existing file, got %v", err)
+	}
+
+	// The file is named after the tool by default
+	t.Chdir(dir)
+	if err := runInit(&stdout, &stderr, []string{"--go", "mvdan.cc/sh/v3/cmd/shfmt@v3.8.0"}); err != nil {
+		t.Fatalf("init --go failed: %v", err)
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "shfmt"))
+	if err != nil {
+		t.Fatalf("Expected the script to be named shfmt: %v", err)
+	}
+	script, err = parseScript(data)
+	if err != nil || script.Go == nil || script.Go.Run != "mvdan.cc/sh/v3/cmd/shfmt" || script.Go.Version != "v3.8.0" || len(script.Mounts) != 1 {
+		t.Errorf("Expected a go script with a mount, got %+v, %v", script, err)
+	}
+
+	if err := runInit(&stdout, &stderr, []string{"--go", "example.com/tool", "--image", "alpine"}); err == nil {
+		t.Errorf("Expected an error for both --go and --image")
+	}
+	if got := goToolName("github.com/org/tool/v2"); got != "tool" {
+		t.Errorf("goToolName = %q, want tool", got)
+	}
+	if got := imageToolName("gcr.io/google.com/cloudsdktool/google-cloud-cli:stable", "gcloud alpha"); got != "gcloud" {
+		t.Errorf("imageToolName = %q, want gcloud", got)
+	}
+}
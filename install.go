@@ -0,0 +1,439 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// shimMarker identifies the shims which clix install writes, so that other commands are never overwritten.
+const shimMarker = "clix shim"
+
+// installRecord describes an installed script; it is written next to the script as <name>.json.
+type installRecord struct {
+	Name string `json:"name"`
+	// Source is the path or URL the script was installed from
+	Source string `json:"source"`
+	// Script is the installed script: a link to a local source, or a copy of a URL
+	Script    string    `json:"script"`
+	Shim      string    `json:"shim"`
+	Installed time.Time `json:"installed"`
+	// LastRun is when the tool was last run through its installed script
+	LastRun time.Time `json:"lastRun,omitzero"`
+}
+
+var toolNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// runInstall implements `clix install [--name NAME] [--force] <script|url>`, which keeps a link to the script
+// (or a copy of a signed URL) in the clix data directory, and writes a shim named after the tool to
+// ~/.local/bin, so that the tool can be run by name.
+func runInstall(stdout, stderr io.Writer, args []string) error {
+	flags := flag.NewFlagSet("install", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	name := flags.String("name", "", "the command name of the tool; defaults to the script name without its extension")
+	force := flags.Bool("force", false, "replace a shim which clix installed for another script")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: clix install [--name NAME] [--force] <script|url>")
+	}
+	source := flags.Arg(0)
+	remote := strings.Contains(source, "://")
+	if !remote {
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return err
+		}
+		source = abs
+	}
+	if *name == "" {
+		*name = defaultToolName(source)
+	}
+	if !toolNamePattern.MatchString(*name) {
+		return fmt.Errorf("invalid tool name %q; set one with --name", *name)
+	}
+
+	// Scripts from URLs must be signed by a trusted key, as for extends
+	var data, sigData []byte
+	var err error
+	if remote {
+		data, sigData, err = fetchSignedScript(source)
+	} else {
+		data, err = readExtendsBase(source)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := parseScript(data); err != nil {
+		return fmt.Errorf("%s is not a valid script: %w", source, err)
+	}
+
+	scriptsDir, binDir, err := installDirs()
+	if err != nil {
+		return err
+	}
+	shim := shimPath(binDir, *name)
+	if err := checkShimCollision(shim, *name, source, *force); err != nil {
+		return err
+	}
+	for _, dir := range []string{scriptsDir, binDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+
+	ext := path.Ext(source)
+	if ext == "" || strings.ContainsAny(ext, "?#") {
+		ext = ".yaml"
+	}
+	record := installRecord{Name: *name, Source: source, Script: filepath.Join(scriptsDir, *name+ext), Shim: shim, Installed: time.Now().UTC()}
+	if err := removeInstalledScript(scriptsDir, *name); err != nil {
+		return err
+	}
+	if err := installScript(record, data, sigData); err != nil {
+		return err
+	}
+	if err := writeShim(record); err != nil {
+		return err
+	}
+	if err := writeInstallRecord(scriptsDir, record); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Installed %s from %s\n", *name, source)
+	if found, err := lookPathFn(*name); err == nil && !sameFile(found, shim) {
+		fmt.Fprintf(stderr, "Warning: %s is found first on PATH, so %s runs it instead of the shim\n", found, *name)
+	} else if !onPathList(binDir) {
+		fmt.Fprintf(stderr, "Warning: %s is not on PATH; add it to run %s by name\n", binDir, *name)
+	}
+	return nil
+}
+
+// defaultToolName is the script's file name without its extension, e.g. shfmt for shfmt.yaml.
+func defaultToolName(source string) string {
+	base := path.Base(filepath.ToSlash(source))
+	if u, err := url.Parse(source); err == nil && strings.Contains(source, "://") {
+		base = path.Base(u.Path)
+	}
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+// installDirs returns the directory of installed scripts, in the XDG data directory, and of the shims.
+func installDirs() (string, string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get user home dir: %w", err)
+	}
+	data := filepath.Join(home, ".local", "share")
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		data = dir
+	}
+	return filepath.Join(data, "clix", "scripts"), filepath.Join(home, ".local", "bin"), nil
+}
+
+func shimPath(binDir, name string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(binDir, name+".cmd")
+	}
+	return filepath.Join(binDir, name)
+}
+
+// checkShimCollision refuses to overwrite a command which clix did not install, or without force, a shim
+// for another script.
+func checkShimCollision(shim, name, source string, force bool) error {
+	data, err := os.ReadFile(shim)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error reading %s: %w", shim, err)
+	}
+	if !strings.Contains(string(data), shimMarker) {
+		return fmt.Errorf("%s already exists and was not installed by clix; choose another name with --name", shim)
+	}
+	record, err := readInstallRecord(name)
+	if err == nil && record.Source != source && !force {
+		return fmt.Errorf("%s is already installed from %s; use --force to replace it, or --name to install under another name", name, record.Source)
+	}
+	return nil
+}
+
+// installScript links a local script into the scripts directory, so that edits and the files next to it
+// still apply, or writes the fetched copy of a URL.
+func installScript(record installRecord, data, sigData []byte) error {
+	if strings.Contains(record.Source, "://") {
+		if err := os.WriteFile(record.Script, data, 0644); err != nil {
+			return err
+		}
+		// The signature is kept with the copy, so that it verifies when it is run
+		return os.WriteFile(signaturePath(record.Script), sigData, 0644)
+	}
+	if err := os.Symlink(record.Source, record.Script); err != nil {
+		return fmt.Errorf("failed to link %s: %w", record.Source, err)
+	}
+	// The signature is verified next to the script that is run
+	if _, err := os.Stat(signaturePath(record.Source)); err == nil {
+		return os.Symlink(signaturePath(record.Source), signaturePath(record.Script))
+	}
+	return nil
+}
+
+// removeInstalledScript removes the installed script of name and its signature, whatever its extension.
+func removeInstalledScript(scriptsDir, name string) error {
+	if record, err := readInstallRecord(name); err == nil {
+		for _, p := range []string{record.Script, signaturePath(record.Script)} {
+			if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeShim writes the command which runs the installed script with clix.
+func writeShim(record installRecord) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find clix executable: %w", err)
+	}
+	var shim string
+	if runtime.GOOS == "windows" {
+		shim = fmt.Sprintf("@echo off\r\nrem %s for %s, installed from %s\r\n\"%s\" \"%s\" %%*\r\n", shimMarker, record.Name, record.Source, self, record.Script)
+	} else {
+		shim = fmt.Sprintf("#!/bin/sh\n# %s for %s, installed from %s\nexec %s %s \"$@\"\n", shimMarker, record.Name, record.Source, shellQuote(self), shellQuote(record.Script))
+	}
+	return os.WriteFile(record.Shim, []byte(shim), 0755)
+}
+
+// readInstallRecord reads the record of the installed script name.
+func readInstallRecord(name string) (installRecord, error) {
+	var record installRecord
+	scriptsDir, _, err := installDirs()
+	if err != nil {
+		return record, err
+	}
+	data, err := os.ReadFile(filepath.Join(scriptsDir, name+".json"))
+	if err != nil {
+		return record, err
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return record, fmt.Errorf("error parsing install record of %s: %w", name, err)
+	}
+	return record, nil
+}
+
+func writeInstallRecord(scriptsDir string, record installRecord) error {
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(scriptsDir, record.Name+".json"), append(data, '\n'), 0644)
+}
+
+// recordInstalledRun sets the last run of an installed script, if scriptPath is one.
+func recordInstalledRun(scriptPath string) {
+	scriptsDir, _, err := installDirs()
+	if err != nil {
+		return
+	}
+	abs, err := filepath.Abs(scriptPath)
+	if err != nil || filepath.Dir(abs) != scriptsDir {
+		return
+	}
+	base := filepath.Base(abs)
+	record, err := readInstallRecord(strings.TrimSuffix(base, filepath.Ext(base)))
+	if err != nil || record.Script != abs {
+		return
+	}
+	record.LastRun = time.Now().UTC()
+	if err := writeInstallRecord(scriptsDir, record); err != nil {
+		log(1, "Failed to record the run of %s: %v", record.Name, err)
+	}
+}
+
+// runList implements `clix list [--json]`, which lists the installed scripts with their sources, pinned
+// versions and last runs.
+func runList(stdout, stderr io.Writer, args []string) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	jsonOutput := flags.Bool("json", false, "print the installed scripts as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	records, err := installRecords()
+	if err != nil {
+		return err
+	}
+	type listed struct {
+		installRecord
+		Version string `json:"version,omitempty"`
+	}
+	var scripts []listed
+	for _, record := range records {
+		l := listed{installRecord: record}
+		if data, err := os.ReadFile(record.Script); err != nil {
+			fmt.Fprintf(stderr, "Warning: %s: %v\n", record.Name, err)
+		} else if script, err := parseScript(data); err == nil {
+			l.Version = pinnedVersion(script)
+		}
+		scripts = append(scripts, l)
+	}
+	if *jsonOutput {
+		if scripts == nil {
+			scripts = []listed{}
+		}
+		return writeJSON(stdout, scripts)
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSOURCE\tVERSION\tLAST RUN")
+	for _, s := range scripts {
+		version, lastRun := s.Version, "never"
+		if version == "" {
+			version = "-"
+		}
+		if !s.LastRun.IsZero() {
+			lastRun = s.LastRun.Local().Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Name, s.Source, version, lastRun)
+	}
+	return w.Flush()
+}
+
+// installRecords reads the records of the installed scripts, in name order.
+func installRecords() ([]installRecord, error) {
+	scriptsDir, _, err := installDirs()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(scriptsDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var records []installRecord
+	for _, p := range paths {
+		record, err := readInstallRecord(strings.TrimSuffix(filepath.Base(p), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// pinnedVersion returns the version of the tool which the script runs: the runtime package's version,
+// the image tag or digest, or latest when the script doesn't pin one.
+func pinnedVersion(script Script) string {
+	version := ""
+	switch {
+	case script.Go != nil:
+		if _, v, ok := strings.Cut(script.Go.Run, "@"); ok {
+			return v
+		}
+		version = script.Go.Version
+	case script.Python != nil:
+		version = script.Python.Version
+	case script.Node != nil:
+		version = script.Node.Version
+	case script.Rust != nil:
+		version = script.Rust.Version
+	case script.Deno != nil:
+		version = script.Deno.Version
+	case script.Bun != nil:
+		version = script.Bun.Version
+	case script.Dotnet != nil:
+		version = script.Dotnet.Version
+	case script.Binary != nil:
+		version = script.Binary.Version
+	case script.Java != nil && script.Java.Maven != "":
+		if parts := strings.Split(script.Java.Maven, ":"); len(parts) >= 3 {
+			return parts[2]
+		}
+	case script.Image != "":
+		if _, digest, ok := strings.Cut(script.Image, "@"); ok {
+			return digest
+		}
+		if i := strings.LastIndex(script.Image, ":"); i > strings.LastIndex(script.Image, "/") {
+			return script.Image[i+1:]
+		}
+		return "latest"
+	default:
+		return ""
+	}
+	if version == "" {
+		return "latest"
+	}
+	return version
+}
+
+// runUninstall implements `clix uninstall <name>`, which removes the shim, the installed script and its
+// record.
+func runUninstall(stdout io.Writer, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: clix uninstall <name>")
+	}
+	name := args[0]
+	record, err := readInstallRecord(name)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s is not installed; clix list shows the installed scripts", name)
+	} else if err != nil {
+		return err
+	}
+	// The shim is only removed if clix wrote it, in case another command has replaced it since
+	if data, err := os.ReadFile(record.Shim); err == nil && strings.Contains(string(data), shimMarker) {
+		if err := os.Remove(record.Shim); err != nil {
+			return err
+		}
+	}
+	scriptsDir, _, err := installDirs()
+	if err != nil {
+		return err
+	}
+	if err := removeInstalledScript(scriptsDir, name); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(scriptsDir, name+".json")); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Uninstalled %s\n", name)
+	return nil
+}
+
+func onPathList(dir string) bool {
+	for _, p := range filepath.SplitList(os.Getenv("PATH")) {
+		if filepath.Clean(p) == filepath.Clean(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
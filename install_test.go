@@ -0,0 +1,217 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstall(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("PATH", filepath.Join(home, ".local", "bin"))
+	src := t.TempDir()
+	script := filepath.Join(src, "shfmt.yaml")
+	os.WriteFile(script, []byte("image: mvdan/shfmt\n"), 0644)
+	other := filepath.Join(src, "other.yaml")
+	os.WriteFile(other, []byte("image: alpine\n"), 0644)
+
+	var stdout, stderr bytes.Buffer
+	if err := runInstall(&stdout, &stderr, []string{script}); err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+	installed := filepath.Join(home, ".local", "share", "clix", "scripts", "shfmt.yaml")
+	if target, err := os.Readlink(installed); err != nil || target != script {
+		t.Errorf("Expected %s to link to %s, got %q, %v", installed, script, target, err)
+	}
+	shim, err := os.ReadFile(filepath.Join(home, ".local", "bin", "shfmt"))
+	if err != nil {
+		t.Fatalf("Expected a shim: %v", err)
+	}
+	if !strings.HasPrefix(string(shim), "#!/bin/sh\n# clix shim for shfmt") || !strings.Contains(string(shim), shellQuote(installed)+` "$@"`) {
+		t.Errorf("Unexpected shim:\n%s", shim)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("Expected no warnings, got %q", stderr.String())
+	}
+
+	// Reinstalling the same script is fine; another script under the same name needs --force
+	if err := runInstall(&stdout, &stderr, []string{script}); err != nil {
+		t.Errorf("reinstall failed: %v", err)
+	}
+	if err := runInstall(&stdout, &stderr, []string{"--name", "shfmt", other}); err == nil || !strings.Contains(err.Error(), "already installed from "+script) {
+		t.Errorf("Expected a collision error, got %v", err)
+	}
+	if err := runInstall(&stdout, &stderr, []string{"--name", "shfmt", "--force", other}); err != nil {
+		t.Errorf("install --force failed: %v", err)
+	}
+	if target, _ := os.Readlink(installed); target != other {
+		t.Errorf("Expected the forced install to replace the link, got %q", target)
+	}
+
+	// Commands which clix did not install are never replaced
+	os.WriteFile(filepath.Join(home, ".local", "bin", "jq"), []byte("binary"), 0755)
+	if err := runInstall(&stdout, &stderr, []string{"--name", "jq", "--force", other}); err == nil || !strings.Contains(err.Error(), "was not installed by clix") {
+		t.Errorf("Expected an error for an existing command, got %v", err)
+	}
+	if err := runInstall(&stdout, &stderr, []string{"--name", "../evil", other}); err == nil {
+		t.Errorf("Expected an error for an invalid name")
+	}
+}
+
+func TestInstallUnsignedURL(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tools/shfmt.yaml" {
+			w.Write([]byte("image: mvdan/shfmt\n"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	err := runInstall(&stdout, &stderr, []string{server.URL + "/tools/shfmt.yaml"})
+	if err == nil || !strings.Contains(err.Error(), "remote scripts must be signed") {
+		t.Errorf("Expected unsigned URLs to be refused, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".local", "bin", "shfmt")); !os.IsNotExist(err) {
+		t.Errorf("Expected no shim for a refused script")
+	}
+	if got := defaultToolName(server.URL + "/tools/shfmt.yaml?ref=v1"); got != "shfmt" {
+		t.Errorf("defaultToolName = %q, want shfmt", got)
+	}
+}
+
+func TestInstallSignedURL(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	t.Setenv("CLIX_SIGNING_KEY", "")
+	t.Setenv("CLIX_SANDBOX", "docker")
+	t.Setenv("CLIX_DRY_RUN", "")
+	src := filepath.Join(t.TempDir(), "shfmt.yaml")
+	os.WriteFile(src, []byte("image: mvdan/shfmt\nmountCwd: false\n"), 0644)
+	if err := runSign(io.Discard, io.Discard, []string{src}); err != nil {
+		t.Fatalf("runSign failed: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, filepath.Join(filepath.Dir(src), path.Base(r.URL.Path)))
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	if err := runInstall(&stdout, &stderr, []string{server.URL + "/tools/shfmt.yaml"}); err != nil {
+		t.Fatalf("install failed: %v", err)
+	}
+	installed := filepath.Join(home, ".local", "share", "clix", "scripts", "shfmt.yaml")
+	if _, err := os.Stat(signaturePath(installed)); err != nil {
+		t.Fatalf("Expected the signature to be installed with the script: %v", err)
+	}
+
+	// The installed copy still verifies when signatures are required
+	t.Setenv("CLIX_VERIFY_SIGNATURES", "1")
+	stdout.Reset()
+	if err := run(strings.NewReader(""), &stdout, &stderr, []string{"clix", "--dry-run", installed}); err != nil {
+		t.Fatalf("running the installed script failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "mvdan/shfmt") {
+		t.Errorf("Expected the dry run of the installed script, got:\n%s", stdout.String())
+	}
+}
+
+func TestListAndUninstall(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("PATH", filepath.Join(home, ".local", "bin"))
+	src := t.TempDir()
+	for name, content := range map[string]string{
+		"shfmt.yaml": "go:\n  run: mvdan.cc/sh/v3/cmd/shfmt@v3.8.0\n",
+		"jq.yaml":    "image: ghcr.io/jqlang/jq:1.7.1\n",
+	} {
+		os.WriteFile(filepath.Join(src, name), []byte(content), 0644)
+		var stdout, stderr bytes.Buffer
+		if err := runInstall(&stdout, &stderr, []string{filepath.Join(src, name)}); err != nil {
+			t.Fatalf("install failed: %v", err)
+		}
+	}
+	recordInstalledRun(filepath.Join(home, ".local", "share", "clix", "scripts", "jq.yaml"))
+
+	var stdout, stderr bytes.Buffer
+	if err := runList(&stdout, &stderr, nil); err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and two scripts, got %q", stdout.String())
+	}
+	if fields := strings.Fields(lines[1]); fields[0] != "jq" || fields[2] != "1.7.1" || fields[3] == "never" {
+		t.Errorf("Unexpected jq listing %q", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); fields[0] != "shfmt" || fields[1] != filepath.Join(src, "shfmt.yaml") || fields[2] != "v3.8.0" || fields[3] != "never" {
+		t.Errorf("Unexpected shfmt listing %q", lines[2])
+	}
+
+	stdout.Reset()
+	if err := runUninstall(&stdout, []string{"shfmt"}); err != nil {
+		t.Fatalf("uninstall failed: %v", err)
+	}
+	for _, p := range []string{
+		filepath.Join(home, ".local", "bin", "shfmt"),
+		filepath.Join(home, ".local", "share", "clix", "scripts", "shfmt.yaml"),
+		filepath.Join(home, ".local", "share", "clix", "scripts", "shfmt.json"),
+	} {
+		if _, err := os.Lstat(p); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", p)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(src, "shfmt.yaml")); err != nil {
+		t.Errorf("Expected the source script to be kept: %v", err)
+	}
+	if err := runUninstall(&stdout, []string{"shfmt"}); err == nil || !strings.Contains(err.Error(), "not installed") {
+		t.Errorf("Expected an error for a script which isn't installed, got %v", err)
+	}
+}
+
+func TestPinnedVersion(t *testing.T) {
+	for _, tc := range []struct {
+		script Script
+		want   string
+	}{
+		{Script{Image: "localhost:5000/tools/jq"}, "latest"},
+		{Script{Image: "alpine@sha256:abc"}, "sha256:abc"},
+		{Script{Python: &PythonConfig{Run: "ruff"}}, "latest"},
+		{Script{Java: &JavaConfig{Maven: "com.google.googlejavaformat:google-java-format:1.22.0:all-deps"}}, "1.22.0"},
+		{Script{Shell: &ShellConfig{}}, ""},
+	} {
+		if got := pinnedVersion(tc.script); got != tc.want {
+			t.Errorf("pinnedVersion(%+v) = %q, want %q", tc.script, got, tc.want)
+		}
+	}
+}
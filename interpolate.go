@@ -0,0 +1,71 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// envReference matches ${env.NAME} and ${env.NAME:-default} in script fields.
+var envReference = regexp.MustCompile(`\$\{env\.([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// interpolateEnv substitutes host environment variables into the script's image, entrypoint, command,
+// confirm message, mounts, env values and args, so that one script works for users whose paths and
+// project IDs differ. As in a shell, the default is used when the variable is unset or empty; a variable
+// without a default must be set.
+func interpolateEnv(script *Script) error {
+	var err error
+	interpolate := func(field string, s *string) {
+		if err != nil {
+			return
+		}
+		*s = envReference.ReplaceAllStringFunc(*s, func(ref string) string {
+			m := envReference.FindStringSubmatch(ref)
+			if v := os.Getenv(m[1]); v != "" {
+				return v
+			}
+			if m[2] == "" && err == nil {
+				err = fmt.Errorf("%s: environment variable %s is not set (use ${env.%s:-default} for a default)", field, m[1], m[1])
+			}
+			return m[3]
+		})
+	}
+
+	interpolate("image", &script.Image)
+	interpolate("entrypoint", &script.Entrypoint)
+	interpolate("workdir", &script.Workdir)
+	interpolate("confirm", &script.Confirm)
+	for i := range script.Command {
+		interpolate("command", &script.Command[i])
+	}
+	for i := range script.Mounts {
+		interpolate("mounts", &script.Mounts[i].HostPath)
+		interpolate("mounts", &script.Mounts[i].SandboxPath)
+	}
+	for i := range script.Env {
+		interpolate("env "+script.Env[i].Name, &script.Env[i].Value)
+	}
+	if script.Args != nil {
+		for i := range script.Args.Prepend {
+			interpolate("args", &script.Args.Prepend[i])
+		}
+		for i := range script.Args.Append {
+			interpolate("args", &script.Args.Append[i])
+		}
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestInterpolateEnv(t *testing.T) {
+	t.Setenv("CLIX_TEST_PROJECT", "my-project")
+	t.Setenv("CLIX_TEST_EMPTY", "")
+
+	script := Script{
+		Image:      "gcr.io/${env.CLIX_TEST_PROJECT}/tool:${env.CLIX_TEST_TAG:-latest}",
+		Entrypoint: "/bin/${env.CLIX_TEST_EMPTY:-sh}",
+		Mounts:     []Mount{{HostPath: "${cacheDir}/${env.CLIX_TEST_PROJECT}", SandboxPath: "/work/${env.CLIX_TEST_PROJECT}"}},
+		Env:        []EnvVar{{Name: "PROJECT", Value: "${env.CLIX_TEST_PROJECT}"}},
+	}
+	if err := interpolateEnv(&script); err != nil {
+		t.Fatalf("interpolateEnv failed: %v", err)
+	}
+	if script.Image != "gcr.io/my-project/tool:latest" {
+		t.Errorf("Unexpected image %q", script.Image)
+	}
+	if script.Entrypoint != "/bin/sh" {
+		t.Errorf("Unexpected entrypoint %q", script.Entrypoint)
+	}
+	if m := script.Mounts[0]; m.HostPath != "${cacheDir}/my-project" || m.SandboxPath != "/work/my-project" {
+		t.Errorf("Unexpected mount %+v", m)
+	}
+	if script.Env[0].Value != "my-project" {
+		t.Errorf("Unexpected env %+v", script.Env[0])
+	}
+
+	script = Script{Env: []EnvVar{{Name: "PROJECT", Value: "${env.CLIX_TEST_UNSET}"}}}
+	if err := interpolateEnv(&script); err == nil {
+		t.Errorf("Expected an error for an unset variable without a default")
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"sigs.k8s.io/yaml"
+)
+
+// ScriptLock is the content of a script's lockfile, <script>.lock, which pins what the script resolves to
+// when it is run. Each part records the script fields it was resolved from, so that a lock which is out
+// of date with its script is noticed rather than applied.
+type ScriptLock struct {
+	Image         *ImageLock         `json:"image,omitempty"`
+	Build         *BuildLock         `json:"build,omitempty"`
+	Go            *GoLock            `json:"go,omitempty"`
+	Binary        *BinaryLock        `json:"binary,omitempty"`
+	KubectlPlugin *KubectlPluginLock `json:"kubectl-plugin,omitempty"`
+}
+
+// ImageLock pins the script's image to the digest its reference resolved to.
+type ImageLock struct {
+	Ref    string `json:"ref"`
+	Digest string `json:"digest"`
+}
+
+// BuildLock pins the image built from a git repository to a commit.
+type BuildLock struct {
+	Git    string `json:"git"`
+	Branch string `json:"branch,omitempty"`
+	Commit string `json:"commit"`
+}
+
+// GoLock pins a go script's package to the module version its version query resolved to.
+type GoLock struct {
+	Run     string `json:"run"`
+	Version string `json:"version,omitempty"`
+	// Module is the module containing the package, and Resolved the exact version of the module
+	Module   string `json:"module"`
+	Resolved string `json:"resolved"`
+}
+
+// BinaryLock records the checksums of the script's binary downloads.
+type BinaryLock struct {
+	URL     string    `json:"url"`
+	Version string    `json:"version,omitempty"`
+	SHA256  Checksums `json:"sha256"`
+}
+
+// KubectlPluginLock pins a kubectl plugin to the version, and downloads, of its manifest when it was locked.
+type KubectlPluginLock struct {
+	Name      string         `json:"name"`
+	Manifest  string         `json:"manifest,omitempty"`
+	Version   string         `json:"version"`
+	Platforms []krewPlatform `json:"platforms"`
+}
+
+// imageDigestFn returns the digest of an image in its registry. Multi-platform images resolve to the
+// digest of their index, so that the lock pins the image on every platform.
+var imageDigestFn = func(ref string) (string, error) {
+	return crane.Digest(ref)
+}
+
+// lockPath returns the path of the script's lockfile.
+func lockPath(scriptPath string) string {
+	return scriptPath + ".lock"
+}
+
+// runLock implements clix lock, which resolves a script and writes its lockfile.
+func runLock(stdout, stderr io.Writer, args []string) error {
+	if len(args) != 1 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("usage: clix lock <script>")
+	}
+	scriptPath := args[0]
+	script, err := readScript(scriptPath)
+	if err != nil {
+		return err
+	}
+	lock, err := resolveLock(script)
+	if err != nil {
+		return err
+	}
+	if *lock == (ScriptLock{}) {
+		return fmt.Errorf("nothing to lock in %s: it has no image tag, build, go version, binary or kubectl plugin", scriptPath)
+	}
+	if err := writeLock(lockPath(scriptPath), scriptPath, lock); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Wrote %s\n", lockPath(scriptPath))
+	return nil
+}
+
+// readScript reads and parses the script at scriptPath, with the scripts it extends.
+func readScript(scriptPath string) (Script, error) {
+	data, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return Script{}, fmt.Errorf("error reading script file: %w", err)
+	}
+	exprScriptPath = scriptPath
+	data, err = expandExtends(scriptPath, data)
+	if err != nil {
+		return Script{}, err
+	}
+	return parseScript(data)
+}
+
+// resolveLock resolves the parts of the script which can change between runs: the digest of its image
+// tag, the commit of its build branch, the module version of its go package, and its downloads.
+func resolveLock(script Script) (*ScriptLock, error) {
+	lock := &ScriptLock{}
+	if script.Image != "" && script.Build == nil && !strings.Contains(script.Image, "@") {
+		if err := checkOnline("the digest of image " + script.Image); err != nil {
+			return nil, err
+		}
+		digest, err := imageDigestFn(script.Image)
+		if err != nil {
+			return nil, fmt.Errorf("resolving the digest of image %s: %w", script.Image, err)
+		}
+		lock.Image = &ImageLock{Ref: script.Image, Digest: digest}
+	}
+	if build := script.Build; build != nil && build.Commit == "" {
+		commit, err := getRemoteHead(build.Git, build.Branch)
+		if err != nil {
+			return nil, fmt.Errorf("resolving the head of %s: %w", build.Git, err)
+		}
+		lock.Build = &BuildLock{Git: build.Git, Branch: build.Branch, Commit: commit}
+	}
+	if config := script.Go; config != nil {
+		pkg, version, ok := strings.Cut(config.Run, "@")
+		if !ok {
+			version = config.Version
+		}
+		// Without a version, the package is built from the module in the working directory
+		if version != "" {
+			module, resolved, err := resolveGoVersion(pkg, version)
+			if err != nil {
+				return nil, err
+			}
+			lock.Go = &GoLock{Run: config.Run, Version: config.Version, Module: module, Resolved: resolved}
+		}
+	}
+	if config := script.Binary; config != nil {
+		lock.Binary = &BinaryLock{URL: config.URL, Version: config.Version, SHA256: config.SHA256}
+	}
+	if config := script.KubectlPlugin; config != nil {
+		manifest, err := loadKrewManifest(config)
+		if err != nil {
+			return nil, fmt.Errorf("error loading manifest for kubectl plugin %s: %w", config.Name, err)
+		}
+		lock.KubectlPlugin = &KubectlPluginLock{
+			Name:      config.Name,
+			Manifest:  config.Manifest,
+			Version:   manifest.Spec.Version,
+			Platforms: manifest.Spec.Platforms,
+		}
+	}
+	return lock, nil
+}
+
+// resolveGoVersion resolves the version query (latest, a branch, a version prefix etc) for the go
+// package, returning the module containing the package and its exact version. The module is the
+// longest prefix of the package path which the go command finds at the version.
+func resolveGoVersion(pkg, version string) (string, string, error) {
+	if err := checkOnline("the version of " + pkg); err != nil {
+		return "", "", err
+	}
+	for module := pkg; module != "." && module != "/"; module = path.Dir(module) {
+		out, err := execCommand("go", "list", "-m", "-f", "{{.Version}}", module+"@"+version).Output()
+		if err == nil {
+			log(2, "%s@%s is in module %s %s", pkg, version, module, strings.TrimSpace(string(out)))
+			return module, strings.TrimSpace(string(out)), nil
+		}
+	}
+	return "", "", fmt.Errorf("resolving %s@%s: no module containing the package was found", pkg, version)
+}
+
+// writeLock writes the lockfile for the script.
+func writeLock(lockFile, scriptPath string, lock *ScriptLock) error {
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	header := fmt.Sprintf("# Written by clix lock %s; run it again to update the lock.\n", scriptPath)
+	return os.WriteFile(lockFile, append([]byte(header), data...), 0644)
+}
+
+// readLock reads the script's lockfile, returning nil if the script has none.
+func readLock(scriptPath string) (*ScriptLock, error) {
+	data, err := os.ReadFile(lockPath(scriptPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var lock ScriptLock
+	if err := yaml.UnmarshalStrict(data, &lock); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", lockPath(scriptPath), err)
+	}
+	return &lock, nil
+}
+
+// applyLock pins the script to its lockfile, if it has one. Parts of the lock which no longer match the
+// script are not applied, with a warning to update the lock.
+func applyLock(stderr io.Writer, script *Script, scriptPath string) error {
+	lock, err := readLock(scriptPath)
+	if lock == nil || err != nil {
+		return err
+	}
+	stale := func(part string) {
+		fmt.Fprintf(stderr, "Warning: the %s in %s is out of date with the script; run clix lock %s to update it\n", part, lockPath(scriptPath), scriptPath)
+	}
+
+	if l := lock.Image; l != nil {
+		if l.Ref == script.Image && script.Build == nil {
+			log(1, "Using image %s@%s from the lock", l.Ref, l.Digest)
+			script.Image = l.Ref + "@" + l.Digest
+		} else {
+			stale("image")
+		}
+	}
+	if l := lock.Build; l != nil {
+		if b := script.Build; b != nil && b.Git == l.Git && b.Branch == l.Branch && b.Commit == "" {
+			log(1, "Building %s at commit %s from the lock", l.Git, l.Commit)
+			b.Commit = l.Commit
+		} else {
+			stale("build")
+		}
+	}
+	if l := lock.Go; l != nil {
+		if g := script.Go; g != nil && g.Run == l.Run && g.Version == l.Version {
+			pkg, _, _ := strings.Cut(g.Run, "@")
+			log(1, "Using %s %s from the lock", l.Module, l.Resolved)
+			g.Run = pkg
+			g.Version = l.Resolved
+		} else {
+			stale("go version")
+		}
+	}
+	if l := lock.Binary; l != nil {
+		if b := script.Binary; b == nil || b.URL != l.URL || b.Version != l.Version || !maps.Equal(b.SHA256, l.SHA256) {
+			stale("binary")
+		}
+	}
+	if l := lock.KubectlPlugin; l != nil {
+		if k := script.KubectlPlugin; k != nil && k.Name == l.Name && k.Manifest == l.Manifest {
+			log(1, "Using kubectl plugin %s %s from the lock", l.Name, l.Version)
+			k.locked = &krewManifest{}
+			k.locked.Spec.Version = l.Version
+			k.locked.Spec.Platforms = l.Platforms
+		} else {
+			stale("kubectl plugin")
+		}
+	}
+	return nil
+}
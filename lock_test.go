@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLock(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	defer func(fn func(string) (string, error)) { imageDigestFn = fn }(imageDigestFn)
+	imageDigestFn = func(ref string) (string, error) {
+		return "sha256:0123456789abcdef", nil
+	}
+
+	dir := t.TempDir()
+	scripts := map[string]string{
+		"image": "apiVersion: clix.dev/v1alpha1\nkind: Script\nimage: alpine:3.20\n",
+		"build": "apiVersion: clix.dev/v1alpha1\nkind: Script\nbuild:\n  git: https://github.com/example/tool\n",
+		"go":    "apiVersion: clix.dev/v1alpha1\nkind: Script\ngo:\n  run: example.com/tool/cmd/tool\n  version: latest\n",
+	}
+	for name, content := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var stdout, stderr bytes.Buffer
+		if err := run(nil, &stdout, &stderr, []string{"clix", "lock", filepath.Join(dir, name)}); err != nil {
+			t.Fatalf("lock %s failed: %v", name, err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "go.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "# Written by clix lock") || !strings.Contains(string(data), "module: example.com/tool\n") || !strings.Contains(string(data), "resolved: v1.4.2\n") {
+		t.Errorf("Unexpected lock:\n%s", data)
+	}
+
+	// Runs use the pinned image, commit and version
+	var stderr bytes.Buffer
+	script, _ := readScript(filepath.Join(dir, "image"))
+	if err := applyLock(&stderr, &script, filepath.Join(dir, "image")); err != nil || script.Image != "alpine:3.20@sha256:0123456789abcdef" {
+		t.Errorf("Expected the image pinned by digest, got %q, %v", script.Image, err)
+	}
+	script, _ = readScript(filepath.Join(dir, "build"))
+	if err := applyLock(&stderr, &script, filepath.Join(dir, "build")); err != nil || script.Build.Commit != "abcdef1234567890" {
+		t.Errorf("Expected the build pinned to the commit, got %+v, %v", script.Build, err)
+	}
+	script, _ = readScript(filepath.Join(dir, "go"))
+	if err := applyLock(&stderr, &script, filepath.Join(dir, "go")); err != nil || script.Go.Run != "example.com/tool/cmd/tool" || script.Go.Version != "v1.4.2" {
+		t.Errorf("Expected the go version pinned, got %+v, %v", script.Go, err)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("Unexpected warnings: %s", stderr.String())
+	}
+
+	// A lock which no longer matches the script is not applied
+	script.Image, script.Go = "alpine:3.21", nil
+	if err := applyLock(&stderr, &script, filepath.Join(dir, "image")); err != nil || script.Image != "alpine:3.21" || !strings.Contains(stderr.String(), "the image in "+filepath.Join(dir, "image.lock")+" is out of date") {
+		t.Errorf("Expected a warning for the out of date lock, got %q, %v: %s", script.Image, err, stderr.String())
+	}
+
+	// Scripts without a lock are unchanged, and scripts with nothing to pin can't be locked
+	script = Script{Image: "alpine@sha256:0123456789abcdef"}
+	if err := applyLock(&stderr, &script, filepath.Join(dir, "missing")); err != nil {
+		t.Errorf("Expected no error without a lock, got %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, "pinned"), []byte("apiVersion: clix.dev/v1alpha1\nkind: Script\nimage: alpine@sha256:0123456789abcdef\n"), 0644)
+	if err := runLock(&stderr, &stderr, []string{filepath.Join(dir, "pinned")}); err == nil || !strings.Contains(err.Error(), "nothing to lock") {
+		t.Errorf("Expected an error for a script with nothing to lock, got %v", err)
+	}
+}
+
+func TestResolveGoVersion(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	module, version, err := resolveGoVersion("example.com/tool/cmd/tool", "latest")
+	if err != nil || module != "example.com/tool" || version != "v1.4.2" {
+		t.Errorf("resolveGoVersion = %q, %q, %v", module, version, err)
+	}
+	if _, _, err := resolveGoVersion("example.org/missing", "latest"); err == nil {
+		t.Errorf("Expected an error for a missing module")
+	}
+}
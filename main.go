@@ -17,14 +17,14 @@ package main
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
-
-	"sigs.k8s.io/yaml"
 )
 
 var execCommand = exec.Command
@@ -35,17 +35,121 @@ func log(level int, format string, v ...any) {
 		fmt.Sscanf(vStr, "%d", &verbosity)
 	}
 	if verbosity >= level {
-		fmt.Fprintf(os.Stderr, "clix: "+format+"\n", v...)
+		fmt.Fprintln(os.Stderr, redactSecrets(fmt.Sprintf("clix: "+format, v...)))
 	}
 }
 
 type Script struct {
+	// APIVersion is the version of the script format, currently clix.dev/v1alpha1
+	APIVersion string `json:"apiVersion,omitempty"`
+	// Kind is always Script
+	Kind string `json:"kind,omitempty"`
+
 	Go         *GoConfig    `json:"go,omitempty"`
 	Build      *BuildConfig `json:"build,omitempty"`
+	Wasm       *WasmConfig  `json:"wasm,omitempty"`
 	Image      string       `json:"image,omitempty"`
 	Entrypoint string       `json:"entrypoint,omitempty"`
 	Mounts     []Mount      `json:"mounts,omitempty"`
 	Env        []EnvVar     `json:"env,omitempty"`
+	// PassEnv are patterns of host environment variables copied into the sandbox, e.g. AWS_* or TERM
+	PassEnv []string `json:"passEnv,omitempty"`
+	// ProxyEnv passes the host's HTTP_PROXY, HTTPS_PROXY and NO_PROXY to image scripts; it defaults to true
+	ProxyEnv *bool `json:"proxyEnv,omitempty"`
+	// EnvFrom are sources of several env vars, such as dotenv files; env takes precedence over them
+	EnvFrom []EnvFromSource `json:"envFrom,omitempty"`
+	// Credentials are presets (gcloud, kube, git, docker) which mount the user's credentials read-only, and
+	// set the env vars which point the tool at them
+	Credentials []string `json:"credentials,omitempty"`
+	// Workdir is the host directory the tool runs in, which may be an expression such as git.repoRoot(cwd),
+	// defaulting to the current directory; in a sandbox, it must be mounted
+	Workdir string `json:"workdir,omitempty"`
+	// Network is the tool's network: "none" disables networking (recommended for tools which only
+	// transform files), "host" shares the host's network, and "bridge" or another name selects a
+	// container engine network; {allow: [domains]} restricts it to an egress allowlist
+	Network NetworkPolicy `json:"network,omitzero"`
+	// DockerContext is the docker context used to run the tool, instead of the current context
+	DockerContext string `json:"dockerContext,omitempty"`
+	// Runtime is the OCI runtime used by the container engine, e.g. "kata" for VM-level isolation
+	Runtime string `json:"runtime,omitempty"`
+	// Arch is the architecture of the image to run (amd64, arm64 etc), defaulting to the host architecture.
+	// The proot and chroot sandboxes use qemu-user to run foreign architectures.
+	Arch string `json:"arch,omitempty"`
+	// Platform is the image platform (os/arch[/variant], e.g. linux/amd64), passed as --platform to the
+	// container engine; its architecture takes precedence over arch
+	Platform string `json:"platform,omitempty"`
+	// Rlimits are resource limits for the tool, in sandboxes which support them
+	Rlimits *RlimitConfig `json:"rlimits,omitempty"`
+	// Sandbox is the sandbox to run the tool in (docker, podman etc), or a list of sandboxes to choose from,
+	// unless overridden by CLIX_SANDBOX
+	Sandbox SandboxList `json:"sandbox,omitempty"`
+	// Profiles are named variants of the script, selected with --profile or CLIX_PROFILE
+	Profiles map[string]Profile `json:"profiles,omitempty"`
+	// Args are default and templated arguments, passed around those given by the user
+	Args *ArgsConfig `json:"args,omitempty"`
+	// Command is the full command line of the tool, replacing entrypoint; the user arguments are spliced in
+	// at ${args}, or appended if it is absent
+	Command []string `json:"command,omitempty"`
+	// Confirm is a message the user must confirm before the tool runs, e.g. for destructive tools.
+	// ${command} and ${mounts} are replaced with the command and the mounted host paths.
+	Confirm string `json:"confirm,omitempty"`
+	// Hooks are commands run before and after the tool
+	Hooks *HooksConfig `json:"hooks,omitempty"`
+	// Commands are further tools run with the script's image, selected with file.yaml:command or by
+	// running the script through a symlink with the command's name
+	Commands map[string]Command `json:"commands,omitempty"`
+	// Overrides are applied in order when their conditions hold, to vary the script by os and arch
+	Overrides []Override `json:"overrides,omitempty"`
+	// Devices are host devices passed to the sandbox, e.g. /dev/fuse
+	Devices []string `json:"devices,omitempty"`
+	// GPUs are the GPUs passed to the sandbox ("all", a count, or "device=0,1"), which needs the NVIDIA
+	// container toolkit
+	GPUs string `json:"gpus,omitempty"`
+	// DNS are the DNS servers of the sandbox, instead of the host's (or its VPN's)
+	DNS []string `json:"dns,omitempty"`
+	// DNSSearch are the DNS search domains of the sandbox
+	DNSSearch []string `json:"dnsSearch,omitempty"`
+	// ExtraHosts are added to the sandbox's /etc/hosts, as name:ip; the ip host-gateway is the host
+	ExtraHosts []string `json:"extraHosts,omitempty"`
+	// Ports are published from the sandbox to the host, as [hostIP:]hostPort:containerPort[/protocol]
+	Ports []string `json:"ports,omitempty"`
+	// MountCwd mounts the current directory (by default), its git repository root, or neither
+	MountCwd CwdMount `json:"mountCwd,omitempty"`
+	// MountScriptDir mounts the directory containing the script read-only, at the same path
+	MountScriptDir bool `json:"mountScriptDir,omitempty"`
+	// DockerSocket mounts the container engine socket into the sandbox, for tools such as kind which run
+	// containers; it must be allowed in the user configuration
+	DockerSocket bool `json:"dockerSocket,omitempty"`
+	// PullPolicy is when the container engine pulls the image: "always", "missing" (the default) or "never"
+	PullPolicy string `json:"pullPolicy,omitempty"`
+
+	// usesHostGateway is set when ${hostGateway} was expanded, so that container engines define it
+	usesHostGateway bool
+	// userConfig is the user configuration, applied when the script runs in a container
+	userConfig *UserConfig
+	// scriptPath is the path of the script, which labels its containers for clix exec
+	scriptPath string
+
+	// Python runs a tool from PyPI
+	Python *PythonConfig `json:"python,omitempty"`
+	// Node runs a tool from npm
+	Node *NodeConfig `json:"node,omitempty"`
+	// Rust runs a tool from crates.io
+	Rust *RustConfig `json:"rust,omitempty"`
+	// Java runs a jar, from a URL, path or Maven repository
+	Java *JavaConfig `json:"java,omitempty"`
+	// Deno runs a Deno module, with permissions limited to the mounts
+	Deno *DenoConfig `json:"deno,omitempty"`
+	// Bun runs a tool from npm with bun
+	Bun *BunConfig `json:"bun,omitempty"`
+	// Dotnet runs a .NET tool from NuGet
+	Dotnet *DotnetConfig `json:"dotnet,omitempty"`
+	// Shell runs a shell script under a pinned interpreter image
+	Shell *ShellConfig `json:"shell,omitempty"`
+	// Binary runs a prebuilt release binary, verified against its sha256
+	Binary *BinaryConfig `json:"binary,omitempty"`
+	// KubectlPlugin runs a kubectl plugin from the krew index
+	KubectlPlugin *KubectlPluginConfig `json:"kubectl-plugin,omitempty"`
 }
 
 // BuildConfig allows building an image from source code
@@ -54,6 +158,8 @@ type BuildConfig struct {
 	Git string `json:"git"`
 	// Branch is the branch (or tag) we should clone. Defaults to the default branch
 	Branch string `json:"branch,omitempty"`
+	// Commit is the commit we should build, rather than the head of the branch. clix lock pins it
+	Commit string `json:"commit,omitempty"`
 	// Dockerfile is the path to the Dockerfile, relative to the git repo root
 	Dockerfile string `json:"dockerfile,omitempty"`
 }
@@ -61,11 +167,35 @@ type BuildConfig struct {
 type EnvVar struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
+	// ValueFrom computes the value on the host when the tool is launched, instead of value
+	ValueFrom *EnvValueSource `json:"valueFrom,omitempty"`
 }
 
 type Mount struct {
-	HostPath    string `json:"hostPath"`
+	HostPath    string `json:"hostPath,omitempty"`
 	SandboxPath string `json:"sandboxPath,omitempty"`
+	// HostPaths are several host paths or globs, each mounted at the same path, or in the sandboxPath
+	// directory; paths which don't exist are skipped, as they are for a hostPath glob
+	HostPaths []string `json:"hostPaths,omitempty"`
+	// Volume is a named volume managed by clix, mounted instead of a host path, in docker-compatible
+	// sandboxes; it works with remote daemons, where host paths are not available
+	Volume string `json:"volume,omitempty"`
+	// Mode is "snapshot" to give the tool a writable copy of the host path, with changes reviewed before
+	// they are copied back
+	Mode string `json:"mode,omitempty"`
+	// ReadOnly mounts the host path read-only, e.g. for credential directories
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// Required fails the run when the host path doesn't exist, or with false, skips the mount; by default the
+	// sandbox decides, and docker creates a directory
+	Required *bool `json:"required,omitempty"`
+	// CreateIfMissing creates an empty "file" or "directory" at the host path when it doesn't exist
+	CreateIfMissing string `json:"createIfMissing,omitempty"`
+	// Mask are paths beneath the host path which are hidden from the tool, e.g. a credential file in a
+	// config directory
+	Mask []string `json:"mask,omitempty"`
+
+	// masked is set on the mounts which hide mask paths
+	masked bool
 }
 
 type GoConfig struct {
@@ -74,8 +204,44 @@ type GoConfig struct {
 }
 
 func main() {
-	if err := run(os.Stdin, os.Stdout, os.Stderr, os.Args); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+	if config := os.Getenv(namespaceInitEnv); config != "" {
+		runNamespaceInit(config)
+	}
+	if len(os.Args) > 2 && os.Args[1] == landlockInitArg {
+		runLandlockInit(os.Args[2], os.Args[3:])
+	}
+
+	var stdout, stderr io.Writer = os.Stdout, os.Stderr
+	var output *outputTail
+	if diagnosticsEnabled() {
+		output = newOutputTail(diagnosticsOutputLines)
+		stdout = io.MultiWriter(os.Stdout, output)
+		stderr = io.MultiWriter(os.Stderr, output)
+	}
+
+	err := run(os.Stdin, stdout, stderr, os.Args)
+	if usage.Wall != 0 {
+		if timingsEnabled() {
+			printUsage(os.Stderr)
+		}
+		if aerr := writeAuditRecord(os.Args, err); aerr != nil {
+			log(1, "Unable to write the audit log: %v", aerr)
+		}
+	}
+	if err != nil && diagnosticsEnabled() {
+		if path, derr := writeDiagnostics(os.Args, err, output); derr != nil {
+			fmt.Fprintf(os.Stderr, "clix: failed to capture diagnostics: %v\n", derr)
+		} else {
+			fmt.Fprintf(os.Stderr, "clix: diagnostics written to %s\n", path)
+		}
+	}
+	if err != nil {
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			// Propagate the exit code of the tool
+			os.Exit(exitErr.code)
+		}
+		fmt.Fprintln(os.Stderr, redactSecrets(err.Error()))
 		os.Exit(1)
 	}
 }
@@ -85,44 +251,356 @@ func run(stdin io.Reader, stdout, stderr io.Writer, args []string) error {
 		return fmt.Errorf("usage: %s <script> [args...]", args[0])
 	}
 
-	scriptPath := args[1]
+	// Flags before the script or subcommand are clix's own, and are parsed until the first non-flag
+	if strings.HasPrefix(args[1], "-") {
+		rest, err := parseLeadingFlags(stderr, args[1:])
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if len(rest) == 0 {
+			return fmt.Errorf("usage: %s [flags] <script> [args...]", args[0])
+		}
+		args = append(args[:1:1], rest...)
+	}
+
+	switch args[1] {
+	case "run":
+		return runRun(stdin, stdout, stderr, args[2:])
+	case "sign":
+		return runSign(stdout, stderr, args[2:])
+	case "validate":
+		return runValidate(stdout, stderr, args[2:])
+	case "cache":
+		return runCache(stdout, stderr, args[2:])
+	case "secret":
+		return runSecret(stdin, stdout, stderr, args[2:])
+	case "explain":
+		return runExplain(stdin, stdout, stderr, args[2:])
+	case "doctor":
+		return runDoctor(stdout, args[2:])
+	case "install":
+		return runInstall(stdout, stderr, args[2:])
+	case "init":
+		return runInit(stdout, stderr, args[2:])
+	case "uninstall":
+		return runUninstall(stdout, args[2:])
+	case "list":
+		return runList(stdout, stderr, args[2:])
+	case "lock":
+		return runLock(stdout, stderr, args[2:])
+	case "verify":
+		return runVerify(stdout, args[2:])
+	case "upgrade":
+		return runUpgrade(stdout, stderr, args[2:])
+	case "fmt":
+		return runFmt(stdout, stderr, args[2:])
+	case "shell":
+		return runSandboxShell(stdin, stdout, stderr, args[2:])
+	case "exec":
+		return runExec(stdin, stdout, stderr, args[2:])
+	case "build":
+		return runBuild(stdin, stdout, stderr, args[2:])
+	case "completion":
+		return runCompletion(stdout, args[2:])
+	}
+
+	scriptPath, command := splitCommand(args[1])
 	scriptArgs := args[2:]
+	exprScriptPath = scriptPath
 
 	data, err := os.ReadFile(scriptPath)
 	if err != nil {
 		return fmt.Errorf("error reading script file: %w", err)
 	}
+	recordInstalledRun(scriptPath)
+
+	if signatureRequired() {
+		if err := verifyScriptSignature(scriptPath, data); err != nil {
+			return err
+		}
+	}
+
+	data, err = expandExtends(scriptPath, data)
+	if err != nil {
+		return err
+	}
+
+	script, err := parseScript(data)
+	if err != nil {
+		return err
+	}
+	if err := applyLock(stderr, &script, scriptPath); err != nil {
+		return err
+	}
+	script.scriptPath = scriptPath
+
+	if err := selectCommand(&script, scriptPath, command); err != nil {
+		return err
+	}
+	if err := applyOverrides(&script); err != nil {
+		return err
+	}
+	if err := applyProfile(&script, selectedProfile()); err != nil {
+		return err
+	}
+	if err := applyCredentials(&script); err != nil {
+		return err
+	}
+
+	userConfig, err := loadUserConfig()
+	if err != nil {
+		return err
+	}
+	userConfig.applyDefaults(&script)
+	script.userConfig = userConfig
+	script.Ports = append(script.Ports, publishedPorts()...)
+	applyNetworkFlag(&script)
+	applyOffline(&script)
+	if err := mountDockerSocket(stderr, &script, userConfig); err != nil {
+		return err
+	}
+	if err := expandScriptDir(&script, scriptPath); err != nil {
+		return fmt.Errorf("error expanding scriptDir: %w", err)
+	}
+
+	if err := interpolateEnv(&script); err != nil {
+		return fmt.Errorf("error interpolating script: %w", err)
+	}
+	if err := evaluateScriptExpressions(&script); err != nil {
+		return fmt.Errorf("error evaluating script: %w", err)
+	}
+	expandHostGateway(&script)
+	if err := resolveWorkdir(&script); err != nil {
+		return err
+	}
+	if err := mountCwd(&script); err != nil {
+		return err
+	}
+	applyPassEnv(stderr, &script)
+	applyProxyEnv(&script)
+	if err := loadEnvFrom(&script, scriptPath); err != nil {
+		return err
+	}
+	scriptArgs, err = script.Args.expand(scriptArgs)
+	if err != nil {
+		return fmt.Errorf("error expanding args: %w", err)
+	}
+	scriptArgs, err = expandCommand(&script, scriptPath, scriptArgs)
+	if err != nil {
+		return fmt.Errorf("error expanding command: %w", err)
+	}
+	// A dry run asks for nothing, as it runs nothing, and clix build only prepares the tool
+	if !dryRunEnabled() && !prebuildOnly {
+		if err := confirmRun(stdin, stderr, script, scriptArgs); err != nil {
+			return err
+		}
+		if err := approveMounts(stdin, stderr, scriptPath, data, script.Mounts); err != nil {
+			return err
+		}
+	}
+	if !prebuildOnly {
+		if err := resolveEnvValues(stdin, stderr, &script); err != nil {
+			return err
+		}
+	}
+
+	if script.Wasm != nil {
+		module, err := resolveWasmModule(scriptPath, script.Wasm.Module)
+		if err != nil {
+			return fmt.Errorf("error resolving wasm module: %w", err)
+		}
+		script.Wasm.Module = module
+	}
 
-	var script Script
-	if err := yaml.Unmarshal(data, &script); err != nil {
-		return fmt.Errorf("error parsing script file: %w", err)
+	if script.Java != nil {
+		jar, err := resolveScriptPath(scriptPath, script.Java.Jar)
+		if err != nil {
+			return fmt.Errorf("error resolving jar: %w", err)
+		}
+		script.Java.Jar = jar
+	}
+
+	if script.KubectlPlugin != nil {
+		manifest, err := resolveScriptPath(scriptPath, script.KubectlPlugin.Manifest)
+		if err != nil {
+			return fmt.Errorf("error resolving kubectl plugin manifest: %w", err)
+		}
+		script.KubectlPlugin.Manifest = manifest
+	}
+
+	if script.Shell != nil {
+		if err := loadShellFile(scriptPath, script.Shell); err != nil {
+			return fmt.Errorf("error reading shell script: %w", err)
+		}
+	}
+
+	if dockerContext := os.Getenv("CLIX_DOCKER_CONTEXT"); dockerContext != "" {
+		script.DockerContext = dockerContext
 	}
 
 	if script.Build != nil {
-		imageName, err := buildImage(stdin, stdout, stderr, script.Build, scriptPath)
+		imageName, err := buildImage(stdin, stdout, stderr, script, scriptPath)
 		if err != nil {
 			return fmt.Errorf("error building image: %w", err)
 		}
 		script.Image = imageName
 	}
 
+	if dryRunEnabled() {
+		if err := printResolvedScript(stdout, script, scriptArgs); err != nil {
+			return err
+		}
+	}
+
+	if prebuildOnly {
+		return execute(stdin, stdout, stderr, script, scriptArgs)
+	}
+
+	if !dryRunEnabled() {
+		if err := ensureMountPaths(script.Mounts); err != nil {
+			return err
+		}
+	}
+	snapshots, err := prepareSnapshots(script.Mounts)
+	if err != nil {
+		return fmt.Errorf("error preparing snapshot mounts: %w", err)
+	}
+	if snapshots == nil {
+		return executeWithHooks(stdin, stdout, stderr, script, scriptArgs)
+	}
+	defer snapshots.cleanup()
+
+	script.Mounts = snapshots.mounts
+	runErr := executeWithHooks(stdin, stdout, stderr, script, scriptArgs)
+	if err := snapshots.review(stdin, stderr); err != nil {
+		if runErr != nil {
+			return fmt.Errorf("%w (and reviewing snapshot mounts failed: %v)", runErr, err)
+		}
+		return fmt.Errorf("error reviewing snapshot mounts: %w", err)
+	}
+	return runErr
+}
+
+// noSandbox reports whether the user asked to run go tools natively, without the script's sandbox.
+func noSandbox() bool {
+	return os.Getenv("CLIX_NO_SANDBOX") != ""
+}
+
+// selectedSandbox returns the name of the sandbox to use: the first available of CLIX_SANDBOX, or of the
+// script's sandbox field, or of the default sandboxes.
+func selectedSandbox(script Script) string {
+	if sandboxes := parseSandboxList(os.Getenv("CLIX_SANDBOX")); len(sandboxes) > 0 {
+		return resolveSandbox(sandboxes)
+	}
+	if len(script.Sandbox) > 0 {
+		return resolveSandbox(script.Sandbox)
+	}
+	return resolveSandbox(defaultSandboxChain)
+}
+
+// execute runs the script in the selected sandbox.
+func execute(stdin io.Reader, stdout, stderr io.Writer, script Script, scriptArgs []string) error {
+	if script.Wasm != nil {
+		// WASI modules bring their own sandbox
+		log(1, "Running wasm module: %s", script.Wasm.Module)
+		recordRun("wasm", script, scriptArgs)
+		return (&WasmSandbox{}).Run(stdin, stdout, stderr, script, scriptArgs)
+	}
+
+	if noSandbox() {
+		if script.Binary != nil {
+			log(1, "Running binary without a sandbox: %s", script.Binary.URL)
+			return runBinary(stdin, stdout, stderr, nil, "native", script, nil, scriptArgs)
+		}
+		if script.KubectlPlugin != nil {
+			log(1, "Running kubectl plugin without a sandbox: %s", script.KubectlPlugin.Name)
+			return runKubectlPlugin(stdin, stdout, stderr, nil, "native", script, nil, scriptArgs)
+		}
+		if script.Go == nil {
+			return fmt.Errorf("error: running without a sandbox is only supported for go, binary and kubectl-plugin scripts")
+		}
+		log(1, "Running go run without a sandbox: %s", script.Go.Run)
+		recordRun("native", script, scriptArgs)
+		return runGo(stdin, stdout, stderr, script, nil, scriptArgs)
+	}
+
 	var sandbox Sandbox
-	sandboxType := os.Getenv("CLIX_SANDBOX")
+	var native NativeSandbox
+	sandboxType := selectedSandbox(script)
 	switch sandboxType {
+	case "seatbelt":
+		native = &SeatbeltSandbox{}
+	case "landlock":
+		native = &LandlockSandbox{}
 	case "chroot":
 		sandbox = &ChrootSandbox{}
 	case "proot":
 		sandbox = &ProotSandbox{}
 	case "apple-container":
 		sandbox = &AppleContainerSandbox{}
+	case "podman":
+		sandbox = &PodmanSandbox{}
+	case "nerdctl":
+		sandbox = &NerdctlSandbox{}
+	case "firecracker":
+		sandbox = &FirecrackerSandbox{}
+	case "nsjail":
+		sandbox = &NsjailSandbox{}
+	case "wsl":
+		sandbox = &WSLSandbox{Distro: os.Getenv("CLIX_WSL_DISTRO")}
+	case "namespace":
+		sandbox = &NamespaceSandbox{}
+	case "runc":
+		sandbox = &RuncSandbox{}
 	default:
+		if sandboxType != "" && sandboxType != "docker" {
+			if hasPlugin(sandboxType) {
+				sandbox = &PluginSandbox{Name: sandboxType}
+				break
+			}
+			fmt.Fprintf(os.Stderr, "Warning: unknown sandbox %q (and no %s on PATH), using docker\n", sandboxType, pluginCommand(sandboxType))
+		}
 		sandboxType = "docker"
 		sandbox = &DockerSandbox{}
 	}
 	log(1, "Using sandbox: %s", sandboxType)
+	if _, plugin := sandbox.(*PluginSandbox); !plugin {
+		if err := checkNetwork(sandboxType, script.Network); err != nil {
+			return err
+		}
+	}
+	if sandbox != nil && script.userConfig != nil {
+		sandbox = &configuredSandbox{Sandbox: sandbox, config: script.userConfig}
+	}
+	if sandbox != nil && sandboxShell != "" {
+		sandbox = &shellSandbox{Sandbox: sandbox, shell: sandboxShell}
+	}
+	if sandbox != nil && prebuildOnly {
+		sandbox = &prebuildSandbox{Sandbox: sandbox, sandboxType: sandboxType}
+	}
+
+	if native != nil {
+		if script.Binary != nil {
+			log(1, "Running binary natively in %s sandbox: %s", sandboxType, script.Binary.URL)
+			return runBinary(stdin, stdout, stderr, nil, sandboxType, script, native, scriptArgs)
+		}
+		if script.KubectlPlugin != nil {
+			log(1, "Running kubectl plugin natively in %s sandbox: %s", sandboxType, script.KubectlPlugin.Name)
+			return runKubectlPlugin(stdin, stdout, stderr, nil, sandboxType, script, native, scriptArgs)
+		}
+		if script.Go == nil {
+			return fmt.Errorf("error: %s sandbox only supports native (go, binary and kubectl-plugin) scripts", sandboxType)
+		}
+		log(1, "Running go run natively in %s sandbox: %s", sandboxType, script.Go.Run)
+		recordRun(sandboxType, script, scriptArgs)
+		return runGo(stdin, stdout, stderr, script, native, scriptArgs)
+	}
 
 	if script.Image != "" {
 		log(1, "Running image: %s", script.Image)
+		recordRun(sandboxType, script, scriptArgs)
 		return sandbox.Run(stdin, stdout, stderr, script, scriptArgs)
 	}
 
@@ -152,16 +630,50 @@ func run(stdin io.Reader, stdout, stderr io.Writer, args []string) error {
 			// So `docker run ... golang:latest go run pkg args...` works.
 			newArgs := append([]string{"go", "run", goPackage}, scriptArgs...)
 			log(1, "Transformed command: go run %s", goPackage)
+			recordRun(sandboxType, script, newArgs)
 			return sandbox.Run(stdin, stdout, stderr, script, newArgs)
 		}
 		log(1, "Running go run: %s", script.Go.Run)
-		return runGo(stdin, stdout, stderr, script.Go, scriptArgs)
+		recordRun("native", script, scriptArgs)
+		return runGo(stdin, stdout, stderr, script, nil, scriptArgs)
+	}
+
+	if script.Python != nil {
+		return runPython(stdin, stdout, stderr, sandbox, sandboxType, script, scriptArgs)
+	}
+	if script.Node != nil {
+		return runNode(stdin, stdout, stderr, sandbox, sandboxType, script, scriptArgs)
+	}
+	if script.Rust != nil {
+		return runRust(stdin, stdout, stderr, sandbox, sandboxType, script, scriptArgs)
+	}
+	if script.Java != nil {
+		return runJava(stdin, stdout, stderr, sandbox, sandboxType, script, scriptArgs)
+	}
+	if script.Deno != nil {
+		return runDeno(stdin, stdout, stderr, sandbox, sandboxType, script, scriptArgs)
+	}
+	if script.Bun != nil {
+		return runBun(stdin, stdout, stderr, sandbox, sandboxType, script, scriptArgs)
+	}
+	if script.Dotnet != nil {
+		return runDotnet(stdin, stdout, stderr, sandbox, sandboxType, script, scriptArgs)
+	}
+	if script.Shell != nil {
+		return runShell(stdin, stdout, stderr, sandbox, sandboxType, script, scriptArgs)
+	}
+	if script.Binary != nil {
+		return runBinary(stdin, stdout, stderr, sandbox, sandboxType, script, nil, scriptArgs)
+	}
+	if script.KubectlPlugin != nil {
+		return runKubectlPlugin(stdin, stdout, stderr, sandbox, sandboxType, script, nil, scriptArgs)
 	}
 
-	return fmt.Errorf("error: script configuration missing (expected 'go' or 'image')")
+	return fmt.Errorf("error: script configuration missing (expected 'image', 'wasm' or a runtime such as 'go' or 'python')")
 }
 
-func runGo(stdin io.Reader, stdout, stderr io.Writer, config *GoConfig, args []string) error {
+func runGo(stdin io.Reader, stdout, stderr io.Writer, script Script, native NativeSandbox, args []string) error {
+	config := script.Go
 	goPackage := config.Run
 	version := config.Version
 
@@ -173,38 +685,96 @@ func runGo(stdin io.Reader, stdout, stderr io.Writer, config *GoConfig, args []s
 	if version != "" {
 		target = fmt.Sprintf("%s@%s", goPackage, version)
 	}
+	if prebuildOnly {
+		// Building the tool fills the go build cache, which go run uses
+		_, cleanup, err := buildGoBinary(stderr, target, version != "")
+		if err != nil {
+			return err
+		}
+		cleanup()
+		return nil
+	}
 
-	log(1, "Running go run %s", target)
-	cmdArgs := append([]string{"run", target}, args...)
-	cmd := execCommand("go", cmdArgs...)
+	var cmd *exec.Cmd
+	if native != nil {
+		// Build outside the sandbox, so that only the tool itself is confined
+		binary, cleanup, err := buildGoBinary(stderr, target, version != "")
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+		cmd, err = native.Command(script, binary, args...)
+		if err != nil {
+			return err
+		}
+		cmd.Env = sandboxEnv(script)
+		cmd.Dir = script.Workdir
+	} else {
+		log(1, "Running go run %s", target)
+		cmdArgs := append([]string{"run", target}, args...)
+		cmd = execCommand("go", cmdArgs...)
+	}
 	cmd.Stdin = stdin
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
-	if err := cmd.Run(); err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			// Propagate the exit code from the subcommand
-			os.Exit(exitErr.ExitCode())
-		}
+	if err := runTool(cmd); err != nil {
 		return fmt.Errorf("error running command: %w", err)
 	}
 
 	return nil
 }
 
-func buildImage(stdin io.Reader, stdout, stderr io.Writer, build *BuildConfig, scriptName string) (string, error) {
+// buildGoBinary compiles the go package target into a temporary directory, returning the path of the binary.
+// Versioned targets (pkg@version) are built with go install, as go build does not accept versions.
+func buildGoBinary(stderr io.Writer, target string, versioned bool) (string, func(), error) {
+	binDir, err := os.MkdirTemp("", "clix-go-*")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(binDir) }
+
+	log(1, "Building %s", target)
+	var cmd *exec.Cmd
+	if versioned {
+		cmd = execCommand("go", "install", target)
+		cmd.Env = append(cmd.Environ(), "GOBIN="+binDir)
+	} else {
+		cmd = execCommand("go", "build", "-o", binDir+string(filepath.Separator), target)
+	}
+	cmd.Stdout = stderr
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("error building %s: %w", target, err)
+	}
+
+	entries, err := os.ReadDir(binDir)
+	if err != nil || len(entries) != 1 {
+		cleanup()
+		return "", nil, fmt.Errorf("error building %s: expected a single binary in %s", target, binDir)
+	}
+	return filepath.Join(binDir, entries[0].Name()), cleanup, nil
+}
+
+func buildImage(stdin io.Reader, stdout, stderr io.Writer, script Script, scriptName string) (string, error) {
+	build := script.Build
 	if build.Git == "" {
 		return "", fmt.Errorf("build.git is required")
 	}
 
 	log(1, "Building image from %s", build.Git)
 
-	// Get the latest commit hash from the remote
-	commitHash, err := getRemoteHead(build.Git, build.Branch)
-	if err != nil {
-		return "", fmt.Errorf("failed to get remote head: %w", err)
+	// Get the latest commit hash from the remote, unless the commit is pinned
+	commitHash := build.Commit
+	if commitHash == "" {
+		var err error
+		commitHash, err = getRemoteHead(build.Git, build.Branch)
+		if err != nil {
+			return "", fmt.Errorf("failed to get remote head: %w", err)
+		}
+		log(2, "Remote head is %s", commitHash)
 	}
-	log(2, "Remote head is %s", commitHash)
 
 	// Construct image tag: clix-<script-name>-<hash-of-repo-url>:<commit-hash>
 	repoHash := sha256.Sum256([]byte(build.Git))
@@ -226,7 +796,7 @@ func buildImage(stdin io.Reader, stdout, stderr io.Writer, build *BuildConfig, s
 	log(1, "Generated image tag: %s", imageTag)
 
 	// Check if image exists
-	exists, err := imageExists(imageTag)
+	exists, err := imageExists(script, imageTag)
 	if err != nil {
 		return "", fmt.Errorf("failed to check if image exists: %w", err)
 	}
@@ -236,6 +806,13 @@ func buildImage(stdin io.Reader, stdout, stderr io.Writer, build *BuildConfig, s
 		return imageTag, nil
 	}
 
+	if dryRunEnabled() {
+		fmt.Fprintf(stderr, "clix: dry run: not building %s from %s\n", imageTag, build.Git)
+		return imageTag, nil
+	}
+	if err := checkOnline("the repository " + build.Git); err != nil {
+		return "", err
+	}
 	log(1, "Image %s not found, building...", imageTag)
 
 	// Clone and build
@@ -259,6 +836,18 @@ func buildImage(stdin io.Reader, stdout, stderr io.Writer, build *BuildConfig, s
 	if err := cmd.Run(); err != nil {
 		return "", fmt.Errorf("git clone failed: %w", err)
 	}
+	if build.Commit != "" {
+		// The shallow clone has the head of the branch, so fetch the pinned commit
+		for _, gitArgs := range [][]string{{"fetch", "--depth", "1", "origin", build.Commit}, {"checkout", "--detach", build.Commit}} {
+			cmd := execCommand("git", gitArgs...)
+			cmd.Dir = tempDir
+			cmd.Stdout = stdout
+			cmd.Stderr = stderr
+			if err := cmd.Run(); err != nil {
+				return "", fmt.Errorf("git %s failed: %w", gitArgs[0], err)
+			}
+		}
+	}
 
 	// Build
 	dockerfile := "Dockerfile"
@@ -269,13 +858,28 @@ func buildImage(stdin io.Reader, stdout, stderr io.Writer, build *BuildConfig, s
 	var buildCmd string
 	var buildArgs []string
 
-	if os.Getenv("CLIX_SANDBOX") == "apple-container" {
+	switch selectedSandbox(script) {
+	case "apple-container":
 		buildCmd = "container"
 		buildArgs = []string{"build", "-t", imageTag, "-f", dockerfile, "."}
-	} else {
-		buildCmd = "docker"
-		// Use standard 'docker build' for better compatibility than 'buildx'
+	case "podman", "nerdctl":
+		buildCmd = selectedSandbox(script)
 		buildArgs = []string{"build", "-f", dockerfile, "-t", imageTag, "."}
+	case "wsl":
+		// wsl.exe translates the Windows working directory into the distro
+		cli := wslDockerCLI(os.Getenv("CLIX_WSL_DISTRO"))
+		buildCmd = cli[0]
+		buildArgs = append(cli[1:], "build", "-f", dockerfile, "-t", imageTag, ".")
+	default:
+		// Use standard 'docker build' for better compatibility than 'buildx'
+		cli := dockerCLI(script)
+		buildCmd = cli[0]
+		buildArgs = append(cli[1:], "build", "-f", dockerfile, "-t", imageTag, ".")
+	}
+
+	if script.Platform != "" {
+		// Keep the context directory last
+		buildArgs = append(buildArgs[:len(buildArgs)-1], "--platform", script.Platform, ".")
 	}
 
 	fmt.Fprintf(stderr, "Building image %s...\n", imageTag)
@@ -291,6 +895,9 @@ func buildImage(stdin io.Reader, stdout, stderr io.Writer, build *BuildConfig, s
 }
 
 func getRemoteHead(repo, branch string) (string, error) {
+	if err := checkOnline("the head of " + repo); err != nil {
+		return "", err
+	}
 	log(2, "Getting remote head for %s (branch: %s)", repo, branch)
 	args := []string{"ls-remote", repo}
 	if branch != "" {
@@ -317,12 +924,21 @@ func getRemoteHead(repo, branch string) (string, error) {
 	return fields[0], nil
 }
 
-func imageExists(tag string) (bool, error) {
-	cmdName := "docker"
-	args := []string{"images", "-q", tag}
-	if os.Getenv("CLIX_SANDBOX") == "apple-container" {
+func imageExists(script Script, tag string) (bool, error) {
+	cli := dockerCLI(script)
+	cmdName := cli[0]
+	args := append(cli[1:], "images", "-q", tag)
+	switch selectedSandbox(script) {
+	case "apple-container":
 		cmdName = "container"
 		args = []string{"image", "list", tag}
+	case "podman", "nerdctl":
+		cmdName = selectedSandbox(script)
+		args = []string{"images", "-q", tag}
+	case "wsl":
+		cli := wslDockerCLI(os.Getenv("CLIX_WSL_DISTRO"))
+		cmdName = cli[0]
+		args = append(cli[1:], "images", "-q", tag)
 	}
 
 	log(2, "Checking if image exists: %s %v", cmdName, args)
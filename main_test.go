@@ -17,6 +17,7 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -109,6 +110,12 @@ func TestResolveMounts(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to get user home: %v", err)
 	}
+	kube := t.TempDir()
+	for _, name := range []string{"a.conf", "b.conf", "config"} {
+		if err := os.WriteFile(filepath.Join(kube, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
 
 	tests := []struct {
 		name     string
@@ -151,6 +158,39 @@ func TestResolveMounts(t *testing.T) {
 				{HostPath: "/tmp", SandboxPath: "/root"},
 			},
 		},
+		{
+			name: "Sandbox path expression",
+			input: []Mount{
+				{HostPath: "/tmp", SandboxPath: "env('CLIX_TEST_UNSET', '/work') + '/tmp'"},
+			},
+			expected: []Mount{
+				{HostPath: "/tmp", SandboxPath: "/work/tmp"},
+			},
+		},
+		{
+			name: "Host path glob",
+			input: []Mount{
+				{HostPath: kube + "/*.conf"},
+				{HostPath: kube + "/*.conf", SandboxPath: "/kube"},
+				{HostPath: kube + "/*.missing"},
+			},
+			expected: []Mount{
+				{HostPath: kube + "/a.conf", SandboxPath: kube + "/a.conf"},
+				{HostPath: kube + "/b.conf", SandboxPath: kube + "/b.conf"},
+				{HostPath: kube + "/a.conf", SandboxPath: "/kube/a.conf"},
+				{HostPath: kube + "/b.conf", SandboxPath: "/kube/b.conf"},
+			},
+		},
+		{
+			name: "Host path list",
+			input: []Mount{
+				{HostPaths: []string{kube + "/config", kube + "/missing", kube + "/b.*"}},
+			},
+			expected: []Mount{
+				{HostPath: kube + "/config", SandboxPath: kube + "/config"},
+				{HostPath: kube + "/b.conf", SandboxPath: kube + "/b.conf"},
+			},
+		},
 		{
 			name:     "Cache directory expansion",
 			input:    []Mount{{HostPath: "${cacheDir}/pycache"}, {HostPath: "{cacheDir}/oldcache"}},
@@ -193,11 +233,84 @@ func TestResolveMounts(t *testing.T) {
 	}
 }
 
+func TestResolveMissingMounts(t *testing.T) {
+	dir := t.TempDir()
+	required, optional := true, false
+
+	resolved, err := resolveMounts([]Mount{
+		{HostPath: dir + "/tool.yaml", SandboxPath: "/etc/tool.yaml", CreateIfMissing: "file"},
+		{HostPath: dir + "/state", CreateIfMissing: "directory"},
+		{HostPath: dir + "/optional.yaml", Required: &optional},
+		{HostPath: dir + "/legacy"},
+	}, "")
+	if err != nil {
+		t.Fatalf("resolveMounts failed: %v", err)
+	}
+	if len(resolved) != 3 || resolved[2].HostPath != dir+"/legacy" {
+		t.Fatalf("Expected the optional mount to be skipped, got %+v", resolved)
+	}
+	// Resolving mounts creates nothing, until the run is about to start
+	if pathExists(dir+"/tool.yaml") || pathExists(dir+"/state") {
+		t.Fatalf("Expected resolveMounts to leave the host unchanged")
+	}
+	if err := ensureMountPaths([]Mount{
+		{HostPath: dir + "/tool.yaml", SandboxPath: "/etc/tool.yaml", CreateIfMissing: "file"},
+		{HostPath: dir + "/state", CreateIfMissing: "directory"},
+	}); err != nil {
+		t.Fatalf("ensureMountPaths failed: %v", err)
+	}
+	if info, err := os.Stat(dir + "/tool.yaml"); err != nil || !info.Mode().IsRegular() || info.Size() != 0 {
+		t.Errorf("Expected an empty file to be created, got %v, %v", info, err)
+	}
+	if info, err := os.Stat(dir + "/state"); err != nil || !info.IsDir() {
+		t.Errorf("Expected a directory to be created, got %v, %v", info, err)
+	}
+
+	for _, m := range []Mount{
+		{HostPath: dir + "/missing.yaml", Required: &required},
+		{HostPath: dir + "/*.missing", Required: &required},
+	} {
+		if _, err := resolveMounts([]Mount{m}, ""); err == nil || !strings.Contains(err.Error(), "required") {
+			t.Errorf("Expected an error for the required mount %s, got %v", m.HostPath, err)
+		}
+	}
+	if _, err := resolveMounts([]Mount{{HostPath: dir + "/x", CreateIfMissing: "socket"}}, ""); err == nil {
+		t.Errorf("Expected an error for an unknown createIfMissing")
+	}
+}
+
+func TestCreateIfMissingWaitsForApproval(t *testing.T) {
+	t.Setenv("CLIX_SANDBOX", "docker")
+	t.Setenv("CLIX_DRY_RUN", "")
+	t.Setenv("CLIX_APPROVE_MOUNTS", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	outside := t.TempDir()
+	scriptPath := filepath.Join(t.TempDir(), "tool.yaml")
+	os.WriteFile(scriptPath, []byte(fmt.Sprintf("image: alpine\nmountCwd: false\nmounts:\n- hostPath: %s/toolrc\n  createIfMissing: file\n- hostPath: %s/state\n  createIfMissing: directory\n", outside, outside)), 0644)
+
+	// The mounts are outside the repo, and without a terminal the approval is declined
+	err := run(strings.NewReader("y\n"), io.Discard, io.Discard, []string{"clix", scriptPath})
+	if err == nil || !strings.Contains(err.Error(), outside) {
+		t.Fatalf("Expected the mounts to need approval, got %v", err)
+	}
+	if pathExists(outside+"/toolrc") || pathExists(outside+"/state") {
+		t.Errorf("Expected a declined run to create nothing")
+	}
+
+	if err := run(strings.NewReader(""), io.Discard, io.Discard, []string{"clix", "--dry-run", scriptPath}); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if pathExists(outside+"/toolrc") || pathExists(outside+"/state") {
+		t.Errorf("Expected a dry run to create nothing")
+	}
+}
+
 func TestBuildDockerArgs(t *testing.T) {
 	// Mock getImageSHA
 	originalGetImageSHA := getImageSHAFn
 	defer func() { getImageSHAFn = originalGetImageSHA }()
-	getImageSHAFn = func(image string) (string, error) {
+	getImageSHAFn = func(cli []string, image string) (string, error) {
 		return "mocksha256", nil
 	}
 
@@ -303,6 +416,36 @@ func TestBuildDockerArgs(t *testing.T) {
 	}
 }
 
+func TestBuildContainerArgsPlatform(t *testing.T) {
+	cmdArgs, err := buildDockerArgs(Script{Image: "alpine", Platform: "linux/amd64"}, nil, false)
+	if err != nil {
+		t.Fatalf("buildDockerArgs failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(cmdArgs, " "), "--platform linux/amd64 alpine") {
+		t.Errorf("Expected --platform flag, got %v", cmdArgs)
+	}
+}
+
+func TestBuildContainerArgsReadOnly(t *testing.T) {
+	cmdArgs, err := buildDockerArgs(Script{Image: "alpine", Mounts: []Mount{{HostPath: "/etc/tool", SandboxPath: "/config", ReadOnly: true}}}, nil, false)
+	if err != nil {
+		t.Fatalf("buildDockerArgs failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(cmdArgs, " "), "-v /etc/tool:/config:ro") {
+		t.Errorf("Expected a read-only volume, got %v", cmdArgs)
+	}
+}
+
+func TestBuildContainerArgsDevices(t *testing.T) {
+	cmdArgs, err := buildDockerArgs(Script{Image: "alpine", Devices: []string{"/dev/fuse"}, GPUs: "all"}, nil, false)
+	if err != nil {
+		t.Fatalf("buildDockerArgs failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(cmdArgs, " "), "--device /dev/fuse --gpus all alpine") {
+		t.Errorf("Expected --device and --gpus flags, got %v", cmdArgs)
+	}
+}
+
 func TestBuildImage(t *testing.T) {
 	execCommand = fakeExecCommand
 	defer func() { execCommand = exec.Command }()
@@ -315,7 +458,7 @@ func TestBuildImage(t *testing.T) {
 		Git: "https://github.com/example/repo",
 	}
 
-	imageTag, err := buildImage(stdin, &stdout, &stderr, build, "test-script.yaml")
+	imageTag, err := buildImage(stdin, &stdout, &stderr, Script{Build: build}, "test-script.yaml")
 	if err != nil {
 		t.Fatalf("buildImage failed: %v", err)
 	}
@@ -360,7 +503,7 @@ func TestBuildImage_Exists(t *testing.T) {
 		Git: "https://github.com/example/repo",
 	}
 
-	imageTag, err := buildImage(stdin, &stdout, &stderr, build, "test-script.yaml")
+	imageTag, err := buildImage(stdin, &stdout, &stderr, Script{Build: build}, "test-script.yaml")
 
 	if err != nil {
 
@@ -394,3 +537,82 @@ func TestBuildImage_Exists(t *testing.T) {
 	}
 
 }
+
+func TestDockerCLI(t *testing.T) {
+	if cli := dockerCLI(Script{}); strings.Join(cli, " ") != "docker" {
+		t.Errorf("Expected plain docker CLI, got %v", cli)
+	}
+
+	cli := dockerCLI(Script{DockerContext: "colima"})
+	if strings.Join(cli, " ") != "docker --context colima" {
+		t.Errorf("Expected docker CLI with context, got %v", cli)
+	}
+}
+
+func TestResolveDockerRuntime(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	os.Setenv("MOCK_BEHAVIOR", "kata_installed")
+	runtime, err := resolveDockerRuntime([]string{"docker"}, "kata")
+	os.Unsetenv("MOCK_BEHAVIOR")
+	if err != nil {
+		t.Fatalf("resolveDockerRuntime failed: %v", err)
+	}
+	if runtime != "io.containerd.kata.v2" {
+		t.Errorf("Expected kata runtime io.containerd.kata.v2, got %q", runtime)
+	}
+
+	_, err = resolveDockerRuntime([]string{"docker"}, "kata")
+	if err == nil || !strings.Contains(err.Error(), "not installed") || !strings.Contains(err.Error(), "runc") {
+		t.Errorf("Expected error listing available runtimes, got %v", err)
+	}
+
+	cmdArgs, err := buildDockerArgs(Script{Image: "alpine", Runtime: "io.containerd.kata.v2"}, nil, false)
+	if err != nil {
+		t.Fatalf("buildDockerArgs failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(cmdArgs, " "), "--runtime io.containerd.kata.v2 alpine") {
+		t.Errorf("Expected --runtime flag, got %v", cmdArgs)
+	}
+}
+
+func TestCheckNvidiaToolkit(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("PATH", t.TempDir())
+
+	t.Setenv("MOCK_BEHAVIOR", "nvidia_installed")
+	if err := checkNvidiaToolkit([]string{"docker"}); err != nil {
+		t.Errorf("Expected the nvidia runtime to be found, got %v", err)
+	}
+
+	t.Setenv("MOCK_BEHAVIOR", "")
+	err := checkNvidiaToolkit([]string{"docker"})
+	if err == nil || !strings.Contains(err.Error(), "NVIDIA container toolkit") {
+		t.Errorf("Expected an error suggesting the toolkit, got %v", err)
+	}
+}
+
+// passthroughSandbox is a NativeSandbox which runs the command unconfined.
+type passthroughSandbox struct{}
+
+func (passthroughSandbox) Command(script Script, name string, args ...string) (*exec.Cmd, error) {
+	return exec.Command(name, args...), nil
+}
+
+func TestRunGoNative(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get cwd: %v", err)
+	}
+	script := Script{Go: &GoConfig{Run: filepath.Join(cwd, "tests", "test-tool")}}
+
+	var stdout, stderr bytes.Buffer
+	if err := runGo(strings.NewReader(""), &stdout, &stderr, script, passthroughSandbox{}, []string{"foo"}); err != nil {
+		t.Fatalf("runGo failed: %v (stderr %q)", err, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Arg 0: foo") {
+		t.Errorf("Expected the built tool to run, got %q", stdout.String())
+	}
+}
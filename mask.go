@@ -0,0 +1,86 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maskMounts returns the mounts which hide the mask paths of a resolved mount from the tool, by mounting
+// an empty file or directory over each of them. Mask paths are relative to the host path, or absolute or
+// ~ paths beneath it; paths which don't exist have nothing to hide and are skipped.
+func maskMounts(m Mount, home string) ([]Mount, error) {
+	var masks []Mount
+	for _, p := range m.Mask {
+		hostPath := p
+		if strings.HasPrefix(hostPath, "~/") {
+			hostPath = filepath.Join(home, hostPath[2:])
+		} else if !filepath.IsAbs(hostPath) {
+			hostPath = filepath.Join(m.HostPath, hostPath)
+		}
+		rel, err := filepath.Rel(m.HostPath, hostPath)
+		if err != nil || rel == "." || !isWithin(m.HostPath, hostPath) {
+			return nil, fmt.Errorf("mask %s is not beneath the mount %s", p, m.HostPath)
+		}
+
+		info, err := os.Lstat(hostPath)
+		if os.IsNotExist(err) {
+			log(1, "Skipping mask %s: it does not exist", hostPath)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		empty, err := emptyMaskPath(info.IsDir())
+		if err != nil {
+			return nil, fmt.Errorf("mask %s: %w", p, err)
+		}
+		masks = append(masks, Mount{
+			HostPath:    empty,
+			SandboxPath: path.Join(m.SandboxPath, filepath.ToSlash(rel)),
+			ReadOnly:    true,
+			masked:      true,
+		})
+	}
+	return masks, nil
+}
+
+// emptyMaskPath returns the empty directory, or empty file, which is mounted over masked paths.
+func emptyMaskPath(dir bool) (string, error) {
+	userCache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user cache dir: %w", err)
+	}
+	base := filepath.Join(userCache, "clix", "mask")
+	if dir {
+		empty := filepath.Join(base, "dir")
+		if err := os.MkdirAll(empty, 0755); err != nil {
+			return "", err
+		}
+		return empty, nil
+	}
+	if err := os.MkdirAll(base, 0755); err != nil {
+		return "", err
+	}
+	// Truncate the file, in case anything has written to it
+	empty := filepath.Join(base, "file")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		return "", err
+	}
+	return empty, nil
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMaskMounts(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	gcloud := t.TempDir()
+	if err := os.WriteFile(filepath.Join(gcloud, "application_default_credentials.json"), []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(gcloud, "legacy_credentials"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	resolved, err := resolveMounts([]Mount{{
+		HostPath:    gcloud,
+		SandboxPath: "/root/.config/gcloud",
+		Mask:        []string{"application_default_credentials.json", gcloud + "/legacy_credentials", "missing"},
+	}}, "")
+	if err != nil {
+		t.Fatalf("resolveMounts failed: %v", err)
+	}
+	if len(resolved) != 3 {
+		t.Fatalf("Expected the mount and two masks, got %+v", resolved)
+	}
+
+	file, dir := resolved[1], resolved[2]
+	if file.SandboxPath != "/root/.config/gcloud/application_default_credentials.json" || !file.ReadOnly || !file.masked {
+		t.Errorf("Unexpected file mask %+v", file)
+	}
+	if data, err := os.ReadFile(file.HostPath); err != nil || len(data) != 0 {
+		t.Errorf("Expected the file mask to be an empty file, got %q, %v", data, err)
+	}
+	if dir.SandboxPath != "/root/.config/gcloud/legacy_credentials" || !dir.masked {
+		t.Errorf("Unexpected directory mask %+v", dir)
+	}
+	if entries, err := os.ReadDir(dir.HostPath); err != nil || len(entries) != 0 {
+		t.Errorf("Expected the directory mask to be an empty directory, got %v, %v", entries, err)
+	}
+
+	// Masks are not approved or listed as mounts
+	if escaping, err := escapingMountPaths([]Mount{{HostPath: gcloud, Mask: []string{"legacy_credentials"}}}); err != nil || len(escaping) != 1 {
+		t.Errorf("Expected only the mount to need approval, got %v, %v", escaping, err)
+	}
+
+	_, err = resolveMounts([]Mount{{HostPath: gcloud, Mask: []string{"../other"}}}, "")
+	if err == nil || !strings.Contains(err.Error(), "not beneath") {
+		t.Errorf("Expected an error for a mask outside the mount, got %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// NetworkPolicy is the tool's network: a mode (none, host, bridge or a network name), or an egress
+// allowlist of domains.
+type NetworkPolicy struct {
+	// Mode is the network mode
+	Mode string `json:"mode,omitempty"`
+	// Allow are the domains the tool may reach, through an egress proxy; *.example.com allows the
+	// subdomains of example.com
+	Allow []string `json:"allow,omitempty"`
+}
+
+func (n *NetworkPolicy) UnmarshalJSON(data []byte) error {
+	var mode string
+	if err := json.Unmarshal(data, &mode); err == nil {
+		*n = NetworkPolicy{Mode: mode}
+		return nil
+	}
+	type plain NetworkPolicy
+	return json.Unmarshal(data, (*plain)(n))
+}
+
+func (n NetworkPolicy) MarshalJSON() ([]byte, error) {
+	if len(n.Allow) == 0 {
+		return json.Marshal(n.Mode)
+	}
+	type plain NetworkPolicy
+	return json.Marshal(plain(n))
+}
+
+// containerEngineSandboxes are the sandboxes whose container engine implements every network mode.
+var containerEngineSandboxes = map[string]bool{"docker": true, "podman": true, "nerdctl": true, "wsl": true, "apple-container": true}
+
+// hostNetworkSandboxes are the sandboxes which always share the host's network, so can't implement
+// network: none.
+var hostNetworkSandboxes = map[string]bool{"chroot": true, "proot": true, "landlock": true}
+
+// applyNetworkFlag applies --network (CLIX_NETWORK), which takes precedence over the script.
+func applyNetworkFlag(script *Script) {
+	if network := os.Getenv("CLIX_NETWORK"); network != "" {
+		script.Network = NetworkPolicy{Mode: network}
+	}
+}
+
+// egressSandboxes are the sandboxes which implement network.allow (nerdctl can't connect running
+// containers to networks) and compose networks.
+var egressSandboxes = map[string]bool{"docker": true, "podman": true}
+
+// checkNetwork returns an error if the built-in sandbox can't implement the script's network, rather
+// than silently giving the tool a different network.
+func checkNetwork(sandboxType string, policy NetworkPolicy) error {
+	if len(policy.Allow) > 0 {
+		if policy.Mode != "" {
+			return fmt.Errorf("network: set a mode or allow, not both")
+		}
+		if !egressSandboxes[sandboxType] {
+			return fmt.Errorf("network.allow is not supported by the %s sandbox; use docker or podman", sandboxType)
+		}
+		return nil
+	}
+	network := policy.Mode
+	if isComposeNetwork(network) && !egressSandboxes[sandboxType] {
+		return fmt.Errorf("network: %s is not supported by the %s sandbox; use docker or podman", network, sandboxType)
+	}
+	switch {
+	case network == "" || containerEngineSandboxes[sandboxType]:
+		return nil
+	case network == "none" && hostNetworkSandboxes[sandboxType]:
+		return fmt.Errorf("network: none is not supported by the %s sandbox, which shares the host's network", sandboxType)
+	case network == "none" || network == "host" && sandboxType != "firecracker":
+		return nil
+	}
+	return fmt.Errorf("network: %s is not supported by the %s sandbox; use a container engine such as docker", network, sandboxType)
+}
+
+// egressProxyImage is the image of the egress proxy.
+var egressProxyImage = "docker.io/ubuntu/squid:latest"
+
+// egressProxyPort is the port the egress proxy listens on.
+const egressProxyPort = "3128"
+
+// egressProxyTimeout is how long to wait for the egress proxy to accept connections, polling its
+// logs every egressProxyPoll.
+var (
+	egressProxyTimeout = 30 * time.Second
+	egressProxyPoll    = 100 * time.Millisecond
+)
+
+// startEgressProxy implements network.allow for container engines: it creates an internal network,
+// which has no route out, and a squid proxy attached to it and to the default network, which only
+// forwards requests for the allowed domains. It returns the script on the internal network with the
+// proxy env vars set, and a function which removes the proxy and network. Without network.allow, it
+// returns the script unchanged.
+func startEgressProxy(cli []string, script Script) (Script, func(), error) {
+	if len(script.Network.Allow) == 0 {
+		return script, func() {}, nil
+	}
+	if err := checkOnline("the egress proxy image " + egressProxyImage); err != nil {
+		return script, nil, err
+	}
+	config, err := squidConfig(script.Network.Allow)
+	if err != nil {
+		return script, nil, err
+	}
+	name := fmt.Sprintf("clix-egress-%d-%d", os.Getpid(), time.Now().UnixNano())
+	engine := func(args ...string) error {
+		if dryRunEnabled() {
+			fmt.Fprintf(os.Stderr, "clix: dry run: not running %s\n", displayCommand(append(cli, args...)))
+			return nil
+		}
+		out, err := execCommand(cli[0], append(cli[1:], args...)...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s %s failed: %w: %s", cli[0], args[0], err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+
+	log(1, "Starting the egress proxy %s, allowing %s", name, strings.Join(script.Network.Allow, ", "))
+	if err := engine("network", "create", "--internal", name); err != nil {
+		return script, nil, fmt.Errorf("creating the egress network: %w", err)
+	}
+	stop := func() {
+		log(1, "Removing the egress proxy %s", name)
+		engine("rm", "-f", name)
+		engine("network", "rm", name)
+	}
+	// The config is passed in the environment, as host files may not be visible to a remote engine
+	if err := engine("run", "-d", "--rm", "--name", name, "-e", "CLIX_SQUID_CONF="+config, "--entrypoint", "sh", egressProxyImage,
+		"-c", `printf '%s\n' "$CLIX_SQUID_CONF" > /etc/squid/squid.conf && exec squid -N -f /etc/squid/squid.conf`); err != nil {
+		stop()
+		return script, nil, fmt.Errorf("starting the egress proxy: %w", err)
+	}
+	if err := engine("network", "connect", name, name); err != nil {
+		stop()
+		return script, nil, fmt.Errorf("connecting the egress proxy: %w", err)
+	}
+	if !dryRunEnabled() {
+		if err := waitForEgressProxy(cli, name); err != nil {
+			stop()
+			return script, nil, err
+		}
+	}
+
+	proxyURL := "http://" + name + ":" + egressProxyPort
+	var env []EnvVar
+	for _, e := range script.Env {
+		if !isProxyVar(e.Name) {
+			env = append(env, e)
+		}
+	}
+	for _, n := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+		env = append(env, EnvVar{Name: n, Value: proxyURL})
+	}
+	script.Env = env
+	script.Network = NetworkPolicy{Mode: name}
+	return script, stop, nil
+}
+
+// waitForEgressProxy waits until squid logs that it accepts connections, as the tool may connect to it
+// as soon as it starts.
+func waitForEgressProxy(cli []string, name string) error {
+	deadline := time.Now().Add(egressProxyTimeout)
+	for {
+		out, err := execCommand(cli[0], append(cli[1:], "logs", name)...).CombinedOutput()
+		if err == nil && strings.Contains(string(out), "Accepting HTTP") {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("the egress proxy did not start listening within %s: %s", egressProxyTimeout, strings.TrimSpace(string(out)))
+		}
+		time.Sleep(egressProxyPoll)
+	}
+}
+
+// isProxyVar reports whether name is one of the proxy env vars.
+func isProxyVar(name string) bool {
+	for _, n := range proxyEnvNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// squidConfig returns the squid configuration which only allows the domains, and HTTPS only on port 443.
+func squidConfig(allow []string) (string, error) {
+	var domains []string
+	for _, d := range allow {
+		if rest, ok := strings.CutPrefix(d, "*."); ok {
+			// squid matches .example.com against example.com and its subdomains
+			d = "." + rest
+		}
+		if d == "" || strings.ContainsAny(d, "*?[ ") {
+			return "", fmt.Errorf("network.allow: %q must be a domain, or *. followed by a domain", d)
+		}
+		domains = append(domains, d)
+	}
+	return strings.Join([]string{
+		"http_port " + egressProxyPort,
+		"acl allowed dstdomain " + strings.Join(domains, " "),
+		"acl SSL_ports port 443",
+		"acl CONNECT method CONNECT",
+		"http_access deny CONNECT !SSL_ports",
+		"http_access allow allowed",
+		"http_access deny all",
+		"cache deny all",
+		"access_log stdio:/dev/stdout",
+		// The logs say when squid accepts connections
+		"cache_log /dev/stderr",
+	}, "\n"), nil
+}
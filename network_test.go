@@ -0,0 +1,160 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckNetwork(t *testing.T) {
+	for _, tc := range []struct {
+		sandbox string
+		network string
+		ok      bool
+	}{
+		{"docker", "none", true},
+		{"podman", "my-net", true},
+		{"chroot", "", true},
+		{"chroot", "none", false},
+		{"landlock", "host", true},
+		{"seatbelt", "none", true},
+		{"runc", "bridge", false},
+		{"firecracker", "host", false},
+	} {
+		if err := checkNetwork(tc.sandbox, NetworkPolicy{Mode: tc.network}); (err == nil) != tc.ok {
+			t.Errorf("checkNetwork(%q, %q) = %v, want ok=%v", tc.sandbox, tc.network, err, tc.ok)
+		}
+	}
+
+	t.Setenv("CLIX_NETWORK", "none")
+	script := Script{Image: "formatter", Network: NetworkPolicy{Mode: "bridge"}}
+	applyNetworkFlag(&script)
+	cmdArgs, err := buildContainerArgs([]string{"docker"}, script, nil, false)
+	if err != nil {
+		t.Fatalf("buildContainerArgs failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(cmdArgs, " "), "--network none") {
+		t.Errorf("Expected --network to override the script, got %v", cmdArgs)
+	}
+}
+
+func TestNetworkPolicyJSON(t *testing.T) {
+	var script Script
+	if err := json.Unmarshal([]byte(`{"network": "none"}`), &script); err != nil || script.Network.Mode != "none" {
+		t.Errorf("Expected a mode, got %+v, %v", script.Network, err)
+	}
+	if err := json.Unmarshal([]byte(`{"network": {"allow": ["*.googleapis.com", "proxy.golang.org"]}}`), &script); err != nil ||
+		!reflect.DeepEqual(script.Network.Allow, []string{"*.googleapis.com", "proxy.golang.org"}) {
+		t.Errorf("Expected an allowlist, got %+v, %v", script.Network, err)
+	}
+	if data, _ := json.Marshal(Script{Network: NetworkPolicy{Mode: "host"}}); string(data) != `{"network":"host"}` {
+		t.Errorf("Expected the mode to be marshalled as a string, got %s", data)
+	}
+	if data, _ := json.Marshal(Script{}); string(data) != `{}` {
+		t.Errorf("Expected no network, got %s", data)
+	}
+
+	allow := NetworkPolicy{Allow: []string{"example.com"}}
+	if err := checkNetwork("docker", allow); err != nil {
+		t.Errorf("Expected docker to support an allowlist, got %v", err)
+	}
+	if err := checkNetwork("nerdctl", allow); err == nil {
+		t.Errorf("Expected an error for an allowlist with nerdctl")
+	}
+}
+
+func TestStartEgressProxy(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("MOCK_CALLS", calls)
+
+	script := Script{
+		Image:   "third-party-cli",
+		Network: NetworkPolicy{Allow: []string{"*.googleapis.com", "proxy.golang.org"}},
+		Env:     []EnvVar{{Name: "HTTPS_PROXY", Value: "http://proxy.corp:3128"}, {Name: "PROJECT", Value: "p"}},
+	}
+	proxied, stop, err := startEgressProxy([]string{"docker"}, script)
+	if err != nil {
+		t.Fatalf("startEgressProxy failed: %v", err)
+	}
+	stop()
+
+	name := proxied.Network.Mode
+	if !strings.HasPrefix(name, "clix-egress-") {
+		t.Fatalf("Expected the egress network, got %+v", proxied.Network)
+	}
+	if len(proxied.Env) != 5 || proxied.Env[0].Name != "PROJECT" || proxied.Env[2] != (EnvVar{Name: "HTTPS_PROXY", Value: "http://" + name + ":3128"}) {
+		t.Errorf("Expected the proxy env to replace the host's, got %+v", proxied.Env)
+	}
+	data, _ := os.ReadFile(calls)
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		// Skip the lines of the squid config, which is in the run command's env
+		if strings.HasPrefix(line, "docker ") {
+			lines = append(lines, line)
+		}
+	}
+	want := []string{
+		"docker network create --internal " + name,
+		"docker run -d --rm --name " + name,
+		"docker network connect " + name + " " + name,
+		"docker logs " + name,
+		"docker rm -f " + name,
+		"docker network rm " + name,
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d commands, got %q", len(want), lines)
+	}
+	for i := range want {
+		if !strings.HasPrefix(lines[i], want[i]) {
+			t.Errorf("Expected %q, got %q", want[i], lines[i])
+		}
+	}
+
+	config, err := squidConfig(script.Network.Allow)
+	if err != nil || !strings.Contains(config, "acl allowed dstdomain .googleapis.com proxy.golang.org\n") {
+		t.Errorf("Unexpected squid config %q, %v", config, err)
+	}
+	if _, err := squidConfig([]string{"*"}); err == nil {
+		t.Errorf("Expected an error for a wildcard which is not a domain")
+	}
+}
+
+func TestStartEgressProxyNotReady(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("MOCK_BEHAVIOR", "proxy_not_ready")
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("MOCK_CALLS", calls)
+	defer func(timeout, poll time.Duration) { egressProxyTimeout, egressProxyPoll = timeout, poll }(egressProxyTimeout, egressProxyPoll)
+	egressProxyTimeout, egressProxyPoll = 50*time.Millisecond, 10*time.Millisecond
+
+	script := Script{Image: "third-party-cli", Network: NetworkPolicy{Allow: []string{"example.com"}}}
+	if _, _, err := startEgressProxy([]string{"docker"}, script); err == nil || !strings.Contains(err.Error(), "did not start listening") {
+		t.Fatalf("Expected the run to wait for the proxy, got %v", err)
+	}
+	data, _ := os.ReadFile(calls)
+	if !strings.Contains(string(data), "docker network rm clix-egress-") {
+		t.Errorf("Expected the proxy to be removed, got %q", data)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// offlineMode reports whether clix runs offline (--offline, CLIX_OFFLINE), using only local caches.
+func offlineMode() bool {
+	return os.Getenv("CLIX_OFFLINE") != ""
+}
+
+// checkOnline returns an error in offline mode, for something which would be downloaded, so that
+// offline runs fail fast rather than waiting for a network timeout.
+func checkOnline(what string) error {
+	if offlineMode() {
+		return fmt.Errorf("offline: %s would need to be downloaded; run the script online first to cache it", what)
+	}
+	return nil
+}
+
+// applyOffline forces the settings of offline runs: no network, images from the local store only, and
+// go modules from the module cache only.
+func applyOffline(script *Script) {
+	if !offlineMode() {
+		return
+	}
+	log(1, "Running offline")
+	script.Network = NetworkPolicy{Mode: "none"}
+	script.PullPolicy = "never"
+	if script.Go != nil {
+		for _, e := range []EnvVar{{Name: "GOPROXY", Value: "off"}, {Name: "GOTOOLCHAIN", Value: "local"}} {
+			script.Env = mergeEnvVar(script.Env, e)
+			// go builds on the host inherit its environment
+			os.Setenv(e.Name, e.Value)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+func TestApplyOffline(t *testing.T) {
+	t.Setenv("CLIX_OFFLINE", "")
+	script := Script{Image: "tool", PullPolicy: "always"}
+	applyOffline(&script)
+	if script.PullPolicy != "always" || script.Network.Mode != "" {
+		t.Errorf("Expected the script to be unchanged online, got %+v", script)
+	}
+
+	t.Setenv("CLIX_OFFLINE", "1")
+	t.Setenv("GOPROXY", "")
+	t.Setenv("GOTOOLCHAIN", "")
+	script = Script{Go: &GoConfig{Run: "golang.org/x/tools/cmd/stringer", Version: "v0.30.0"}, Network: NetworkPolicy{Allow: []string{"proxy.golang.org"}}}
+	applyOffline(&script)
+	if script.PullPolicy != "never" || script.Network.Mode != "none" || len(script.Network.Allow) != 0 {
+		t.Errorf("Expected no network and no pulls, got %+v", script)
+	}
+	if len(script.Env) != 2 || script.Env[0] != (EnvVar{Name: "GOPROXY", Value: "off"}) || os.Getenv("GOPROXY") != "off" {
+		t.Errorf("Expected go modules to come from the cache, got %+v", script.Env)
+	}
+
+	err := downloadFile("https://example.com/tool.tar.gz", filepath.Join(t.TempDir(), "tool.tar.gz"))
+	if err == nil || !strings.Contains(err.Error(), "offline: https://example.com/tool.tar.gz would need to be downloaded") {
+		t.Errorf("Expected downloads to fail offline, got %v", err)
+	}
+	if _, _, _, err := prepareRootFS("alpine", v1.Platform{OS: "linux", Architecture: "amd64"}); err == nil || !strings.Contains(err.Error(), "don't cache images") {
+		t.Errorf("Expected image pulls to fail offline, got %v", err)
+	}
+	if err := verifyMavenChecksum("https://example.com/tool.jar", filepath.Join(t.TempDir(), "tool.jar")); err == nil || !strings.Contains(err.Error(), "offline") {
+		t.Errorf("Expected checksum downloads to fail offline, got %v", err)
+	}
+}
+
+func TestOfflineEngineDownloads(t *testing.T) {
+	t.Setenv("CLIX_OFFLINE", "1")
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("MOCK_CALLS", calls)
+
+	// The mock engine has no images, so they would be pulled
+	if _, err := getImageSHA([]string{"docker"}, "alpine"); err == nil || !strings.Contains(err.Error(), "offline: image alpine") {
+		t.Errorf("Expected the image pull to fail offline, got %v", err)
+	}
+	if _, _, err := startEgressProxy([]string{"docker"}, Script{Network: NetworkPolicy{Allow: []string{"example.com"}}}); err == nil || !strings.Contains(err.Error(), "offline: the egress proxy image") {
+		t.Errorf("Expected the egress proxy to fail offline, got %v", err)
+	}
+	script := Script{Image: "alpine", Build: &BuildConfig{Git: "https://example.com/tool.git", Commit: "0123456789abcdef0123456789abcdef01234567"}}
+	if _, err := buildImage(strings.NewReader(""), io.Discard, io.Discard, script, "tool.yaml"); err == nil || !strings.Contains(err.Error(), "offline: the repository https://example.com/tool.git") {
+		t.Errorf("Expected the clone to fail offline, got %v", err)
+	}
+
+	data, _ := os.ReadFile(calls)
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.Contains(line, " pull ") || strings.Contains(line, "clone") || strings.Contains(line, " run ") {
+			t.Errorf("Expected nothing to be downloaded, got %q", line)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// publishedPorts returns the ports published with --publish (CLIX_PUBLISH, comma separated), in addition
+// to the script's ports.
+func publishedPorts() []string {
+	var ports []string
+	for _, p := range strings.Split(os.Getenv("CLIX_PUBLISH"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			ports = append(ports, p)
+		}
+	}
+	return ports
+}
+
+// addPublishedPort appends a --publish port to CLIX_PUBLISH, so that it applies to the script run.
+func addPublishedPort(port string) {
+	if ports := os.Getenv("CLIX_PUBLISH"); ports != "" {
+		port = ports + "," + port
+	}
+	os.Setenv("CLIX_PUBLISH", port)
+}
+
+// publishFlag is the repeatable --publish flag of clix run.
+type publishFlag struct{}
+
+func (publishFlag) String() string { return "" }
+
+func (publishFlag) Set(port string) error {
+	addPublishedPort(port)
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPublishedPorts(t *testing.T) {
+	t.Setenv("CLIX_PUBLISH", "")
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.Var(publishFlag{}, "publish", "")
+	if err := flags.Parse([]string{"--publish", "8080:8080", "--publish=127.0.0.1:9090:9090"}); err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got, want := publishedPorts(), []string{"8080:8080", "127.0.0.1:9090:9090"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("publishedPorts() = %v, want %v", got, want)
+	}
+
+	script := Script{Image: "docs-preview", Ports: []string{"3000:3000/tcp"}}
+	cmdArgs, err := buildContainerArgs([]string{"docker"}, script, nil, false)
+	if err != nil {
+		t.Fatalf("buildContainerArgs failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(cmdArgs, " "), "-p 3000:3000/tcp") {
+		t.Errorf("Expected the port to be published, got %v", cmdArgs)
+	}
+}
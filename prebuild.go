@@ -0,0 +1,72 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// prebuildOnly is set by clix build, which prepares scripts without running their tools.
+var prebuildOnly bool
+
+// runBuild implements `clix build <script>[:command]...`, which does everything a run does before the
+// tool starts: building build: images, pulling images, compiling go tools and downloading binaries and
+// packages into the clix cache, so that CI can prepare tools ahead of their first run. The tool, its
+// hooks and its prompts are not run.
+func runBuild(stdin io.Reader, stdout, stderr io.Writer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: clix build <script>[:command]...")
+	}
+	prebuildOnly = true
+	defer func() { prebuildOnly = false }()
+	for _, script := range args {
+		if err := run(stdin, stdout, stderr, []string{"clix", script}); err != nil {
+			return fmt.Errorf("building %s: %w", script, err)
+		}
+		fmt.Fprintf(stdout, "%s: ready\n", script)
+	}
+	return nil
+}
+
+// prebuildSandbox prepares the image of the tool for clix build, rather than running the tool.
+type prebuildSandbox struct {
+	Sandbox
+	sandboxType string
+}
+
+func (s *prebuildSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
+	var cli []string
+	switch s.sandboxType {
+	case "docker":
+		cli = dockerCLI(script)
+	case "podman", "nerdctl":
+		cli = []string{s.sandboxType}
+	case "wsl":
+		cli = wslDockerCLI(os.Getenv("CLIX_WSL_DISTRO"))
+	default:
+		log(1, "Not pulling %s: the %s sandbox pulls the image when the tool runs", script.Image, s.sandboxType)
+		return nil
+	}
+	if script.PullPolicy == "never" {
+		return nil
+	}
+	if _, err := getImageSHAFn(cli, script.Image); err != nil {
+		return err
+	}
+	log(1, "Image %s is ready", script.Image)
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuild(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("CLIX_SANDBOX", "docker")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("MOCK_CALLS", calls)
+	defer func(fn func([]string, string) (string, error)) { getImageSHAFn = fn }(getImageSHAFn)
+	var pulled []string
+	getImageSHAFn = func(cli []string, image string) (string, error) {
+		pulled = append(pulled, image)
+		return "mocksha256", nil
+	}
+
+	dir := t.TempDir()
+	imagePath := filepath.Join(dir, "jq.yaml")
+	os.WriteFile(imagePath, []byte("image: ghcr.io/jqlang/jq:1.7.1\nconfirm: Run jq?\nmountCwd: false\n"), 0644)
+	goPath := filepath.Join(dir, "tool.yaml")
+	os.WriteFile(goPath, []byte("go:\n  run: example.com/tool\n  version: v1.2.3\n"), 0644)
+
+	// Nothing is asked for or run, so the confirmation prompt doesn't fail without a terminal
+	var stdout, stderr bytes.Buffer
+	if err := run(strings.NewReader(""), &stdout, &stderr, []string{"clix", "build", imagePath, goPath}); err != nil {
+		t.Fatalf("build failed: %v: %s", err, stderr.String())
+	}
+	if want := imagePath + ": ready\n" + goPath + ": ready\n"; stdout.String() != want {
+		t.Errorf("Unexpected output %q, want %q", stdout.String(), want)
+	}
+	if len(pulled) != 1 || pulled[0] != "ghcr.io/jqlang/jq:1.7.1" {
+		t.Errorf("Expected the image to be pulled, got %v", pulled)
+	}
+	data, _ := os.ReadFile(calls)
+	if string(data) != "go install example.com/tool@v1.2.3\n" {
+		t.Errorf("Expected only the go tool to be built, got:\n%s", data)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Profile is a named variant of a script (dev, ci etc), overriding its image, env and mounts.
+type Profile struct {
+	// Image replaces the script's image, e.g. to use a different tag
+	Image string `json:"image,omitempty"`
+	// Env is merged into the script's env, replacing variables of the same name
+	Env []EnvVar `json:"env,omitempty"`
+	// Mounts are merged into the script's mounts, replacing mounts at the same sandbox path
+	Mounts []Mount `json:"mounts,omitempty"`
+}
+
+// Override is applied to the script when its condition holds, e.g. to pick a different image on macOS.
+type Override struct {
+	// When is the condition, an expression over os and arch such as `os == "darwin"`
+	When string `json:"when"`
+	Profile
+}
+
+// selectedProfile returns the profile chosen with --profile or CLIX_PROFILE.
+func selectedProfile() string {
+	return os.Getenv("CLIX_PROFILE")
+}
+
+// applyProfile merges the named profile into the script.
+func applyProfile(script *Script, name string) error {
+	if name == "" {
+		return nil
+	}
+	profile, ok := script.Profiles[name]
+	if !ok {
+		var names []string
+		for n := range script.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			return fmt.Errorf("profile %q not found: the script has no profiles", name)
+		}
+		return fmt.Errorf("profile %q not found (available: %s)", name, strings.Join(names, ", "))
+	}
+	log(1, "Using profile %s", name)
+	mergeProfile(script, profile)
+	return nil
+}
+
+// applyOverrides merges the overrides whose conditions hold into the script, in order.
+func applyOverrides(script *Script) error {
+	for i, o := range script.Overrides {
+		ok, err := evalCondition(o.When)
+		if err != nil {
+			return fmt.Errorf("overrides[%d]: %w", i, err)
+		}
+		if ok {
+			log(1, "Applying override %s", o.When)
+			mergeProfile(script, o.Profile)
+		}
+	}
+	return nil
+}
+
+// mergeProfile merges the image, env and mounts of the profile into the script.
+func mergeProfile(script *Script, profile Profile) {
+	if profile.Image != "" {
+		script.Image = profile.Image
+	}
+	for _, e := range profile.Env {
+		script.Env = mergeEnvVar(script.Env, e)
+	}
+	for _, m := range profile.Mounts {
+		script.Mounts = mergeMount(script.Mounts, m)
+	}
+}
+
+// mergeEnvVar returns env with e added, replacing any variable of the same name.
+func mergeEnvVar(env []EnvVar, e EnvVar) []EnvVar {
+	for i := range env {
+		if env[i].Name == e.Name {
+			env[i] = e
+			return env
+		}
+	}
+	return append(env, e)
+}
+
+// mergeMount returns mounts with m added, replacing any mount at the same sandbox path.
+func mergeMount(mounts []Mount, m Mount) []Mount {
+	for i := range mounts {
+		if mountTarget(mounts[i]) == mountTarget(m) {
+			mounts[i] = m
+			return mounts
+		}
+	}
+	return append(mounts, m)
+}
+
+// mountTarget is the sandbox path of the mount, as written in the script.
+func mountTarget(m Mount) string {
+	if m.SandboxPath != "" {
+		return m.SandboxPath
+	}
+	return m.HostPath
+}
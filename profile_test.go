@@ -0,0 +1,112 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestApplyProfile(t *testing.T) {
+	data := `
+image: tool:dev
+env:
+- name: MODE
+  value: dev
+- name: REGION
+  value: us-central1
+mounts:
+- hostPath: git.repoRoot(cwd)
+- hostPath: ~/.config/tool
+  sandboxPath: /root/.config/tool
+profiles:
+  ci:
+    image: tool:1.2.3
+    env:
+    - name: MODE
+      value: ci
+    mounts:
+    - hostPath: /etc/ci/tool
+      sandboxPath: /root/.config/tool
+`
+	var script Script
+	if err := yaml.Unmarshal([]byte(data), &script); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := applyProfile(&script, "ci"); err != nil {
+		t.Fatalf("applyProfile failed: %v", err)
+	}
+	if script.Image != "tool:1.2.3" {
+		t.Errorf("Unexpected image %q", script.Image)
+	}
+	if len(script.Env) != 2 || script.Env[0] != (EnvVar{Name: "MODE", Value: "ci"}) || script.Env[1].Name != "REGION" {
+		t.Errorf("Unexpected env %+v", script.Env)
+	}
+	if len(script.Mounts) != 2 || script.Mounts[1].HostPath != "/etc/ci/tool" {
+		t.Errorf("Unexpected mounts %+v", script.Mounts)
+	}
+
+	if err := applyProfile(&script, "prod"); err == nil || !strings.Contains(err.Error(), "available: ci") {
+		t.Errorf("Expected an error listing the profiles, got %v", err)
+	}
+	if err := applyProfile(&script, ""); err != nil {
+		t.Errorf("Expected no profile to be a no-op, got %v", err)
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	data := `
+image: tool:1.2.3
+mounts:
+- hostPath: ~/.config/tool
+  sandboxPath: /root/.config/tool
+overrides:
+- when: os == "` + runtime.GOOS + `"
+  image: tool:1.2.3-host
+  mounts:
+  - hostPath: ~/Library/tool
+    sandboxPath: /root/.config/tool
+- when: os == "plan9"
+  image: tool:1.2.3-plan9
+- when: arch == "` + runtime.GOARCH + `"
+  env:
+  - name: ARCH
+    value: ` + runtime.GOARCH + `
+`
+	var script Script
+	if err := yaml.UnmarshalStrict([]byte(data), &script); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if err := applyOverrides(&script); err != nil {
+		t.Fatalf("applyOverrides failed: %v", err)
+	}
+	if script.Image != "tool:1.2.3-host" {
+		t.Errorf("Unexpected image %q", script.Image)
+	}
+	if len(script.Mounts) != 1 || script.Mounts[0].HostPath != "~/Library/tool" {
+		t.Errorf("Unexpected mounts %+v", script.Mounts)
+	}
+	if len(script.Env) != 1 || script.Env[0] != (EnvVar{Name: "ARCH", Value: runtime.GOARCH}) {
+		t.Errorf("Unexpected env %+v", script.Env)
+	}
+
+	script = Script{Overrides: []Override{{When: "platform == 'linux'"}}}
+	if err := applyOverrides(&script); err == nil || !strings.Contains(err.Error(), "overrides[0]") {
+		t.Errorf("Expected an error for an unknown variable, got %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// PromptSource asks the user for the value on the terminal.
+type PromptSource struct {
+	// Message is shown when prompting, defaulting to the name of the env var
+	Message string `json:"message,omitempty"`
+	// Mask hides the value as it is typed, for secrets
+	Mask bool `json:"mask,omitempty"`
+	// CacheFor is how long the value is cached, encrypted, so the user isn't asked again (e.g. 8h)
+	CacheFor string `json:"cacheFor,omitempty"`
+}
+
+// promptCacheKeyName is the keychain secret holding the key which encrypts cached prompt values, so that
+// the cache is useless without the keychain.
+const promptCacheKeyName = "clix-prompt-cache-key"
+
+var promptInputFn = promptInput
+
+// promptValue returns the value of an env var from the prompt cache, or asks the user for it.
+func promptValue(stdin io.Reader, stderr io.Writer, name string, source *PromptSource) (string, error) {
+	message := source.Message
+	if message == "" {
+		message = name
+	}
+	var cacheFor time.Duration
+	if source.CacheFor != "" {
+		d, err := time.ParseDuration(source.CacheFor)
+		if err != nil || d <= 0 {
+			return "", fmt.Errorf("cacheFor must be a duration such as 8h, not %q", source.CacheFor)
+		}
+		cacheFor = d
+	}
+
+	cachePath := promptCachePath(name, message)
+	if cacheFor > 0 {
+		value, err := readPromptCache(cachePath)
+		if err == nil {
+			log(1, "Using the cached value of %s", name)
+			return value, nil
+		}
+		log(2, "No cached value of %s: %v", name, err)
+	}
+
+	value, err := promptInputFn(stdin, stderr, message, source.Mask)
+	if err != nil {
+		return "", err
+	}
+	if value == "" {
+		return "", fmt.Errorf("no value entered")
+	}
+	if cacheFor > 0 {
+		if err := writePromptCache(cachePath, value, time.Now().Add(cacheFor)); err != nil {
+			fmt.Fprintf(stderr, "Warning: not caching %s: %v\n", name, err)
+		}
+	}
+	return value, nil
+}
+
+// promptInput asks for a value on the terminal, without echo when masked.
+func promptInput(stdin io.Reader, stderr io.Writer, message string, mask bool) (string, error) {
+	f, ok := stdin.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return "", fmt.Errorf("prompting for %q needs a terminal", message)
+	}
+	fmt.Fprintf(stderr, "%s: ", message)
+	if mask {
+		data, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(stderr)
+		return string(data), err
+	}
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// promptCachePath is the file caching the value of a prompt, named by a hash of the env var and message.
+func promptCachePath(name, message string) string {
+	userCache, err := os.UserCacheDir()
+	if err != nil {
+		userCache = os.TempDir()
+	}
+	hash := sha256.Sum256([]byte(name + "\x00" + message))
+	return filepath.Join(userCache, "clix", "prompts", hex.EncodeToString(hash[:16]))
+}
+
+// promptCacheEntry is a cached value, encrypted with AES-GCM. The expiry is authenticated with the value.
+type promptCacheEntry struct {
+	Expires string `json:"expires"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+func readPromptCache(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var entry promptCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return "", err
+	}
+	expires, err := time.Parse(time.RFC3339, entry.Expires)
+	if err != nil {
+		return "", err
+	}
+	if time.Now().After(expires) {
+		os.Remove(path)
+		return "", fmt.Errorf("expired at %s", entry.Expires)
+	}
+	gcm, err := promptCacheCipher(false)
+	if err != nil {
+		return "", err
+	}
+	value, err := gcm.Open(nil, entry.Nonce, entry.Data, []byte(entry.Expires))
+	if err != nil {
+		return "", fmt.Errorf("decrypting: %w", err)
+	}
+	return string(value), nil
+}
+
+func writePromptCache(path, value string, expires time.Time) error {
+	gcm, err := promptCacheCipher(true)
+	if err != nil {
+		return err
+	}
+	entry := promptCacheEntry{Expires: expires.UTC().Format(time.RFC3339), Nonce: make([]byte, gcm.NonceSize())}
+	if _, err := rand.Read(entry.Nonce); err != nil {
+		return err
+	}
+	entry.Data = gcm.Seal(nil, entry.Nonce, []byte(value), []byte(entry.Expires))
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// promptCacheCipher returns the cipher of the prompt cache, with the key from the OS keychain, creating
+// the key if needed.
+func promptCacheCipher(create bool) (cipher.AEAD, error) {
+	var key []byte
+	encoded, err := keychainGet(promptCacheKeyName)
+	if err == nil {
+		key, err = base64.StdEncoding.DecodeString(encoded)
+	}
+	if err != nil || len(key) != 32 {
+		if !create {
+			return nil, fmt.Errorf("no prompt cache key in the keychain")
+		}
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		if err := keychainSet(promptCacheKeyName, base64.StdEncoding.EncodeToString(key)); err != nil {
+			return nil, err
+		}
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
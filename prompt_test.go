@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPromptValue(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	originalOS, originalInput := keychainOS, promptInputFn
+	defer func() { keychainOS, promptInputFn = originalOS, originalInput }()
+	keychainOS = "linux"
+	t.Setenv("MOCK_SECRETS", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	prompts := 0
+	promptInputFn = func(stdin io.Reader, stderr io.Writer, message string, mask bool) (string, error) {
+		prompts++
+		if message != "GitHub token" || !mask {
+			t.Errorf("Unexpected prompt %q (mask %v)", message, mask)
+		}
+		return "ghp_typed", nil
+	}
+
+	source := &PromptSource{Message: "GitHub token", Mask: true, CacheFor: "8h"}
+	var stderr bytes.Buffer
+	for i := 0; i < 2; i++ {
+		value, err := promptValue(nil, &stderr, "GITHUB_TOKEN", source)
+		if err != nil || value != "ghp_typed" {
+			t.Fatalf("promptValue = %q, %v", value, err)
+		}
+	}
+	if prompts != 1 {
+		t.Errorf("Expected the cached value to be used, got %d prompts", prompts)
+	}
+
+	// The cache is encrypted, and expires
+	path := promptCachePath("GITHUB_TOKEN", "GitHub token")
+	if data, err := os.ReadFile(path); err != nil || strings.Contains(string(data), "ghp_typed") {
+		t.Errorf("Expected an encrypted cache file, got %q, %v", data, err)
+	}
+	if err := writePromptCache(path, "ghp_old", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := promptValue(nil, &stderr, "GITHUB_TOKEN", source); err != nil || prompts != 2 {
+		t.Errorf("Expected an expired value to be prompted for, got %d prompts, %v", prompts, err)
+	}
+
+	// Without cacheFor, the user is always asked
+	for i := 0; i < 2; i++ {
+		promptValue(nil, &stderr, "GITHUB_TOKEN", &PromptSource{Message: "GitHub token", Mask: true})
+	}
+	if prompts != 4 {
+		t.Errorf("Expected a prompt for every run without cacheFor, got %d prompts", prompts)
+	}
+
+	if _, err := promptValue(nil, &stderr, "GITHUB_TOKEN", &PromptSource{CacheFor: "forever"}); err == nil {
+		t.Errorf("Expected an error for an invalid cacheFor")
+	}
+	if _, err := promptInput(strings.NewReader(""), &stderr, "GitHub token", true); err == nil || !strings.Contains(err.Error(), "terminal") {
+		t.Errorf("Expected an error prompting without a terminal, got %v", err)
+	}
+}
@@ -58,3 +58,17 @@ entrypoint: /hello
 		t.Errorf("unexpected output: %q", stdout.String())
 	}
 }
+
+func TestBuildProotArgs(t *testing.T) {
+	var stderr bytes.Buffer
+	mounts := []Mount{{HostPath: "/home/me/src", SandboxPath: "/src"}, {HostPath: "/home/me/.kube", SandboxPath: "/root/.kube", ReadOnly: true}}
+	args := buildProotArgs(&stderr, "/tmp/root", "", "/src/pkg", mounts, []string{"/bin/ls", "-l"})
+
+	want := "-r /tmp/root -w /src/pkg -b /home/me/src:/src -b /home/me/.kube:/root/.kube /bin/ls -l"
+	if got := strings.Join(args, " "); got != want {
+		t.Errorf("buildProotArgs() = %q, want %q", got, want)
+	}
+	if !strings.Contains(stderr.String(), "cannot mount /home/me/.kube read-only") {
+		t.Errorf("Expected a warning about the read-only mount, got %q", stderr.String())
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/url"
+	"os"
+	"strings"
+)
+
+// proxyEnvNames are the host environment variables which configure HTTP proxies.
+var proxyEnvNames = []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy"}
+
+// applyProxyEnv copies the host's proxy variables into the env of image scripts, unless the script sets
+// them or disables proxyEnv. Scripts without an image run on the host's network, and inherit them.
+func applyProxyEnv(script *Script) {
+	if script.Image == "" || script.Network.Mode == "none" || (script.ProxyEnv != nil && !*script.ProxyEnv) {
+		return
+	}
+	var passed []string
+	for _, name := range proxyEnvNames {
+		value := os.Getenv(name)
+		if value == "" || hasEnvVar(script.Env, name) {
+			continue
+		}
+		script.Env = append(script.Env, EnvVar{Name: name, Value: value})
+		passed = append(passed, name)
+	}
+	if len(passed) > 0 {
+		log(1, "Passing host proxy settings: %s", strings.Join(passed, ", "))
+	}
+}
+
+// containerProxyEnv returns env with proxies on the host's loopback address rewritten to
+// host.docker.internal, which is the host from inside a container, reporting whether any were.
+func containerProxyEnv(env []EnvVar) ([]EnvVar, bool) {
+	var rewritten []EnvVar
+	for i, e := range env {
+		if !isProxyURLVar(e.Name) {
+			continue
+		}
+		value, ok := rewriteLoopbackProxy(e.Value)
+		if !ok {
+			continue
+		}
+		if rewritten == nil {
+			rewritten = append([]EnvVar(nil), env...)
+		}
+		log(1, "Rewriting %s to use %s", e.Name, hostGatewayAlias)
+		rewritten[i].Value = value
+	}
+	if rewritten == nil {
+		return env, false
+	}
+	return rewritten, true
+}
+
+// isProxyURLVar reports whether name is a proxy variable holding a URL, rather than NO_PROXY's list.
+func isProxyURLVar(name string) bool {
+	switch strings.ToUpper(name) {
+	case "HTTP_PROXY", "HTTPS_PROXY":
+		return true
+	}
+	return false
+}
+
+// rewriteLoopbackProxy replaces the host of a proxy URL such as http://localhost:3128 (or localhost:3128)
+// with host.docker.internal, if it is a loopback address.
+func rewriteLoopbackProxy(value string) (string, bool) {
+	scheme := ""
+	if !strings.Contains(value, "://") {
+		scheme = "http://"
+	}
+	u, err := url.Parse(scheme + value)
+	if err != nil {
+		return value, false
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+	default:
+		return value, false
+	}
+	port := u.Port()
+	u.Host = hostGatewayAlias
+	if port != "" {
+		u.Host += ":" + port
+	}
+	return strings.TrimPrefix(u.String(), scheme), true
+}
@@ -0,0 +1,63 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// secretMask replaces secret values in clix's own output.
+const secretMask = "****"
+
+// minSecretLength is the length of the shortest value which is redacted, so that short values such as
+// "1" don't garble the output.
+const minSecretLength = 4
+
+// secrets are the env values which came from secret sources (valueFrom and sops files), to redact from
+// logs, diagnostics and error messages. They are redacted wherever they appear, such as in the -e
+// arguments of a logged docker command line.
+var secrets struct {
+	sync.Mutex
+	values []string
+}
+
+// registerSecret records a value to redact.
+func registerSecret(value string) {
+	if len(value) < minSecretLength {
+		return
+	}
+	secrets.Lock()
+	defer secrets.Unlock()
+	for _, v := range secrets.values {
+		if v == value {
+			return
+		}
+	}
+	secrets.values = append(secrets.values, value)
+	// Longer values first, so that a secret containing another is redacted whole
+	sort.Slice(secrets.values, func(i, j int) bool { return len(secrets.values[i]) > len(secrets.values[j]) })
+}
+
+// redactSecrets replaces the registered secret values in s.
+func redactSecrets(s string) string {
+	secrets.Lock()
+	defer secrets.Unlock()
+	for _, v := range secrets.values {
+		s = strings.ReplaceAll(s, v, secretMask)
+	}
+	return s
+}
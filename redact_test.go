@@ -0,0 +1,44 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"os/exec"
+	"testing"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	defer func() { secrets.values = nil }()
+	registerSecret("tok")
+	registerSecret("ghp_abc")
+	registerSecret("ghp_abcdef")
+	if got := redactSecrets("run -e A=ghp_abcdef -e B=ghp_abc -e C=tok"); got != "run -e A=**** -e B=**** -e C=tok" {
+		t.Errorf("Unexpected redaction: %q", got)
+	}
+
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	script := Script{Env: []EnvVar{
+		{Name: "GITHUB_TOKEN", ValueFrom: &EnvValueSource{OnePassword: "op://dev/github/token"}},
+		{Name: "PROJECT", Value: "my-project"},
+	}}
+	if err := resolveEnvValues(nil, io.Discard, &script); err != nil {
+		t.Fatalf("resolveEnvValues failed: %v", err)
+	}
+	if got := redactSecrets("docker run -e GITHUB_TOKEN=ghp_mock -e PROJECT=my-project"); got != "docker run -e GITHUB_TOKEN=**** -e PROJECT=my-project" {
+		t.Errorf("Expected the 1Password secret to be redacted, got %q", got)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Language runtimes (python: etc) run a tool from a package registry. They run the tool on the host when
+// the script has no mounts and the runtime's tooling is installed, as for go: scripts, and otherwise in
+// the runtime's image, with its package caches mounted from ${cacheDir}.
+
+// runOnHost runs the runtime's command natively.
+func runOnHost(stdin io.Reader, stdout, stderr io.Writer, script Script, cmdArgs []string) error {
+	log(1, "Running natively: %v", cmdArgs)
+	recordRun("native", script, cmdArgs)
+	cmd := execCommand(cmdArgs[0], cmdArgs[1:]...)
+	cmd.Env = sandboxEnv(script)
+	cmd.Dir = script.Workdir
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := runTool(cmd); err != nil {
+		return fmt.Errorf("error running command: %w", err)
+	}
+	return nil
+}
+
+// runInImage runs the runtime's command in image, adding the runtime's cache mounts and env to the script.
+func runInImage(stdin io.Reader, stdout, stderr io.Writer, sandbox Sandbox, sandboxType string, script Script, image string, mounts []Mount, env []EnvVar, cmdArgs []string) error {
+	script.Image = image
+	script.Mounts = append(script.Mounts, mounts...)
+	script.Env = append(script.Env, env...)
+	log(1, "Running in %s: %v", image, cmdArgs)
+	recordRun(sandboxType, script, cmdArgs)
+	return sandbox.Run(stdin, stdout, stderr, script, cmdArgs)
+}
+
+// onHostPath reports whether command is installed on the host.
+func onHostPath(command string) bool {
+	_, err := exec.LookPath(command)
+	return err == nil
+}
+
+// downloadFile downloads url to path, via a temporary file so that path is only created when complete.
+func downloadFile(url, path string) error {
+	if err := checkOnline(url); err != nil {
+		return err
+	}
+	log(1, "Downloading %s", url)
+	resp, err := http.Get(url)
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("downloading %s: %s", url, resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return fmt.Errorf("downloading %s: %w", url, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// clixCacheDir returns the directory for name in the clix cache on the host, marking it as used.
+func clixCacheDir(name string) (string, error) {
+	root, err := clixCacheRoot()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(root, name)
+	touchCacheDir(dir)
+	return dir, nil
+}
+
+// clixCacheRoot returns the clix cache directory on the host.
+func clixCacheRoot() (string, error) {
+	userCache, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user cache dir: %w", err)
+	}
+	return filepath.Join(userCache, "clix"), nil
+}
+
+// resolveScriptPath makes a path relative to the script absolute; URLs are returned unchanged. Like
+// ${scriptDir}, the path is relative to the script's real location when it is run through a link.
+func resolveScriptPath(scriptPath, p string) (string, error) {
+	if p == "" || strings.Contains(p, "://") || filepath.IsAbs(p) {
+		return p, nil
+	}
+	if resolved, err := filepath.EvalSymlinks(scriptPath); err == nil {
+		scriptPath = resolved
+	}
+	absScript, err := filepath.Abs(scriptPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(absScript), p), nil
+}
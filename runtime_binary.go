@@ -0,0 +1,299 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// BinaryConfig runs a prebuilt release binary, downloaded from a URL and verified against its sha256.
+type BinaryConfig struct {
+	// URL is the URL of the binary, or of an archive containing it, with {os}, {arch} and {version} placeholders
+	URL string `json:"url"`
+	// Version is substituted for {version} in the URL and Path
+	Version string `json:"version,omitempty"`
+	// SHA256 is the sha256 of the download, either one for all platforms or one per os/arch
+	SHA256 Checksums `json:"sha256"`
+	// Path is the path of the binary within a .tar.gz, .tgz or .zip archive, with the same placeholders as URL
+	Path string `json:"path,omitempty"`
+	// Image is the image used when the binary has mounts and runs in a container sandbox, defaulting to debian:stable-slim
+	Image string `json:"image,omitempty"`
+}
+
+// Checksums are sha256 digests keyed by os/arch (linux/amd64 etc).
+// In a script they are either a string, for all platforms, or a map.
+type Checksums map[string]string
+
+func (c *Checksums) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*c = Checksums{"": s}
+		return nil
+	}
+	var m map[string]string
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*c = m
+	return nil
+}
+
+// forPlatform returns the checksum for the os/arch platform.
+func (c Checksums) forPlatform(platform string) string {
+	if sum, ok := c[platform]; ok {
+		return sum
+	}
+	return c[""]
+}
+
+// binaryDir is where the cached binary is mounted in the image.
+const binaryDir = "/clix/binary"
+
+func runBinary(stdin io.Reader, stdout, stderr io.Writer, sandbox Sandbox, sandboxType string, script Script, native NativeSandbox, args []string) error {
+	config := script.Binary
+	if config.URL == "" {
+		return fmt.Errorf("error: 'binary.url' missing in script")
+	}
+	fetch := func(goos, goarch string) (string, error) {
+		return fetchBinary(config, goos, goarch)
+	}
+	return runFetchedBinary(stdin, stdout, stderr, sandbox, sandboxType, script, native, fetch, config.Image, nil, nil, args)
+}
+
+// runFetchedBinary runs the binary returned by fetch for the platform it runs on. Without mounts, or
+// without a container sandbox, the binary runs on the host, confined by native if set; otherwise the
+// linux binary runs in image. The mounts and env are added to the script for the tool.
+func runFetchedBinary(stdin io.Reader, stdout, stderr io.Writer, sandbox Sandbox, sandboxType string, script Script, native NativeSandbox, fetch func(goos, goarch string) (string, error), image string, mounts []Mount, env []EnvVar, args []string) error {
+	if sandbox == nil || len(script.Mounts) == 0 {
+		binary, err := fetch(runtime.GOOS, runtime.GOARCH)
+		if err != nil {
+			return err
+		}
+		if native == nil {
+			return runOnHost(stdin, stdout, stderr, script, append([]string{binary}, args...))
+		}
+		script.Mounts = append(script.Mounts, mounts...)
+		script.Env = append(script.Env, env...)
+		return runNativeBinary(stdin, stdout, stderr, script, native, binary, args)
+	}
+
+	binary, err := fetch("linux", imageArch(script))
+	if err != nil {
+		return err
+	}
+	if image == "" {
+		image = "debian:stable-slim"
+	}
+	mounts = append([]Mount{{HostPath: filepath.Dir(binary), SandboxPath: binaryDir}}, mounts...)
+	cmdArgs := append([]string{binaryDir + "/" + filepath.Base(binary)}, args...)
+	return runInImage(stdin, stdout, stderr, sandbox, sandboxType, script, image, mounts, env, cmdArgs)
+}
+
+// runNativeBinary runs the binary confined by the native sandbox. As for go scripts, the binary is run
+// from the temp dir, because native sandboxes do not allow the clix cache.
+func runNativeBinary(stdin io.Reader, stdout, stderr io.Writer, script Script, native NativeSandbox, binary string, args []string) error {
+	tmpDir, err := os.MkdirTemp("", "clix-binary-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	tmpBinary := filepath.Join(tmpDir, filepath.Base(binary))
+	if err := copyEntryPath(binary, tmpBinary); err != nil {
+		return err
+	}
+
+	cmd, err := native.Command(script, tmpBinary, args...)
+	if err != nil {
+		return err
+	}
+	cmd.Env = sandboxEnv(script)
+	cmd.Dir = script.Workdir
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := runTool(cmd); err != nil {
+		return fmt.Errorf("error running command: %w", err)
+	}
+	return nil
+}
+
+// fetchBinary returns the path of the binary for goos/goarch in the clix cache, downloading it if needed.
+func fetchBinary(config *BinaryConfig, goos, goarch string) (string, error) {
+	expand := func(s string) string {
+		return strings.NewReplacer("{os}", goos, "{arch}", goarch, "{version}", config.Version).Replace(s)
+	}
+	platform := goos + "/" + goarch
+	return fetchArtifact(expand(config.URL), config.SHA256.forPlatform(platform), expand(config.Path), platform)
+}
+
+// fetchArtifact downloads url, verifies its sha256 and caches the binary, which is the download itself or
+// the file at binPath within the downloaded archive. The cache is keyed by the sha256, so a cached binary
+// is only used for the checksum it was verified against.
+func fetchArtifact(url, want, binPath, platform string) (string, error) {
+	name := path.Base(binPath)
+	if binPath == "" {
+		name = path.Base(url)
+	}
+	cacheDir, err := clixCacheDir("binaries")
+	if err != nil {
+		return "", err
+	}
+	want = strings.ToLower(want)
+	binary := filepath.Join(cacheDir, want, name)
+	if want != "" {
+		if _, err := os.Stat(binary); err == nil {
+			return binary, nil
+		}
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", err
+	}
+	tmpDir, err := os.MkdirTemp(cacheDir, ".download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+	artifact := filepath.Join(tmpDir, "artifact")
+	if err := downloadFile(url, artifact); err != nil {
+		return "", err
+	}
+	got, err := fileSHA256(artifact)
+	if err != nil {
+		return "", err
+	}
+	if want == "" {
+		return "", fmt.Errorf("no sha256 for %s in script; the sha256 of %s is %s", platform, url, got)
+	}
+	if got != want {
+		return "", fmt.Errorf("sha256 mismatch for %s: expected %s, got %s", url, want, got)
+	}
+
+	outDir := filepath.Join(tmpDir, "out")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return "", err
+	}
+	out := filepath.Join(outDir, name)
+	switch {
+	case binPath == "":
+		err = os.Rename(artifact, out)
+	case strings.HasSuffix(url, ".zip"):
+		err = extractZipFile(artifact, binPath, out)
+	case strings.HasSuffix(url, ".tar.gz"), strings.HasSuffix(url, ".tgz"):
+		err = extractTarGzFile(artifact, binPath, out)
+	default:
+		err = fmt.Errorf("cannot extract %s from %s: expected a .tar.gz, .tgz or .zip archive", binPath, url)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := os.Chmod(out, 0755); err != nil {
+		return "", err
+	}
+	// Another clix may have cached the binary concurrently, in which case we use theirs
+	if err := os.Rename(outDir, filepath.Dir(binary)); err != nil {
+		if _, statErr := os.Stat(binary); statErr != nil {
+			return "", err
+		}
+	}
+	return binary, nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// archiveName normalizes the name of an archive entry, for comparison with the binary path.
+func archiveName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+func extractTarGzFile(archive, name, dest string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", archive, err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("%s not found in archive", name)
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive: %w", err)
+		}
+		if header.Typeflag == tar.TypeReg && archiveName(header.Name) == archiveName(name) {
+			return writeFile(tr, dest)
+		}
+	}
+}
+
+func extractZipFile(archive, name, dest string) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", archive, err)
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		if !f.FileInfo().Mode().IsRegular() || archiveName(f.Name) != archiveName(name) {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer r.Close()
+		return writeFile(r, dest)
+	}
+	return fmt.Errorf("%s not found in archive", name)
+}
+
+func writeFile(r io.Reader, dest string) error {
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestFetchBinary(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	content := []byte("#!/bin/sh\necho hello\n")
+	tw.WriteHeader(&tar.Header{Name: "./tool-1.0-linux-arm64/tool", Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))})
+	tw.Write(content)
+	tw.Close()
+	gz.Close()
+	sum := sha256.Sum256(archive.Bytes())
+	checksum := hex.EncodeToString(sum[:])
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	var config BinaryConfig
+	data := "url: " + server.URL + "/v{version}/tool-{os}-{arch}.tar.gz\nversion: \"1.0\"\npath: tool-{version}-{os}-{arch}/tool\nsha256:\n  linux/arm64: " + checksum + "\n"
+	if err := yaml.Unmarshal([]byte(data), &config); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if _, err := fetchBinary(&config, "darwin", "arm64"); err == nil || !strings.Contains(err.Error(), "the sha256 of "+server.URL+"/v1.0/tool-darwin-arm64.tar.gz is "+checksum) {
+		t.Errorf("Expected an error reporting the sha256, got %v", err)
+	}
+
+	binary, err := fetchBinary(&config, "linux", "arm64")
+	if err != nil {
+		t.Fatalf("fetchBinary failed: %v", err)
+	}
+	if got, _ := os.ReadFile(binary); !bytes.Equal(got, content) {
+		t.Errorf("Unexpected binary content %q", got)
+	}
+	if info, err := os.Stat(binary); err != nil || info.Mode().Perm()&0100 == 0 {
+		t.Errorf("Expected %s to be executable", binary)
+	}
+
+	// The verified binary is cached
+	if _, err := fetchBinary(&config, "linux", "arm64"); err != nil {
+		t.Fatalf("fetchBinary failed: %v", err)
+	}
+	if len(requests) != 2 || requests[1] != "/v1.0/tool-linux-arm64.tar.gz" {
+		t.Errorf("Unexpected requests %v", requests)
+	}
+
+	config.SHA256 = Checksums{"": strings.Repeat("0", 64)}
+	if _, err := fetchBinary(&config, "linux", "arm64"); err == nil || !strings.Contains(err.Error(), "sha256 mismatch") {
+		t.Errorf("Expected a sha256 mismatch, got %v", err)
+	}
+}
+
+func TestRunBinaryInImage(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	content := []byte("binary")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer server.Close()
+	sum := sha256.Sum256(content)
+
+	sandbox := &recordingSandbox{}
+	script := Script{
+		Binary: &BinaryConfig{URL: server.URL + "/tool-{os}-{arch}", SHA256: Checksums{"": hex.EncodeToString(sum[:])}},
+		Mounts: []Mount{{HostPath: "git.repoRoot(cwd)"}},
+		Arch:   "arm64",
+	}
+	if err := runBinary(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, sandbox, "docker", script, nil, []string{"lint"}); err != nil {
+		t.Fatalf("runBinary failed: %v", err)
+	}
+	if sandbox.script.Image != "debian:stable-slim" {
+		t.Errorf("Expected the default image, got %q", sandbox.script.Image)
+	}
+	if len(sandbox.script.Mounts) != 2 || sandbox.script.Mounts[1].SandboxPath != binaryDir || filepath.Base(sandbox.script.Mounts[1].HostPath) != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected the binary to be mounted, got %+v", sandbox.script.Mounts)
+	}
+	if got := strings.Join(sandbox.args, " "); got != "/clix/binary/tool-linux-arm64 lint" {
+		t.Errorf("Unexpected command %q", got)
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// BunConfig runs a tool from npm with bun, which starts faster than node.
+type BunConfig struct {
+	// Run is the npm package to run
+	Run string `json:"run"`
+	// Version is the version of the package, defaulting to the latest version
+	Version string `json:"version,omitempty"`
+	// Command is the command to run, if the package's command is not named after the package
+	Command string `json:"command,omitempty"`
+	// Image is the bun image used in sandboxes, defaulting to oven/bun:slim
+	Image string `json:"image,omitempty"`
+}
+
+// bunCacheDir is where ${cacheDir}/bun is mounted in the bun image.
+const bunCacheDir = "/clix/bun"
+
+func runBun(stdin io.Reader, stdout, stderr io.Writer, sandbox Sandbox, sandboxType string, script Script, args []string) error {
+	config := script.Bun
+	if config.Run == "" {
+		return fmt.Errorf("error: 'bun.run' missing in script")
+	}
+	cmdArgs := append(bunxCommand(config), args...)
+
+	if len(script.Mounts) == 0 && onHostPath("bunx") {
+		return runOnHost(stdin, stdout, stderr, script, cmdArgs)
+	}
+
+	image := config.Image
+	if image == "" {
+		image = "oven/bun:slim"
+	}
+	mounts := []Mount{{HostPath: "${cacheDir}/bun", SandboxPath: bunCacheDir}}
+	env := []EnvVar{{Name: "BUN_INSTALL_CACHE_DIR", Value: bunCacheDir}}
+	return runInImage(stdin, stdout, stderr, sandbox, sandboxType, script, image, mounts, env, cmdArgs)
+}
+
+// bunxCommand returns the bunx command which runs the package's command, installing the package if needed.
+func bunxCommand(config *BunConfig) []string {
+	spec := config.Run
+	if config.Version != "" {
+		spec += "@" + config.Version
+	}
+	command := config.Command
+	if command == "" {
+		command = npmPackageCommand(config.Run)
+	}
+	return []string{"bunx", "--package", spec, command}
+}
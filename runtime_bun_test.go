@@ -0,0 +1,55 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestBunxCommand(t *testing.T) {
+	for _, tc := range []struct {
+		config BunConfig
+		want   string
+	}{
+		{BunConfig{Run: "prettier", Version: "3.2.0"}, "bunx --package prettier@3.2.0 prettier"},
+		{BunConfig{Run: "@biomejs/biome"}, "bunx --package @biomejs/biome biome"},
+		{BunConfig{Run: "typescript", Command: "tsc"}, "bunx --package typescript tsc"},
+	} {
+		if got := strings.Join(bunxCommand(&tc.config), " "); got != tc.want {
+			t.Errorf("bunxCommand(%+v) = %q, want %q", tc.config, got, tc.want)
+		}
+	}
+}
+
+func TestRunBunInImage(t *testing.T) {
+	fakeHostCommands(t)
+	sandbox := &recordingSandbox{}
+	script := Script{Bun: &BunConfig{Run: "prettier"}}
+	if err := runBun(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, sandbox, "docker", script, []string{"--check", "."}); err != nil {
+		t.Fatalf("runBun failed: %v", err)
+	}
+
+	if sandbox.script.Image != "oven/bun:slim" {
+		t.Errorf("Expected the bun image, got %q", sandbox.script.Image)
+	}
+	if len(sandbox.script.Mounts) != 1 || sandbox.script.Mounts[0].SandboxPath != bunCacheDir {
+		t.Errorf("Expected the bun cache to be mounted, got %+v", sandbox.script.Mounts)
+	}
+	if got := strings.Join(sandbox.args, " "); got != "bunx --package prettier prettier --check ." {
+		t.Errorf("Unexpected command %q", got)
+	}
+}
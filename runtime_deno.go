@@ -0,0 +1,127 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DenoConfig runs a Deno module, with permissions limited to the script's mounts.
+type DenoConfig struct {
+	// Run is the module to run: a jsr: or npm: specifier, or an https URL
+	Run string `json:"run"`
+	// Version is the version of a jsr: or npm: module, defaulting to the latest version
+	Version string `json:"version,omitempty"`
+	// Image is the deno image used in sandboxes, defaulting to denoland/deno
+	Image string `json:"image,omitempty"`
+}
+
+// denoCacheDir is where ${cacheDir}/deno is mounted in the deno image.
+const denoCacheDir = "/clix/deno"
+
+func runDeno(stdin io.Reader, stdout, stderr io.Writer, sandbox Sandbox, sandboxType string, script Script, args []string) error {
+	config := script.Deno
+	module, err := denoModule(config)
+	if err != nil {
+		return err
+	}
+
+	// Deno's permissions confine the tool on the host, when it can see the mounts at their host paths
+	if onHostPath("deno") && !usesCacheDir(script.Mounts) && !remapsMounts(script.Mounts) {
+		resolvedMounts, err := resolveMounts(script.Mounts, "")
+		if err != nil {
+			return fmt.Errorf("error resolving mounts: %w", err)
+		}
+		cmdArgs := append(denoRunArgs(module, resolvedMounts, script), args...)
+		return runOnHost(stdin, stdout, stderr, script, cmdArgs)
+	}
+
+	image := config.Image
+	if image == "" {
+		image = "denoland/deno"
+	}
+	mounts := []Mount{{HostPath: "${cacheDir}/deno", SandboxPath: denoCacheDir}}
+	env := []EnvVar{{Name: "DENO_DIR", Value: denoCacheDir}}
+	// The tool may access the script's mounts, at their sandbox paths
+	var sandboxMounts []Mount
+	for _, m := range script.Mounts {
+		if usesCacheDir([]Mount{m}) {
+			// The cache dir can only be resolved by the sandbox, but we only need the sandbox path
+			m.HostPath = m.SandboxPath
+		}
+		sandboxMounts = append(sandboxMounts, m)
+	}
+	sandboxMounts, err = resolveMounts(sandboxMounts, "")
+	if err != nil {
+		return fmt.Errorf("error resolving mounts: %w", err)
+	}
+	cmdArgs := append(denoRunArgs(module, sandboxMounts, script), args...)
+	return runInImage(stdin, stdout, stderr, sandbox, sandboxType, script, image, mounts, env, cmdArgs)
+}
+
+// denoModule returns the module specifier, with its version.
+func denoModule(config *DenoConfig) (string, error) {
+	switch {
+	case config.Run == "":
+		return "", fmt.Errorf("error: 'deno.run' missing in script")
+	case strings.HasPrefix(config.Run, "jsr:") || strings.HasPrefix(config.Run, "npm:"):
+		if config.Version != "" {
+			return config.Run + "@" + config.Version, nil
+		}
+		return config.Run, nil
+	case strings.HasPrefix(config.Run, "https://"):
+		if config.Version != "" {
+			return "", fmt.Errorf("error: 'deno.version' is not supported for URLs; put the version in the URL")
+		}
+		return config.Run, nil
+	}
+	return "", fmt.Errorf("error: 'deno.run' must be a jsr: or npm: specifier, or an https URL")
+}
+
+// denoRunArgs returns the deno run command, allowing reads and writes of the mounts' sandbox paths,
+// the script's env vars, and network access unless the script disables it.
+func denoRunArgs(module string, mounts []Mount, script Script) []string {
+	cmdArgs := []string{"deno", "run"}
+	var paths []string
+	for _, m := range mounts {
+		paths = append(paths, m.SandboxPath)
+	}
+	if len(paths) > 0 {
+		cmdArgs = append(cmdArgs, "--allow-read="+strings.Join(paths, ","), "--allow-write="+strings.Join(paths, ","))
+	}
+	var names []string
+	for _, e := range script.Env {
+		names = append(names, e.Name)
+	}
+	if len(names) > 0 {
+		cmdArgs = append(cmdArgs, "--allow-env="+strings.Join(names, ","))
+	}
+	if script.Network.Mode != "none" {
+		cmdArgs = append(cmdArgs, "--allow-net")
+	}
+	return append(cmdArgs, module)
+}
+
+// remapsMounts reports whether any mount is seen by the tool at a different path from the host path.
+func remapsMounts(mounts []Mount) bool {
+	for _, m := range mounts {
+		if m.SandboxPath != "" && m.SandboxPath != m.HostPath {
+			return true
+		}
+	}
+	return false
+}
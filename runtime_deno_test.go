@@ -0,0 +1,99 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestDenoModule(t *testing.T) {
+	for _, tc := range []struct {
+		config DenoConfig
+		want   string
+	}{
+		{DenoConfig{Run: "jsr:@std/http/file-server", Version: "1.0.0"}, "jsr:@std/http/file-server@1.0.0"},
+		{DenoConfig{Run: "npm:cowsay"}, "npm:cowsay"},
+		{DenoConfig{Run: "https://deno.land/std@0.224.0/http/file_server.ts"}, "https://deno.land/std@0.224.0/http/file_server.ts"},
+	} {
+		got, err := denoModule(&tc.config)
+		if err != nil || got != tc.want {
+			t.Errorf("denoModule(%+v) = %q, %v; want %q", tc.config, got, err, tc.want)
+		}
+	}
+	for _, config := range []DenoConfig{
+		{},
+		{Run: "cowsay"},
+		{Run: "https://example.com/tool.ts", Version: "1.0"},
+	} {
+		if _, err := denoModule(&config); err == nil {
+			t.Errorf("Expected an error for %+v", config)
+		}
+	}
+}
+
+func TestDenoRunArgs(t *testing.T) {
+	script := Script{
+		Env:     []EnvVar{{Name: "TOKEN", Value: "x"}},
+		Network: NetworkPolicy{Mode: "none"},
+	}
+	mounts := []Mount{{HostPath: "/home/me/src", SandboxPath: "/src"}, {HostPath: "/tmp/out", SandboxPath: "/out"}}
+	got := strings.Join(denoRunArgs("jsr:@scope/tool", mounts, script), " ")
+	want := "deno run --allow-read=/src,/out --allow-write=/src,/out --allow-env=TOKEN jsr:@scope/tool"
+	if got != want {
+		t.Errorf("denoRunArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestRunDenoOnHost(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("MOCK_BEHAVIOR", "echo_args")
+	fakeHostCommands(t, "deno")
+
+	dir := t.TempDir()
+	var stdout bytes.Buffer
+	script := Script{
+		Deno:   &DenoConfig{Run: "jsr:@scope/tool"},
+		Mounts: []Mount{{HostPath: dir}},
+	}
+	if err := runDeno(strings.NewReader(""), &stdout, &bytes.Buffer{}, nil, "docker", script, []string{"fmt"}); err != nil {
+		t.Fatalf("runDeno failed: %v", err)
+	}
+	want := "args: [run --allow-read=" + dir + " --allow-write=" + dir + " --allow-net jsr:@scope/tool fmt]"
+	if !strings.Contains(stdout.String(), want) {
+		t.Errorf("Expected %q, got %q", want, stdout.String())
+	}
+}
+
+func TestRunDenoInImage(t *testing.T) {
+	fakeHostCommands(t)
+	sandbox := &recordingSandbox{}
+	script := Script{
+		Deno:   &DenoConfig{Run: "npm:prettier"},
+		Mounts: []Mount{{HostPath: "${cacheDir}/out", SandboxPath: "/out"}},
+	}
+	if err := runDeno(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, sandbox, "docker", script, nil); err != nil {
+		t.Fatalf("runDeno failed: %v", err)
+	}
+	if sandbox.script.Image != "denoland/deno" {
+		t.Errorf("Expected the deno image, got %q", sandbox.script.Image)
+	}
+	if got := strings.Join(sandbox.args, " "); got != "deno run --allow-read=/out --allow-write=/out --allow-net npm:prettier" {
+		t.Errorf("Unexpected command %q", got)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DotnetConfig runs a .NET tool from NuGet.
+type DotnetConfig struct {
+	// Run is the id of the tool package
+	Run string `json:"run"`
+	// Version is the version of the tool, defaulting to the latest version
+	Version string `json:"version,omitempty"`
+	// Command is the command to run, if the tool's command is not named after the package
+	Command string `json:"command,omitempty"`
+	// Image is the .NET SDK image used in sandboxes, defaulting to mcr.microsoft.com/dotnet/sdk
+	Image string `json:"image,omitempty"`
+}
+
+// dotnetCacheDir is where ${cacheDir}/dotnet is mounted in the .NET SDK image.
+const dotnetCacheDir = "/clix/dotnet"
+
+func (c *DotnetConfig) command() string {
+	if c.Command != "" {
+		return c.Command
+	}
+	// NuGet package ids are case-insensitive, and tool commands are conventionally lower case
+	return strings.ToLower(c.Run)
+}
+
+// installName names the tool path of this tool version; unversioned tools are installed once.
+func (c *DotnetConfig) installName() string {
+	version := c.Version
+	if version == "" {
+		version = "latest"
+	}
+	return strings.ToLower(c.Run) + "-" + version
+}
+
+// toolInstallArgs returns the dotnet command which installs the tool into toolPath.
+func (c *DotnetConfig) toolInstallArgs(toolPath string) []string {
+	cmdArgs := []string{"dotnet", "tool", "install", "--tool-path", toolPath, c.Run}
+	if c.Version != "" {
+		cmdArgs = append(cmdArgs, "--version", c.Version)
+	}
+	return cmdArgs
+}
+
+func runDotnet(stdin io.Reader, stdout, stderr io.Writer, sandbox Sandbox, sandboxType string, script Script, args []string) error {
+	config := script.Dotnet
+	if config.Run == "" {
+		return fmt.Errorf("error: 'dotnet.run' missing in script")
+	}
+
+	if len(script.Mounts) == 0 && onHostPath("dotnet") {
+		binary, err := installDotnetOnHost(stderr, config)
+		if err != nil {
+			return err
+		}
+		return runOnHost(stdin, stdout, stderr, script, append([]string{binary}, args...))
+	}
+
+	image := config.Image
+	if image == "" {
+		image = "mcr.microsoft.com/dotnet/sdk"
+	}
+	mounts := []Mount{{HostPath: "${cacheDir}/dotnet", SandboxPath: dotnetCacheDir}}
+	env := []EnvVar{
+		{Name: "NUGET_PACKAGES", Value: dotnetCacheDir + "/nuget"},
+		{Name: "DOTNET_CLI_TELEMETRY_OPTOUT", Value: "1"},
+		{Name: "DOTNET_NOLOGO", Value: "1"},
+	}
+	toolPath := dotnetCacheDir + "/tools/" + config.installName()
+	installScript := cachedInstallScript(config.toolInstallArgs(toolPath), toolPath+"/"+config.command())
+	cmdArgs := append([]string{"sh", "-c", installScript, "sh"}, args...)
+	return runInImage(stdin, stdout, stderr, sandbox, sandboxType, script, image, mounts, env, cmdArgs)
+}
+
+// installDotnetOnHost installs the tool into the clix cache on the host, if it is not already installed,
+// returning the path of its command.
+func installDotnetOnHost(stderr io.Writer, config *DotnetConfig) (string, error) {
+	dotnetDir, err := clixCacheDir("dotnet")
+	if err != nil {
+		return "", err
+	}
+	toolPath := filepath.Join(dotnetDir, config.installName())
+	binary := filepath.Join(toolPath, config.command())
+	if _, err := os.Stat(binary); err == nil {
+		return binary, nil
+	}
+
+	log(1, "Installing %s into %s", config.installName(), toolPath)
+	installArgs := config.toolInstallArgs(toolPath)
+	cmd := execCommand(installArgs[0], installArgs[1:]...)
+	cmd.Stdout = stderr
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("error installing .NET tool %s: %w", config.Run, err)
+	}
+	return binary, nil
+}
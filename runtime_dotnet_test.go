@@ -0,0 +1,53 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunDotnetInImage(t *testing.T) {
+	fakeHostCommands(t, "dotnet")
+	sandbox := &recordingSandbox{}
+	script := Script{
+		Dotnet: &DotnetConfig{Run: "GitVersion.Tool", Version: "5.12.0", Command: "dotnet-gitversion"},
+		Mounts: []Mount{{HostPath: "git.repoRoot(cwd)"}},
+	}
+	if err := runDotnet(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, sandbox, "docker", script, []string{"/showvariable", "SemVer"}); err != nil {
+		t.Fatalf("runDotnet failed: %v", err)
+	}
+
+	if sandbox.script.Image != "mcr.microsoft.com/dotnet/sdk" {
+		t.Errorf("Expected the .NET SDK image, got %q", sandbox.script.Image)
+	}
+	if len(sandbox.script.Mounts) != 2 || sandbox.script.Mounts[1].SandboxPath != dotnetCacheDir {
+		t.Errorf("Expected the dotnet cache to be mounted, got %+v", sandbox.script.Mounts)
+	}
+	installScript := sandbox.args[2]
+	for _, want := range []string{
+		"'dotnet' 'tool' 'install' '--tool-path' '/clix/dotnet/tools/gitversion.tool-5.12.0' 'GitVersion.Tool' '--version' '5.12.0'",
+		"exec '/clix/dotnet/tools/gitversion.tool-5.12.0/dotnet-gitversion' \"$@\"",
+	} {
+		if !strings.Contains(installScript, want) {
+			t.Errorf("Expected install script to contain %q, got %q", want, installScript)
+		}
+	}
+	checkShellSyntax(t, installScript)
+	if got := strings.Join(sandbox.args[3:], " "); got != "sh /showvariable SemVer" {
+		t.Errorf("Unexpected arguments %q", got)
+	}
+}
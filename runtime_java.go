@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// JavaConfig runs a self-contained (executable) jar.
+type JavaConfig struct {
+	// Jar is the URL or path of the jar; paths are relative to the script
+	Jar string `json:"jar,omitempty"`
+	// Maven is the Maven coordinates of the jar, as group:artifact:version[:classifier]
+	Maven string `json:"maven,omitempty"`
+	// Repository is the Maven repository to fetch from, defaulting to Maven Central
+	Repository string `json:"repository,omitempty"`
+	// Image is the JDK image used in sandboxes, defaulting to eclipse-temurin:21-jre
+	Image string `json:"image,omitempty"`
+}
+
+const mavenCentral = "https://repo1.maven.org/maven2"
+
+// javaJarDir is where the directory containing the jar is mounted in the JDK image.
+const javaJarDir = "/clix/java"
+
+func runJava(stdin io.Reader, stdout, stderr io.Writer, sandbox Sandbox, sandboxType string, script Script, args []string) error {
+	jar, err := resolveJar(script.Java)
+	if err != nil {
+		return err
+	}
+
+	if len(script.Mounts) == 0 && onHostPath("java") {
+		return runOnHost(stdin, stdout, stderr, script, append([]string{"java", "-jar", jar}, args...))
+	}
+
+	image := script.Java.Image
+	if image == "" {
+		image = "eclipse-temurin:21-jre"
+	}
+	mounts := []Mount{{HostPath: filepath.Dir(jar), SandboxPath: javaJarDir}}
+	cmdArgs := append([]string{"java", "-jar", javaJarDir + "/" + filepath.Base(jar)}, args...)
+	return runInImage(stdin, stdout, stderr, sandbox, sandboxType, script, image, mounts, nil, cmdArgs)
+}
+
+// resolveJar returns the path of the jar, downloading it into the clix cache if needed.
+// Maven artifacts are cached in the layout of a local Maven repository.
+func resolveJar(config *JavaConfig) (string, error) {
+	switch {
+	case config.Maven != "":
+		artifactPath, err := mavenArtifactPath(config.Maven)
+		if err != nil {
+			return "", err
+		}
+		repoDir, err := clixCacheDir("maven")
+		if err != nil {
+			return "", err
+		}
+		jar := filepath.Join(repoDir, filepath.FromSlash(artifactPath))
+		if _, err := os.Stat(jar); err == nil {
+			return jar, nil
+		}
+		repository := config.Repository
+		if repository == "" {
+			repository = mavenCentral
+		}
+		url := strings.TrimSuffix(repository, "/") + "/" + artifactPath
+		if err := downloadFile(url, jar); err != nil {
+			return "", err
+		}
+		if err := verifyMavenChecksum(url, jar); err != nil {
+			os.Remove(jar)
+			return "", err
+		}
+		return jar, nil
+	case strings.HasPrefix(config.Jar, "https://") || strings.HasPrefix(config.Jar, "http://"):
+		jarDir, err := clixCacheDir("jars")
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256([]byte(config.Jar))
+		jar := filepath.Join(jarDir, hex.EncodeToString(sum[:8]), filepath.Base(config.Jar))
+		if _, err := os.Stat(jar); err == nil {
+			return jar, nil
+		}
+		return jar, downloadFile(config.Jar, jar)
+	case config.Jar != "":
+		return config.Jar, nil
+	}
+	return "", fmt.Errorf("error: 'java.jar' or 'java.maven' missing in script")
+}
+
+// mavenArtifactPath returns the path of the jar within a Maven repository, for group:artifact:version[:classifier].
+func mavenArtifactPath(coordinates string) (string, error) {
+	parts := strings.Split(coordinates, ":")
+	if len(parts) < 3 || len(parts) > 4 {
+		return "", fmt.Errorf("invalid maven coordinates %q, expected group:artifact:version[:classifier]", coordinates)
+	}
+	group, artifact, version := parts[0], parts[1], parts[2]
+	name := artifact + "-" + version
+	if len(parts) == 4 {
+		name += "-" + parts[3]
+	}
+	return fmt.Sprintf("%s/%s/%s/%s.jar", strings.ReplaceAll(group, ".", "/"), artifact, version, name), nil
+}
+
+// verifyMavenChecksum checks the jar against the repository's .sha1 checksum, if the repository has one.
+func verifyMavenChecksum(url, jar string) error {
+	if err := checkOnline(url + ".sha1"); err != nil {
+		return err
+	}
+	resp, err := http.Get(url + ".sha1")
+	if err != nil {
+		return fmt.Errorf("downloading checksum of %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		fmt.Fprintf(os.Stderr, "Warning: no checksum for %s (%s)\n", url, resp.Status)
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("downloading checksum of %s: %w", url, err)
+	}
+	want := strings.Fields(string(body))
+	if len(want) == 0 {
+		return fmt.Errorf("empty checksum for %s", url)
+	}
+
+	data, err := os.ReadFile(jar)
+	if err != nil {
+		return err
+	}
+	sum := sha1.Sum(data)
+	if got := hex.EncodeToString(sum[:]); got != strings.ToLower(want[0]) {
+		return fmt.Errorf("checksum mismatch for %s: got sha1 %s, want %s", url, got, want[0])
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMavenArtifactPath(t *testing.T) {
+	for coordinates, want := range map[string]string{
+		"org.openapitools:openapi-generator-cli:7.6.0": "org/openapitools/openapi-generator-cli/7.6.0/openapi-generator-cli-7.6.0.jar",
+		"com.example:tool:1.0:all":                     "com/example/tool/1.0/tool-1.0-all.jar",
+	} {
+		got, err := mavenArtifactPath(coordinates)
+		if err != nil {
+			t.Fatalf("mavenArtifactPath(%q) failed: %v", coordinates, err)
+		}
+		if got != want {
+			t.Errorf("mavenArtifactPath(%q) = %q, want %q", coordinates, got, want)
+		}
+	}
+	if _, err := mavenArtifactPath("com.example:tool"); err == nil {
+		t.Error("Expected an error for coordinates without a version")
+	}
+}
+
+func TestResolveJarFromMaven(t *testing.T) {
+	jarData := []byte("PK fake jar")
+	sum := sha1.Sum(jarData)
+	checksum := hex.EncodeToString(sum[:])
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/com/example/tool/1.0/tool-1.0.jar":
+			w.Write(jarData)
+		case "/com/example/tool/1.0/tool-1.0.jar.sha1":
+			w.Write([]byte(checksum + "\n"))
+		case "/com/example/bad/1.0/bad-1.0.jar":
+			w.Write(jarData)
+		case "/com/example/bad/1.0/bad-1.0.jar.sha1":
+			w.Write([]byte("0000000000000000000000000000000000000000"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+
+	config := &JavaConfig{Maven: "com.example:tool:1.0", Repository: server.URL}
+	jar, err := resolveJar(config)
+	if err != nil {
+		t.Fatalf("resolveJar failed: %v", err)
+	}
+	if want := filepath.Join(cache, "clix", "maven", "com", "example", "tool", "1.0", "tool-1.0.jar"); jar != want {
+		t.Errorf("resolveJar() = %q, want %q", jar, want)
+	}
+	if data, _ := os.ReadFile(jar); !bytes.Equal(data, jarData) {
+		t.Errorf("Unexpected jar contents %q", data)
+	}
+
+	// The cached jar is not downloaded again
+	requests = 0
+	if _, err := resolveJar(config); err != nil || requests != 0 {
+		t.Errorf("Expected the cached jar to be used, got %v after %d requests", err, requests)
+	}
+
+	_, err = resolveJar(&JavaConfig{Maven: "com.example:bad:1.0", Repository: server.URL})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+}
+
+func TestRunJavaInImage(t *testing.T) {
+	fakeHostCommands(t, "java")
+	dir := t.TempDir()
+	jar := filepath.Join(dir, "tool.jar")
+	sandbox := &recordingSandbox{}
+	script := Script{
+		Java:   &JavaConfig{Jar: jar},
+		Mounts: []Mount{{HostPath: "git.repoRoot(cwd)"}},
+	}
+	if err := runJava(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, sandbox, "docker", script, []string{"generate"}); err != nil {
+		t.Fatalf("runJava failed: %v", err)
+	}
+	if sandbox.script.Image != "eclipse-temurin:21-jre" {
+		t.Errorf("Expected the JDK image, got %q", sandbox.script.Image)
+	}
+	if m := sandbox.script.Mounts[1]; m.HostPath != dir || m.SandboxPath != javaJarDir {
+		t.Errorf("Expected the jar directory to be mounted, got %+v", m)
+	}
+	if got := strings.Join(sandbox.args, " "); got != "java -jar /clix/java/tool.jar generate" {
+		t.Errorf("Unexpected command %q", got)
+	}
+}
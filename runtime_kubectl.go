@@ -0,0 +1,223 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// KubectlPluginConfig runs a kubectl plugin from the krew index, without needing krew.
+type KubectlPluginConfig struct {
+	// Name is the name of the plugin in the krew index
+	Name string `json:"name"`
+	// Manifest is the URL or path of the plugin manifest, defaulting to the manifest in the krew index.
+	// A URL at a commit of the index pins the plugin version.
+	Manifest string `json:"manifest,omitempty"`
+	// Image is the image used when the plugin has mounts and runs in a container sandbox, defaulting to debian:stable-slim
+	Image string `json:"image,omitempty"`
+
+	// locked is the manifest pinned by the script's lock, used instead of loading the manifest
+	locked *krewManifest
+}
+
+// krewIndexURL is the location of plugin manifests in the default krew index.
+const krewIndexURL = "https://raw.githubusercontent.com/kubernetes-sigs/krew-index/master/plugins/"
+
+// krewManifestTTL is how long a downloaded manifest is used before it is downloaded again.
+const krewManifestTTL = 24 * time.Hour
+
+// krewManifest is the subset of a krew plugin manifest that clix uses.
+type krewManifest struct {
+	Spec struct {
+		Version   string         `json:"version"`
+		Platforms []krewPlatform `json:"platforms"`
+	} `json:"spec"`
+}
+
+type krewPlatform struct {
+	Selector struct {
+		MatchLabels      map[string]string `json:"matchLabels"`
+		MatchExpressions []struct {
+			Key      string   `json:"key"`
+			Operator string   `json:"operator"`
+			Values   []string `json:"values"`
+		} `json:"matchExpressions"`
+	} `json:"selector"`
+	URI    string `json:"uri"`
+	SHA256 string `json:"sha256"`
+	Bin    string `json:"bin"`
+	Files  []struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"files"`
+}
+
+func runKubectlPlugin(stdin io.Reader, stdout, stderr io.Writer, sandbox Sandbox, sandboxType string, script Script, native NativeSandbox, args []string) error {
+	config := script.KubectlPlugin
+	if config.Name == "" {
+		return fmt.Errorf("error: 'kubectl-plugin.name' missing in script")
+	}
+	manifest := config.locked
+	if manifest == nil {
+		var err error
+		manifest, err = loadKrewManifest(config)
+		if err != nil {
+			return fmt.Errorf("error loading manifest for kubectl plugin %s: %w", config.Name, err)
+		}
+	}
+	log(1, "kubectl plugin %s is version %s", config.Name, manifest.Spec.Version)
+
+	fetch := func(goos, goarch string) (string, error) {
+		platform, err := manifest.platform(goos, goarch)
+		if err != nil {
+			return "", fmt.Errorf("kubectl plugin %s: %w", config.Name, err)
+		}
+		return fetchArtifact(platform.URI, platform.SHA256, platform.archivePath(), goos+"/"+goarch)
+	}
+	mounts, env, err := kubeconfigMounts()
+	if err != nil {
+		return err
+	}
+	return runFetchedBinary(stdin, stdout, stderr, sandbox, sandboxType, script, native, fetch, config.Image, mounts, env, args)
+}
+
+// kubeconfigMounts returns the mounts and env which give the plugin the user's kubeconfig files,
+// at the same paths as on the host.
+func kubeconfigMounts() ([]Mount, []EnvVar, error) {
+	kubeconfig := os.Getenv("KUBECONFIG")
+	if kubeconfig == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get user home dir: %w", err)
+		}
+		kubeconfig = filepath.Join(home, ".kube", "config")
+	}
+	var mounts []Mount
+	var paths []string
+	for _, p := range filepath.SplitList(kubeconfig) {
+		if _, err := os.Stat(p); err != nil {
+			log(1, "Skipping missing kubeconfig %s", p)
+			continue
+		}
+		mounts = append(mounts, Mount{HostPath: p})
+		paths = append(paths, p)
+	}
+	if len(paths) == 0 {
+		return nil, nil, nil
+	}
+	return mounts, []EnvVar{{Name: "KUBECONFIG", Value: strings.Join(paths, ":")}}, nil
+}
+
+// loadKrewManifest reads the plugin manifest from a path, or from its URL via the clix cache.
+func loadKrewManifest(config *KubectlPluginConfig) (*krewManifest, error) {
+	source := config.Manifest
+	if source == "" {
+		source = krewIndexURL + config.Name + ".yaml"
+	}
+
+	manifestPath := source
+	if strings.Contains(source, "://") {
+		krewDir, err := clixCacheDir("krew")
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256([]byte(source))
+		manifestPath = filepath.Join(krewDir, hex.EncodeToString(sum[:])+".yaml")
+		if info, err := os.Stat(manifestPath); err != nil || time.Since(info.ModTime()) > krewManifestTTL {
+			if err := downloadFile(source, manifestPath); err != nil {
+				if info == nil {
+					return nil, err
+				}
+				fmt.Fprintf(os.Stderr, "Warning: using cached manifest for %s: %v\n", config.Name, err)
+			}
+		}
+	}
+
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	var manifest krewManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", source, err)
+	}
+	return &manifest, nil
+}
+
+// platform returns the manifest's artifact for goos/goarch.
+func (m *krewManifest) platform(goos, goarch string) (*krewPlatform, error) {
+	labels := map[string]string{"os": goos, "arch": goarch}
+	for i := range m.Spec.Platforms {
+		if p := &m.Spec.Platforms[i]; p.matches(labels) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("no artifact for %s/%s in manifest", goos, goarch)
+}
+
+// matches evaluates the platform's label selector.
+func (p *krewPlatform) matches(labels map[string]string) bool {
+	for k, v := range p.Selector.MatchLabels {
+		if labels[k] != v {
+			return false
+		}
+	}
+	for _, e := range p.Selector.MatchExpressions {
+		in := false
+		for _, v := range e.Values {
+			in = in || labels[e.Key] == v
+		}
+		switch e.Operator {
+		case "In":
+			if !in {
+				return false
+			}
+		case "NotIn":
+			if in {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// archivePath returns the path of the plugin binary within the artifact. krew installs the files
+// selected by the manifest and then runs bin, so we map bin back through the files it was installed from.
+func (p *krewPlatform) archivePath() string {
+	for _, f := range p.Files {
+		to := path.Clean(f.To)
+		switch {
+		case to == p.Bin:
+			return f.From
+		case to == "." && strings.ContainsAny(path.Base(f.From), "*?["):
+			return path.Join(path.Dir(f.From), p.Bin)
+		case to == "." && path.Base(f.From) == p.Bin:
+			return f.From
+		}
+	}
+	return p.Bin
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestKrewPlatform(t *testing.T) {
+	var manifest krewManifest
+	data := `
+spec:
+  version: v0.9.5
+  platforms:
+  - selector:
+      matchLabels:
+        os: darwin
+    uri: https://example.com/ctx_darwin.tar.gz
+    bin: kubectl-ctx
+  - selector:
+      matchExpressions:
+      - key: os
+        operator: In
+        values: [linux]
+      - key: arch
+        operator: NotIn
+        values: [arm]
+    uri: https://example.com/ctx_linux.tar.gz
+    bin: kubectl-ctx
+    files:
+    - from: "kubectx-0.9.5/*"
+      to: "."
+`
+	if err := yaml.Unmarshal([]byte(data), &manifest); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	p, err := manifest.platform("linux", "amd64")
+	if err != nil || p.URI != "https://example.com/ctx_linux.tar.gz" {
+		t.Fatalf("Expected the linux artifact, got %+v, %v", p, err)
+	}
+	if got := p.archivePath(); got != "kubectx-0.9.5/kubectl-ctx" {
+		t.Errorf("archivePath() = %q", got)
+	}
+	if p, err := manifest.platform("darwin", "arm64"); err != nil || p.archivePath() != "kubectl-ctx" {
+		t.Errorf("Expected the darwin artifact, got %+v, %v", p, err)
+	}
+	if _, err := manifest.platform("linux", "arm"); err == nil {
+		t.Errorf("Expected no artifact for linux/arm")
+	}
+}
+
+func TestRunKubectlPluginInImage(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfig, []byte("apiVersion: v1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("KUBECONFIG", kubeconfig)
+
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	content := []byte("plugin")
+	tw.WriteHeader(&tar.Header{Name: "kubectl-tree", Typeflag: tar.TypeReg, Mode: 0755, Size: int64(len(content))})
+	tw.Write(content)
+	tw.Close()
+	gz.Close()
+	sum := sha256.Sum256(archive.Bytes())
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tree.yaml" {
+			w.Write([]byte("spec:\n  platforms:\n  - selector:\n      matchLabels: {os: linux, arch: amd64}\n    uri: " + server.URL + "/tree.tar.gz\n    sha256: " + hex.EncodeToString(sum[:]) + "\n    bin: kubectl-tree\n"))
+			return
+		}
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	sandbox := &recordingSandbox{}
+	script := Script{
+		KubectlPlugin: &KubectlPluginConfig{Name: "tree", Manifest: server.URL + "/tree.yaml"},
+		Mounts:        []Mount{{HostPath: "git.repoRoot(cwd)"}},
+		Arch:          "amd64",
+	}
+	if err := runKubectlPlugin(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, sandbox, "docker", script, nil, []string{"deployment", "web"}); err != nil {
+		t.Fatalf("runKubectlPlugin failed: %v", err)
+	}
+	if got := strings.Join(sandbox.args, " "); got != "/clix/binary/kubectl-tree deployment web" {
+		t.Errorf("Unexpected command %q", got)
+	}
+	mounts := sandbox.script.Mounts
+	if len(mounts) != 3 || mounts[1].SandboxPath != binaryDir || mounts[2].HostPath != kubeconfig {
+		t.Errorf("Expected the plugin and kubeconfig to be mounted, got %+v", mounts)
+	}
+	if env := sandbox.script.Env; len(env) != 1 || env[0] != (EnvVar{Name: "KUBECONFIG", Value: kubeconfig}) {
+		t.Errorf("Expected KUBECONFIG to be set, got %+v", env)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NodeConfig runs a tool from npm.
+type NodeConfig struct {
+	// Run is the npm package to run
+	Run string `json:"run"`
+	// Version is the version of the package, defaulting to the latest version
+	Version string `json:"version,omitempty"`
+	// Command is the command to run, if the package's command is not named after the package
+	Command string `json:"command,omitempty"`
+	// Image is the node image used in sandboxes, defaulting to node:lts-slim
+	Image string `json:"image,omitempty"`
+}
+
+// npmCacheDir is where ${cacheDir}/npm is mounted in the node image.
+const npmCacheDir = "/clix/npm"
+
+func runNode(stdin io.Reader, stdout, stderr io.Writer, sandbox Sandbox, sandboxType string, script Script, args []string) error {
+	config := script.Node
+	if config.Run == "" {
+		return fmt.Errorf("error: 'node.run' missing in script")
+	}
+	cmdArgs := append(npxCommand(config), args...)
+
+	if len(script.Mounts) == 0 && onHostPath("npx") {
+		return runOnHost(stdin, stdout, stderr, script, cmdArgs)
+	}
+
+	image := config.Image
+	if image == "" {
+		image = "node:lts-slim"
+	}
+	mounts := []Mount{{HostPath: "${cacheDir}/npm", SandboxPath: npmCacheDir}}
+	env := []EnvVar{{Name: "npm_config_cache", Value: npmCacheDir}}
+	return runInImage(stdin, stdout, stderr, sandbox, sandboxType, script, image, mounts, env, cmdArgs)
+}
+
+// npxCommand returns the npx command which runs the package's command, installing the package if needed.
+func npxCommand(config *NodeConfig) []string {
+	spec := config.Run
+	if config.Version != "" {
+		spec += "@" + config.Version
+	}
+	command := config.Command
+	if command == "" {
+		command = npmPackageCommand(config.Run)
+	}
+	return []string{"npx", "--yes", "--package", spec, "--", command}
+}
+
+// npmPackageCommand returns the default command of a package: its name, without any scope.
+func npmPackageCommand(pkg string) string {
+	if i := strings.LastIndex(pkg, "/"); i >= 0 && strings.HasPrefix(pkg, "@") {
+		return pkg[i+1:]
+	}
+	return pkg
+}
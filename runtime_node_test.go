@@ -0,0 +1,58 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNpxCommand(t *testing.T) {
+	for _, tc := range []struct {
+		config NodeConfig
+		want   string
+	}{
+		{NodeConfig{Run: "prettier", Version: "3.2.0"}, "npx --yes --package prettier@3.2.0 -- prettier"},
+		{NodeConfig{Run: "@biomejs/biome"}, "npx --yes --package @biomejs/biome -- biome"},
+		{NodeConfig{Run: "typescript", Command: "tsc"}, "npx --yes --package typescript -- tsc"},
+	} {
+		if got := strings.Join(npxCommand(&tc.config), " "); got != tc.want {
+			t.Errorf("npxCommand(%+v) = %q, want %q", tc.config, got, tc.want)
+		}
+	}
+}
+
+func TestRunNodeInImage(t *testing.T) {
+	fakeHostCommands(t, "npx")
+	sandbox := &recordingSandbox{}
+	script := Script{
+		Node:   &NodeConfig{Run: "prettier", Version: "3.2.0"},
+		Mounts: []Mount{{HostPath: "git.repoRoot(cwd)"}},
+	}
+	if err := runNode(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, sandbox, "docker", script, []string{"--check", "."}); err != nil {
+		t.Fatalf("runNode failed: %v", err)
+	}
+
+	if sandbox.script.Image != "node:lts-slim" {
+		t.Errorf("Expected the node image, got %q", sandbox.script.Image)
+	}
+	if len(sandbox.script.Mounts) != 2 || sandbox.script.Mounts[1].SandboxPath != npmCacheDir {
+		t.Errorf("Expected the npm cache to be mounted, got %+v", sandbox.script.Mounts)
+	}
+	if got := strings.Join(sandbox.args, " "); got != "npx --yes --package prettier@3.2.0 -- prettier --check ." {
+		t.Errorf("Unexpected command %q", got)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PythonConfig runs a tool from PyPI.
+type PythonConfig struct {
+	// Run is the PyPI package to run
+	Run string `json:"run"`
+	// Version is the version of the package, defaulting to the latest version
+	Version string `json:"version,omitempty"`
+	// Command is the command to run, if the package's command is not named after the package
+	Command string `json:"command,omitempty"`
+	// Image is the python image used in sandboxes, defaulting to an image with uv installed
+	Image string `json:"image,omitempty"`
+}
+
+// pythonCacheDir is where ${cacheDir}/python is mounted in the python image.
+const pythonCacheDir = "/clix/python"
+
+// defaultPythonImage bundles uv, as cold-start pip installs are too slow for interactive tools.
+const defaultPythonImage = "ghcr.io/astral-sh/uv:python3.12-bookworm-slim"
+
+func (c *PythonConfig) requirement() string {
+	if c.Version != "" {
+		return c.Run + "==" + c.Version
+	}
+	return c.Run
+}
+
+func (c *PythonConfig) command() string {
+	if c.Command != "" {
+		return c.Command
+	}
+	return c.Run
+}
+
+func runPython(stdin io.Reader, stdout, stderr io.Writer, sandbox Sandbox, sandboxType string, script Script, args []string) error {
+	config := script.Python
+	if config.Run == "" {
+		return fmt.Errorf("error: 'python.run' missing in script")
+	}
+
+	if len(script.Mounts) == 0 {
+		if cmdArgs := hostPythonCommand(config); cmdArgs != nil {
+			return runOnHost(stdin, stdout, stderr, script, append(cmdArgs, args...))
+		}
+	}
+
+	image := config.Image
+	if image == "" {
+		image = defaultPythonImage
+	}
+	mounts := []Mount{{HostPath: "${cacheDir}/python", SandboxPath: pythonCacheDir}}
+	env := []EnvVar{
+		{Name: "PIP_CACHE_DIR", Value: pythonCacheDir + "/pip"},
+		{Name: "UV_CACHE_DIR", Value: pythonCacheDir + "/uv"},
+		// The cache is a mount, so uv cannot hardlink from it into its environments
+		{Name: "UV_LINK_MODE", Value: "copy"},
+	}
+	cmdArgs := append([]string{"sh", "-c", pythonImageScript(config), "sh"}, args...)
+	return runInImage(stdin, stdout, stderr, sandbox, sandboxType, script, image, mounts, env, cmdArgs)
+}
+
+// hostPythonCommand returns the command which runs the tool with uvx or pipx on the host, if installed.
+func hostPythonCommand(config *PythonConfig) []string {
+	if onHostPath("uvx") {
+		return []string{"uvx", "--from", config.requirement(), config.command()}
+	}
+	if onHostPath("pipx") {
+		return []string{"pipx", "run", "--spec", config.requirement(), config.command()}
+	}
+	return nil
+}
+
+// pythonImageScript returns a shell script which runs the tool with uvx if the image has uv, and otherwise
+// installs the package into a venv in the cache, if it is not already installed, and then runs it.
+// Unversioned packages are installed into the venv once, and not upgraded.
+func pythonImageScript(config *PythonConfig) string {
+	version := config.Version
+	if version == "" {
+		version = "latest"
+	}
+	venv := shellQuote(fmt.Sprintf("%s/venvs/%s-%s", pythonCacheDir, config.Run, version))
+	command := shellQuote(config.command())
+	var sb strings.Builder
+	sb.WriteString("if command -v uvx >/dev/null 2>&1; then\n")
+	fmt.Fprintf(&sb, "  exec uvx --quiet --from %s %s \"$@\"\n", shellQuote(config.requirement()), command)
+	sb.WriteString("fi\n")
+	fmt.Fprintf(&sb, "venv=%s\n", venv)
+	fmt.Fprintf(&sb, "if [ ! -x \"$venv/bin/\"%s ]; then\n", command)
+	fmt.Fprintf(&sb, "  python -m venv \"$venv\" >&2 && \"$venv/bin/pip\" install --quiet %s >&2 || exit 1\n", shellQuote(config.requirement()))
+	sb.WriteString("fi\n")
+	fmt.Fprintf(&sb, "exec \"$venv/bin/\"%s \"$@\"\n", command)
+	return sb.String()
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestHostPythonCommand(t *testing.T) {
+	config := &PythonConfig{Run: "httpie", Version: "3.2.2", Command: "http"}
+
+	fakeHostCommands(t, "uvx", "pipx")
+	if got := strings.Join(hostPythonCommand(config), " "); got != "uvx --from httpie==3.2.2 http" {
+		t.Errorf("Expected uvx to be preferred, got %q", got)
+	}
+
+	fakeHostCommands(t, "pipx")
+	if got := strings.Join(hostPythonCommand(config), " "); got != "pipx run --spec httpie==3.2.2 http" {
+		t.Errorf("Expected pipx, got %q", got)
+	}
+
+	fakeHostCommands(t)
+	if got := hostPythonCommand(config); got != nil {
+		t.Errorf("Expected no host command, got %v", got)
+	}
+}
+
+func TestRunPythonInImage(t *testing.T) {
+	fakeHostCommands(t, "uvx")
+	sandbox := &recordingSandbox{}
+	script := Script{
+		Python: &PythonConfig{Run: "black", Version: "24.4.2"},
+		Mounts: []Mount{{HostPath: "git.repoRoot(cwd)"}},
+	}
+	if err := runPython(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, sandbox, "docker", script, []string{"--check", "."}); err != nil {
+		t.Fatalf("runPython failed: %v", err)
+	}
+
+	if sandbox.script.Image != defaultPythonImage {
+		t.Errorf("Expected the python image, got %q", sandbox.script.Image)
+	}
+	if len(sandbox.script.Mounts) != 2 || sandbox.script.Mounts[1].SandboxPath != pythonCacheDir {
+		t.Errorf("Expected the python cache to be mounted, got %+v", sandbox.script.Mounts)
+	}
+	if len(sandbox.args) != 6 || sandbox.args[0] != "sh" || sandbox.args[4] != "--check" || sandbox.args[5] != "." {
+		t.Fatalf("Expected sh -c <script> sh --check ., got %v", sandbox.args)
+	}
+	imageScript := sandbox.args[2]
+	for _, want := range []string{
+		"exec uvx --quiet --from 'black==24.4.2' 'black' \"$@\"",
+		"/clix/python/venvs/black-24.4.2",
+		"install --quiet 'black==24.4.2'",
+	} {
+		if !strings.Contains(imageScript, want) {
+			t.Errorf("Expected image script to contain %q, got %q", want, imageScript)
+		}
+	}
+	checkShellSyntax(t, imageScript)
+	uvCache := false
+	for _, e := range sandbox.script.Env {
+		uvCache = uvCache || e == EnvVar{Name: "UV_CACHE_DIR", Value: pythonCacheDir + "/uv"}
+	}
+	if !uvCache {
+		t.Errorf("Expected the uv cache in the python cache, got %+v", sandbox.script.Env)
+	}
+}
+
+func TestRunPythonOnHost(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("MOCK_BEHAVIOR", "echo_args")
+	fakeHostCommands(t, "uvx")
+
+	var stdout bytes.Buffer
+	script := Script{Python: &PythonConfig{Run: "black"}}
+	if err := runPython(strings.NewReader(""), &stdout, &bytes.Buffer{}, nil, "docker", script, []string{"."}); err != nil {
+		t.Fatalf("runPython failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "args: [--from black black .]") {
+		t.Errorf("Expected the tool to run with uvx, got %q", stdout.String())
+	}
+}
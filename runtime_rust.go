@@ -0,0 +1,133 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RustConfig runs a tool from crates.io.
+type RustConfig struct {
+	// Run is the crate to install and run
+	Run string `json:"run"`
+	// Version is the version of the crate, defaulting to the latest version
+	Version string `json:"version,omitempty"`
+	// Command is the command to run, if the crate's binary is not named after the crate
+	Command string `json:"command,omitempty"`
+	// Image is the rust image used in sandboxes, defaulting to rust:slim
+	Image string `json:"image,omitempty"`
+}
+
+// cargoCacheDir is where ${cacheDir}/cargo is mounted in the rust image.
+const cargoCacheDir = "/clix/cargo"
+
+func (c *RustConfig) command() string {
+	if c.Command != "" {
+		return c.Command
+	}
+	return c.Run
+}
+
+// installName names the install root of this crate version; unversioned crates are installed once.
+func (c *RustConfig) installName() string {
+	version := c.Version
+	if version == "" {
+		version = "latest"
+	}
+	return c.Run + "-" + version
+}
+
+// cargoInstallArgs returns the cargo install command which installs the crate into root.
+func (c *RustConfig) cargoInstallArgs(root string) []string {
+	cmdArgs := []string{"cargo", "install", "--locked", "--quiet", "--root", root, c.Run}
+	if c.Version != "" {
+		cmdArgs = append(cmdArgs, "--version", c.Version)
+	}
+	return cmdArgs
+}
+
+func runRust(stdin io.Reader, stdout, stderr io.Writer, sandbox Sandbox, sandboxType string, script Script, args []string) error {
+	config := script.Rust
+	if config.Run == "" {
+		return fmt.Errorf("error: 'rust.run' missing in script")
+	}
+
+	if len(script.Mounts) == 0 && onHostPath("cargo") {
+		binary, err := installRustOnHost(stderr, config)
+		if err != nil {
+			return err
+		}
+		return runOnHost(stdin, stdout, stderr, script, append([]string{binary}, args...))
+	}
+
+	image := config.Image
+	if image == "" {
+		image = "rust:slim"
+	}
+	mounts := []Mount{{HostPath: "${cacheDir}/cargo", SandboxPath: cargoCacheDir}}
+	env := []EnvVar{{Name: "CARGO_HOME", Value: cargoCacheDir + "/home"}}
+	cmdArgs := append([]string{"sh", "-c", cargoInstallScript(config), "sh"}, args...)
+	return runInImage(stdin, stdout, stderr, sandbox, sandboxType, script, image, mounts, env, cmdArgs)
+}
+
+// installRustOnHost installs the crate into the clix cache on the host, if it is not already installed,
+// returning the path of its binary.
+func installRustOnHost(stderr io.Writer, config *RustConfig) (string, error) {
+	cargoDir, err := clixCacheDir("cargo")
+	if err != nil {
+		return "", err
+	}
+	root := filepath.Join(cargoDir, config.installName())
+	binary := filepath.Join(root, "bin", config.command())
+	if _, err := os.Stat(binary); err == nil {
+		return binary, nil
+	}
+
+	log(1, "Installing %s into %s", config.installName(), root)
+	installArgs := config.cargoInstallArgs(root)
+	cmd := execCommand(installArgs[0], installArgs[1:]...)
+	cmd.Stdout = stderr
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("error installing crate %s: %w", config.Run, err)
+	}
+	return binary, nil
+}
+
+// cargoInstallScript returns a shell script which installs the crate into the cache, if it is not
+// already installed, and then runs it.
+func cargoInstallScript(config *RustConfig) string {
+	root := cargoCacheDir + "/installs/" + config.installName()
+	return cachedInstallScript(config.cargoInstallArgs(root), root+"/bin/"+config.command())
+}
+
+// cachedInstallScript returns a shell script which runs installArgs if binary is not already installed,
+// and then runs binary with the script's arguments.
+func cachedInstallScript(installArgs []string, binary string) string {
+	var quoted []string
+	for _, arg := range installArgs {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "if [ ! -x %s ]; then\n", shellQuote(binary))
+	fmt.Fprintf(&sb, "  %s >&2 || exit 1\n", strings.Join(quoted, " "))
+	sb.WriteString("fi\n")
+	fmt.Fprintf(&sb, "exec %s \"$@\"\n", shellQuote(binary))
+	return sb.String()
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunRustInImage(t *testing.T) {
+	fakeHostCommands(t, "cargo")
+	sandbox := &recordingSandbox{}
+	script := Script{
+		Rust:   &RustConfig{Run: "ripgrep", Version: "14.1.0", Command: "rg"},
+		Mounts: []Mount{{HostPath: "git.repoRoot(cwd)"}},
+	}
+	if err := runRust(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, sandbox, "docker", script, []string{"TODO"}); err != nil {
+		t.Fatalf("runRust failed: %v", err)
+	}
+
+	if sandbox.script.Image != "rust:slim" {
+		t.Errorf("Expected the rust image, got %q", sandbox.script.Image)
+	}
+	if len(sandbox.script.Mounts) != 2 || sandbox.script.Mounts[1].SandboxPath != cargoCacheDir {
+		t.Errorf("Expected the cargo cache to be mounted, got %+v", sandbox.script.Mounts)
+	}
+	installScript := sandbox.args[2]
+	for _, want := range []string{
+		"'cargo' 'install' '--locked' '--quiet' '--root' '/clix/cargo/installs/ripgrep-14.1.0' 'ripgrep' '--version' '14.1.0'",
+		"exec '/clix/cargo/installs/ripgrep-14.1.0/bin/rg' \"$@\"",
+	} {
+		if !strings.Contains(installScript, want) {
+			t.Errorf("Expected install script to contain %q, got %q", want, installScript)
+		}
+	}
+	checkShellSyntax(t, installScript)
+}
+
+func TestInstallRustOnHost(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	cache := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cache)
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("MOCK_CALLS", calls)
+
+	config := &RustConfig{Run: "ripgrep", Command: "rg"}
+	binary, err := installRustOnHost(&bytes.Buffer{}, config)
+	if err != nil {
+		t.Fatalf("installRustOnHost failed: %v", err)
+	}
+	root := filepath.Join(cache, "clix", "cargo", "ripgrep-latest")
+	if binary != filepath.Join(root, "bin", "rg") {
+		t.Errorf("Unexpected binary %q", binary)
+	}
+	data, _ := os.ReadFile(calls)
+	if got := strings.TrimSpace(string(data)); got != "cargo install --locked --quiet --root "+root+" ripgrep" {
+		t.Errorf("Unexpected install command %q", got)
+	}
+
+	// Once installed, the crate is not installed again
+	if err := os.MkdirAll(filepath.Dir(binary), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(binary, nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(calls)
+	if _, err := installRustOnHost(&bytes.Buffer{}, config); err != nil {
+		t.Fatalf("installRustOnHost failed: %v", err)
+	}
+	if _, err := os.Stat(calls); err == nil {
+		t.Errorf("Expected no install when the crate is cached")
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// ShellConfig runs a shell script under a pinned interpreter image, so that it behaves the same on every machine.
+type ShellConfig struct {
+	// Script is the body of the shell script
+	Script string `json:"script,omitempty"`
+	// File is the path of the shell script, relative to the clix script, if Script is not set
+	File string `json:"file,omitempty"`
+	// Interpreter is the shell which runs the script, sh (the default) or bash
+	Interpreter string `json:"interpreter,omitempty"`
+	// Image is the image which provides the interpreter, defaulting to a pinned busybox or bash image
+	Image string `json:"image,omitempty"`
+}
+
+// shellImages are the default images for each interpreter.
+var shellImages = map[string]string{
+	"sh":   "busybox:1.36.1",
+	"bash": "bash:5.2.26",
+}
+
+func runShell(stdin io.Reader, stdout, stderr io.Writer, sandbox Sandbox, sandboxType string, script Script, args []string) error {
+	config := script.Shell
+	if config.Script == "" {
+		return fmt.Errorf("error: 'shell.script' or 'shell.file' missing in script")
+	}
+	interpreter := config.Interpreter
+	if interpreter == "" {
+		interpreter = "sh"
+	}
+	image := config.Image
+	if image == "" {
+		image = shellImages[interpreter]
+		if image == "" {
+			return fmt.Errorf("error: unknown shell interpreter %q (expected 'sh' or 'bash'), or set 'shell.image'", interpreter)
+		}
+	}
+
+	// Unlike the other runtimes, shell scripts never run on the host: the pinned image is the point
+	cmdArgs := append([]string{interpreter, "-c", config.Script, "clix-shell"}, args...)
+	return runInImage(stdin, stdout, stderr, sandbox, sandboxType, script, image, nil, nil, cmdArgs)
+}
+
+// loadShellFile reads the shell script referenced by file, relative to the clix script, into config.Script.
+func loadShellFile(scriptPath string, config *ShellConfig) error {
+	if config.File == "" {
+		return nil
+	}
+	if config.Script != "" {
+		return fmt.Errorf("'shell.script' and 'shell.file' are mutually exclusive")
+	}
+	path, err := resolveScriptPath(scriptPath, config.File)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	config.Script = string(data)
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunShell(t *testing.T) {
+	sandbox := &recordingSandbox{}
+	script := Script{
+		Shell:  &ShellConfig{Script: "echo \"$@\"", Interpreter: "bash"},
+		Mounts: []Mount{{HostPath: "git.repoRoot(cwd)"}},
+	}
+	if err := runShell(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, sandbox, "docker", script, []string{"a", "b"}); err != nil {
+		t.Fatalf("runShell failed: %v", err)
+	}
+	if sandbox.script.Image != "bash:5.2.26" {
+		t.Errorf("Expected the pinned bash image, got %q", sandbox.script.Image)
+	}
+	if len(sandbox.script.Mounts) != 1 {
+		t.Errorf("Expected the script's mounts, got %+v", sandbox.script.Mounts)
+	}
+	if got := strings.Join(sandbox.args, " "); got != "bash -c echo \"$@\" clix-shell a b" {
+		t.Errorf("Unexpected command %q", got)
+	}
+
+	script.Shell = &ShellConfig{Script: "true", Interpreter: "zsh"}
+	if err := runShell(strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{}, sandbox, "docker", script, nil); err == nil {
+		t.Errorf("Expected an error for an interpreter without a default image")
+	}
+}
+
+func TestLoadShellFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "build.sh"), []byte("make all\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := &ShellConfig{File: "build.sh"}
+	if err := loadShellFile(filepath.Join(dir, "build"), config); err != nil {
+		t.Fatalf("loadShellFile failed: %v", err)
+	}
+	if config.Script != "make all\n" {
+		t.Errorf("Unexpected script %q", config.Script)
+	}
+
+	if err := loadShellFile(filepath.Join(dir, "build"), &ShellConfig{File: "build.sh", Script: "true"}); err == nil {
+		t.Errorf("Expected an error when both script and file are set")
+	}
+}
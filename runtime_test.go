@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recordingSandbox records the script and args it is asked to run.
+type recordingSandbox struct {
+	script Script
+	args   []string
+}
+
+func (s *recordingSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
+	s.script = script
+	s.args = args
+	return nil
+}
+
+// fakeHostCommands puts empty executables named commands on PATH, in place of the host's PATH. Tools
+// run on the host get the host env rather than the mock's, so it also asks for the helper process there.
+func fakeHostCommands(t *testing.T, commands ...string) {
+	t.Helper()
+	t.Setenv("GO_WANT_HELPER_PROCESS", "1")
+	dir := t.TempDir()
+	for _, command := range commands {
+		if err := os.WriteFile(filepath.Join(dir, command), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+}
+
+// checkShellSyntax fails the test if script is not valid sh.
+func checkShellSyntax(t *testing.T, script string) {
+	t.Helper()
+	// Tests may have replaced PATH with fakeHostCommands
+	const sh = "/bin/sh"
+	if _, err := os.Stat(sh); err != nil {
+		t.Skip("sh not found")
+	}
+	if out, err := exec.Command(sh, "-n", "-c", script).CombinedOutput(); err != nil {
+		t.Errorf("Invalid shell script %q: %v\n%s", script, err, out)
+	}
+}
+
+func TestRunOnHostEnv(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("MOCK_BEHAVIOR", "echo_env")
+	fakeHostCommands(t, "uvx")
+	t.Setenv("GITHUB_TOKEN", "host-secret")
+
+	var stdout strings.Builder
+	script := Script{Env: []EnvVar{{Name: "TOOL_MODE", Value: "ci"}}}
+	if err := runOnHost(strings.NewReader(""), &stdout, io.Discard, script, []string{"uvx", "black"}); err != nil {
+		t.Fatalf("runOnHost failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "\nTOOL_MODE=ci\n") {
+		t.Errorf("Expected the script env to reach the tool, got:\n%s", stdout.String())
+	}
+	if strings.Contains(stdout.String(), "host-secret") {
+		t.Errorf("Expected sensitive host variables to be scrubbed, got:\n%s", stdout.String())
+	}
+}
@@ -19,10 +19,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
 
 	"github.com/google/go-containerregistry/pkg/crane"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 )
 
 // Sandbox is the interface implemented by all our sandboxing technologies (docker, chroot etc)
@@ -31,13 +34,31 @@ type Sandbox interface {
 	Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error
 }
 
-func prepareRootFS(imageRef string) (string, string, func(), error) {
-	// Assume it is a container image
-	img, err := crane.Pull(imageRef)
+// NativeSandbox is implemented by sandboxes that confine a process running directly on the host (seatbelt etc),
+// rather than running a container image.
+type NativeSandbox interface {
+	// Command returns the command that runs name with args, confined to the mounts declared by script
+	Command(script Script, name string, args ...string) (*exec.Cmd, error)
+}
+
+// prepareRootFS pulls the image for the platform, and extracts it to a temporary directory.
+func prepareRootFS(imageRef string, platform v1.Platform) (string, string, func(), error) {
+	// Assume it is a container image. There is no local cache of extracted images, so the sandboxes which
+	// use them can't run offline
+	if offlineMode() {
+		return "", "", nil, fmt.Errorf("offline: image %s would need to be pulled, and the chroot, proot, nsjail, namespace, runc and firecracker sandboxes don't cache images; use a container engine such as docker", imageRef)
+	}
+	img, err := crane.Pull(imageRef, crane.WithPlatform(&platform))
 	if err != nil {
 		return "", "", nil, fmt.Errorf("pulling image %q: %w", imageRef, err)
 	}
 
+	// Images which are not multi-platform are returned whatever their architecture
+	arch := platform.Architecture
+	if config, err := img.ConfigFile(); err == nil && config.Architecture != "" && config.Architecture != arch {
+		return "", "", nil, fmt.Errorf("image %q is %s, not %s; set arch: %s in the script", imageRef, config.Architecture, arch, config.Architecture)
+	}
+
 	digest, err := img.Digest()
 	if err != nil {
 		return "", "", nil, fmt.Errorf("getting image digest: %w", err)
@@ -115,65 +136,224 @@ func untar(r io.Reader, dest string) error {
 	return nil
 }
 
+// usesCacheDir reports whether any of the mounts reference the per-image cache directory.
+func usesCacheDir(mounts []Mount) bool {
+	for _, m := range mounts {
+		for _, p := range append([]string{m.HostPath}, m.HostPaths...) {
+			if strings.Contains(p, "{cacheDir}") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// volumeArg returns the -v argument of docker-compatible CLIs for mounting source (a host path or volume)
+// at target.
+func volumeArg(source, target string, readOnly bool) string {
+	if readOnly {
+		return source + ":" + target + ":ro"
+	}
+	return source + ":" + target
+}
+
 func resolveMounts(mounts []Mount, imageSHA string) ([]Mount, error) {
 	var resolved []Mount
-	cwd, err := os.Getwd()
-	if err != nil {
-		return nil, err
-	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user home dir: %w", err)
 	}
 
 	for _, m := range mounts {
-		if strings.Contains(m.HostPath, "{cacheDir}") || strings.Contains(m.HostPath, "${cacheDir}") {
-			if strings.Count(m.HostPath, "{cacheDir}") > strings.Count(m.HostPath, "${cacheDir}") {
-				fmt.Fprintf(os.Stderr, "Warning: usage of {cacheDir} is deprecated and will be removed in future versions. Please use ${cacheDir} instead.\n")
+		if m.Volume != "" {
+			return nil, fmt.Errorf("volume %s: volume mounts are only supported by docker-compatible sandboxes (docker, podman, nerdctl)", m.Volume)
+		}
+		if m.HostPath != "" && len(m.HostPaths) > 0 {
+			return nil, fmt.Errorf("mount %s: set hostPath or hostPaths, not both", m.HostPath)
+		}
+
+		sandboxPath, _, err := evalExpression(m.SandboxPath)
+		if err != nil {
+			return nil, fmt.Errorf("mount %s: %w", m.SandboxPath, err)
+		}
+		if strings.HasPrefix(sandboxPath, "~/") {
+			// TODO: Resolve this better once we find a container image where HOME is not /root
+			sandboxPath = "/root/" + sandboxPath[2:]
+		} else if sandboxPath == "~" {
+			sandboxPath = "/root"
+		}
+
+		paths := m.HostPaths
+		if len(paths) == 0 {
+			paths = []string{m.HostPath}
+		}
+		for _, p := range paths {
+			hostPath, err := resolveHostPath(p, imageSHA, home)
+			if err != nil {
+				return nil, err
 			}
-			if imageSHA == "" {
-				return nil, fmt.Errorf("cacheDir variable used but image SHA not available")
+
+			// Globs and lists of paths mount the paths which exist, rather than creating missing ones
+			if len(m.HostPaths) == 0 && !hasGlobMeta(hostPath) {
+				if _, err := os.Lstat(hostPath); os.IsNotExist(err) {
+					skip, err := handleMissingMount(m, hostPath)
+					if err != nil {
+						return nil, err
+					}
+					if skip {
+						continue
+					}
+				}
+				r := m
+				r.HostPath = hostPath
+				r.SandboxPath = sandboxPath
+				if r.SandboxPath == "" {
+					r.SandboxPath = hostPath
+				}
+				masks, err := maskMounts(r, home)
+				if err != nil {
+					return nil, err
+				}
+				resolved = append(resolved, r)
+				resolved = append(resolved, masks...)
+				continue
 			}
-			userCache, err := os.UserCacheDir()
+			matches, err := filepath.Glob(hostPath)
 			if err != nil {
-				return nil, fmt.Errorf("failed to get user cache dir: %w", err)
+				return nil, fmt.Errorf("mount %s: %w", p, err)
 			}
-			// TODO: Eventually we'll need to do garbage collection
-			cacheDir := filepath.Join(userCache, "clix", "cache", imageSHA)
-			if err := os.MkdirAll(cacheDir, 0755); err != nil {
-				return nil, fmt.Errorf("failed to create cache dir: %w", err)
+			if len(matches) == 0 {
+				if m.Required != nil && *m.Required {
+					return nil, fmt.Errorf("mount %s: no matching paths, and the mount is required", p)
+				}
+				log(1, "Skipping mount %s: no matching paths", p)
 			}
-			m.HostPath = strings.ReplaceAll(m.HostPath, "${cacheDir}", cacheDir)
-			m.HostPath = strings.ReplaceAll(m.HostPath, "{cacheDir}", cacheDir)
-		}
-
-		if m.HostPath == "git.repoRoot(cwd)" {
-			root, err := findGitRoot(cwd)
-			if err != nil {
-				return nil, fmt.Errorf("failed to find git root: %w", err)
+			for _, match := range matches {
+				r := m
+				r.HostPaths = nil
+				r.HostPath = match
+				r.SandboxPath = match
+				if sandboxPath != "" {
+					// The matches are mounted in the sandboxPath directory
+					r.SandboxPath = path.Join(sandboxPath, filepath.Base(match))
+				}
+				masks, err := maskMounts(r, home)
+				if err != nil {
+					return nil, err
+				}
+				resolved = append(resolved, r)
+				resolved = append(resolved, masks...)
 			}
-			m.HostPath = root
 		}
+	}
+	return resolved, nil
+}
 
-		if strings.HasPrefix(m.HostPath, "~/") {
-			m.HostPath = filepath.Join(home, m.HostPath[2:])
-		} else if m.HostPath == "~" {
-			m.HostPath = home
+// resolveHostPath substitutes the cache directory into a mount's host path, and evaluates expressions and ~.
+func resolveHostPath(hostPath, imageSHA, home string) (string, error) {
+	if strings.Contains(hostPath, "{cacheDir}") || strings.Contains(hostPath, "${cacheDir}") {
+		if strings.Count(hostPath, "{cacheDir}") > strings.Count(hostPath, "${cacheDir}") {
+			fmt.Fprintf(os.Stderr, "Warning: usage of {cacheDir} is deprecated and will be removed in future versions. Please use ${cacheDir} instead.\n")
 		}
-
-		// TODO: Resolve this better once we find a container image where HOME is not /root
-		if strings.HasPrefix(m.SandboxPath, "~/") {
-			m.SandboxPath = "/root/" + m.SandboxPath[2:]
-		} else if m.SandboxPath == "~" {
-			m.SandboxPath = "/root"
+		if imageSHA == "" {
+			return "", fmt.Errorf("cacheDir variable used but image SHA not available")
 		}
+		userCache, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get user cache dir: %w", err)
+		}
+		// The directory's modification time is its last use, for clix cache gc
+		cacheDir := filepath.Join(userCache, "clix", "cache", imageSHA)
+		if err := os.MkdirAll(cacheDir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create cache dir: %w", err)
+		}
+		touchCacheDir(cacheDir)
+		hostPath = strings.ReplaceAll(hostPath, "${cacheDir}", cacheDir)
+		hostPath = strings.ReplaceAll(hostPath, "{cacheDir}", cacheDir)
+	}
+
+	evaluated, _, err := evalExpression(hostPath)
+	if err != nil {
+		return "", fmt.Errorf("mount %s: %w", hostPath, err)
+	}
+	hostPath = evaluated
+
+	if strings.HasPrefix(hostPath, "~/") {
+		hostPath = filepath.Join(home, hostPath[2:])
+	} else if hostPath == "~" {
+		hostPath = home
+	}
+	return hostPath, nil
+}
+
+// handleMissingMount applies the mount's policy for a host path which doesn't exist: failing, keeping a
+// mount which ensureMountPaths will create, or skipping the mount, which it reports.
+func handleMissingMount(m Mount, hostPath string) (bool, error) {
+	switch m.CreateIfMissing {
+	case "file", "directory":
+		return false, nil
+	case "":
+	default:
+		return false, fmt.Errorf("mount %s: createIfMissing must be file or directory, not %q", hostPath, m.CreateIfMissing)
+	}
+	if m.Required == nil {
+		return false, nil
+	}
+	if *m.Required {
+		return false, fmt.Errorf("mount %s does not exist, and is required", hostPath)
+	}
+	log(1, "Skipping mount %s: it does not exist", hostPath)
+	return true, nil
+}
 
-		if m.SandboxPath == "" {
-			m.SandboxPath = m.HostPath
+// ensureMountPaths creates the missing host paths of mounts with createIfMissing. Resolving mounts
+// changes nothing on the host, as it also runs for approval and dry runs, so this runs only once the run
+// is approved, just before the sandbox starts. Mounts in the cache directory are left to the sandbox,
+// as the image SHA isn't known yet.
+func ensureMountPaths(mounts []Mount) error {
+	var create []Mount
+	for _, m := range mounts {
+		if m.CreateIfMissing != "" && !usesCacheDir([]Mount{m}) {
+			create = append(create, m)
 		}
-		resolved = append(resolved, m)
 	}
-	return resolved, nil
+	resolved, err := resolveMounts(create, "")
+	if err != nil {
+		return err
+	}
+	for _, m := range resolved {
+		if m.masked {
+			continue
+		}
+		if _, err := os.Lstat(m.HostPath); !os.IsNotExist(err) {
+			continue
+		}
+		switch m.CreateIfMissing {
+		case "file":
+			if err := os.MkdirAll(filepath.Dir(m.HostPath), 0755); err != nil {
+				return fmt.Errorf("mount %s: %w", m.HostPath, err)
+			}
+			f, err := os.OpenFile(m.HostPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+			if err != nil {
+				return fmt.Errorf("mount %s: %w", m.HostPath, err)
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+			log(1, "Created empty file %s for mount", m.HostPath)
+		case "directory":
+			if err := os.MkdirAll(m.HostPath, 0755); err != nil {
+				return fmt.Errorf("mount %s: %w", m.HostPath, err)
+			}
+			log(1, "Created directory %s for mount", m.HostPath)
+		}
+	}
+	return nil
+}
+
+// hasGlobMeta reports whether a host path is a glob pattern.
+func hasGlobMeta(p string) bool {
+	return strings.ContainsAny(p, "*?[")
 }
 
 func findGitRoot(path string) (string, error) {
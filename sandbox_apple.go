@@ -23,6 +23,8 @@ import (
 	"strings"
 )
 
+// AppleContainerSandbox runs images with Apple's container CLI, which runs each container in a lightweight
+// VM using the Virtualization framework. Mounts are shared into the VM with virtiofs.
 type AppleContainerSandbox struct{}
 
 func (s *AppleContainerSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
@@ -36,16 +38,18 @@ func (s *AppleContainerSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, s
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
-	if err := cmd.Run(); err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			// Propagate the exit code from the subcommand
-			os.Exit(exitErr.ExitCode())
-		}
+	if err := runTool(cmd); err != nil {
 		return fmt.Errorf("error running container command: %w", err)
 	}
 	return nil
 }
 
+// hasAppleContainer reports whether Apple's container CLI is installed.
+func hasAppleContainer() bool {
+	_, err := exec.LookPath("container")
+	return err == nil
+}
+
 func buildAppleContainerArgs(script Script, args []string, isTerm bool) ([]string, error) {
 	cmdArgs := []string{"run", "--rm"}
 	if isTerm {
@@ -54,15 +58,7 @@ func buildAppleContainerArgs(script Script, args []string, isTerm bool) ([]strin
 
 	// Resolve cache directory if needed
 	imageSHA := ""
-	needsSHA := false
-	for _, m := range script.Mounts {
-		if strings.Contains(m.HostPath, "{cacheDir}") || strings.Contains(m.HostPath, "${cacheDir}") {
-			needsSHA = true
-			break
-		}
-	}
-
-	if needsSHA {
+	if usesCacheDir(script.Mounts) {
 		var err error
 		imageSHA, err = getAppleContainerImageSHAFn(script.Image)
 		if err != nil {
@@ -76,19 +72,29 @@ func buildAppleContainerArgs(script Script, args []string, isTerm bool) ([]strin
 	}
 
 	for _, m := range resolvedMounts {
-		cmdArgs = append(cmdArgs, "-v", fmt.Sprintf("%s:%s", m.HostPath, m.SandboxPath))
+		// virtiofs shares directories, not individual files
+		if info, err := os.Stat(m.HostPath); err == nil && !info.IsDir() {
+			return nil, fmt.Errorf("apple/container can only mount directories, but %s is a file; mount its directory instead", m.HostPath)
+		}
+		cmdArgs = append(cmdArgs, "-v", volumeArg(m.HostPath, m.SandboxPath, m.ReadOnly))
 	}
 
 	for _, e := range script.Env {
 		cmdArgs = append(cmdArgs, "-e", fmt.Sprintf("%s=%s", e.Name, e.Value))
 	}
 
-	// Set working directory to CWD if possible
-	cwd, err := os.Getwd()
+	if script.Network.Mode != "" {
+		cmdArgs = append(cmdArgs, "--network", script.Network.Mode)
+	}
+	for _, p := range script.Ports {
+		cmdArgs = append(cmdArgs, "-p", p)
+	}
+
+	workdir, err := sandboxWorkdir(script, resolvedMounts)
 	if err != nil {
-		return nil, fmt.Errorf("error getting current working directory: %w", err)
+		return nil, err
 	}
-	cmdArgs = append(cmdArgs, "-w", cwd)
+	cmdArgs = append(cmdArgs, "-w", workdir)
 
 	if script.Entrypoint != "" {
 		cmdArgs = append(cmdArgs, "--entrypoint", script.Entrypoint)
@@ -17,6 +17,7 @@ package main
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -110,7 +111,7 @@ func TestBuildImageAppleContainer(t *testing.T) {
 		Git: "https://github.com/example/repo",
 	}
 
-	_, err := buildImage(stdin, &stdout, &stderr, build, "test-script.yaml")
+	_, err := buildImage(stdin, &stdout, &stderr, Script{Build: build}, "test-script.yaml")
 	if err != nil {
 		t.Fatalf("buildImage failed: %v", err)
 	}
@@ -141,3 +142,17 @@ func TestGetAppleContainerImageSHA(t *testing.T) {
 		t.Errorf("Expected SHA %s, got %s", expectedSHA, sha)
 	}
 }
+
+func TestBuildAppleContainerArgsFileMount(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(file, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script := Script{
+		Image:  "alpine",
+		Mounts: []Mount{{HostPath: file, SandboxPath: "/etc/config.json"}},
+	}
+	if _, err := buildAppleContainerArgs(script, nil, false); err == nil || !strings.Contains(err.Error(), "only mount directories") {
+		t.Errorf("Expected an error for a file mount, got %v", err)
+	}
+}
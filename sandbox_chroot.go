@@ -17,20 +17,33 @@ package main
 import (
 	"fmt"
 	"io"
-	"os"
-	"os/exec"
+	"path/filepath"
 	"syscall"
 )
 
 type ChrootSandbox struct{}
 
+// chrootEmulatorPath is where the qemu-user emulator is copied to inside the chroot, for foreign images.
+const chrootEmulatorPath = "/.clix-qemu"
+
 func (s *ChrootSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
 	rootPath := script.Image
 	if rootPath == "" {
 		return fmt.Errorf("ChrootSandbox requires an image path (used as root directory)")
 	}
+	// We are not handling environment variables here yet, or mounts.
+	// Issue says: "leave a lot of functionality not supported"
+	if len(script.Mounts) > 0 {
+		return fmt.Errorf("mounts are not supported in chroot sandbox")
+	}
+	if len(script.Env) > 0 {
+		return fmt.Errorf("environment variables are not supported in chroot sandbox")
+	}
+	if script.Workdir != "" {
+		return fmt.Errorf("workdir is not supported in chroot sandbox, which has no mounts")
+	}
 
-	realRoot, _, cleanup, err := prepareRootFS(rootPath)
+	realRoot, _, cleanup, err := prepareRootFS(rootPath, imagePlatform(script))
 	if err != nil {
 		return err
 	}
@@ -53,6 +66,19 @@ func (s *ChrootSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Sc
 		}
 	}
 
+	emulator, err := findEmulator(imageArch(script))
+	if err != nil {
+		return err
+	}
+	if emulator != "" {
+		// The emulator must be inside the chroot; it needs to be statically linked to run there
+		if err := copyEntryPath(emulator, filepath.Join(realRoot, chrootEmulatorPath)); err != nil {
+			return fmt.Errorf("copying emulator into chroot: %w", err)
+		}
+		cmdPath = chrootEmulatorPath
+		cmdArgs = append([]string{chrootEmulatorPath}, cmdArgs...)
+	}
+
 	// Prepare the command
 	cmd := execCommand(cmdPath, cmdArgs[1:]...)
 	cmd.Stdin = stdin
@@ -68,20 +94,9 @@ func (s *ChrootSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Sc
 
 	// We start at root of the new root
 	cmd.Dir = "/"
+	cmd.Env = sandboxEnv(script)
 
-	// We are not handling environment variables here yet, or mounts.
-	// Issue says: "leave a lot of functionality not supported"
-	if len(script.Mounts) > 0 {
-		return fmt.Errorf("mounts are not supported in chroot sandbox")
-	}
-	if len(script.Env) > 0 {
-		return fmt.Errorf("environment variables are not supported in chroot sandbox")
-	}
-
-	if err := cmd.Run(); err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			os.Exit(exitErr.ExitCode())
-		}
+	if err := runTool(cmd); err != nil {
 		return fmt.Errorf("error running chroot command: %w", err)
 	}
 
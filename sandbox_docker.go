@@ -15,83 +15,185 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"sort"
 	"strings"
+	"time"
 
 	"golang.org/x/term"
 )
 
 type DockerSandbox struct{}
 
+// dockerCLI returns the command line prefix used to invoke docker for script,
+// selecting the docker context if one is configured.
+func dockerCLI(script Script) []string {
+	if script.DockerContext != "" {
+		return []string{"docker", "--context", script.DockerContext}
+	}
+	return []string{"docker"}
+}
+
 func (s *DockerSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
+	if script.Runtime != "" {
+		runtime, err := resolveDockerRuntime(dockerCLI(script), script.Runtime)
+		if err != nil {
+			return err
+		}
+		script.Runtime = runtime
+	}
+	if script.GPUs != "" {
+		if err := checkNvidiaToolkit(dockerCLI(script)); err != nil {
+			return err
+		}
+	}
+
+	if err := resolveComposeNetwork(dockerCLI(script), &script); err != nil {
+		return err
+	}
+	script, stopProxy, err := startEgressProxy(dockerCLI(script), script)
+	if err != nil {
+		return err
+	}
+	defer stopProxy()
+
+	if len(script.Mounts) > 0 {
+		host, err := dockerHostFn(dockerCLI(script))
+		if err != nil {
+			log(1, "Unable to determine docker host: %v", err)
+		} else if isRemoteDockerHost(host) {
+			return s.runCopyingMounts(stdin, stdout, stderr, script, args, func(string) string {
+				return fmt.Sprintf("docker daemon at %s is remote", host)
+			})
+		} else if vm := limaVMFn(host); vm != nil {
+			return s.runCopyingMounts(stdin, stdout, stderr, script, args, vm.unshared)
+		}
+	}
+
 	log(2, "DockerSandbox: preparing args")
 	cmdArgs, err := buildDockerArgs(script, args, isTerminal(stdin))
 	if err != nil {
 		return fmt.Errorf("error building docker args: %w", err)
 	}
 
-	log(1, "DockerSandbox: running docker %v", cmdArgs)
-	cmd := execCommand("docker", cmdArgs...)
+	cli := dockerCLI(script)
+	if timingsEnabled() {
+		// Name the container, so that we can sample its resource usage, and remove it when it exits, so
+		// that failed runs don't leave named containers behind
+		name := fmt.Sprintf("clix-%d-%d", os.Getpid(), time.Now().UnixNano())
+		cmdArgs = append([]string{cmdArgs[0], "--rm", "--name", name}, cmdArgs[1:]...)
+		sampler := startDockerStatsSampler(cli, name)
+		defer sampler.Stop()
+	}
+
+	log(1, "DockerSandbox: running %v %v", cli, cmdArgs)
+	cmd := execCommand(cli[0], append(cli[1:], cmdArgs...)...)
 	cmd.Stdin = stdin
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
-	if err := cmd.Run(); err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			// Propagate the exit code from the subcommand
-			os.Exit(exitErr.ExitCode())
-		}
+	if err := runTool(cmd); err != nil {
 		return fmt.Errorf("error running docker command: %w", err)
 	}
 	return nil
 }
 
 func buildDockerArgs(script Script, args []string, isTerm bool) ([]string, error) {
-	cmdArgs := []string{"run", "-i"}
-	if isTerm {
-		cmdArgs = append(cmdArgs, "-t")
+	return buildContainerArgs(dockerCLI(script), script, args, isTerm)
+}
+
+// buildContainerArgs builds the `run` arguments for docker-compatible CLIs (docker, podman etc),
+// where cli is the command line prefix used to look up images.
+func buildContainerArgs(cli []string, script Script, args []string, isTerm bool) ([]string, error) {
+	volumes, hostMounts := splitVolumeMounts(script.Mounts)
+	mountArgs, err := namedVolumeArgs(cli, volumes)
+	if err != nil {
+		return nil, err
 	}
 
 	// Resolve cache directory if needed
 	imageSHA := ""
-	needsSHA := false
-	for _, m := range script.Mounts {
-		if strings.Contains(m.HostPath, "{cacheDir}") || strings.Contains(m.HostPath, "${cacheDir}") {
-			needsSHA = true
-			break
-		}
-	}
-
-	if needsSHA {
-		var err error
-		imageSHA, err = getImageSHAFn(script.Image)
+	if usesCacheDir(hostMounts) {
+		imageSHA, err = getImageSHAFn(cli, script.Image)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get image SHA: %w", err)
 		}
 	}
 
-	resolvedMounts, err := resolveMounts(script.Mounts, imageSHA)
+	resolvedMounts, err := resolveMounts(hostMounts, imageSHA)
 	if err != nil {
 		return nil, fmt.Errorf("error resolving mounts: %w", err)
 	}
 
 	for _, m := range resolvedMounts {
-		cmdArgs = append(cmdArgs, "-v", fmt.Sprintf("%s:%s", m.HostPath, m.SandboxPath))
+		if !pathExists(m.HostPath) && m.CreateIfMissing == "" {
+			fmt.Fprintf(os.Stderr, "Warning: mount %s does not exist, and the container engine may create a directory in its place; set createIfMissing or required on the mount\n", m.HostPath)
+		}
+		mountArgs = append(mountArgs, "-v", volumeArg(m.HostPath, m.SandboxPath, m.ReadOnly))
 	}
+	return containerRunArgs(cli, script, mountArgs, resolvedMounts, args, isTerm)
+}
 
-	for _, e := range script.Env {
+// containerRunArgs builds the `run` arguments for docker-compatible CLIs from the arguments which mount
+// the script's mounts, for sandboxes which mount them differently, where resolvedMounts are the resolved
+// host mounts which the workdir is mapped through.
+func containerRunArgs(cli []string, script Script, mountArgs []string, resolvedMounts []Mount, args []string, isTerm bool) ([]string, error) {
+	cmdArgs := []string{"run", "-i"}
+	if isTerm {
+		cmdArgs = append(cmdArgs, "-t")
+	}
+	if script.scriptPath != "" {
+		cmdArgs = append(cmdArgs, "--label", scriptLabelArg(script.scriptPath))
+	}
+	cmdArgs = append(cmdArgs, mountArgs...)
+
+	env, rewritten := containerProxyEnv(script.Env)
+	if rewritten || script.usesHostGateway {
+		cmdArgs = append(cmdArgs, hostGatewayArgs(cli)...)
+	}
+	for _, e := range env {
 		cmdArgs = append(cmdArgs, "-e", fmt.Sprintf("%s=%s", e.Name, e.Value))
 	}
 
-	// Set working directory to CWD if possible
-	cwd, err := os.Getwd()
+	if script.Network.Mode != "" {
+		cmdArgs = append(cmdArgs, "--network", script.Network.Mode)
+	}
+	cmdArgs = append(cmdArgs, dnsArgs(script)...)
+	for _, h := range script.ExtraHosts {
+		if err := checkExtraHost(h); err != nil {
+			return nil, err
+		}
+		cmdArgs = append(cmdArgs, "--add-host", h)
+	}
+	for _, p := range script.Ports {
+		cmdArgs = append(cmdArgs, "-p", p)
+	}
+
+	workdir, err := sandboxWorkdir(script, resolvedMounts)
 	if err != nil {
-		return nil, fmt.Errorf("error getting current working directory: %w", err)
+		return nil, err
+	}
+	cmdArgs = append(cmdArgs, "-w", workdir)
+
+	if script.Runtime != "" {
+		cmdArgs = append(cmdArgs, "--runtime", script.Runtime)
+	}
+	if script.Platform != "" {
+		cmdArgs = append(cmdArgs, "--platform", script.Platform)
+	}
+	for _, d := range script.Devices {
+		cmdArgs = append(cmdArgs, "--device", d)
+	}
+	if script.GPUs != "" {
+		cmdArgs = append(cmdArgs, "--gpus", script.GPUs)
+	}
+	if script.PullPolicy != "" {
+		cmdArgs = append(cmdArgs, "--pull", script.PullPolicy)
 	}
-	cmdArgs = append(cmdArgs, "-w", cwd)
 
 	if script.Entrypoint != "" {
 		cmdArgs = append(cmdArgs, "--entrypoint", script.Entrypoint)
@@ -102,27 +204,80 @@ func buildDockerArgs(script Script, args []string, isTerm bool) ([]string, error
 	return cmdArgs, nil
 }
 
+// resolveDockerRuntime returns the name under which the requested runtime is registered with the docker daemon.
+// Runtimes may be registered under different names (e.g. kata, kata-runtime, io.containerd.kata.v2),
+// so we accept any runtime whose name contains the requested name.
+func resolveDockerRuntime(cli []string, requested string) (string, error) {
+	out, err := execCommand(cli[0], append(cli[1:], "info", "--format", "{{json .Runtimes}}")...).Output()
+	if err != nil {
+		return "", fmt.Errorf("error querying docker runtimes: %w", err)
+	}
+	var runtimes map[string]json.RawMessage
+	if err := json.Unmarshal(out, &runtimes); err != nil {
+		return "", fmt.Errorf("error parsing docker runtimes: %w", err)
+	}
+
+	var names []string
+	for name := range runtimes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if _, found := runtimes[requested]; found {
+		return requested, nil
+	}
+	for _, name := range names {
+		if strings.Contains(name, requested) {
+			return name, nil
+		}
+	}
+	hint := ""
+	if requested == "kata" {
+		hint = "; install Kata Containers and register it as a docker runtime (https://katacontainers.io)"
+	}
+	return "", fmt.Errorf("runtime %q is not installed in docker (available runtimes: %s)%s", requested, strings.Join(names, ", "), hint)
+}
+
+// checkNvidiaToolkit checks that docker can pass GPUs to containers, which needs the NVIDIA container
+// toolkit: either registered as the nvidia runtime, or installed on the host for docker's --gpus hook.
+func checkNvidiaToolkit(cli []string) error {
+	if _, err := resolveDockerRuntime(cli, "nvidia"); err == nil {
+		return nil
+	}
+	if onHostPath("nvidia-container-cli") || onHostPath("nvidia-ctk") {
+		return nil
+	}
+	return fmt.Errorf("gpus requires the NVIDIA container toolkit, which was not found; install it (https://docs.nvidia.com/datacenter/cloud-native/container-toolkit/latest/install-guide.html) and restart docker")
+}
+
 var getImageSHAFn = getImageSHA
 
-func getImageSHA(image string) (string, error) {
+// getImageSHA returns the SHA of image, pulling it if needed, using the docker-compatible CLI invoked by cli.
+func getImageSHA(cli []string, image string) (string, error) {
 	log(2, "Getting SHA for image: %s", image)
-	cmd := execCommand("docker", "images", "--no-trunc", "--quiet", image)
+	cliCommand := func(args ...string) *exec.Cmd {
+		return execCommand(cli[0], append(cli[1:], args...)...)
+	}
+	cmd := cliCommand("images", "--no-trunc", "--quiet", image)
 	out, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("error running docker images: %w", err)
 	}
 	sha := strings.TrimSpace(string(out))
 	if sha == "" {
+		if err := checkOnline("image " + image); err != nil {
+			return "", err
+		}
 		log(1, "Image %s not found locally, pulling...", image)
 		// Try pulling it
-		pullCmd := execCommand("docker", "pull", image)
+		pullCmd := cliCommand("pull", image)
 		pullCmd.Stdout = os.Stderr
 		pullCmd.Stderr = os.Stderr
 		if err := pullCmd.Run(); err != nil {
 			return "", fmt.Errorf("failed to pull image %s: %w", image, err)
 		}
 		// Try again
-		cmd = execCommand("docker", "images", "--no-trunc", "--quiet", image)
+		cmd = cliCommand("images", "--no-trunc", "--quiet", image)
 		out, err = cmd.Output()
 		if err != nil {
 			return "", fmt.Errorf("error running docker images after pull: %w", err)
@@ -0,0 +1,184 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var dockerHostFn = dockerHost
+
+// dockerHost returns the endpoint of the docker daemon used by cli: DOCKER_HOST, or the host of the docker context.
+func dockerHost(cli []string) (string, error) {
+	if host := os.Getenv("DOCKER_HOST"); host != "" && len(cli) == 1 {
+		return host, nil
+	}
+	out, err := execCommand(cli[0], append(cli[1:], "context", "inspect", "--format", "{{.Endpoints.docker.Host}}")...).Output()
+	if err != nil {
+		return "", fmt.Errorf("error inspecting docker context: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// isRemoteDockerHost reports whether the docker daemon at host runs on another machine,
+// in which case bind mounts would refer to paths on that machine.
+func isRemoteDockerHost(host string) bool {
+	if host == "" || strings.HasPrefix(host, "unix://") || strings.HasPrefix(host, "npipe://") {
+		return false
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return true
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return false
+	}
+	return true
+}
+
+// runCopyingMounts runs the tool on a docker daemon which cannot bind mount some host paths, because it is
+// remote or runs in a VM which does not share them. unshared returns why hostPath cannot be bind mounted,
+// or "" if it can. Those cache mounts become docker volumes on the daemon, and other mounts are copied into
+// the container before the run and copied back afterwards. Files deleted by the tool are not deleted on the host.
+func (s *DockerSandbox) runCopyingMounts(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string, unshared func(hostPath string) string) error {
+	cli := dockerCLI(script)
+	volumes, hostMounts := splitVolumeMounts(script.Mounts)
+	volumeArgs, err := namedVolumeArgs(cli, volumes)
+	if err != nil {
+		return err
+	}
+	imageSHA := ""
+	if usesCacheDir(hostMounts) {
+		imageSHA, err = getImageSHAFn(cli, script.Image)
+		if err != nil {
+			return fmt.Errorf("failed to get image SHA: %w", err)
+		}
+	}
+	resolvedMounts, err := resolveMounts(hostMounts, imageSHA)
+	if err != nil {
+		return fmt.Errorf("error resolving mounts: %w", err)
+	}
+
+	var copied []Mount
+	for _, m := range resolvedMounts {
+		reason := unshared(m.HostPath)
+		switch {
+		case reason == "":
+			volumeArgs = append(volumeArgs, "-v", volumeArg(m.HostPath, m.SandboxPath, m.ReadOnly))
+		case m.masked:
+			// An empty file copied in could land in a bind mounted directory, and overwrite the masked file
+			if info, err := os.Stat(m.HostPath); err != nil || !info.IsDir() {
+				return fmt.Errorf("cannot mask the file %s on a remote docker daemon; %s", m.SandboxPath, reason)
+			}
+			volumeArgs = append(volumeArgs, "--tmpfs", m.SandboxPath+":ro")
+		case isCacheMount(m.HostPath):
+			volumeArgs = append(volumeArgs, "-v", volumeArg(remoteCacheVolume(m.HostPath), m.SandboxPath, m.ReadOnly))
+		default:
+			fmt.Fprintf(stderr, "Warning: %s; copying %s into the container instead of bind mounting it\n", reason, m.HostPath)
+			copied = append(copied, m)
+		}
+	}
+
+	// The workdir is mapped through all the mounts, as copied mounts are at the same paths in the container
+	cmdArgs, err := containerRunArgs(cli, script, volumeArgs, resolvedMounts, args, isTerminal(stdin))
+	if err != nil {
+		return fmt.Errorf("error building docker args: %w", err)
+	}
+
+	if len(copied) == 0 {
+		log(1, "DockerSandbox: running %v %v", cli, cmdArgs)
+		cmd := execCommand(cli[0], append(cli[1:], cmdArgs...)...)
+		cmd.Stdin = stdin
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		if err := runTool(cmd); err != nil {
+			return fmt.Errorf("error running docker command: %w", err)
+		}
+		return nil
+	}
+	// docker create, rather than docker run, so that we can copy the mounts in before starting
+	createArgs := append([]string{"create"}, cmdArgs[1:]...)
+
+	docker := func(args ...string) ([]byte, error) {
+		log(1, "DockerSandbox: running %v %v", cli, args)
+		cmd := execCommand(cli[0], append(cli[1:], args...)...)
+		cmd.Stderr = stderr
+		return cmd.Output()
+	}
+
+	out, err := docker(createArgs...)
+	if err != nil {
+		return fmt.Errorf("error creating container: %w", err)
+	}
+	container := strings.TrimSpace(string(out))
+	defer docker("rm", "-f", container)
+
+	for _, m := range copied {
+		src, dst := copyPaths(m.HostPath, m.HostPath, container+":"+m.SandboxPath)
+		if _, err := docker("cp", src, dst); err != nil {
+			return fmt.Errorf("error copying %s into container: %w", m.HostPath, err)
+		}
+	}
+
+	cmd := execCommand(cli[0], append(cli[1:], "start", "--attach", "--interactive", container)...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	runErr := runTool(cmd)
+
+	for _, m := range copied {
+		if m.ReadOnly {
+			// Changes to read-only mounts are not copied back
+			continue
+		}
+		src, dst := copyPaths(m.HostPath, container+":"+m.SandboxPath, m.HostPath)
+		if _, err := docker("cp", src, dst); err != nil {
+			fmt.Fprintf(stderr, "Warning: failed to copy %s back from container: %v\n", m.SandboxPath, err)
+		}
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("error running docker command: %w", runErr)
+	}
+	return nil
+}
+
+// copyPaths returns the docker cp arguments that copy src to dst, copying the contents if hostPath is a directory.
+func copyPaths(hostPath, src, dst string) (string, string) {
+	if info, err := os.Stat(hostPath); err == nil && info.IsDir() {
+		return src + "/.", dst
+	}
+	return src, dst
+}
+
+// isCacheMount reports whether a resolved host path is in the clix cache directory.
+func isCacheMount(hostPath string) bool {
+	userCache, err := os.UserCacheDir()
+	return err == nil && isWithin(filepath.Join(userCache, "clix", "cache"), hostPath)
+}
+
+// remoteCacheVolume names the docker volume which replaces the cache directory hostPath on a remote daemon.
+func remoteCacheVolume(hostPath string) string {
+	hash := sha256.Sum256([]byte(hostPath))
+	return "clix-cache-" + hex.EncodeToString(hash[:])[:16]
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsRemoteDockerHost(t *testing.T) {
+	for host, want := range map[string]bool{
+		"":                             false,
+		"unix:///var/run/docker.sock":  false,
+		"npipe:////./pipe/docker":      false,
+		"tcp://127.0.0.1:2375":         false,
+		"tcp://localhost:2375":         false,
+		"tcp://build.example.com:2376": true,
+		"ssh://me@build.example.com":   true,
+	} {
+		if got := isRemoteDockerHost(host); got != want {
+			t.Errorf("isRemoteDockerHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestDockerHost(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "ssh://me@build.example.com")
+	host, err := dockerHost([]string{"docker"})
+	if err != nil {
+		t.Fatalf("dockerHost failed: %v", err)
+	}
+	if host != "ssh://me@build.example.com" {
+		t.Errorf("Expected DOCKER_HOST to be used, got %q", host)
+	}
+}
+
+func TestRunRemoteDocker(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	originalDockerHost := dockerHostFn
+	defer func() { dockerHostFn = originalDockerHost }()
+	dockerHostFn = func(cli []string) (string, error) {
+		return "ssh://me@build.example.com", nil
+	}
+	originalGetImageSHA := getImageSHAFn
+	defer func() { getImageSHAFn = originalGetImageSHA }()
+	getImageSHAFn = func(cli []string, image string) (string, error) {
+		return "mocksha256", nil
+	}
+
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("MOCK_CALLS", calls)
+
+	src := t.TempDir()
+	script := Script{
+		Image:   "alpine",
+		Workdir: filepath.Join(src, "pkg"),
+		Mounts: []Mount{
+			{HostPath: src, SandboxPath: "/src"},
+			{HostPath: "${cacheDir}/cache", SandboxPath: "/root/.cache"},
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := (&DockerSandbox{}).Run(strings.NewReader(""), &stdout, &stderr, script, []string{"ls"}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "is remote") {
+		t.Errorf("Expected a warning about the remote daemon, got %q", stderr.String())
+	}
+
+	data, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected create, cp, start, cp, rm; got %q", lines)
+	}
+	for i, want := range []string{
+		"docker create -i -v clix-cache-",
+		"docker cp " + src + "/. mockcontainer:/src",
+		"docker start --attach --interactive mockcontainer",
+		"docker cp mockcontainer:/src/. " + src,
+		"docker rm -f mockcontainer",
+	} {
+		if !strings.HasPrefix(lines[i], want) {
+			t.Errorf("Expected call %d to start with %q, got %q", i, want, lines[i])
+		}
+	}
+	if strings.Contains(lines[0], src) {
+		t.Errorf("Expected no bind mount of %s, got %q", src, lines[0])
+	}
+	// The workdir is in the copied mount
+	if !strings.Contains(lines[0], " -w /src/pkg ") {
+		t.Errorf("Expected the workdir to be mapped through the copied mount, got %q", lines[0])
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// firecrackerExitMarker is printed on the VM console by the init script, so that we can recover the exit code.
+const firecrackerExitMarker = "clix-exit-code:"
+
+// FirecrackerSandbox runs the image rootfs in a Firecracker microVM, for high isolation on bare-metal Linux
+// hosts without docker. The rootfs is converted to an ext4 image, and the tool is run by a generated init
+// script, with stdin/stdout wired through the VM serial console.
+//
+// The guest kernel is configured with CLIX_FIRECRACKER_KERNEL (an uncompressed vmlinux).
+// Firecracker has no shared filesystems, so mounts are copied into the VM and changes are not written back.
+type FirecrackerSandbox struct{}
+
+func (s *FirecrackerSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
+	if script.Image == "" {
+		return fmt.Errorf("FirecrackerSandbox requires an image")
+	}
+	kernel := os.Getenv("CLIX_FIRECRACKER_KERNEL")
+	if kernel == "" {
+		return fmt.Errorf("firecracker sandbox requires a guest kernel; set CLIX_FIRECRACKER_KERNEL to the path of a vmlinux image")
+	}
+
+	var cmdArgs []string
+	if script.Entrypoint != "" {
+		cmdArgs = append([]string{script.Entrypoint}, args...)
+	} else if len(args) > 0 {
+		cmdArgs = args
+	} else {
+		return fmt.Errorf("no command specified and no entrypoint in script")
+	}
+
+	rootDir, imageSHA, cleanup, err := prepareRootFS(script.Image, imagePlatform(script))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resolvedMounts, err := resolveMounts(script.Mounts, imageSHA)
+	if err != nil {
+		return fmt.Errorf("error resolving mounts: %w", err)
+	}
+	for _, m := range resolvedMounts {
+		if m.masked {
+			// Remove the copy of the masked path, which came with its parent mount
+			if err := os.RemoveAll(filepath.Join(rootDir, m.SandboxPath)); err != nil {
+				return fmt.Errorf("masking %s: %w", m.SandboxPath, err)
+			}
+		} else if !m.ReadOnly {
+			fmt.Fprintf(stderr, "Warning: firecracker sandbox copies %s into the VM; changes will not be written back\n", m.HostPath)
+		}
+		if err := copyTree(m.HostPath, filepath.Join(rootDir, m.SandboxPath), nil); err != nil {
+			return fmt.Errorf("copying mount %s into rootfs: %w", m.HostPath, err)
+		}
+	}
+
+	cwd, err := sandboxWorkdir(script, resolvedMounts)
+	if err != nil {
+		return err
+	}
+	initScript := firecrackerInitScript(cmdArgs, script.Env, cwd)
+	if err := os.WriteFile(filepath.Join(rootDir, ".clix-init"), []byte(initScript), 0755); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(rootDir, "bin", "sh")); err != nil {
+		return fmt.Errorf("firecracker sandbox requires /bin/sh in the image to run its init script")
+	}
+
+	workDir, err := os.MkdirTemp("", "clix-firecracker-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workDir)
+
+	rootfsImage := filepath.Join(workDir, "rootfs.ext4")
+	size, err := dirSize(rootDir)
+	if err != nil {
+		return err
+	}
+	// Leave room for filesystem overhead, and for the tool to write temporary files
+	sizeMiB := size*3/2/(1<<20) + 256
+	log(1, "Creating %dMiB ext4 rootfs image", sizeMiB)
+	mkfs := execCommand("mkfs.ext4", "-q", "-F", "-d", rootDir, rootfsImage, fmt.Sprintf("%dM", sizeMiB))
+	mkfs.Stderr = stderr
+	if err := mkfs.Run(); err != nil {
+		return fmt.Errorf("error creating ext4 rootfs image (is e2fsprogs installed?): %w", err)
+	}
+
+	config, err := firecrackerConfig(kernel, rootfsImage)
+	if err != nil {
+		return err
+	}
+	configPath := filepath.Join(workDir, "config.json")
+	if err := os.WriteFile(configPath, config, 0644); err != nil {
+		return err
+	}
+
+	console := &consoleWriter{w: stdout}
+	cmd := execCommand("firecracker", "--no-api", "--config-file", configPath)
+	cmd.Stdin = stdin
+	cmd.Stdout = console
+	cmd.Stderr = stderr
+
+	log(1, "FirecrackerSandbox: booting %s", script.Image)
+	if err := runTool(cmd); err != nil {
+		return fmt.Errorf("error running firecracker: %w", err)
+	}
+	console.Flush()
+	if code, ok := console.ExitCode(); !ok {
+		return fmt.Errorf("firecracker VM exited without reporting the exit code of the tool")
+	} else if code != 0 {
+		return &exitError{code: code}
+	}
+	return nil
+}
+
+// firecrackerConfig returns the firecracker configuration for booting the rootfs image.
+func firecrackerConfig(kernel, rootfsImage string) ([]byte, error) {
+	config := map[string]any{
+		"boot-source": map[string]any{
+			"kernel_image_path": kernel,
+			"boot_args":         "console=ttyS0 reboot=k panic=1 pci=off quiet init=/.clix-init",
+		},
+		"drives": []map[string]any{
+			{
+				"drive_id":       "rootfs",
+				"path_on_host":   rootfsImage,
+				"is_root_device": true,
+				"is_read_only":   false,
+			},
+		},
+		"machine-config": map[string]any{
+			"vcpu_count":   2,
+			"mem_size_mib": 1024,
+		},
+	}
+	return json.MarshalIndent(config, "", "  ")
+}
+
+// firecrackerInitScript returns the init script which runs the tool inside the VM, reports its exit code
+// on the console and powers off the VM.
+func firecrackerInitScript(cmdArgs []string, env []EnvVar, workDir string) string {
+	var sb strings.Builder
+	sb.WriteString("#!/bin/sh\n")
+	sb.WriteString("mount -t proc proc /proc 2>/dev/null\n")
+	sb.WriteString("mount -t sysfs sysfs /sys 2>/dev/null\n")
+	sb.WriteString("mount -t devtmpfs devtmpfs /dev 2>/dev/null\n")
+	sb.WriteString("export PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin HOME=/root\n")
+	for _, e := range env {
+		fmt.Fprintf(&sb, "export %s=%s\n", e.Name, shellQuote(e.Value))
+	}
+	fmt.Fprintf(&sb, "cd %s 2>/dev/null || cd /\n", shellQuote(workDir))
+	var quoted []string
+	for _, arg := range cmdArgs {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	sb.WriteString(strings.Join(quoted, " ") + "\n")
+	fmt.Fprintf(&sb, "echo \"%s $?\"\n", firecrackerExitMarker)
+	sb.WriteString("sync\n")
+	sb.WriteString("reboot -f\n")
+	return sb.String()
+}
+
+// shellQuote quotes s for use in a POSIX shell script.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// consoleWriter passes through the VM console output, removing the exit code marker line.
+type consoleWriter struct {
+	w        io.Writer
+	mutex    sync.Mutex
+	partial  []byte
+	exitCode int
+	found    bool
+}
+
+func (c *consoleWriter) Write(p []byte) (int, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.partial = append(c.partial, p...)
+	for {
+		i := bytes.IndexByte(c.partial, '\n')
+		if i < 0 {
+			break
+		}
+		c.writeLine(c.partial[:i+1])
+		c.partial = c.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+func (c *consoleWriter) writeLine(line []byte) {
+	text := strings.TrimSpace(string(line))
+	if strings.HasPrefix(text, firecrackerExitMarker) {
+		if code, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(text, firecrackerExitMarker))); err == nil {
+			c.exitCode = code
+			c.found = true
+			return
+		}
+	}
+	c.w.Write(line)
+}
+
+// Flush writes any trailing incomplete line.
+func (c *consoleWriter) Flush() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(c.partial) > 0 {
+		c.writeLine(c.partial)
+		c.partial = nil
+	}
+}
+
+// ExitCode returns the exit code reported by the init script, if it was seen.
+func (c *consoleWriter) ExitCode() (int, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.exitCode, c.found
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFirecrackerInitScript(t *testing.T) {
+	script := firecrackerInitScript([]string{"/hello", "it's"}, []EnvVar{{Name: "FOO", Value: "a b"}}, "/src")
+
+	for _, want := range []string{
+		"#!/bin/sh\n",
+		"export FOO='a b'\n",
+		"cd '/src' 2>/dev/null || cd /\n",
+		`'/hello' 'it'\''s'` + "\n",
+		`echo "clix-exit-code: $?"`,
+		"reboot -f\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("Expected init script to contain %q, got:\n%s", want, script)
+		}
+	}
+}
+
+func TestFirecrackerConfig(t *testing.T) {
+	data, err := firecrackerConfig("/boot/vmlinux", "/tmp/rootfs.ext4")
+	if err != nil {
+		t.Fatalf("firecrackerConfig failed: %v", err)
+	}
+	var config struct {
+		BootSource struct {
+			KernelImagePath string `json:"kernel_image_path"`
+			BootArgs        string `json:"boot_args"`
+		} `json:"boot-source"`
+		Drives []struct {
+			PathOnHost   string `json:"path_on_host"`
+			IsRootDevice bool   `json:"is_root_device"`
+		} `json:"drives"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	if config.BootSource.KernelImagePath != "/boot/vmlinux" || !strings.Contains(config.BootSource.BootArgs, "init=/.clix-init") {
+		t.Errorf("Unexpected boot source: %+v", config.BootSource)
+	}
+	if len(config.Drives) != 1 || config.Drives[0].PathOnHost != "/tmp/rootfs.ext4" || !config.Drives[0].IsRootDevice {
+		t.Errorf("Unexpected drives: %+v", config.Drives)
+	}
+}
+
+func TestConsoleWriter(t *testing.T) {
+	var out bytes.Buffer
+	console := &consoleWriter{w: &out}
+	fmt.Fprintf(console, "hello\nclix-exit-")
+	fmt.Fprintf(console, "code: 3\r\nbye")
+	console.Flush()
+
+	if out.String() != "hello\nbye" {
+		t.Errorf("Expected marker to be removed from output, got %q", out.String())
+	}
+	if code, ok := console.ExitCode(); !ok || code != 3 {
+		t.Errorf("Expected exit code 3, got %d (found=%v)", code, ok)
+	}
+}
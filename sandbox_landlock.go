@@ -0,0 +1,107 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// LandlockSandbox confines native runs on Linux 5.13+ with Landlock. The tool can read the system
+// directories, and can only read and write the declared mounts, the temp dir and the go toolchain directories.
+// clix re-executes itself to apply the Landlock rules, and then execs the tool.
+type LandlockSandbox struct{}
+
+// landlockInitArg is the first argument when clix is re-executed to apply Landlock rules.
+const landlockInitArg = "__clix-landlock-init"
+
+// landlockConfig lists the paths the tool may access.
+type landlockConfig struct {
+	ReadOnly  []string `json:"readOnly"`
+	ReadWrite []string `json:"readWrite"`
+}
+
+// landlockSystemPaths may be read (and executed) by the tool.
+var landlockSystemPaths = []string{"/bin", "/sbin", "/usr", "/lib", "/lib64", "/etc", "/opt", "/proc", "/sys"}
+
+func (s *LandlockSandbox) Command(script Script, name string, args ...string) (*exec.Cmd, error) {
+	resolvedMounts, err := resolveMounts(script.Mounts, "")
+	if err != nil {
+		return nil, fmt.Errorf("error resolving mounts: %w", err)
+	}
+
+	config := landlockConfig{ReadOnly: landlockSystemPaths}
+	for _, m := range resolvedMounts {
+		if m.masked {
+			// Landlock only grants access, so it can't hide a path beneath a mount
+			return nil, fmt.Errorf("landlock sandbox cannot mask %s; use a sandbox which mounts, such as docker", m.SandboxPath)
+		}
+		if m.SandboxPath != m.HostPath {
+			fmt.Fprintf(os.Stderr, "Warning: landlock sandbox cannot remap %s to %s; the tool will see the host path\n", m.HostPath, m.SandboxPath)
+		}
+		if m.ReadOnly {
+			config.ReadOnly = append(config.ReadOnly, m.HostPath)
+		} else {
+			config.ReadWrite = append(config.ReadWrite, m.HostPath)
+		}
+	}
+	config.ReadWrite = append(config.ReadWrite, goEnvPaths()...)
+	config.ReadWrite = append(config.ReadWrite, os.TempDir(), "/dev")
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding landlock config: %w", err)
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("finding clix executable: %w", err)
+	}
+	log(2, "Landlock config: %s", configJSON)
+
+	initArgs := append([]string{landlockInitArg, string(configJSON), name}, args...)
+	return execCommand(self, initArgs...), nil
+}
+
+// runLandlockInit applies the Landlock rules and execs the tool; it does not return.
+func runLandlockInit(configJSON string, args []string) {
+	var config landlockConfig
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		fmt.Fprintf(os.Stderr, "clix: invalid landlock config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := landlockExec(config, args); err != nil {
+		fmt.Fprintf(os.Stderr, "clix: landlock sandbox: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// goEnvPaths returns the go toolchain directories (GOROOT, GOMODCACHE, GOCACHE) of the host.
+func goEnvPaths() []string {
+	out, err := execCommand("go", "env", "GOROOT", "GOMODCACHE", "GOCACHE").Output()
+	if err != nil {
+		log(1, "Unable to query go env: %v", err)
+		return nil
+	}
+	var paths []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths
+}
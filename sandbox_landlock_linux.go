@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// landlockAccessFS returns the filesystem access rights known to the Landlock ABI version.
+func landlockAccessFS(abi int) uint64 {
+	access := uint64(unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_WRITE_FILE | unix.LANDLOCK_ACCESS_FS_READ_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_DIR | unix.LANDLOCK_ACCESS_FS_REMOVE_DIR | unix.LANDLOCK_ACCESS_FS_REMOVE_FILE |
+		unix.LANDLOCK_ACCESS_FS_MAKE_CHAR | unix.LANDLOCK_ACCESS_FS_MAKE_DIR | unix.LANDLOCK_ACCESS_FS_MAKE_REG |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SOCK | unix.LANDLOCK_ACCESS_FS_MAKE_FIFO | unix.LANDLOCK_ACCESS_FS_MAKE_BLOCK |
+		unix.LANDLOCK_ACCESS_FS_MAKE_SYM)
+	if abi >= 2 {
+		access |= unix.LANDLOCK_ACCESS_FS_REFER
+	}
+	if abi >= 3 {
+		access |= unix.LANDLOCK_ACCESS_FS_TRUNCATE
+	}
+	return access
+}
+
+// landlockSupported reports whether the kernel supports Landlock.
+func landlockSupported() bool {
+	_, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	return errno == 0
+}
+
+const landlockReadAccess = unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_READ_DIR
+
+// landlockExec restricts the process to the configured paths, and execs args.
+func landlockExec(config landlockConfig, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no command")
+	}
+	binary, err := exec.LookPath(args[0])
+	if err != nil {
+		return err
+	}
+
+	// Landlock domains apply to the calling thread, which must be the one that execs
+	runtime.LockOSThread()
+
+	abi, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	if errno != 0 {
+		return fmt.Errorf("landlock is not supported by this kernel (requires Linux 5.13+ with landlock enabled): %w", errno)
+	}
+	handled := landlockAccessFS(int(abi))
+
+	attr := unix.LandlockRulesetAttr{Access_fs: handled}
+	size := unsafe.Sizeof(attr)
+	if abi < 4 {
+		// Older kernels only know the filesystem field
+		size = unsafe.Offsetof(attr.Access_net)
+	}
+	fd, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, uintptr(unsafe.Pointer(&attr)), size, 0)
+	if errno != 0 {
+		return fmt.Errorf("creating landlock ruleset: %w", errno)
+	}
+	defer unix.Close(int(fd))
+
+	addRules := func(paths []string, access uint64) error {
+		for _, path := range paths {
+			if err := landlockAllow(int(fd), path, access&handled); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := addRules(config.ReadOnly, landlockReadAccess); err != nil {
+		return err
+	}
+	if err := addRules(config.ReadWrite, handled); err != nil {
+		return err
+	}
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("setting no_new_privs: %w", err)
+	}
+	if _, _, errno := unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, fd, 0, 0); errno != 0 {
+		return fmt.Errorf("applying landlock ruleset: %w", errno)
+	}
+	return unix.Exec(binary, args, os.Environ())
+}
+
+// landlockAllow adds a rule allowing access beneath path; paths which do not exist are skipped.
+func landlockAllow(rulesetFD int, path string, access uint64) error {
+	pathFD, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if err != nil {
+		log(2, "Skipping landlock rule for %s: %v", path, err)
+		return nil
+	}
+	defer unix.Close(pathFD)
+
+	// Rights which only apply to directories are rejected for files
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		access &= unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_WRITE_FILE | unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_TRUNCATE
+	}
+	rule := unix.LandlockPathBeneathAttr{Allowed_access: access, Parent_fd: int32(pathFD)}
+	if _, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, uintptr(rulesetFD), unix.LANDLOCK_RULE_PATH_BENEATH, uintptr(unsafe.Pointer(&rule)), 0, 0, 0); errno != 0 {
+		return fmt.Errorf("adding landlock rule for %s: %w", path, errno)
+	}
+	return nil
+}
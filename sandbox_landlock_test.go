@@ -0,0 +1,58 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestLandlockSandboxCommand(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	dir := t.TempDir()
+	script := Script{Mounts: []Mount{{HostPath: dir}}}
+	cmd, err := (&LandlockSandbox{}).Command(script, "/tmp/tool", "fmt", "./...")
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+
+	// fakeExecCommand runs: test-binary -test.run=TestHelperProcess -- <self> <args...>
+	args := cmd.Args[3:]
+	self, _ := os.Executable()
+	if len(args) != 6 || args[0] != self || args[1] != landlockInitArg {
+		t.Fatalf("Expected clix to re-execute itself with %s, got %v", landlockInitArg, args)
+	}
+	if args[3] != "/tmp/tool" || args[4] != "fmt" || args[5] != "./..." {
+		t.Errorf("Expected the tool and its args to follow the config, got %v", args[3:])
+	}
+
+	var config landlockConfig
+	if err := json.Unmarshal([]byte(args[2]), &config); err != nil {
+		t.Fatalf("Invalid landlock config %q: %v", args[2], err)
+	}
+	if !contains(config.ReadWrite, dir) {
+		t.Errorf("Expected mount %s to be writable, got %v", dir, config.ReadWrite)
+	}
+	if !contains(config.ReadWrite, os.TempDir()) {
+		t.Errorf("Expected temp dir to be writable, got %v", config.ReadWrite)
+	}
+	if !contains(config.ReadOnly, "/usr") {
+		t.Errorf("Expected system paths to be readable, got %v", config.ReadOnly)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// limaVM is a Lima (or Colima) VM running the docker daemon. Only the directories the VM shares from
+// the host can be bind mounted; other paths produce empty directories in the container.
+type limaVM struct {
+	Name   string
+	Mounts []limaMount `json:"mounts"`
+}
+
+type limaMount struct {
+	Location string `json:"location"`
+	Writable bool   `json:"writable"`
+}
+
+var limaVMFn = limaVMForDockerHost
+
+// limaVMForDockerHost returns the Lima VM serving the docker socket at host, or nil if it is not a Lima VM
+// (or the VM configuration cannot be read).
+func limaVMForDockerHost(host string) *limaVM {
+	configPath, name := limaConfigPath(host)
+	if configPath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		log(1, "Unable to read Lima config for %s: %v", name, err)
+		return nil
+	}
+	vm := &limaVM{Name: name}
+	if err := yaml.Unmarshal(data, vm); err != nil {
+		log(1, "Unable to parse Lima config %s: %v", configPath, err)
+		return nil
+	}
+	log(1, "Docker is running in the %s VM, with mounts %+v", name, vm.Mounts)
+	return vm
+}
+
+// limaConfigPath returns the path of the lima.yaml of the VM serving the docker socket at host, e.g.
+// unix://~/.colima/<profile>/docker.sock or unix://~/.lima/<instance>/sock/docker.sock.
+func limaConfigPath(host string) (string, string) {
+	socket, ok := strings.CutPrefix(host, "unix://")
+	if !ok {
+		return "", ""
+	}
+	if home, _, ok := strings.Cut(socket, "/.colima/"); ok {
+		profile := filepath.Base(filepath.Dir(socket))
+		instance := "colima"
+		if profile != "default" {
+			instance = "colima-" + profile
+		}
+		return filepath.Join(home, ".colima", "_lima", instance, "lima.yaml"), "colima " + profile
+	}
+	if strings.Contains(socket, "/.lima/") {
+		instanceDir := filepath.Dir(filepath.Dir(socket))
+		return filepath.Join(instanceDir, "lima.yaml"), "lima " + filepath.Base(instanceDir)
+	}
+	return "", ""
+}
+
+// unshared returns why hostPath cannot be bind mounted from the VM, or "" if the VM shares it writably.
+func (vm *limaVM) unshared(hostPath string) string {
+	home, _ := os.UserHomeDir()
+	for _, m := range vm.Mounts {
+		location := m.Location
+		if location == "~" {
+			location = home
+		} else if strings.HasPrefix(location, "~/") {
+			location = filepath.Join(home, location[2:])
+		}
+		if !isWithin(location, hostPath) {
+			continue
+		}
+		if !m.Writable {
+			return fmt.Sprintf("%s is shared read-only with the %s VM", location, vm.Name)
+		}
+		return ""
+	}
+	return fmt.Sprintf("%s is not shared with the %s VM", hostPath, vm.Name)
+}
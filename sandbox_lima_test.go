@@ -0,0 +1,73 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLimaConfigPath(t *testing.T) {
+	for _, tc := range []struct {
+		host, wantPath, wantName string
+	}{
+		{"unix:///Users/me/.colima/default/docker.sock", "/Users/me/.colima/_lima/colima/lima.yaml", "colima default"},
+		{"unix:///Users/me/.colima/work/docker.sock", "/Users/me/.colima/_lima/colima-work/lima.yaml", "colima work"},
+		{"unix:///Users/me/.lima/docker/sock/docker.sock", "/Users/me/.lima/docker/lima.yaml", "lima docker"},
+		{"unix:///var/run/docker.sock", "", ""},
+		{"ssh://me@build.example.com", "", ""},
+	} {
+		gotPath, gotName := limaConfigPath(tc.host)
+		if gotPath != tc.wantPath || gotName != tc.wantName {
+			t.Errorf("limaConfigPath(%q) = %q, %q; want %q, %q", tc.host, gotPath, gotName, tc.wantPath, tc.wantName)
+		}
+	}
+}
+
+func TestLimaVMUnshared(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	configDir := filepath.Join(home, ".colima", "_lima", "colima")
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `
+mounts:
+- location: "~/src"
+  writable: true
+- location: "~"
+`
+	if err := os.WriteFile(filepath.Join(configDir, "lima.yaml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	vm := limaVMForDockerHost("unix://" + filepath.Join(home, ".colima", "default", "docker.sock"))
+	if vm == nil {
+		t.Fatal("Expected a Lima VM")
+	}
+
+	if reason := vm.unshared(filepath.Join(home, "src", "repo")); reason != "" {
+		t.Errorf("Expected writable share to be bind mounted, got %q", reason)
+	}
+	if reason := vm.unshared(filepath.Join(home, "notes")); !strings.Contains(reason, "read-only") {
+		t.Errorf("Expected read-only share to be reported, got %q", reason)
+	}
+	if reason := vm.unshared("/opt/data"); !strings.Contains(reason, "not shared") {
+		t.Errorf("Expected unshared path to be reported, got %q", reason)
+	}
+}
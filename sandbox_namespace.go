@@ -0,0 +1,124 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// NamespaceSandbox runs the extracted image rootfs in new user, mount and pid namespaces, without requiring
+// root or any external runtime. clix re-executes itself as the init process of the namespaces, which sets up
+// the bind mounts and chroot before exec'ing the tool.
+type NamespaceSandbox struct{}
+
+// namespaceInitEnv is set (to the JSON namespaceConfig) when clix is re-executed as the namespace init process.
+const namespaceInitEnv = "CLIX_NAMESPACE_INIT"
+
+// namespaceConfig is passed to the namespace init process.
+type namespaceConfig struct {
+	Root    string   `json:"root"`
+	Workdir string   `json:"workdir"`
+	Mounts  []Mount  `json:"mounts,omitempty"`
+	Env     []string `json:"env,omitempty"`
+	Args    []string `json:"args"`
+	Network bool     `json:"network"`
+}
+
+func (s *NamespaceSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
+	if script.Image == "" {
+		return fmt.Errorf("NamespaceSandbox requires an image")
+	}
+
+	var cmdArgs []string
+	if script.Entrypoint != "" {
+		cmdArgs = append([]string{script.Entrypoint}, args...)
+	} else if len(args) > 0 {
+		cmdArgs = args
+	} else {
+		return fmt.Errorf("no command specified and no entrypoint in script")
+	}
+
+	realRoot, imageSHA, cleanup, err := prepareRootFS(script.Image, imagePlatform(script))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	config, err := newNamespaceConfig(realRoot, imageSHA, script, cmdArgs)
+	if err != nil {
+		return err
+	}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("error encoding namespace config: %w", err)
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding clix executable: %w", err)
+	}
+
+	log(1, "NamespaceSandbox: running %v in %s", cmdArgs, realRoot)
+	cmd := execCommand(self)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = []string{namespaceInitEnv + "=" + string(configJSON)}
+	cmd.SysProcAttr, err = namespaceSysProcAttr(config)
+	if err != nil {
+		return err
+	}
+
+	if err := runTool(cmd); err != nil {
+		return fmt.Errorf("error running namespace sandbox: %w", err)
+	}
+	return nil
+}
+
+// newNamespaceConfig returns the config of the namespace init process, which runs cmdArgs in the rootfs.
+func newNamespaceConfig(root, imageSHA string, script Script, cmdArgs []string) (namespaceConfig, error) {
+	resolvedMounts, err := resolveMounts(script.Mounts, imageSHA)
+	if err != nil {
+		return namespaceConfig{}, fmt.Errorf("error resolving mounts: %w", err)
+	}
+	workdir, err := rootfsWorkdir(script, resolvedMounts)
+	if err != nil {
+		return namespaceConfig{}, err
+	}
+	return namespaceConfig{
+		Root:    root,
+		Workdir: workdir,
+		Mounts:  resolvedMounts,
+		Env:     sandboxEnv(script),
+		Args:    cmdArgs,
+		Network: script.Network.Mode != "none",
+	}, nil
+}
+
+// runNamespaceInit is called at startup when clix is the namespace init process; it does not return.
+func runNamespaceInit(configJSON string) {
+	var config namespaceConfig
+	if err := json.Unmarshal([]byte(configJSON), &config); err != nil {
+		fmt.Fprintf(os.Stderr, "clix: invalid namespace config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := namespaceInit(config); err != nil {
+		fmt.Fprintf(os.Stderr, "clix: namespace sandbox: %v\n", err)
+		os.Exit(1)
+	}
+}
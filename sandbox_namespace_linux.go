@@ -0,0 +1,148 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// defaultPath is used to find the tool in the rootfs when the environment does not set PATH.
+const defaultPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+func namespaceSysProcAttr(config namespaceConfig) (*syscall.SysProcAttr, error) {
+	flags := uintptr(syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID | syscall.CLONE_NEWUTS | syscall.CLONE_NEWIPC)
+	if !config.Network {
+		flags |= syscall.CLONE_NEWNET
+	}
+	// Map the current user to root inside the namespace, so the init process can mount and chroot
+	return &syscall.SysProcAttr{
+		Cloneflags:                 flags,
+		UidMappings:                []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings:                []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+		GidMappingsEnableSetgroups: false,
+		Pdeathsig:                  syscall.SIGKILL,
+	}, nil
+}
+
+// namespaceInit runs inside the new namespaces: it bind mounts the host paths and /dev, mounts /proc,
+// chroots into the rootfs and execs the tool in its workdir.
+func namespaceInit(config namespaceConfig) error {
+	// Keep our mounts out of the host mount namespace
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("making mounts private: %w", err)
+	}
+
+	mounts := append([]Mount{{HostPath: "/dev", SandboxPath: "/dev"}}, config.Mounts...)
+	for _, m := range mounts {
+		target := filepath.Join(config.Root, m.SandboxPath)
+		if err := prepareMountTarget(m.HostPath, target); err != nil {
+			return fmt.Errorf("preparing mount %s: %w", m.SandboxPath, err)
+		}
+		if err := bindMount(m.HostPath, target, m.ReadOnly); err != nil {
+			return fmt.Errorf("mounting %s: %w", m.SandboxPath, err)
+		}
+	}
+
+	proc := filepath.Join(config.Root, "proc")
+	if err := os.MkdirAll(proc, 0755); err != nil {
+		return fmt.Errorf("creating /proc: %w", err)
+	}
+	if err := syscall.Mount("proc", proc, "proc", 0, ""); err != nil {
+		log(1, "Unable to mount /proc in namespace sandbox: %v", err)
+	}
+
+	if err := syscall.Chroot(config.Root); err != nil {
+		return fmt.Errorf("chroot to %s: %w", config.Root, err)
+	}
+	if err := os.Chdir(config.Workdir); err != nil {
+		return fmt.Errorf("chdir to %s: %w", config.Workdir, err)
+	}
+
+	// Look up the tool with the sandbox PATH, now that we are in the rootfs
+	path := defaultPath
+	for _, kv := range config.Env {
+		if v, ok := strings.CutPrefix(kv, "PATH="); ok {
+			path = v
+		}
+	}
+	os.Setenv("PATH", path)
+	binary, err := exec.LookPath(config.Args[0])
+	if err != nil {
+		return fmt.Errorf("finding %s in image: %w", config.Args[0], err)
+	}
+	return syscall.Exec(binary, config.Args, config.Env)
+}
+
+// bindMount bind mounts source on target, read-only if readOnly.
+func bindMount(source, target string, readOnly bool) error {
+	if err := syscall.Mount(source, target, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return fmt.Errorf("bind mounting %s: %w", source, err)
+	}
+	if !readOnly {
+		return nil
+	}
+	// Bind mounts only become read-only when remounted. In a user namespace, the flags of the source mount
+	// (e.g. nosuid on /tmp) are locked, and the remount fails unless it keeps them
+	var statfs unix.Statfs_t
+	if err := unix.Statfs(target, &statfs); err != nil {
+		return fmt.Errorf("getting the flags of %s: %w", source, err)
+	}
+	flags := uintptr(syscall.MS_BIND | syscall.MS_REMOUNT | syscall.MS_RDONLY)
+	for st, ms := range lockedMountFlags {
+		if int64(statfs.Flags)&st != 0 {
+			flags |= ms
+		}
+	}
+	if err := syscall.Mount("", target, "", flags, ""); err != nil {
+		return fmt.Errorf("making it read-only: %w", err)
+	}
+	return nil
+}
+
+// lockedMountFlags maps the statfs flags of a mount to the mount flags which a remount must keep.
+var lockedMountFlags = map[int64]uintptr{
+	unix.ST_NOSUID:     syscall.MS_NOSUID,
+	unix.ST_NODEV:      syscall.MS_NODEV,
+	unix.ST_NOEXEC:     syscall.MS_NOEXEC,
+	unix.ST_NOATIME:    syscall.MS_NOATIME,
+	unix.ST_NODIRATIME: syscall.MS_NODIRATIME,
+	unix.ST_RELATIME:   syscall.MS_RELATIME,
+}
+
+// prepareMountTarget creates the mount point in the rootfs: a directory, or an empty file if source is a file.
+func prepareMountTarget(source, target string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return os.MkdirAll(target, 0755)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,152 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestNamespaceSysProcAttr(t *testing.T) {
+	attr, err := namespaceSysProcAttr(namespaceConfig{Network: true})
+	if err != nil {
+		t.Fatalf("namespaceSysProcAttr failed: %v", err)
+	}
+	for _, flag := range []uintptr{syscall.CLONE_NEWUSER, syscall.CLONE_NEWNS, syscall.CLONE_NEWPID} {
+		if attr.Cloneflags&flag == 0 {
+			t.Errorf("Cloneflags %#x missing %#x", attr.Cloneflags, flag)
+		}
+	}
+	if attr.Cloneflags&syscall.CLONE_NEWNET != 0 {
+		t.Errorf("network namespace should not be created when network is enabled")
+	}
+	if len(attr.UidMappings) != 1 || attr.UidMappings[0].ContainerID != 0 || attr.UidMappings[0].HostID != os.Getuid() {
+		t.Errorf("unexpected UidMappings %+v", attr.UidMappings)
+	}
+
+	attr, err = namespaceSysProcAttr(namespaceConfig{Network: false})
+	if err != nil {
+		t.Fatalf("namespaceSysProcAttr failed: %v", err)
+	}
+	if attr.Cloneflags&syscall.CLONE_NEWNET == 0 {
+		t.Errorf("network namespace should be created for network: none")
+	}
+}
+
+func TestPrepareMountTarget(t *testing.T) {
+	host := t.TempDir()
+	hostFile := filepath.Join(host, "config")
+	if err := os.WriteFile(hostFile, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+
+	dirTarget := filepath.Join(root, "work", "src")
+	if err := prepareMountTarget(host, dirTarget); err != nil {
+		t.Fatalf("prepareMountTarget(dir) failed: %v", err)
+	}
+	if info, err := os.Stat(dirTarget); err != nil || !info.IsDir() {
+		t.Errorf("expected directory at %s", dirTarget)
+	}
+
+	fileTarget := filepath.Join(root, "etc", "app", "config")
+	if err := prepareMountTarget(hostFile, fileTarget); err != nil {
+		t.Fatalf("prepareMountTarget(file) failed: %v", err)
+	}
+	if info, err := os.Stat(fileTarget); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("expected file at %s", fileTarget)
+	}
+}
+
+func TestNewNamespaceConfig(t *testing.T) {
+	src := t.TempDir()
+	script := Script{Image: "alpine", Workdir: filepath.Join(src, "pkg"), Mounts: []Mount{{HostPath: src, SandboxPath: "/src"}}}
+	config, err := newNamespaceConfig("/tmp/root", "", script, []string{"ls"})
+	if err != nil {
+		t.Fatalf("newNamespaceConfig failed: %v", err)
+	}
+	if config.Workdir != "/src/pkg" {
+		t.Errorf("Expected the tool to start in /src/pkg, got %q", config.Workdir)
+	}
+
+	script.Workdir = "/elsewhere"
+	if _, err := newNamespaceConfig("/tmp/root", "", script, []string{"ls"}); err == nil {
+		t.Errorf("Expected an error for an unmounted workdir")
+	}
+}
+
+// bindMountTestEnv selects the step of TestBindMountReadOnlyLockedFlags run by a re-executed test binary.
+const bindMountTestEnv = "CLIX_TEST_BIND_MOUNT"
+
+func TestBindMountReadOnlyLockedFlags(t *testing.T) {
+	switch os.Getenv(bindMountTestEnv) {
+	case "outer":
+		// In a user namespace, mount a nosuid, nodev tmpfs (like /tmp or /run), whose flags are locked in
+		// the namespaces created from this one
+		dir := os.Args[len(os.Args)-1]
+		if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+			t.Fatalf("making mounts private: %v", err)
+		}
+		if err := syscall.Mount("tmpfs", dir, "tmpfs", syscall.MS_NOSUID|syscall.MS_NODEV, ""); err != nil {
+			t.Fatalf("mounting tmpfs: %v", err)
+		}
+		for _, d := range []string{"src", "target"} {
+			if err := os.Mkdir(filepath.Join(dir, d), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		runInNamespaces(t, "inner", dir)
+		return
+	case "inner":
+		dir := os.Args[len(os.Args)-1]
+		target := filepath.Join(dir, "target")
+		if err := bindMount(filepath.Join(dir, "src"), target, true); err != nil {
+			t.Fatalf("bindMount failed: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(target, "file"), nil, 0644); err == nil {
+			t.Errorf("Expected the mount to be read-only")
+		}
+		return
+	}
+
+	if _, err := os.Stat("/proc/self/ns/user"); err != nil {
+		t.Skip("skipping bind mount test: no user namespaces")
+	}
+	runInNamespaces(t, "outer", t.TempDir())
+}
+
+// runInNamespaces runs this test in new namespaces, as the namespace sandbox's init process is.
+func runInNamespaces(t *testing.T, step, dir string) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestBindMountReadOnlyLockedFlags$", "--", dir)
+	cmd.Env = append(os.Environ(), bindMountTestEnv+"="+step)
+	attr, err := namespaceSysProcAttr(namespaceConfig{Network: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without a pid namespace, so that the outer step's /proc has the pids of the processes it starts
+	attr.Cloneflags &^= syscall.CLONE_NEWPID
+	cmd.SysProcAttr = attr
+	out, err := cmd.CombinedOutput()
+	if err != nil && step == "outer" && strings.Contains(err.Error(), "operation not permitted") {
+		t.Skipf("skipping bind mount test: %v", err)
+	}
+	if err != nil || strings.Contains(string(out), "FAIL") {
+		t.Fatalf("%s namespace failed: %v\n%s", step, err, out)
+	}
+}
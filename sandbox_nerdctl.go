@@ -0,0 +1,54 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// NerdctlSandbox runs tools with `nerdctl run`, for hosts with containerd but not docker (k3s nodes,
+// Rancher Desktop in containerd mode etc). nerdctl honors CONTAINERD_ADDRESS and CONTAINERD_NAMESPACE,
+// e.g. to use the k8s.io namespace of k3s.
+type NerdctlSandbox struct{}
+
+func (s *NerdctlSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
+	// nerdctl can't connect running containers to networks, which the egress proxy and compose networks
+	// need, so refuse them rather than run the tool with a wider network
+	if err := checkNetwork("nerdctl", script.Network); err != nil {
+		return err
+	}
+
+	log(2, "NerdctlSandbox: preparing args")
+	cmdArgs, err := buildNerdctlArgs(script, args, isTerminal(stdin))
+	if err != nil {
+		return fmt.Errorf("error building nerdctl args: %w", err)
+	}
+
+	log(1, "NerdctlSandbox: running nerdctl %v", cmdArgs)
+	cmd := execCommand("nerdctl", cmdArgs...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := runTool(cmd); err != nil {
+		return fmt.Errorf("error running nerdctl command: %w", err)
+	}
+	return nil
+}
+
+func buildNerdctlArgs(script Script, args []string, isTerm bool) ([]string, error) {
+	return buildContainerArgs([]string{"nerdctl"}, script, args, isTerm)
+}
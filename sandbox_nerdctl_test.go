@@ -0,0 +1,69 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestBuildNerdctlArgs(t *testing.T) {
+	originalGetImageSHA := getImageSHAFn
+	defer func() { getImageSHAFn = originalGetImageSHA }()
+	var gotCLI []string
+	getImageSHAFn = func(cli []string, image string) (string, error) {
+		gotCLI = cli
+		return "mocksha256", nil
+	}
+
+	script := Script{
+		Image:  "alpine",
+		Mounts: []Mount{{HostPath: "${cacheDir}/data", SandboxPath: "/data"}},
+	}
+	cmdArgs, err := buildNerdctlArgs(script, []string{"ls"}, false)
+	if err != nil {
+		t.Fatalf("buildNerdctlArgs failed: %v", err)
+	}
+
+	if strings.Join(gotCLI, " ") != "nerdctl" {
+		t.Errorf("Expected image SHA to be looked up with nerdctl, got %v", gotCLI)
+	}
+	if cmdArgs[0] != "run" || cmdArgs[len(cmdArgs)-2] != "alpine" || cmdArgs[len(cmdArgs)-1] != "ls" {
+		t.Errorf("Unexpected nerdctl args: %v", cmdArgs)
+	}
+	if !strings.Contains(strings.Join(cmdArgs, " "), "mocksha256/data:/data") {
+		t.Errorf("Expected cache mount, got %v", cmdArgs)
+	}
+}
+
+func TestNerdctlSandboxNetwork(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("MOCK_CALLS", t.TempDir()+"/calls")
+
+	for _, network := range []NetworkPolicy{{Allow: []string{"*.googleapis.com"}}, {Mode: "compose:shop"}} {
+		script := Script{Image: "alpine", Network: network}
+		err := (&NerdctlSandbox{}).Run(strings.NewReader(""), io.Discard, io.Discard, script, nil)
+		if err == nil || !strings.Contains(err.Error(), "not supported by the nerdctl sandbox") {
+			t.Errorf("Expected %+v to be refused, got %v", network, err)
+		}
+	}
+	if pathExists(os.Getenv("MOCK_CALLS")) {
+		t.Errorf("Expected nerdctl not to run")
+	}
+}
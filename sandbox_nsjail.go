@@ -0,0 +1,117 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// NsjailSandbox runs the extracted image rootfs under nsjail, which gives fine-grained namespace, rlimit
+// and seccomp control on hosts where docker is not allowed.
+type NsjailSandbox struct{}
+
+// RlimitConfig configures resource limits for sandboxes which support them (nsjail).
+type RlimitConfig struct {
+	// AS is the maximum address space, in MiB
+	AS int `json:"as,omitempty"`
+	// CPU is the maximum CPU time, in seconds
+	CPU int `json:"cpu,omitempty"`
+	// FSize is the maximum file size, in MiB
+	FSize int `json:"fsize,omitempty"`
+	// NoFile is the maximum number of open files
+	NoFile int `json:"nofile,omitempty"`
+	// NProc is the maximum number of processes
+	NProc int `json:"nproc,omitempty"`
+}
+
+func (s *NsjailSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
+	if script.Image == "" {
+		return fmt.Errorf("NsjailSandbox requires an image path (used as root directory)")
+	}
+
+	var cmdArgs []string
+	if script.Entrypoint != "" {
+		cmdArgs = append([]string{script.Entrypoint}, args...)
+	} else if len(args) > 0 {
+		cmdArgs = args
+	} else {
+		return fmt.Errorf("no command specified and no entrypoint in script")
+	}
+
+	realRoot, imageSHA, cleanup, err := prepareRootFS(script.Image, imagePlatform(script))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resolvedMounts, err := resolveMounts(script.Mounts, imageSHA)
+	if err != nil {
+		return fmt.Errorf("error resolving mounts: %w", err)
+	}
+
+	workdir, err := rootfsWorkdir(script, resolvedMounts)
+	if err != nil {
+		return err
+	}
+
+	nsjailArgs := buildNsjailArgs(realRoot, workdir, resolvedMounts, script, cmdArgs)
+	log(1, "NsjailSandbox: running nsjail %v", nsjailArgs)
+	cmd := execCommand("nsjail", nsjailArgs...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := runTool(cmd); err != nil {
+		return fmt.Errorf("error running nsjail command: %w", err)
+	}
+	return nil
+}
+
+func buildNsjailArgs(rootDir, workdir string, mounts []Mount, script Script, cmdArgs []string) []string {
+	// Run once, with no time limit (nsjail defaults to 600s)
+	nsjailArgs := []string{"--mode", "o", "--quiet", "--time_limit", "0", "--chroot", rootDir, "--rw", "--cwd", workdir}
+	for _, m := range mounts {
+		flag := "--bindmount"
+		if m.ReadOnly {
+			flag = "--bindmount_ro"
+		}
+		nsjailArgs = append(nsjailArgs, flag, fmt.Sprintf("%s:%s", m.HostPath, m.SandboxPath))
+	}
+	for _, e := range script.Env {
+		nsjailArgs = append(nsjailArgs, "--env", fmt.Sprintf("%s=%s", e.Name, e.Value))
+	}
+	if script.Network.Mode != "none" {
+		// nsjail isolates the network namespace by default
+		nsjailArgs = append(nsjailArgs, "--disable_clone_newnet")
+	}
+
+	if r := script.Rlimits; r != nil {
+		rlimit := func(flag string, value int) {
+			if value != 0 {
+				nsjailArgs = append(nsjailArgs, flag, strconv.Itoa(value))
+			}
+		}
+		rlimit("--rlimit_as", r.AS)
+		rlimit("--rlimit_cpu", r.CPU)
+		rlimit("--rlimit_fsize", r.FSize)
+		rlimit("--rlimit_nofile", r.NoFile)
+		rlimit("--rlimit_nproc", r.NProc)
+	}
+
+	nsjailArgs = append(nsjailArgs, "--")
+	return append(nsjailArgs, cmdArgs...)
+}
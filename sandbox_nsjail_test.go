@@ -0,0 +1,59 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildNsjailArgs(t *testing.T) {
+	script := Script{
+		Env:     []EnvVar{{Name: "FOO", Value: "bar"}},
+		Network: NetworkPolicy{Mode: "none"},
+		Rlimits: &RlimitConfig{AS: 2048, NoFile: 256},
+	}
+	mounts := []Mount{{HostPath: "/home/me/src", SandboxPath: "/src"}, {HostPath: "/home/me/.kube", SandboxPath: "/root/.kube", ReadOnly: true}}
+
+	args := buildNsjailArgs("/tmp/root", "/src/pkg", mounts, script, []string{"/bin/ls", "-l"})
+	joined := strings.Join(args, " ")
+
+	for _, want := range []string{
+		"--mode o",
+		"--chroot /tmp/root",
+		"--cwd /src/pkg",
+		"--bindmount /home/me/src:/src",
+		"--bindmount_ro /home/me/.kube:/root/.kube",
+		"--env FOO=bar",
+		"--rlimit_as 2048",
+		"--rlimit_nofile 256",
+		"-- /bin/ls -l",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected nsjail args to contain %q, got %v", want, args)
+		}
+	}
+	if strings.Contains(joined, "--rlimit_cpu") {
+		t.Errorf("Did not expect unset rlimits, got %v", args)
+	}
+	if strings.Contains(joined, "--disable_clone_newnet") {
+		t.Errorf("Expected network to stay isolated with network: none, got %v", args)
+	}
+
+	args = buildNsjailArgs("/tmp/root", "/", nil, Script{}, []string{"/bin/ls"})
+	if !strings.Contains(strings.Join(args, " "), "--disable_clone_newnet") {
+		t.Errorf("Expected host network by default, got %v", args)
+	}
+}
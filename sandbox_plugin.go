@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// PluginSandbox runs the tool with an external sandbox: a clix-sandbox-<name> executable on PATH.
+//
+// The plugin protocol is JSON over stdio. The first line of the plugin's stdin is a pluginRequest,
+// and the rest of stdin is the tool's stdin. The plugin's stdout and stderr are the tool's stdout and
+// stderr, and its exit code is the tool's exit code. Plugins should exit with pluginFailureCode when
+// they fail to run the tool.
+type PluginSandbox struct {
+	Name string
+}
+
+// pluginProtocolVersion is incremented when pluginRequest changes incompatibly.
+const pluginProtocolVersion = 1
+
+// pluginFailureCode is the exit code of a plugin which failed to run the tool (as for docker run).
+const pluginFailureCode = 125
+
+// pluginRequest asks a plugin to run the tool.
+type pluginRequest struct {
+	Version int    `json:"version"`
+	Script  Script `json:"script"`
+	// Mounts are the script's mounts, with host expressions resolved to paths
+	Mounts []Mount  `json:"mounts,omitempty"`
+	Args   []string `json:"args"`
+	Cwd    string   `json:"cwd"`
+}
+
+// pluginCommand returns the executable implementing the sandbox name.
+func pluginCommand(name string) string {
+	return "clix-sandbox-" + name
+}
+
+// hasPlugin reports whether a plugin implementing the sandbox name is on PATH.
+func hasPlugin(name string) bool {
+	_, err := exec.LookPath(pluginCommand(name))
+	return err == nil
+}
+
+func (s *PluginSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
+	// There is no image digest to key the cache on, so we key it on the image reference
+	cacheKey := ""
+	if usesCacheDir(script.Mounts) {
+		sum := sha256.Sum256([]byte(s.Name + "\x00" + script.Image))
+		cacheKey = hex.EncodeToString(sum[:])
+	}
+	resolvedMounts, err := resolveMounts(script.Mounts, cacheKey)
+	if err != nil {
+		return fmt.Errorf("error resolving mounts: %w", err)
+	}
+	cwd, err := sandboxWorkdir(script, resolvedMounts)
+	if err != nil {
+		return err
+	}
+
+	request, err := json.Marshal(pluginRequest{
+		Version: pluginProtocolVersion,
+		Script:  script,
+		Mounts:  resolvedMounts,
+		Args:    args,
+		Cwd:     cwd,
+	})
+	if err != nil {
+		return fmt.Errorf("error encoding plugin request: %w", err)
+	}
+
+	plugin := pluginCommand(s.Name)
+	log(1, "PluginSandbox: running %s", plugin)
+	log(2, "Plugin request: %s", request)
+	cmd := execCommand(plugin)
+	cmd.Stdin = io.MultiReader(strings.NewReader(string(request)+"\n"), stdin)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := runTool(cmd); err != nil {
+		var exitErr *exitError
+		if errors.As(err, &exitErr) && exitErr.code == pluginFailureCode {
+			return fmt.Errorf("sandbox plugin %s failed to run the tool", plugin)
+		}
+		return fmt.Errorf("error running sandbox plugin %s: %w", plugin, err)
+	}
+	return nil
+}
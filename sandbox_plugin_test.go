@@ -0,0 +1,70 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestPluginSandbox(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	dir := t.TempDir()
+	script := Script{
+		Image:  "internal/tool",
+		Mounts: []Mount{{HostPath: dir, SandboxPath: "/work"}},
+	}
+	var stdout, stderr bytes.Buffer
+	if err := (&PluginSandbox{Name: "test"}).Run(strings.NewReader("input"), &stdout, &stderr, script, []string{"lint"}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	line, ok := strings.CutPrefix(strings.TrimSpace(stdout.String()), "request: ")
+	if !ok {
+		t.Fatalf("Expected the plugin to receive a request, got %q", stdout.String())
+	}
+	var request pluginRequest
+	if err := json.Unmarshal([]byte(line), &request); err != nil {
+		t.Fatalf("Invalid plugin request %q: %v", line, err)
+	}
+	cwd, _ := os.Getwd()
+	if request.Version != pluginProtocolVersion || request.Script.Image != "internal/tool" || request.Cwd != cwd {
+		t.Errorf("Unexpected plugin request %+v", request)
+	}
+	if len(request.Args) != 1 || request.Args[0] != "lint" {
+		t.Errorf("Expected args [lint], got %v", request.Args)
+	}
+	if len(request.Mounts) != 1 || request.Mounts[0].HostPath != dir || request.Mounts[0].SandboxPath != "/work" {
+		t.Errorf("Expected resolved mounts, got %+v", request.Mounts)
+	}
+}
+
+func TestPluginSandboxFailure(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	t.Setenv("MOCK_BEHAVIOR", "plugin_failure")
+
+	var stdout, stderr bytes.Buffer
+	err := (&PluginSandbox{Name: "test"}).Run(strings.NewReader(""), &stdout, &stderr, Script{Image: "internal/tool"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to run the tool") {
+		t.Errorf("Expected the plugin failure to be reported, got %v", err)
+	}
+}
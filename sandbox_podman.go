@@ -0,0 +1,55 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// PodmanSandbox runs tools with `podman run`, for hosts with (rootless) podman rather than docker.
+type PodmanSandbox struct{}
+
+func (s *PodmanSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
+	if err := resolveComposeNetwork([]string{"podman"}, &script); err != nil {
+		return err
+	}
+	script, stopProxy, err := startEgressProxy([]string{"podman"}, script)
+	if err != nil {
+		return err
+	}
+	defer stopProxy()
+
+	log(2, "PodmanSandbox: preparing args")
+	cmdArgs, err := buildPodmanArgs(script, args, isTerminal(stdin))
+	if err != nil {
+		return fmt.Errorf("error building podman args: %w", err)
+	}
+
+	log(1, "PodmanSandbox: running podman %v", cmdArgs)
+	cmd := execCommand("podman", cmdArgs...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := runTool(cmd); err != nil {
+		return fmt.Errorf("error running podman command: %w", err)
+	}
+	return nil
+}
+
+func buildPodmanArgs(script Script, args []string, isTerm bool) ([]string, error) {
+	return buildContainerArgs([]string{"podman"}, script, args, isTerm)
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestBuildPodmanArgs(t *testing.T) {
+	originalGetImageSHA := getImageSHAFn
+	defer func() { getImageSHAFn = originalGetImageSHA }()
+	var gotCLI []string
+	getImageSHAFn = func(cli []string, image string) (string, error) {
+		gotCLI = cli
+		return "mocksha256", nil
+	}
+
+	script := Script{
+		Image:      "python:3.11",
+		Entrypoint: "python",
+		Mounts: []Mount{
+			{HostPath: "${cacheDir}/python", SandboxPath: "/tmp/.clix-pycache"},
+		},
+		Env: []EnvVar{
+			{Name: "PYTHONPYCACHEPREFIX", Value: "/tmp/.clix-pycache"},
+		},
+	}
+	cmdArgs, err := buildPodmanArgs(script, []string{"script.py"}, false)
+	if err != nil {
+		t.Fatalf("buildPodmanArgs failed: %v", err)
+	}
+
+	if strings.Join(gotCLI, " ") != "podman" {
+		t.Errorf("Expected image SHA to be looked up with podman, got %v", gotCLI)
+	}
+
+	cwd, _ := os.Getwd()
+	joined := strings.Join(cmdArgs, " ")
+	for _, want := range []string{
+		"mocksha256/python:/tmp/.clix-pycache",
+		"-e PYTHONPYCACHEPREFIX=/tmp/.clix-pycache",
+		"-w " + cwd,
+		"--entrypoint python python:3.11 script.py",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected args to contain %q, got %v", want, cmdArgs)
+		}
+	}
+}
+
+func TestSelectedSandbox(t *testing.T) {
+	t.Setenv("CLIX_SANDBOX", "")
+	if got := selectedSandbox(Script{Sandbox: SandboxList{"podman"}}); got != "podman" {
+		t.Errorf("Expected script sandbox podman, got %q", got)
+	}
+
+	t.Setenv("CLIX_SANDBOX", "docker")
+	if got := selectedSandbox(Script{Sandbox: SandboxList{"podman"}}); got != "docker" {
+		t.Errorf("Expected CLIX_SANDBOX to override the script, got %q", got)
+	}
+}
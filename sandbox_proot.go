@@ -17,8 +17,6 @@ package main
 import (
 	"fmt"
 	"io"
-	"os"
-	"os/exec"
 )
 
 type ProotSandbox struct{}
@@ -29,7 +27,7 @@ func (s *ProotSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Scr
 		return fmt.Errorf("ProotSandbox requires an image path (used as root directory)")
 	}
 
-	realRoot, imageSHA, cleanup, err := prepareRootFS(rootPath)
+	realRoot, imageSHA, cleanup, err := prepareRootFS(rootPath, imagePlatform(script))
 	if err != nil {
 		return err
 	}
@@ -57,37 +55,47 @@ func (s *ProotSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Scr
 		return fmt.Errorf("error resolving mounts: %w", err)
 	}
 
-	// proot -r realRoot [-b host:guest ...] cmdArgs
-	prootArgs := []string{"-r", realRoot}
-	for _, m := range resolvedMounts {
-		prootArgs = append(prootArgs, "-b", fmt.Sprintf("%s:%s", m.HostPath, m.SandboxPath))
+	emulator, err := findEmulator(imageArch(script))
+	if err != nil {
+		return err
 	}
 
-	prootArgs = append(prootArgs, cmdArgs...)
+	workdir, err := rootfsWorkdir(script, resolvedMounts)
+	if err != nil {
+		return err
+	}
 
 	// Prepare the command
-	cmd := execCommand("proot", prootArgs...)
+	cmd := execCommand("proot", buildProotArgs(stderr, realRoot, emulator, workdir, resolvedMounts, cmdArgs)...)
 	cmd.Stdin = stdin
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
-	// We start at root of the new root
+	// proot starts the tool in the workdir of the new root
 	cmd.Dir = "/"
 
 	// Handle environment variables
-	if len(script.Env) > 0 {
-		cmd.Env = os.Environ()
-		for _, env := range script.Env {
-			cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", env.Name, env.Value))
-		}
-	}
+	cmd.Env = sandboxEnv(script)
 
-	if err := cmd.Run(); err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			os.Exit(exitErr.ExitCode())
-		}
+	if err := runTool(cmd); err != nil {
 		return fmt.Errorf("error running proot command: %w", err)
 	}
 
 	return nil
 }
+
+// buildProotArgs builds the arguments of proot: -r rootDir [-q qemu] -w workdir [-b host:guest ...] cmdArgs
+func buildProotArgs(stderr io.Writer, rootDir, emulator, workdir string, mounts []Mount, cmdArgs []string) []string {
+	prootArgs := []string{"-r", rootDir}
+	if emulator != "" {
+		prootArgs = append(prootArgs, "-q", emulator)
+	}
+	prootArgs = append(prootArgs, "-w", workdir)
+	for _, m := range mounts {
+		if m.ReadOnly {
+			fmt.Fprintf(stderr, "Warning: proot sandbox cannot mount %s read-only; the tool can write to it\n", m.HostPath)
+		}
+		prootArgs = append(prootArgs, "-b", fmt.Sprintf("%s:%s", m.HostPath, m.SandboxPath))
+	}
+	return append(prootArgs, cmdArgs...)
+}
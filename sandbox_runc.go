@@ -0,0 +1,230 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// RuncSandbox runs the extracted image as an OCI bundle with runc, without a container daemon.
+// The OCI runtime spec is generated from the script's mounts, env and entrypoint.
+type RuncSandbox struct{}
+
+// ociSpec is the subset of the OCI runtime spec (config.json) that we generate.
+type ociSpec struct {
+	Version  string     `json:"ociVersion"`
+	Process  ociProcess `json:"process"`
+	Root     ociRoot    `json:"root"`
+	Hostname string     `json:"hostname"`
+	Mounts   []ociMount `json:"mounts"`
+	Linux    ociLinux   `json:"linux"`
+}
+
+type ociProcess struct {
+	Terminal        bool            `json:"terminal"`
+	User            ociUser         `json:"user"`
+	Args            []string        `json:"args"`
+	Env             []string        `json:"env"`
+	Cwd             string          `json:"cwd"`
+	Capabilities    ociCapabilities `json:"capabilities"`
+	Rlimits         []ociRlimit     `json:"rlimits"`
+	NoNewPrivileges bool            `json:"noNewPrivileges"`
+}
+
+type ociUser struct {
+	UID uint32 `json:"uid"`
+	GID uint32 `json:"gid"`
+}
+
+type ociCapabilities struct {
+	Bounding  []string `json:"bounding"`
+	Effective []string `json:"effective"`
+	Permitted []string `json:"permitted"`
+}
+
+type ociRlimit struct {
+	Type string `json:"type"`
+	Hard uint64 `json:"hard"`
+	Soft uint64 `json:"soft"`
+}
+
+type ociRoot struct {
+	Path     string `json:"path"`
+	Readonly bool   `json:"readonly"`
+}
+
+type ociMount struct {
+	Destination string   `json:"destination"`
+	Type        string   `json:"type"`
+	Source      string   `json:"source"`
+	Options     []string `json:"options,omitempty"`
+}
+
+type ociLinux struct {
+	Namespaces    []ociNamespace `json:"namespaces"`
+	UIDMappings   []ociIDMapping `json:"uidMappings,omitempty"`
+	GIDMappings   []ociIDMapping `json:"gidMappings,omitempty"`
+	MaskedPaths   []string       `json:"maskedPaths"`
+	ReadonlyPaths []string       `json:"readonlyPaths"`
+}
+
+type ociNamespace struct {
+	Type string `json:"type"`
+}
+
+type ociIDMapping struct {
+	ContainerID uint32 `json:"containerID"`
+	HostID      uint32 `json:"hostID"`
+	Size        uint32 `json:"size"`
+}
+
+// ociDefaultCapabilities are the capabilities docker grants by default.
+var ociDefaultCapabilities = []string{
+	"CAP_CHOWN", "CAP_DAC_OVERRIDE", "CAP_FSETID", "CAP_FOWNER", "CAP_MKNOD", "CAP_NET_RAW", "CAP_SETGID",
+	"CAP_SETUID", "CAP_SETFCAP", "CAP_SETPCAP", "CAP_NET_BIND_SERVICE", "CAP_SYS_CHROOT", "CAP_KILL", "CAP_AUDIT_WRITE",
+}
+
+func (s *RuncSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
+	if script.Image == "" {
+		return fmt.Errorf("RuncSandbox requires an image")
+	}
+
+	var cmdArgs []string
+	if script.Entrypoint != "" {
+		cmdArgs = append([]string{script.Entrypoint}, args...)
+	} else if len(args) > 0 {
+		cmdArgs = args
+	} else {
+		return fmt.Errorf("no command specified and no entrypoint in script")
+	}
+
+	realRoot, imageSHA, cleanup, err := prepareRootFS(script.Image, imagePlatform(script))
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resolvedMounts, err := resolveMounts(script.Mounts, imageSHA)
+	if err != nil {
+		return fmt.Errorf("error resolving mounts: %w", err)
+	}
+	cwd, err := sandboxWorkdir(script, resolvedMounts)
+	if err != nil {
+		return err
+	}
+
+	bundle, err := os.MkdirTemp("", "clix-runc-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(bundle)
+
+	spec := buildOCISpec(realRoot, resolvedMounts, script, cmdArgs, cwd, isTerminal(stdin), os.Geteuid() != 0)
+	specJSON, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding OCI spec: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(bundle, "config.json"), specJSON, 0644); err != nil {
+		return fmt.Errorf("error writing OCI spec: %w", err)
+	}
+	log(2, "OCI spec:\n%s", specJSON)
+
+	id := fmt.Sprintf("clix-%d-%d", os.Getpid(), time.Now().UnixNano())
+	log(1, "RuncSandbox: running runc run --bundle %s %s", bundle, id)
+	cmd := execCommand("runc", "run", "--bundle", bundle, id)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := runTool(cmd); err != nil {
+		return fmt.Errorf("error running runc command: %w", err)
+	}
+	return nil
+}
+
+// buildOCISpec generates the OCI runtime spec to run cmdArgs in rootDir. Rootless specs map the
+// current user to root in a user namespace, as generated by `runc spec --rootless`.
+func buildOCISpec(rootDir string, mounts []Mount, script Script, cmdArgs []string, cwd string, isTerm, rootless bool) ociSpec {
+	env := []string{"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"}
+	if isTerm {
+		env = append(env, "TERM=xterm")
+	}
+	for _, e := range script.Env {
+		env = append(env, fmt.Sprintf("%s=%s", e.Name, e.Value))
+	}
+
+	spec := ociSpec{
+		Version: "1.0.2",
+		Process: ociProcess{
+			Terminal: isTerm,
+			Args:     cmdArgs,
+			Env:      env,
+			Cwd:      cwd,
+			Capabilities: ociCapabilities{
+				Bounding:  ociDefaultCapabilities,
+				Effective: ociDefaultCapabilities,
+				Permitted: ociDefaultCapabilities,
+			},
+			Rlimits:         []ociRlimit{{Type: "RLIMIT_NOFILE", Hard: 1024, Soft: 1024}},
+			NoNewPrivileges: true,
+		},
+		Root:     ociRoot{Path: rootDir},
+		Hostname: "clix",
+		Mounts: []ociMount{
+			{Destination: "/proc", Type: "proc", Source: "proc"},
+			{Destination: "/dev", Type: "tmpfs", Source: "tmpfs", Options: []string{"nosuid", "strictatime", "mode=755", "size=65536k"}},
+			{Destination: "/dev/pts", Type: "devpts", Source: "devpts", Options: []string{"nosuid", "noexec", "newinstance", "ptmxmode=0666", "mode=0620"}},
+			{Destination: "/dev/shm", Type: "tmpfs", Source: "shm", Options: []string{"nosuid", "noexec", "nodev", "mode=1777", "size=65536k"}},
+			{Destination: "/dev/mqueue", Type: "mqueue", Source: "mqueue", Options: []string{"nosuid", "noexec", "nodev"}},
+		},
+		Linux: ociLinux{
+			Namespaces: []ociNamespace{{Type: "pid"}, {Type: "ipc"}, {Type: "uts"}, {Type: "mount"}},
+			MaskedPaths: []string{"/proc/acpi", "/proc/kcore", "/proc/keys", "/proc/latency_stats", "/proc/timer_list",
+				"/proc/timer_stats", "/proc/sched_debug", "/sys/firmware", "/proc/scsi"},
+			ReadonlyPaths: []string{"/proc/asound", "/proc/bus", "/proc/fs", "/proc/irq", "/proc/sys", "/proc/sysrq-trigger"},
+		},
+	}
+
+	if rootless {
+		// sysfs cannot be mounted without a network namespace we own, so bind mount it
+		spec.Mounts = append(spec.Mounts, ociMount{Destination: "/sys", Type: "none", Source: "/sys", Options: []string{"rbind", "nosuid", "noexec", "nodev", "ro"}})
+		spec.Linux.Namespaces = append(spec.Linux.Namespaces, ociNamespace{Type: "user"})
+		spec.Linux.UIDMappings = []ociIDMapping{{ContainerID: 0, HostID: uint32(os.Getuid()), Size: 1}}
+		spec.Linux.GIDMappings = []ociIDMapping{{ContainerID: 0, HostID: uint32(os.Getgid()), Size: 1}}
+	} else {
+		spec.Mounts = append(spec.Mounts, ociMount{Destination: "/sys", Type: "sysfs", Source: "sysfs", Options: []string{"nosuid", "noexec", "nodev", "ro"}})
+	}
+
+	if script.Network.Mode == "none" {
+		spec.Linux.Namespaces = append(spec.Linux.Namespaces, ociNamespace{Type: "network"})
+	} else {
+		// The container shares the host network, so it needs the host's resolver configuration
+		spec.Mounts = append(spec.Mounts, ociMount{Destination: "/etc/resolv.conf", Type: "bind", Source: "/etc/resolv.conf", Options: []string{"rbind", "ro"}})
+	}
+
+	for _, m := range mounts {
+		mode := "rw"
+		if m.ReadOnly {
+			mode = "ro"
+		}
+		spec.Mounts = append(spec.Mounts, ociMount{Destination: m.SandboxPath, Type: "bind", Source: m.HostPath, Options: []string{"rbind", mode}})
+	}
+	return spec
+}
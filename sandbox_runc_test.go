@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestBuildOCISpec(t *testing.T) {
+	script := Script{
+		Env:     []EnvVar{{Name: "LANG", Value: "C"}},
+		Network: NetworkPolicy{Mode: "none"},
+	}
+	mounts := []Mount{{HostPath: "/home/me/src", SandboxPath: "/src"}, {HostPath: "/home/me/.config/gcloud", SandboxPath: "/root/.config/gcloud", ReadOnly: true}}
+	spec := buildOCISpec("/tmp/root", mounts, script, []string{"gofmt", "-l", "."}, "/src", false, false)
+
+	if spec.Root.Path != "/tmp/root" || spec.Process.Cwd != "/src" {
+		t.Errorf("Unexpected root %q or cwd %q", spec.Root.Path, spec.Process.Cwd)
+	}
+	if len(spec.Process.Args) != 3 || spec.Process.Args[0] != "gofmt" {
+		t.Errorf("Unexpected args %v", spec.Process.Args)
+	}
+	if !contains(spec.Process.Env, "LANG=C") {
+		t.Errorf("Expected script env in %v", spec.Process.Env)
+	}
+
+	namespaces := map[string]bool{}
+	for _, ns := range spec.Linux.Namespaces {
+		namespaces[ns.Type] = true
+	}
+	for _, want := range []string{"pid", "mount", "network"} {
+		if !namespaces[want] {
+			t.Errorf("Expected %s namespace, got %v", want, spec.Linux.Namespaces)
+		}
+	}
+	if namespaces["user"] {
+		t.Errorf("Expected no user namespace when running as root")
+	}
+
+	found := false
+	for _, m := range spec.Mounts {
+		if m.Destination == "/src" && m.Source == "/home/me/src" && m.Type == "bind" {
+			found = true
+		}
+		if m.Destination == "/root/.config/gcloud" && !contains(m.Options, "ro") {
+			t.Errorf("Expected a read-only bind mount, got %+v", m)
+		}
+		if m.Destination == "/etc/resolv.conf" {
+			t.Errorf("Expected no resolv.conf mount without network")
+		}
+	}
+	if !found {
+		t.Errorf("Expected bind mount of /home/me/src to /src, got %+v", spec.Mounts)
+	}
+}
+
+func TestBuildOCISpecRootless(t *testing.T) {
+	spec := buildOCISpec("/tmp/root", nil, Script{}, []string{"sh"}, "/", true, true)
+
+	namespaces := map[string]bool{}
+	for _, ns := range spec.Linux.Namespaces {
+		namespaces[ns.Type] = true
+	}
+	if !namespaces["user"] || namespaces["network"] {
+		t.Errorf("Expected a user namespace and the host network, got %v", spec.Linux.Namespaces)
+	}
+	if len(spec.Linux.UIDMappings) != 1 || spec.Linux.UIDMappings[0].ContainerID != 0 {
+		t.Errorf("Expected the user to be mapped to root, got %+v", spec.Linux.UIDMappings)
+	}
+	if !spec.Process.Terminal || !contains(spec.Process.Env, "TERM=xterm") {
+		t.Errorf("Expected a terminal, got %+v", spec.Process)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SeatbeltSandbox confines native runs on macOS by wrapping the compiled tool with sandbox-exec and a generated Seatbelt profile.
+// Filesystem access under the user's home directory is limited to the declared mounts,
+// and network access is denied when the script sets `network: none`.
+type SeatbeltSandbox struct{}
+
+func (s *SeatbeltSandbox) Command(script Script, name string, args ...string) (*exec.Cmd, error) {
+	if _, err := exec.LookPath("sandbox-exec"); err != nil {
+		return nil, fmt.Errorf("seatbelt sandbox requires sandbox-exec (macOS only): %w", err)
+	}
+
+	resolvedMounts, err := resolveMounts(script.Mounts, "")
+	if err != nil {
+		return nil, fmt.Errorf("error resolving mounts: %w", err)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user home dir: %w", err)
+	}
+
+	var allowed, readOnly, denied []string
+	for _, m := range resolvedMounts {
+		if m.masked {
+			// Seatbelt doesn't remap, so the sandbox path is the masked host path
+			denied = append(denied, m.SandboxPath)
+			continue
+		}
+		if m.SandboxPath != m.HostPath {
+			fmt.Fprintf(os.Stderr, "Warning: seatbelt sandbox cannot remap %s to %s; the tool will see the host path\n", m.HostPath, m.SandboxPath)
+		}
+		if m.ReadOnly {
+			readOnly = append(readOnly, m.HostPath)
+		} else {
+			allowed = append(allowed, m.HostPath)
+		}
+	}
+	// The tool is built before it is sandboxed, so it only needs the temp dir beyond its mounts
+	allowed = append(allowed, os.TempDir())
+
+	profile := seatbeltProfile(canonicalPaths(home)[0], canonicalPaths(allowed...), canonicalPaths(readOnly...), canonicalPaths(denied...), script.Network.Mode == "none")
+	log(2, "Seatbelt profile:\n%s", profile)
+
+	sandboxArgs := append([]string{"-p", profile, name}, args...)
+	return execCommand("sandbox-exec", sandboxArgs...), nil
+}
+
+// seatbeltProfile generates a Seatbelt (SBPL) profile which denies reads and writes beneath home,
+// and writes everywhere, except for the allowed paths and reads of the read-only paths; the denied
+// (masked) paths are inaccessible even beneath those. Later rules take precedence in SBPL.
+func seatbeltProfile(home string, allowed, readOnly, denied []string, denyNetwork bool) string {
+	var sb strings.Builder
+	sb.WriteString("(version 1)\n")
+	sb.WriteString("(allow default)\n")
+	fmt.Fprintf(&sb, "(deny file-read* file-write* (subpath %q))\n", home)
+	sb.WriteString("(deny file-write*)\n")
+	sb.WriteString("(allow file-write* (literal \"/dev/null\") (literal \"/dev/tty\") (regex #\"^/dev/fd/\"))\n")
+	for _, p := range allowed {
+		fmt.Fprintf(&sb, "(allow file-read* file-write* (subpath %q))\n", p)
+	}
+	for _, p := range readOnly {
+		// Also deny writes, in case the path is beneath an allowed path
+		fmt.Fprintf(&sb, "(allow file-read* (subpath %q))\n", p)
+		fmt.Fprintf(&sb, "(deny file-write* (subpath %q))\n", p)
+	}
+	for _, p := range denied {
+		fmt.Fprintf(&sb, "(deny file-read* file-write* (subpath %q))\n", p)
+	}
+	if denyNetwork {
+		sb.WriteString("(deny network*)\n")
+	}
+	return sb.String()
+}
+
+// canonicalPaths resolves symlinks (e.g. /var -> /private/var on macOS), as Seatbelt matches on real paths.
+func canonicalPaths(paths ...string) []string {
+	var result []string
+	for _, p := range paths {
+		if resolved, err := filepath.EvalSymlinks(p); err == nil {
+			p = resolved
+		}
+		result = append(result, p)
+	}
+	return result
+}
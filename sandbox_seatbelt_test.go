@@ -0,0 +1,70 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSeatbeltProfile(t *testing.T) {
+	profile := seatbeltProfile("/Users/me", []string{"/Users/me/src/repo", "/private/tmp"}, nil, nil, false)
+
+	for _, want := range []string{
+		"(version 1)",
+		`(deny file-read* file-write* (subpath "/Users/me"))`,
+		"(deny file-write*)",
+		`(allow file-read* file-write* (subpath "/Users/me/src/repo"))`,
+		`(allow file-read* file-write* (subpath "/private/tmp"))`,
+	} {
+		if !strings.Contains(profile, want) {
+			t.Errorf("expected profile to contain %q, got:\n%s", want, profile)
+		}
+	}
+	if strings.Contains(profile, "network") {
+		t.Errorf("did not expect network rules, got:\n%s", profile)
+	}
+
+	// Allow rules must come after the deny rules, as later rules take precedence
+	if strings.Index(profile, "(allow file-read*") < strings.Index(profile, "(deny file-write*)") {
+		t.Errorf("expected allow rules after deny rules, got:\n%s", profile)
+	}
+
+	// Read-only paths are readable, but not writable even beneath an allowed path
+	profile = seatbeltProfile("/Users/me", []string{"/Users/me"}, []string{"/Users/me/.config/gcloud"}, nil, false)
+	for _, want := range []string{
+		`(allow file-read* (subpath "/Users/me/.config/gcloud"))`,
+		`(deny file-write* (subpath "/Users/me/.config/gcloud"))`,
+	} {
+		if !strings.Contains(profile, want) {
+			t.Errorf("expected profile to contain %q, got:\n%s", want, profile)
+		}
+	}
+	if strings.Index(profile, `(deny file-write* (subpath "/Users/me/.config/gcloud"))`) < strings.Index(profile, `(allow file-read* file-write* (subpath "/Users/me"))`) {
+		t.Errorf("expected read-only rules after allow rules, got:\n%s", profile)
+	}
+
+	// Masked paths are inaccessible, even beneath an allowed path
+	profile = seatbeltProfile("/Users/me", []string{"/Users/me/.config/gcloud"}, nil, []string{"/Users/me/.config/gcloud/credentials.db"}, false)
+	masked := `(deny file-read* file-write* (subpath "/Users/me/.config/gcloud/credentials.db"))`
+	if strings.Index(profile, masked) < strings.Index(profile, `(allow file-read* file-write* (subpath "/Users/me/.config/gcloud"))`) {
+		t.Errorf("expected masked paths to be denied after allow rules, got:\n%s", profile)
+	}
+
+	profile = seatbeltProfile("/Users/me", nil, nil, nil, true)
+	if !strings.Contains(profile, "(deny network*)") {
+		t.Errorf("expected network to be denied, got:\n%s", profile)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// SandboxList is the sandbox to run the tool in, or a list of sandboxes in order of preference.
+// In a script it is either a string (`sandbox: podman`) or a list (`sandbox: [podman, namespace]`).
+type SandboxList []string
+
+func (l *SandboxList) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*l = parseSandboxList(s)
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// parseSandboxList parses a comma-separated list of sandboxes, as used by CLIX_SANDBOX.
+func parseSandboxList(s string) SandboxList {
+	var list SandboxList
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
+// defaultSandboxChain is probed when neither CLIX_SANDBOX nor the script choose a sandbox.
+var defaultSandboxChain = SandboxList{"docker", "podman", "nerdctl", "apple-container", "namespace"}
+
+var sandboxAvailableFn = sandboxAvailable
+
+// sandboxAvailable probes whether the host can run the sandbox.
+func sandboxAvailable(name string) bool {
+	onPath := func(command string) bool {
+		_, err := exec.LookPath(command)
+		return err == nil
+	}
+	switch name {
+	case "docker", "podman", "nerdctl", "proot", "nsjail", "runc":
+		return onPath(name)
+	case "apple-container":
+		return runtime.GOOS == "darwin" && hasAppleContainer()
+	case "seatbelt":
+		return runtime.GOOS == "darwin" && onPath("sandbox-exec")
+	case "wsl":
+		return runtime.GOOS == "windows" && onPath("wsl.exe")
+	case "chroot":
+		return runtime.GOOS == "linux" && os.Geteuid() == 0
+	case "namespace":
+		return runtime.GOOS == "linux" && userNamespacesEnabled()
+	case "landlock":
+		return runtime.GOOS == "linux" && landlockSupported()
+	case "firecracker":
+		if !onPath("firecracker") {
+			return false
+		}
+		f, err := os.OpenFile("/dev/kvm", os.O_RDWR, 0)
+		if err != nil {
+			return false
+		}
+		f.Close()
+		return true
+	}
+	return hasPlugin(name)
+}
+
+// userNamespacesEnabled reports whether unprivileged users may create user namespaces.
+func userNamespacesEnabled() bool {
+	if data, err := os.ReadFile("/proc/sys/kernel/unprivileged_userns_clone"); err == nil && strings.TrimSpace(string(data)) == "0" {
+		return false
+	}
+	if data, err := os.ReadFile("/proc/sys/user/max_user_namespaces"); err == nil && strings.TrimSpace(string(data)) == "0" {
+		return false
+	}
+	return true
+}
+
+// resolveSandbox returns the first available sandbox of candidates. A single candidate is used without
+// probing, so that it reports its own errors, as is the first candidate when none are available.
+func resolveSandbox(candidates SandboxList) string {
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	for _, name := range candidates {
+		if sandboxAvailableFn(name) {
+			log(2, "Sandbox %s is available", name)
+			return name
+		}
+		log(2, "Sandbox %s is not available", name)
+	}
+	log(1, "None of the sandboxes %s are available", strings.Join(candidates, ", "))
+	return candidates[0]
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestSandboxListUnmarshal(t *testing.T) {
+	for _, tc := range []struct {
+		yaml string
+		want SandboxList
+	}{
+		{"sandbox: podman", SandboxList{"podman"}},
+		{"sandbox: docker, namespace", SandboxList{"docker", "namespace"}},
+		{"sandbox: [podman, nsjail]", SandboxList{"podman", "nsjail"}},
+		{"image: alpine", nil},
+	} {
+		var script Script
+		if err := yaml.Unmarshal([]byte(tc.yaml), &script); err != nil {
+			t.Fatalf("Unmarshal(%q) failed: %v", tc.yaml, err)
+		}
+		if !reflect.DeepEqual(script.Sandbox, tc.want) {
+			t.Errorf("Unmarshal(%q).Sandbox = %v, want %v", tc.yaml, script.Sandbox, tc.want)
+		}
+	}
+}
+
+func TestResolveSandbox(t *testing.T) {
+	originalAvailable := sandboxAvailableFn
+	defer func() { sandboxAvailableFn = originalAvailable }()
+	var probed []string
+	sandboxAvailableFn = func(name string) bool {
+		probed = append(probed, name)
+		return name == "podman" || name == "namespace"
+	}
+
+	t.Setenv("CLIX_SANDBOX", "docker,podman,namespace")
+	if got := selectedSandbox(Script{}); got != "podman" {
+		t.Errorf("Expected the first available sandbox of CLIX_SANDBOX, got %q", got)
+	}
+	if !reflect.DeepEqual(probed, []string{"docker", "podman"}) {
+		t.Errorf("Expected probing to stop at the first available sandbox, probed %v", probed)
+	}
+
+	t.Setenv("CLIX_SANDBOX", "")
+	if got := selectedSandbox(Script{Sandbox: SandboxList{"nsjail", "namespace"}}); got != "namespace" {
+		t.Errorf("Expected the first available sandbox of the script, got %q", got)
+	}
+	if got := selectedSandbox(Script{}); got != "podman" {
+		t.Errorf("Expected the first available default sandbox, got %q", got)
+	}
+
+	probed = nil
+	if got := selectedSandbox(Script{Sandbox: SandboxList{"firecracker"}}); got != "firecracker" || len(probed) != 0 {
+		t.Errorf("Expected a single sandbox to be used without probing, got %q (probed %v)", got, probed)
+	}
+	if got := resolveSandbox(SandboxList{"nsjail", "proot"}); got != "nsjail" {
+		t.Errorf("Expected the first sandbox when none are available, got %q", got)
+	}
+}
@@ -0,0 +1,239 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+)
+
+// WasmConfig configures a WebAssembly (WASI) tool.
+type WasmConfig struct {
+	// Module is the .wasm module to run: a path, relative to the script, or an OCI image containing the module
+	Module string `json:"module"`
+}
+
+// WasmSandbox runs WASI modules in-process with wazero, so they need no external runtime. The module can
+// only access the declared mounts, which are mapped to WASI preopened directories, and has no network
+// access.
+type WasmSandbox struct{}
+
+func (s *WasmSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
+	module, moduleSHA, cleanup, err := fetchWasmModule(script.Wasm.Module)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resolvedMounts, err := resolveMounts(script.Mounts, moduleSHA)
+	if err != nil {
+		return fmt.Errorf("error resolving mounts: %w", err)
+	}
+	for _, m := range resolvedMounts {
+		if m.masked {
+			return fmt.Errorf("wasm sandbox cannot mask %s; WASI preopens whole directories", m.SandboxPath)
+		}
+		if info, err := os.Stat(m.HostPath); err == nil && !info.IsDir() {
+			return fmt.Errorf("wasm sandbox cannot mount the file %s; WASI preopens directories", m.HostPath)
+		}
+	}
+
+	if err := checkShellSandbox(); err != nil {
+		return err
+	}
+	if prebuildOnly {
+		log(1, "Not running %s for clix build", module)
+		return nil
+	}
+	if dryRunEnabled() {
+		return printWasmCommand(stdout, module, resolvedMounts, script, args)
+	}
+
+	data, err := os.ReadFile(module)
+	if err != nil {
+		return fmt.Errorf("reading wasm module: %w", err)
+	}
+	ctx := context.Background()
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+	wasi_snapshot_preview1.MustInstantiate(ctx, r)
+	compiled, err := r.CompileModule(ctx, data)
+	if err != nil {
+		return fmt.Errorf("compiling wasm module %s: %w", module, err)
+	}
+
+	log(1, "WasmSandbox: running %s %v", module, args)
+	config := wasmModuleConfig(stdin, stdout, stderr, module, resolvedMounts, script, args)
+	start := time.Now()
+	_, err = r.InstantiateModule(ctx, compiled, config)
+	usage.Wall += time.Since(start)
+	// Modules which exit 0 return no error
+	var exitErr *sys.ExitError
+	if errors.As(err, &exitErr) {
+		return &exitError{code: int(exitErr.ExitCode())}
+	}
+	if err != nil {
+		return fmt.Errorf("error running wasm module: %w", err)
+	}
+	return nil
+}
+
+// wasmModuleConfig returns the WASI configuration of the module: its args and env, and the mounts as
+// preopened directories, read-only where the mount is.
+func wasmModuleConfig(stdin io.Reader, stdout, stderr io.Writer, module string, mounts []Mount, script Script, args []string) wazero.ModuleConfig {
+	fsConfig := wazero.NewFSConfig()
+	for _, m := range mounts {
+		if m.ReadOnly {
+			fsConfig = fsConfig.WithReadOnlyDirMount(m.HostPath, m.SandboxPath)
+		} else {
+			fsConfig = fsConfig.WithDirMount(m.HostPath, m.SandboxPath)
+		}
+	}
+	config := wazero.NewModuleConfig().
+		WithArgs(append([]string{filepath.Base(module)}, args...)...).
+		WithStdin(stdin).
+		WithStdout(stdout).
+		WithStderr(stderr).
+		WithFSConfig(fsConfig).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithRandSource(rand.Reader)
+	for _, e := range script.Env {
+		config = config.WithEnv(e.Name, e.Value)
+	}
+	return config
+}
+
+// printWasmCommand prints the module which a dry run would run, with its env and preopened directories.
+func printWasmCommand(w io.Writer, module string, mounts []Mount, script Script, args []string) error {
+	var words []string
+	for _, e := range script.Env {
+		words = append(words, e.Name+"="+e.Value)
+	}
+	words = append(append(words, module), args...)
+	var preopens strings.Builder
+	for _, m := range mounts {
+		fmt.Fprintf(&preopens, "%s\n", volumeArg(m.HostPath, m.SandboxPath, m.ReadOnly))
+	}
+	_, err := fmt.Fprintf(w, "# Command\n%s\n# Preopened directories\n%s", redactSecrets(displayCommand(words)), preopens.String())
+	return err
+}
+
+// isLocalWasmModule reports whether module is a path to a .wasm file, rather than an OCI image reference.
+func isLocalWasmModule(module string) bool {
+	return strings.HasSuffix(module, ".wasm")
+}
+
+// resolveWasmModule makes a local module path relative to the script absolute.
+func resolveWasmModule(scriptPath, module string) (string, error) {
+	if !isLocalWasmModule(module) || filepath.IsAbs(module) {
+		return module, nil
+	}
+	absScript, err := filepath.Abs(scriptPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(absScript), module), nil
+}
+
+// fetchWasmModule returns the path and sha256 of the module, pulling and unpacking OCI images.
+func fetchWasmModule(module string) (string, string, func(), error) {
+	if isLocalWasmModule(module) {
+		data, err := os.ReadFile(module)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("reading wasm module: %w", err)
+		}
+		sum := sha256.Sum256(data)
+		return module, hex.EncodeToString(sum[:]), func() {}, nil
+	}
+
+	if err := checkOnline("wasm module " + module); err != nil {
+		return "", "", nil, err
+	}
+	img, err := crane.Pull(module, crane.WithPlatform(&v1.Platform{OS: "wasip1", Architecture: "wasm"}))
+	if err != nil {
+		return "", "", nil, fmt.Errorf("pulling wasm image %q: %w", module, err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return "", "", nil, fmt.Errorf("getting image digest: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "clix-wasm-*")
+	if err != nil {
+		return "", "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+
+	pr, pw := io.Pipe()
+	go func() {
+		err := crane.Export(img, pw)
+		pw.CloseWithError(err)
+	}()
+	if err := untar(pr, tmpDir); err != nil {
+		cleanup()
+		return "", "", nil, fmt.Errorf("unpacking wasm image: %w", err)
+	}
+
+	// Prefer the image entrypoint, otherwise the image should contain a single module
+	var path string
+	if config, err := img.ConfigFile(); err == nil && len(config.Config.Entrypoint) > 0 {
+		path = filepath.Join(tmpDir, config.Config.Entrypoint[0])
+	} else {
+		path, err = findWasmModule(tmpDir)
+		if err != nil {
+			cleanup()
+			return "", "", nil, fmt.Errorf("wasm image %q: %w", module, err)
+		}
+	}
+	return path, digest.Hex, cleanup, nil
+}
+
+// findWasmModule returns the single .wasm file beneath dir.
+func findWasmModule(dir string) (string, error) {
+	var modules []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".wasm") {
+			modules = append(modules, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(modules) != 1 {
+		return "", fmt.Errorf("expected one .wasm module, found %d", len(modules))
+	}
+	return modules[0], nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveWasmModule(t *testing.T) {
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "tool")
+
+	for _, tc := range []struct {
+		module string
+		want   string
+	}{
+		{"tool.wasm", filepath.Join(dir, "tool.wasm")},
+		{"../lib/tool.wasm", filepath.Join(filepath.Dir(dir), "lib", "tool.wasm")},
+		{"/opt/tool.wasm", "/opt/tool.wasm"},
+		{"ghcr.io/example/tool:v1", "ghcr.io/example/tool:v1"},
+	} {
+		got, err := resolveWasmModule(scriptPath, tc.module)
+		if err != nil {
+			t.Fatalf("resolveWasmModule(%q) failed: %v", tc.module, err)
+		}
+		if got != tc.want {
+			t.Errorf("resolveWasmModule(%q) = %q, want %q", tc.module, got, tc.want)
+		}
+	}
+}
+
+// buildWasmTool compiles tests/wasm-tool to a WASI module in dir.
+func buildWasmTool(t *testing.T, dir string) string {
+	t.Helper()
+	module := filepath.Join(dir, "tool.wasm")
+	cmd := exec.Command("go", "build", "-o", module, "./tests/wasm-tool")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("building the wasm tool failed: %v\n%s", err, out)
+	}
+	return module
+}
+
+func TestRunWasm(t *testing.T) {
+	t.Setenv("CLIX_DRY_RUN", "")
+	t.Setenv("CLIX_APPROVE_MOUNTS", "1")
+	dir := t.TempDir()
+	buildWasmTool(t, dir)
+	data := t.TempDir()
+	readOnly := t.TempDir()
+	scriptPath := filepath.Join(dir, "tool")
+	scriptContent := fmt.Sprintf(`#!/usr/bin/env clix
+wasm:
+  module: tool.wasm
+env:
+- name: TOOL_MODE
+  value: ci
+mounts:
+- hostPath: %s
+  sandboxPath: /data
+- hostPath: %s
+  sandboxPath: /config
+  readOnly: true
+`, data, readOnly)
+	if err := os.WriteFile(scriptPath, []byte(scriptContent), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := run(strings.NewReader(""), &stdout, &stderr, []string{"clix", scriptPath, "hello"}); err != nil {
+		t.Fatalf("run failed: %v (stderr %q)", err, stderr.String())
+	}
+	if want := "Hello from wasm-tool\nArg 0: hello\nTOOL_MODE=ci\n"; stdout.String() != want {
+		t.Errorf("Expected output %q, got %q", want, stdout.String())
+	}
+
+	// Mounts are preopened, and read-only mounts can't be written
+	if err := run(strings.NewReader(""), &stdout, &stderr, []string{"clix", scriptPath, "write", "/data/out"}); err != nil {
+		t.Fatalf("writing to the mount failed: %v (stderr %q)", err, stderr.String())
+	}
+	if got, err := os.ReadFile(filepath.Join(data, "out")); err != nil || string(got) != "written by wasm-tool\n" {
+		t.Errorf("Expected the module to write to the mount, got %q, %v", got, err)
+	}
+	err := run(strings.NewReader(""), &stdout, &stderr, []string{"clix", scriptPath, "write", "/config/out"})
+	if exitErr, ok := err.(*exitError); !ok || exitErr.code != 3 {
+		t.Errorf("Expected the write to the read-only mount to fail with exit status 3, got %v", err)
+	}
+	if pathExists(filepath.Join(readOnly, "out")) {
+		t.Errorf("Expected the read-only mount to be unchanged")
+	}
+
+	stdout.Reset()
+	if err := run(strings.NewReader(""), &stdout, &stderr, []string{"clix", "--dry-run", scriptPath, "hello"}); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if want := "# Command\nTOOL_MODE=ci " + filepath.Join(dir, "tool.wasm") + " hello\n# Preopened directories\n" + data + ":/data\n" + readOnly + ":/config:ro\n"; !strings.Contains(stdout.String(), want) {
+		t.Errorf("Expected the dry run to contain %q, got:\n%s", want, stdout.String())
+	}
+}
+
+func TestFindWasmModule(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := findWasmModule(dir); err == nil {
+		t.Error("expected error for image without a module")
+	}
+	module := filepath.Join(dir, "app", "tool.wasm")
+	if err := os.MkdirAll(filepath.Dir(module), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(module, []byte("\x00asm"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := findWasmModule(dir)
+	if err != nil {
+		t.Fatalf("findWasmModule failed: %v", err)
+	}
+	if got != module {
+		t.Errorf("findWasmModule() = %q, want %q", got, module)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// WSLSandbox runs tools on Windows hosts using the docker engine inside a WSL2 distro.
+// Host paths are translated to their /mnt/<drive> equivalents inside the distro. The other Linux
+// sandboxes (e.g. namespace) are not supported inside the distro.
+type WSLSandbox struct {
+	// Distro is the WSL distro to run in; the default distro is used if empty
+	Distro string
+}
+
+func (s *WSLSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
+	log(2, "WSLSandbox: preparing args")
+	cmdArgs, err := buildWSLDockerArgs(s.Distro, script, args, isTerminal(stdin))
+	if err != nil {
+		return fmt.Errorf("error building docker args: %w", err)
+	}
+
+	cli := wslDockerCLI(s.Distro)
+	log(1, "WSLSandbox: running %v %v", cli, cmdArgs)
+	cmd := execCommand(cli[0], append(cli[1:], cmdArgs...)...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := runTool(cmd); err != nil {
+		return fmt.Errorf("error running docker command in WSL: %w", err)
+	}
+	return nil
+}
+
+// wslDockerCLI returns the command line prefix which invokes docker inside the WSL distro.
+func wslDockerCLI(distro string) []string {
+	cli := []string{"wsl.exe"}
+	if distro != "" {
+		cli = append(cli, "--distribution", distro)
+	}
+	return append(cli, "--exec", "docker")
+}
+
+var getWSLImageSHAFn = func(distro, image string) (string, error) {
+	return getImageSHA(wslDockerCLI(distro), image)
+}
+
+func buildWSLDockerArgs(distro string, script Script, args []string, isTerm bool) ([]string, error) {
+	cli := wslDockerCLI(distro)
+	// Resolve mounts on the Windows side, so that expressions like ~ refer to the Windows user
+	volumes, hostMounts := splitVolumeMounts(script.Mounts)
+	mountArgs, err := namedVolumeArgs(cli, volumes)
+	if err != nil {
+		return nil, err
+	}
+	imageSHA := ""
+	if usesCacheDir(hostMounts) {
+		var err error
+		imageSHA, err = getWSLImageSHAFn(distro, script.Image)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get image SHA: %w", err)
+		}
+	}
+
+	resolvedMounts, err := resolveMounts(hostMounts, imageSHA)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving mounts: %w", err)
+	}
+
+	// The mounts were checked and created on the Windows side, where they exist, so they are mounted
+	// as they are, translated, rather than resolved again inside the distro
+	var wslMounts []Mount
+	for _, m := range resolvedMounts {
+		m.HostPath = windowsToWSLPath(m.HostPath)
+		m.SandboxPath = windowsToWSLPath(m.SandboxPath)
+		wslMounts = append(wslMounts, m)
+		mountArgs = append(mountArgs, "-v", volumeArg(m.HostPath, m.SandboxPath, m.ReadOnly))
+	}
+	if script.Workdir != "" {
+		script.Workdir = windowsToWSLPath(script.Workdir)
+	}
+
+	cmdArgs, err := containerRunArgs(cli, script, mountArgs, wslMounts, args, isTerm)
+	if err != nil {
+		return nil, err
+	}
+
+	// The working directory is a host path, which also needs translating
+	for i := range cmdArgs {
+		if cmdArgs[i] == "-w" && i+1 < len(cmdArgs) {
+			cmdArgs[i+1] = windowsToWSLPath(cmdArgs[i+1])
+			break
+		}
+	}
+	return cmdArgs, nil
+}
+
+var windowsDrivePath = regexp.MustCompile(`^([A-Za-z]):[\\/]?`)
+
+// windowsToWSLPath converts a Windows path (C:\Users\me) to the path WSL mounts it on (/mnt/c/Users/me).
+// Paths without a drive letter are returned unchanged.
+func windowsToWSLPath(p string) string {
+	match := windowsDrivePath.FindStringSubmatch(p)
+	if match == nil {
+		return p
+	}
+	rest := strings.ReplaceAll(p[len(match[0]):], `\`, "/")
+	return path.Clean("/mnt/" + strings.ToLower(match[1]) + "/" + rest)
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWindowsToWSLPath(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `C:\Users\me\src`, expected: "/mnt/c/Users/me/src"},
+		{input: `d:\data`, expected: "/mnt/d/data"},
+		{input: `C:/Users/me`, expected: "/mnt/c/Users/me"},
+		{input: `C:\`, expected: "/mnt/c"},
+		{input: "/root/.config", expected: "/root/.config"},
+	}
+
+	for _, tt := range tests {
+		if got := windowsToWSLPath(tt.input); got != tt.expected {
+			t.Errorf("windowsToWSLPath(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestBuildWSLDockerArgs(t *testing.T) {
+	originalGetImageSHA := getWSLImageSHAFn
+	defer func() { getWSLImageSHAFn = originalGetImageSHA }()
+	var gotDistro string
+	getWSLImageSHAFn = func(distro, image string) (string, error) {
+		gotDistro = distro
+		return "mocksha256", nil
+	}
+
+	script := Script{
+		Image: "python:3.11",
+		Mounts: []Mount{
+			{HostPath: `C:\Users\me\src`},
+			{HostPath: "${cacheDir}/python", SandboxPath: "/tmp/.clix-pycache"},
+		},
+	}
+	cmdArgs, err := buildWSLDockerArgs("Ubuntu", script, []string{"script.py"}, false)
+	if err != nil {
+		t.Fatalf("buildWSLDockerArgs failed: %v", err)
+	}
+	if gotDistro != "Ubuntu" {
+		t.Errorf("Expected image SHA to be looked up in distro Ubuntu, got %q", gotDistro)
+	}
+
+	joined := strings.Join(cmdArgs, " ")
+	if !strings.Contains(joined, "-v /mnt/c/Users/me/src:/mnt/c/Users/me/src") {
+		t.Errorf("Expected translated mount, got args: %v", cmdArgs)
+	}
+	if !strings.Contains(joined, "mocksha256/python:/tmp/.clix-pycache") {
+		t.Errorf("Expected cache mount, got args: %v", cmdArgs)
+	}
+
+	// Masks resolved on the Windows side stay read-only mounts of the empty file
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	src := t.TempDir()
+	if err := os.WriteFile(filepath.Join(src, "secret.json"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	script = Script{Image: "alpine", Mounts: []Mount{{HostPath: src, SandboxPath: "/src", Mask: []string{"secret.json"}}}}
+	cmdArgs, err = buildWSLDockerArgs("Ubuntu", script, nil, false)
+	if err != nil {
+		t.Fatalf("buildWSLDockerArgs failed: %v", err)
+	}
+	mask := volumeArg(filepath.Join(os.Getenv("XDG_CACHE_HOME"), "clix", "mask", "file"), "/src/secret.json", true)
+	if joined := strings.Join(cmdArgs, " "); strings.Count(joined, "-v ") != 2 || !strings.Contains(joined, "-v "+mask) {
+		t.Errorf("Expected the mount and its mask, got args: %v", cmdArgs)
+	}
+
+	cli := wslDockerCLI("Ubuntu")
+	if strings.Join(cli, " ") != "wsl.exe --distribution Ubuntu --exec docker" {
+		t.Errorf("Unexpected WSL docker CLI: %v", cli)
+	}
+}
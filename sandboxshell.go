@@ -0,0 +1,73 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+// sandboxShell is the shell started by clix shell in place of the tool, or "" when running the tool.
+var sandboxShell string
+
+// shellStarted records that the shell is being started in a container sandbox, so that runTool can
+// refuse to start a tool which would run on the host instead.
+var shellStarted bool
+
+// runSandboxShell implements `clix shell [--shell SHELL] <script>[:command]`, which starts an interactive shell in
+// the sandbox the tool would run in, with the same image, mounts, env and workdir.
+func runSandboxShell(stdin io.Reader, stdout, stderr io.Writer, args []string) error {
+	flags := flag.NewFlagSet("shell", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	shell := flags.String("shell", "/bin/sh", "the shell to start, which must be in the image")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *shell == "" {
+		return fmt.Errorf("usage: clix shell [--shell SHELL] <script>[:command]")
+	}
+	sandboxShell, shellStarted = *shell, false
+	defer func() { sandboxShell, shellStarted = "", false }()
+	return run(stdin, stdout, stderr, []string{"clix", flags.Arg(0)})
+}
+
+// shellSandbox runs the shell of clix shell instead of the tool.
+type shellSandbox struct {
+	Sandbox
+	shell string
+}
+
+func (s *shellSandbox) Run(stdin io.Reader, stdout, stderr io.Writer, script Script, args []string) error {
+	command := args
+	if script.Entrypoint != "" {
+		command = append([]string{script.Entrypoint}, args...)
+	}
+	if len(command) > 0 {
+		fmt.Fprintf(stderr, "clix: starting %s in %s; the tool would run: %s\n", s.shell, script.Image, displayCommand(command))
+	}
+	script.Entrypoint = s.shell
+	shellStarted = true
+	return s.Sandbox.Run(stdin, stdout, stderr, script, nil)
+}
+
+// checkShellSandbox returns an error when clix shell would start the tool on the host rather than a shell
+// in a container sandbox.
+func checkShellSandbox() error {
+	if sandboxShell != "" && !shellStarted {
+		return fmt.Errorf("clix shell needs a container sandbox, but the tool runs on the host; set sandbox to a container sandbox such as docker, or add a mount to a go script")
+	}
+	return nil
+}
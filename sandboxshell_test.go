@@ -0,0 +1,54 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSandboxShell(t *testing.T) {
+	t.Setenv("CLIX_DRY_RUN", "1")
+	t.Setenv("CLIX_SANDBOX", "docker")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	dir := t.TempDir()
+	imagePath := filepath.Join(dir, "jq.yaml")
+	os.WriteFile(imagePath, []byte("image: ghcr.io/jqlang/jq:1.7.1\nentrypoint: jq\nmountCwd: false\nargs:\n  prepend: [--color-output]\n"), 0644)
+
+	var stdout, stderr bytes.Buffer
+	if err := run(strings.NewReader(""), &stdout, &stderr, []string{"clix", "shell", "--shell", "/bin/bash", imagePath}); err != nil {
+		t.Fatalf("shell failed: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "--entrypoint /bin/bash ") || !strings.HasSuffix(stdout.String(), " ghcr.io/jqlang/jq:1.7.1\n") {
+		t.Errorf("Expected the shell to be started in the image, got:\n%s", stdout.String())
+	}
+	if want := "clix: starting /bin/bash in ghcr.io/jqlang/jq:1.7.1; the tool would run: jq --color-output\n"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("Expected the tool's command on stderr, got:\n%s", stderr.String())
+	}
+
+	// go scripts without mounts run on the host, where there's no sandbox to start a shell in
+	goPath := filepath.Join(dir, "tool.yaml")
+	os.WriteFile(goPath, []byte("go:\n  run: example.com/tool\n  version: v1.2.3\n"), 0644)
+	stdout.Reset()
+	if err := runSandboxShell(strings.NewReader(""), &stdout, &stderr, []string{goPath}); err == nil || !strings.Contains(err.Error(), "needs a container sandbox") || strings.Contains(stdout.String(), "go run") {
+		t.Errorf("Expected an error for a tool which runs on the host, got %v: %s", err, stdout.String())
+	}
+	if sandboxShell != "" {
+		t.Errorf("Expected the shell to be reset after clix shell, got %q", sandboxShell)
+	}
+}
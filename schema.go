@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// scriptAPIVersion is the current version of the script format.
+const scriptAPIVersion = "clix.dev/v1alpha1"
+
+// scriptConversions convert a script from an older version of the format to the next version, keyed by
+// the older apiVersion; "" is a script written before versioning. parseScript applies them in turn until
+// the script is at scriptAPIVersion.
+var scriptConversions = map[string]func(script map[string]any) (string, error){
+	"": func(script map[string]any) (string, error) {
+		// Unversioned scripts are v1alpha1 scripts, which were parsed without rejecting unknown fields
+		return scriptAPIVersion, nil
+	},
+}
+
+// parseScript parses a script, converting it from older versions of the format. Scripts which declare an
+// apiVersion must not have unknown fields; for unversioned scripts, unknown fields are a warning.
+func parseScript(data []byte) (Script, error) {
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return Script{}, fmt.Errorf("error parsing script file: %w", err)
+	}
+	apiVersion, _ := raw["apiVersion"].(string)
+	kind, _ := raw["kind"].(string)
+	if apiVersion != "" && kind != "Script" {
+		return Script{}, fmt.Errorf("error parsing script file: kind must be Script, not %q", kind)
+	}
+	versioned := apiVersion != ""
+
+	for apiVersion != scriptAPIVersion {
+		convert, ok := scriptConversions[apiVersion]
+		if !ok {
+			return Script{}, fmt.Errorf("error parsing script file: unsupported apiVersion %q (this clix supports %s); upgrade clix", apiVersion, scriptAPIVersion)
+		}
+		next, err := convert(raw)
+		if err != nil {
+			return Script{}, fmt.Errorf("error converting script from %q: %w", apiVersion, err)
+		}
+		log(2, "Converted script from %q to %s", apiVersion, next)
+		apiVersion = next
+	}
+	raw["apiVersion"] = apiVersion
+	raw["kind"] = "Script"
+
+	converted, err := yaml.Marshal(raw)
+	if err != nil {
+		return Script{}, err
+	}
+	var script Script
+	if err := yaml.UnmarshalStrict(converted, &script); err != nil {
+		if !strings.Contains(err.Error(), "unknown field") {
+			return Script{}, fmt.Errorf("error parsing script file: %w", err)
+		}
+		if versioned {
+			return Script{}, fmt.Errorf("error parsing script file: %s", unknownFieldError(err))
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %v; add apiVersion: %s and kind: Script to make this an error\n", unknownFieldError(err), scriptAPIVersion)
+		script = Script{}
+		if err := yaml.Unmarshal(converted, &script); err != nil {
+			return Script{}, fmt.Errorf("error parsing script file: %w", err)
+		}
+	}
+	return script, nil
+}
+
+// unknownFieldError trims the decoder's prefixes from an unknown field error.
+func unknownFieldError(err error) string {
+	msg := err.Error()
+	if i := strings.Index(msg, "unknown field"); i >= 0 {
+		return "script has " + msg[i:]
+	}
+	return msg
+}
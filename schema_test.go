@@ -0,0 +1,53 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseScript(t *testing.T) {
+	script, err := parseScript([]byte("apiVersion: clix.dev/v1alpha1\nkind: Script\nimage: alpine\nentrypoint: sh\n"))
+	if err != nil {
+		t.Fatalf("parseScript failed: %v", err)
+	}
+	if script.Image != "alpine" || script.Entrypoint != "sh" || script.APIVersion != scriptAPIVersion {
+		t.Errorf("Unexpected script %+v", script)
+	}
+
+	// Unversioned scripts are converted, and unknown fields are only a warning
+	script, err = parseScript([]byte("image: alpine\nentrypont: sh\n"))
+	if err != nil {
+		t.Fatalf("parseScript failed for an unversioned script: %v", err)
+	}
+	if script.Image != "alpine" || script.APIVersion != scriptAPIVersion || script.Kind != "Script" {
+		t.Errorf("Unexpected script %+v", script)
+	}
+
+	for _, tc := range []struct {
+		data string
+		want string
+	}{
+		{"apiVersion: clix.dev/v1alpha1\nkind: Script\nimage: alpine\nentrypont: sh\n", `unknown field "entrypont"`},
+		{"apiVersion: clix.dev/v1alpha1\nkind: Script\nmounts:\n- hostpth: /tmp\n", `script has unknown field "hostpth"`},
+		{"apiVersion: clix.dev/v1alpha1\nimage: alpine\n", "kind must be Script"},
+		{"apiVersion: clix.dev/v2\nkind: Script\n", `unsupported apiVersion "clix.dev/v2"`},
+	} {
+		if _, err := parseScript([]byte(tc.data)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("parseScript(%q) = %v, want an error containing %q", tc.data, err, tc.want)
+		}
+	}
+}
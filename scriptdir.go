@@ -0,0 +1,72 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// scriptDirectory returns the directory containing the script, following symlinks so that the files
+// next to the script are found when it is run through a link on the PATH.
+func scriptDirectory(scriptPath string) (string, error) {
+	if resolved, err := filepath.EvalSymlinks(scriptPath); err == nil {
+		scriptPath = resolved
+	}
+	return filepath.Abs(filepath.Dir(scriptPath))
+}
+
+// expandScriptDir replaces ${scriptDir} in the script's mounts (including their hostPaths and masks),
+// workdir, env values and args, and with
+// mountScriptDir: true, mounts the script's directory read-only at the same path, so that tools can read
+// the configs and templates which live next to the script.
+func expandScriptDir(script *Script, scriptPath string) error {
+	dir, err := scriptDirectory(scriptPath)
+	if err != nil {
+		return err
+	}
+
+	expand := func(s *string) {
+		*s = strings.ReplaceAll(*s, "${scriptDir}", dir)
+	}
+	expand(&script.Workdir)
+	for i := range script.Mounts {
+		m := &script.Mounts[i]
+		expand(&m.HostPath)
+		expand(&m.SandboxPath)
+		for j := range m.HostPaths {
+			expand(&m.HostPaths[j])
+		}
+		for j := range m.Mask {
+			expand(&m.Mask[j])
+		}
+	}
+	for i := range script.Env {
+		expand(&script.Env[i].Value)
+	}
+	if script.Args != nil {
+		for i := range script.Args.Prepend {
+			expand(&script.Args.Prepend[i])
+		}
+		for i := range script.Args.Append {
+			expand(&script.Args.Append[i])
+		}
+	}
+
+	if script.MountScriptDir {
+		script.Mounts = append(script.Mounts, Mount{HostPath: dir, ReadOnly: true})
+	}
+	return nil
+}
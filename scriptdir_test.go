@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandScriptDir(t *testing.T) {
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "scripts", "tool.clix")
+	if err := os.MkdirAll(filepath.Dir(scriptPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(scriptPath, []byte("image: tool:1.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Run through a link on the PATH, the script's directory is where the script lives
+	link := filepath.Join(dir, "tool")
+	if err := os.Symlink(scriptPath, link); err != nil {
+		t.Fatal(err)
+	}
+	scriptDir, _ := filepath.EvalSymlinks(filepath.Dir(scriptPath))
+
+	script := Script{
+		MountScriptDir: true,
+		Mounts: []Mount{
+			{HostPath: "${scriptDir}/templates", SandboxPath: "/templates", Mask: []string{"${scriptDir}/templates/secret.yaml"}},
+			{HostPaths: []string{"${scriptDir}/a.yaml", "${scriptDir}/b.yaml"}, SandboxPath: "/config"},
+		},
+		Env:  []EnvVar{{Name: "TOOL_CONFIG", Value: "${scriptDir}/tool.yaml"}},
+		Args: &ArgsConfig{Prepend: []string{"--config", "${scriptDir}/tool.yaml"}},
+	}
+	if err := expandScriptDir(&script, link); err != nil {
+		t.Fatalf("expandScriptDir failed: %v", err)
+	}
+
+	if got := script.Mounts[0].HostPath; got != scriptDir+"/templates" {
+		t.Errorf("Expected the mount under the script's directory, got %q", got)
+	}
+	if got := script.Mounts[0].Mask; len(got) != 1 || got[0] != scriptDir+"/templates/secret.yaml" {
+		t.Errorf("Expected the mask under the script's directory, got %q", got)
+	}
+	if got := script.Mounts[1].HostPaths; len(got) != 2 || got[0] != scriptDir+"/a.yaml" || got[1] != scriptDir+"/b.yaml" {
+		t.Errorf("Expected the hostPaths under the script's directory, got %q", got)
+	}
+	if got := script.Env[0].Value; got != scriptDir+"/tool.yaml" {
+		t.Errorf("Expected the env value under the script's directory, got %q", got)
+	}
+	if got := script.Args.Prepend[1]; got != scriptDir+"/tool.yaml" {
+		t.Errorf("Expected the arg under the script's directory, got %q", got)
+	}
+	if len(script.Mounts) != 3 || script.Mounts[2].HostPath != scriptDir || !script.Mounts[2].ReadOnly {
+		t.Errorf("Expected the script's directory to be mounted read-only, got %+v", script.Mounts)
+	}
+
+	script = Script{}
+	if err := expandScriptDir(&script, scriptPath); err != nil || len(script.Mounts) != 0 {
+		t.Errorf("Expected no mount without mountScriptDir, got %+v, %v", script.Mounts, err)
+	}
+}
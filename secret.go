@@ -0,0 +1,175 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// keychainService is the service (or label) under which clix stores secrets in the OS keychain.
+const keychainService = "clix"
+
+// keychainOS selects the keychain backend: the macOS Keychain, the Secret Service on Linux, or the
+// Windows Credential Manager.
+var keychainOS = runtime.GOOS
+
+// runSecret implements `clix secret <set|get|rm> NAME`, which manage secrets in the OS keychain for
+// valueFrom: {clixSecret: NAME}.
+func runSecret(stdin io.Reader, stdout, stderr io.Writer, args []string) error {
+	const usage = "usage: clix secret <set|get|rm> NAME"
+	if len(args) != 2 {
+		return fmt.Errorf(usage)
+	}
+	name := args[1]
+	if name == "" {
+		return fmt.Errorf(usage)
+	}
+	switch args[0] {
+	case "set":
+		value, err := readSecretValue(stdin, stderr, name)
+		if err != nil {
+			return err
+		}
+		return keychainSet(name, value)
+	case "get":
+		value, err := keychainGet(name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, value)
+		return nil
+	case "rm":
+		return keychainDelete(name)
+	}
+	return fmt.Errorf("unknown secret command %q; %s", args[0], usage)
+}
+
+// readSecretValue reads a secret without echoing it from a terminal, or from piped stdin.
+func readSecretValue(stdin io.Reader, stderr io.Writer, name string) (string, error) {
+	var value string
+	if f, ok := stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		fmt.Fprintf(stderr, "Value of secret %s: ", name)
+		data, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(stderr)
+		if err != nil {
+			return "", fmt.Errorf("reading secret: %w", err)
+		}
+		value = string(data)
+	} else {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("reading secret: %w", err)
+		}
+		value = strings.TrimRight(string(data), "\r\n")
+	}
+	if value == "" {
+		return "", fmt.Errorf("secret %s is empty", name)
+	}
+	return value, nil
+}
+
+// windowsVault is the PowerShell prelude which opens the Windows Credential Manager's password vault.
+const windowsVault = `[void][Windows.Security.Credentials.PasswordVault,Windows.Security.Credentials,ContentType=WindowsRuntime]; ` +
+	`$vault = New-Object Windows.Security.Credentials.PasswordVault; `
+
+// keychainSet stores a secret in the OS keychain, replacing any existing value. The value is passed on
+// stdin, so that it is not visible in the process list.
+func keychainSet(name, value string) error {
+	var err error
+	switch keychainOS {
+	case "darwin":
+		// security's interactive mode reads the command from stdin
+		quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+		command := fmt.Sprintf("add-generic-password -U -s %s -a \"%s\" -w \"%s\"\n", keychainService, quote.Replace(name), quote.Replace(value))
+		_, err = keychainCommand(name, command, "security", "-i")
+	case "linux":
+		_, err = keychainCommand(name, value, "secret-tool", "store", "--label", keychainService+": "+name, "service", keychainService, "account", name)
+	case "windows":
+		_, err = keychainCommand(name, value, "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsVault+
+			`$vault.Add((New-Object Windows.Security.Credentials.PasswordCredential('`+keychainService+`', $env:CLIX_SECRET_NAME, [Console]::In.ReadToEnd())))`)
+	default:
+		return fmt.Errorf("clix secrets are not supported on %s", keychainOS)
+	}
+	if err != nil {
+		return fmt.Errorf("storing secret %s: %w", name, err)
+	}
+	return nil
+}
+
+// keychainGet reads a secret from the OS keychain.
+func keychainGet(name string) (string, error) {
+	var out []byte
+	var err error
+	switch keychainOS {
+	case "darwin":
+		out, err = keychainCommand(name, "", "security", "find-generic-password", "-s", keychainService, "-a", name, "-w")
+	case "linux":
+		out, err = keychainCommand(name, "", "secret-tool", "lookup", "service", keychainService, "account", name)
+	case "windows":
+		out, err = keychainCommand(name, "", "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsVault+
+			`$c = $vault.Retrieve('`+keychainService+`', $env:CLIX_SECRET_NAME); $c.RetrievePassword(); [Console]::Out.Write($c.Password)`)
+	default:
+		return "", fmt.Errorf("clix secrets are not supported on %s", keychainOS)
+	}
+	value := strings.TrimRight(string(out), "\r\n")
+	if err != nil || value == "" {
+		return "", fmt.Errorf("secret %s not found in the keychain; set it with clix secret set %s", name, name)
+	}
+	return value, nil
+}
+
+// keychainDelete removes a secret from the OS keychain.
+func keychainDelete(name string) error {
+	var err error
+	switch keychainOS {
+	case "darwin":
+		_, err = keychainCommand(name, "", "security", "delete-generic-password", "-s", keychainService, "-a", name)
+	case "linux":
+		_, err = keychainCommand(name, "", "secret-tool", "clear", "service", keychainService, "account", name)
+	case "windows":
+		_, err = keychainCommand(name, "", "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsVault+
+			`$vault.Remove($vault.Retrieve('`+keychainService+`', $env:CLIX_SECRET_NAME))`)
+	default:
+		return fmt.Errorf("clix secrets are not supported on %s", keychainOS)
+	}
+	if err != nil {
+		return fmt.Errorf("removing secret %s: %w", name, err)
+	}
+	return nil
+}
+
+// keychainCommand runs a keychain CLI with input on stdin. The name of the secret is also passed in
+// CLIX_SECRET_NAME, for PowerShell, so that it doesn't need quoting.
+func keychainCommand(secret, input, name string, args ...string) ([]byte, error) {
+	cmd := execCommand(name, args...)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Env = append(cmd.Environ(), "CLIX_SECRET_NAME="+secret)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return out, fmt.Errorf("%s failed: %s", name, msg)
+		}
+		return out, fmt.Errorf("%s failed: %w", name, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,64 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRunSecret(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	originalOS := keychainOS
+	defer func() { keychainOS = originalOS }()
+	keychainOS = "linux"
+	t.Setenv("MOCK_SECRETS", t.TempDir())
+
+	var stdout, stderr bytes.Buffer
+	if err := runSecret(strings.NewReader("s3cr3t\n"), &stdout, &stderr, []string{"set", "API_TOKEN"}); err != nil {
+		t.Fatalf("secret set failed: %v", err)
+	}
+	if err := runSecret(strings.NewReader(""), &stdout, &stderr, []string{"get", "API_TOKEN"}); err != nil {
+		t.Fatalf("secret get failed: %v", err)
+	}
+	if stdout.String() != "s3cr3t\n" {
+		t.Errorf("Expected the secret, got %q", stdout.String())
+	}
+
+	script := Script{Env: []EnvVar{{Name: "API_TOKEN", ValueFrom: &EnvValueSource{ClixSecret: "API_TOKEN"}}}}
+	if err := resolveEnvValues(nil, io.Discard, &script); err != nil || script.Env[0].Value != "s3cr3t" {
+		t.Errorf("Expected valueFrom clixSecret to read the secret, got %q, %v", script.Env[0].Value, err)
+	}
+
+	if err := runSecret(strings.NewReader(""), &stdout, &stderr, []string{"rm", "API_TOKEN"}); err != nil {
+		t.Fatalf("secret rm failed: %v", err)
+	}
+	err := runSecret(strings.NewReader(""), &stdout, &stderr, []string{"get", "API_TOKEN"})
+	if err == nil || !strings.Contains(err.Error(), "clix secret set API_TOKEN") {
+		t.Errorf("Expected an error suggesting clix secret set, got %v", err)
+	}
+
+	if err := runSecret(strings.NewReader(""), &stdout, &stderr, []string{"set", "EMPTY"}); err == nil {
+		t.Errorf("Expected an error for an empty secret")
+	}
+	keychainOS = "plan9"
+	if err := runSecret(strings.NewReader(""), &stdout, &stderr, []string{"get", "API_TOKEN"}); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("Expected an error on an unsupported OS, got %v", err)
+	}
+}
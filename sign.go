@@ -0,0 +1,257 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// scriptSignature is the detached signature of a script, stored next to it as <script>.sig
+type scriptSignature struct {
+	// PublicKey is the base64 encoded ed25519 public key of the signer
+	PublicKey string `json:"publicKey"`
+	// Signature is the base64 encoded ed25519 signature of the script contents
+	Signature string `json:"signature"`
+}
+
+// configDir returns the directory of the clix user configuration.
+func configDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "clix"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home dir: %w", err)
+	}
+	return filepath.Join(home, ".config", "clix"), nil
+}
+
+func signaturePath(scriptPath string) string {
+	return scriptPath + ".sig"
+}
+
+// signingKeyPath returns the path of the private key used by `clix sign`, overridable with CLIX_SIGNING_KEY.
+func signingKeyPath() (string, error) {
+	if path := os.Getenv("CLIX_SIGNING_KEY"); path != "" {
+		return path, nil
+	}
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "signing-key.pem"), nil
+}
+
+func trustedKeysPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "trusted-keys"), nil
+}
+
+// runSign implements `clix sign <script>...`
+func runSign(stdout, stderr io.Writer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: clix sign <script>...")
+	}
+
+	key, created, err := loadOrCreateSigningKey()
+	if err != nil {
+		return err
+	}
+	publicKey := base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+	if created {
+		fmt.Fprintf(stderr, "Created signing key; public key: %s\n", publicKey)
+		fmt.Fprintf(stderr, "Add the public key to the trusted-keys file of users who verify your scripts.\n")
+		if err := trustKey(publicKey); err != nil {
+			return err
+		}
+	}
+
+	for _, scriptPath := range args {
+		data, err := os.ReadFile(scriptPath)
+		if err != nil {
+			return fmt.Errorf("error reading script file: %w", err)
+		}
+		sig := scriptSignature{
+			PublicKey: publicKey,
+			Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)),
+		}
+		out, err := json.MarshalIndent(sig, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(signaturePath(scriptPath), append(out, '\n'), 0644); err != nil {
+			return fmt.Errorf("error writing signature: %w", err)
+		}
+		fmt.Fprintf(stdout, "Signed %s (%s)\n", scriptPath, signaturePath(scriptPath))
+	}
+	return nil
+}
+
+func loadOrCreateSigningKey() (ed25519.PrivateKey, bool, error) {
+	path, err := signingKeyPath()
+	if err != nil {
+		return nil, false, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return nil, false, fmt.Errorf("signing key %s is not PEM encoded", path)
+		}
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, false, fmt.Errorf("error parsing signing key %s: %w", path, err)
+		}
+		edKey, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, false, fmt.Errorf("signing key %s is not an ed25519 key", path)
+		}
+		return edKey, false, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, false, fmt.Errorf("error reading signing key: %w", err)
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, false, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, false, err
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		return nil, false, fmt.Errorf("error writing signing key: %w", err)
+	}
+	return key, true, nil
+}
+
+// trustedKeys returns the public keys (base64) listed in the trusted-keys file, one per line.
+func trustedKeys() ([]string, error) {
+	path, err := trustedKeysPath()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var keys []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, strings.Fields(line)[0])
+	}
+	return keys, scanner.Err()
+}
+
+func trustKey(publicKey string) error {
+	keys, err := trustedKeys()
+	if err != nil {
+		return err
+	}
+	if contains(keys, publicKey) {
+		return nil
+	}
+	path, err := trustedKeysPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, publicKey); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// signatureRequired reports whether scripts must be signed by a trusted key before they are run.
+// Verification is opt-in with CLIX_VERIFY_SIGNATURES for local scripts.
+func signatureRequired() bool {
+	return os.Getenv("CLIX_VERIFY_SIGNATURES") != ""
+}
+
+// verifyScriptSignature checks that the script data has a valid signature from a trusted key.
+func verifyScriptSignature(scriptPath string, data []byte) error {
+	sigData, err := os.ReadFile(signaturePath(scriptPath))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("script %s is not signed (no %s)", scriptPath, signaturePath(scriptPath))
+	}
+	if err != nil {
+		return fmt.Errorf("error reading signature: %w", err)
+	}
+	return verifySignature(scriptPath, data, sigData)
+}
+
+func verifySignature(scriptPath string, data, sigData []byte) error {
+	var sig scriptSignature
+	if err := json.Unmarshal(sigData, &sig); err != nil {
+		return fmt.Errorf("error parsing signature of %s: %w", scriptPath, err)
+	}
+
+	keys, err := trustedKeys()
+	if err != nil {
+		return fmt.Errorf("error reading trusted keys: %w", err)
+	}
+	if !contains(keys, sig.PublicKey) {
+		return fmt.Errorf("script %s is signed by an untrusted key %s", scriptPath, sig.PublicKey)
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(sig.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key in signature of %s", scriptPath)
+	}
+	signature, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature of %s: %w", scriptPath, err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(publicKey), data, signature) {
+		return fmt.Errorf("signature verification failed for %s: the script has been modified since it was signed", scriptPath)
+	}
+	log(1, "Verified signature of %s", scriptPath)
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSignAndVerifyScript(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("CLIX_SIGNING_KEY", "")
+
+	scriptPath := filepath.Join(t.TempDir(), "tool")
+	data := []byte("#!/usr/bin/env clix\nimage: alpine\n")
+	if err := os.WriteFile(scriptPath, data, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := verifyScriptSignature(scriptPath, data); err == nil || !strings.Contains(err.Error(), "not signed") {
+		t.Errorf("Expected unsigned script to be rejected, got %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := runSign(&stdout, &stderr, []string{scriptPath}); err != nil {
+		t.Fatalf("runSign failed: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "Created signing key") {
+		t.Errorf("Expected signing key to be created, got %q", stderr.String())
+	}
+
+	if err := verifyScriptSignature(scriptPath, data); err != nil {
+		t.Errorf("Expected signed script to verify, got %v", err)
+	}
+
+	tampered := []byte("#!/usr/bin/env clix\nimage: evil\n")
+	if err := verifyScriptSignature(scriptPath, tampered); err == nil || !strings.Contains(err.Error(), "modified") {
+		t.Errorf("Expected tampered script to be rejected, got %v", err)
+	}
+
+	// Signatures from keys which are not trusted are rejected
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := verifyScriptSignature(scriptPath, data); err == nil || !strings.Contains(err.Error(), "untrusted") {
+		t.Errorf("Expected untrusted key to be rejected, got %v", err)
+	}
+}
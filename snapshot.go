@@ -0,0 +1,432 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// snapshotSet tracks the copies made for `mode: snapshot` mounts.
+type snapshotSet struct {
+	// mounts are the script mounts, with snapshot mounts pointing at their copies
+	mounts    []Mount
+	snapshots []snapshot
+	tmpDir    string
+	keep      bool
+}
+
+type snapshot struct {
+	original string
+	copy     string
+	// masked are the paths hidden by masks, relative to the original, which are neither copied nor synced
+	masked []string
+}
+
+// treeChange is a difference between the original host path and its snapshot.
+type treeChange struct {
+	Path string
+	// Kind is A (added), M (modified) or D (deleted)
+	Kind byte
+}
+
+// prepareSnapshots copies the host paths of snapshot mounts, returning nil if there are no snapshot mounts.
+func prepareSnapshots(mounts []Mount) (*snapshotSet, error) {
+	set := &snapshotSet{}
+	for _, m := range mounts {
+		if m.Mode == "" {
+			set.mounts = append(set.mounts, m)
+			continue
+		}
+		if m.Mode != "snapshot" {
+			return nil, fmt.Errorf("unknown mount mode %q for %s", m.Mode, m.HostPath)
+		}
+		if usesCacheDir([]Mount{m}) {
+			return nil, fmt.Errorf("snapshot mode is not supported for cacheDir mounts (%s)", m.HostPath)
+		}
+		if m.ReadOnly {
+			return nil, fmt.Errorf("snapshot mode is for tools which write to the mount, but %s is readOnly", m.HostPath)
+		}
+
+		resolved, err := resolveMounts([]Mount{m}, "")
+		if err != nil {
+			return nil, err
+		}
+		if len(resolved) == 0 {
+			return nil, fmt.Errorf("snapshot mount %s does not exist", m.HostPath)
+		}
+		r, masks := resolved[0], resolved[1:]
+		if len(masks) > 0 && !masks[0].masked {
+			return nil, fmt.Errorf("snapshot mode requires a single directory, %s matches several paths", m.HostPath)
+		}
+		if info, err := os.Stat(r.HostPath); err != nil {
+			return nil, err
+		} else if !info.IsDir() {
+			return nil, fmt.Errorf("snapshot mode requires a directory, %s is not a directory", r.HostPath)
+		}
+
+		if set.tmpDir == "" {
+			set.tmpDir, err = os.MkdirTemp("", "clix-snapshot-*")
+			if err != nil {
+				return nil, err
+			}
+		}
+		// The masked paths are left out of the copy, which may be kept after the run
+		var masked []string
+		for _, mask := range masks {
+			rel, err := filepath.Rel(filepath.FromSlash(r.SandboxPath), filepath.FromSlash(mask.SandboxPath))
+			if err != nil {
+				return nil, err
+			}
+			masked = append(masked, rel)
+		}
+		copyDir := filepath.Join(set.tmpDir, fmt.Sprintf("%d", len(set.snapshots)))
+		log(1, "Snapshotting %s to %s", r.HostPath, copyDir)
+		if err := copyTree(r.HostPath, copyDir, masked); err != nil {
+			set.cleanup()
+			return nil, fmt.Errorf("copying %s: %w", r.HostPath, err)
+		}
+
+		set.snapshots = append(set.snapshots, snapshot{original: r.HostPath, copy: copyDir, masked: masked})
+		set.mounts = append(set.mounts, Mount{HostPath: copyDir, SandboxPath: r.SandboxPath})
+		set.mounts = append(set.mounts, masks...)
+	}
+	if len(set.snapshots) == 0 {
+		return nil, nil
+	}
+	return set, nil
+}
+
+func (s *snapshotSet) cleanup() {
+	if s.tmpDir != "" && !s.keep {
+		os.RemoveAll(s.tmpDir)
+	}
+}
+
+// review shows the changes the tool made to each snapshot, and copies them back to the host if the user agrees.
+// Unapproved changes are kept in the snapshot directory so that they can be inspected.
+func (s *snapshotSet) review(stdin io.Reader, stderr io.Writer) error {
+	reader := bufio.NewReader(stdin)
+	for _, snap := range s.snapshots {
+		changes, err := diffTrees(snap.original, snap.copy, snap.masked)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			log(1, "No changes to snapshot of %s", snap.original)
+			continue
+		}
+
+		fmt.Fprintf(stderr, "The tool changed the snapshot of %s:\n", snap.original)
+		for _, c := range changes {
+			fmt.Fprintf(stderr, "  %c %s\n", c.Kind, c.Path)
+		}
+		showDiff(stderr, snap.original, snap.copy, changes)
+
+		apply := false
+		if isTerminal(stdin) {
+			fmt.Fprintf(stderr, "Apply these changes to %s? [y/N] ", snap.original)
+			answer, _ := reader.ReadString('\n')
+			answer = strings.ToLower(strings.TrimSpace(answer))
+			apply = answer == "y" || answer == "yes"
+		}
+		if !apply {
+			s.keep = true
+			fmt.Fprintf(stderr, "Changes not applied; the modified copy is at %s\n", snap.copy)
+			continue
+		}
+		if err := applyChanges(snap.copy, snap.original, changes, snap.masked); err != nil {
+			return fmt.Errorf("applying changes to %s: %w", snap.original, err)
+		}
+	}
+	return nil
+}
+
+// showDiff prints a unified diff of the changed files, if git is available to produce one. Only the
+// changes are diffed, so that masked paths aren't shown.
+func showDiff(w io.Writer, original, copy string, changes []treeChange) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return
+	}
+	for _, c := range changes {
+		before, after := filepath.Join(original, c.Path), filepath.Join(copy, c.Path)
+		switch c.Kind {
+		case 'A':
+			before = os.DevNull
+		case 'D':
+			after = os.DevNull
+		}
+		if info, err := os.Lstat(before); err == nil && info.IsDir() {
+			continue
+		}
+		if info, err := os.Lstat(after); err == nil && info.IsDir() {
+			continue
+		}
+		cmd := execCommand("git", "diff", "--no-index", "--no-color", "--", before, after)
+		cmd.Stdout = w
+		cmd.Stderr = w
+		// git diff exits non-zero when there are differences
+		_ = cmd.Run()
+	}
+}
+
+// isMasked reports whether the relative path rel is one of the masked paths, or beneath one.
+func isMasked(rel string, masked []string) bool {
+	for _, m := range masked {
+		if isWithin(m, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsMasked reports whether the relative path rel has a masked path beneath it.
+func containsMasked(rel string, masked []string) bool {
+	for _, m := range masked {
+		if isWithin(rel, m) {
+			return true
+		}
+	}
+	return false
+}
+
+type treeEntry struct {
+	mode fs.FileMode
+	path string
+}
+
+// listTree lists the entries beneath root, except the masked paths.
+func listTree(root string, masked []string) (map[string]treeEntry, error) {
+	entries := make(map[string]treeEntry)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		if isMasked(rel, masked) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries[rel] = treeEntry{mode: info.Mode(), path: p}
+		return nil
+	})
+	return entries, err
+}
+
+// diffTrees returns the changes needed to turn the original tree into the modified tree, ignoring the
+// masked paths.
+func diffTrees(original, modified string, masked []string) ([]treeChange, error) {
+	before, err := listTree(original, masked)
+	if err != nil {
+		return nil, err
+	}
+	after, err := listTree(modified, masked)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []treeChange
+	for rel, a := range after {
+		b, found := before[rel]
+		if !found {
+			changes = append(changes, treeChange{Path: rel, Kind: 'A'})
+			continue
+		}
+		same, err := sameEntry(b, a)
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			changes = append(changes, treeChange{Path: rel, Kind: 'M'})
+		}
+	}
+	for rel := range before {
+		if _, found := after[rel]; !found {
+			changes = append(changes, treeChange{Path: rel, Kind: 'D'})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+func sameEntry(a, b treeEntry) (bool, error) {
+	if a.mode.Type() != b.mode.Type() {
+		return false, nil
+	}
+	switch {
+	case a.mode.IsDir():
+		return true, nil
+	case a.mode&fs.ModeSymlink != 0:
+		aTarget, err := os.Readlink(a.path)
+		if err != nil {
+			return false, err
+		}
+		bTarget, err := os.Readlink(b.path)
+		if err != nil {
+			return false, err
+		}
+		return aTarget == bTarget, nil
+	case a.mode.IsRegular():
+		if a.mode.Perm() != b.mode.Perm() {
+			return false, nil
+		}
+		aData, err := os.ReadFile(a.path)
+		if err != nil {
+			return false, err
+		}
+		bData, err := os.ReadFile(b.path)
+		if err != nil {
+			return false, err
+		}
+		return bytes.Equal(aData, bData), nil
+	}
+	// Other file types (devices, sockets) are not synced
+	return true, nil
+}
+
+// applyChanges copies the changes from the snapshot back to the original tree, leaving the masked
+// paths as they are.
+func applyChanges(from, to string, changes []treeChange, masked []string) error {
+	// Deletions are applied deepest first, so directories are empty when they are removed
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		if isMasked(c.Path, masked) {
+			continue
+		}
+		if c.Kind == 'D' && containsMasked(c.Path, masked) {
+			// The directory still holds the masked paths
+			log(1, "Not deleting %s, which contains masked paths", c.Path)
+			continue
+		}
+		if c.Kind == 'D' {
+			if err := os.RemoveAll(filepath.Join(to, c.Path)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, c := range changes {
+		if c.Kind == 'D' || isMasked(c.Path, masked) {
+			continue
+		}
+		src := filepath.Join(from, c.Path)
+		dst := filepath.Join(to, c.Path)
+		info, err := os.Lstat(src)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.RemoveAll(dst); err != nil {
+			return err
+		}
+		if err := copyEntry(src, dst, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyTree copies the directory (or file) src to dst, preserving permissions and symlinks, except the
+// masked paths (relative to src).
+func copyTree(src, dst string, masked []string) error {
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if isMasked(rel, masked) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		return copyEntry(p, target, info)
+	})
+}
+
+// copyEntryPath copies the file src to dst, following symlinks.
+func copyEntryPath(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	return copyEntry(src, dst, info)
+}
+
+func copyEntry(src, dst string, info fs.FileInfo) error {
+	switch {
+	case info.Mode()&fs.ModeSymlink != 0:
+		link, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(link, dst)
+	case info.Mode().IsRegular():
+		in, err := os.Open(src)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	}
+	log(1, "Skipping copy of special file %s", src)
+	return nil
+}
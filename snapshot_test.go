@@ -0,0 +1,172 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotMounts(t *testing.T) {
+	original := t.TempDir()
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(filepath.Join(original, "keep.txt"), "keep")
+	writeFile(filepath.Join(original, "modify.txt"), "before")
+	writeFile(filepath.Join(original, "dir", "delete.txt"), "delete")
+
+	snapshots, err := prepareSnapshots([]Mount{
+		{HostPath: "/other"},
+		{HostPath: original, SandboxPath: "/src", Mode: "snapshot"},
+	})
+	if err != nil {
+		t.Fatalf("prepareSnapshots failed: %v", err)
+	}
+	defer snapshots.cleanup()
+
+	if len(snapshots.mounts) != 2 || snapshots.mounts[0].HostPath != "/other" {
+		t.Fatalf("Expected regular mounts to be unchanged, got %v", snapshots.mounts)
+	}
+	copy := snapshots.mounts[1].HostPath
+	if copy == original || snapshots.mounts[1].SandboxPath != "/src" {
+		t.Fatalf("Expected snapshot mount of a copy at /src, got %v", snapshots.mounts[1])
+	}
+
+	// Simulate the tool modifying the snapshot
+	writeFile(filepath.Join(copy, "modify.txt"), "after")
+	writeFile(filepath.Join(copy, "added.txt"), "added")
+	if err := os.RemoveAll(filepath.Join(copy, "dir")); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := diffTrees(original, copy, nil)
+	if err != nil {
+		t.Fatalf("diffTrees failed: %v", err)
+	}
+	expected := []treeChange{
+		{Path: "added.txt", Kind: 'A'},
+		{Path: "dir", Kind: 'D'},
+		{Path: filepath.Join("dir", "delete.txt"), Kind: 'D'},
+		{Path: "modify.txt", Kind: 'M'},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("diffTrees = %v, want %v", changes, expected)
+	}
+
+	// Without a terminal the changes are not applied
+	var stderr bytes.Buffer
+	if err := snapshots.review(strings.NewReader(""), &stderr); err != nil {
+		t.Fatalf("review failed: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "Changes not applied") {
+		t.Errorf("Expected changes not to be applied, got %q", stderr.String())
+	}
+	if data, _ := os.ReadFile(filepath.Join(original, "modify.txt")); string(data) != "before" {
+		t.Errorf("Expected original to be unchanged, got %q", data)
+	}
+
+	if err := applyChanges(copy, original, changes, nil); err != nil {
+		t.Fatalf("applyChanges failed: %v", err)
+	}
+	changes, err = diffTrees(original, copy, nil)
+	if err != nil {
+		t.Fatalf("diffTrees failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes after applying, got %v", changes)
+	}
+
+	// The copy is kept for inspection, as the changes were not approved
+	snapshots.cleanup()
+	if _, err := os.Stat(copy); err != nil {
+		t.Errorf("Expected snapshot copy to be kept: %v", err)
+	}
+	os.RemoveAll(snapshots.tmpDir)
+}
+
+func TestSnapshotMasks(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	original := t.TempDir()
+	for path, content := range map[string]string{"app.txt": "before", filepath.Join("config", "creds.json"): "secret"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(original, path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(original, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshots, err := prepareSnapshots([]Mount{{HostPath: original, SandboxPath: "/src", Mode: "snapshot", Mask: []string{"config/creds.json"}}})
+	if err != nil {
+		t.Fatalf("prepareSnapshots failed: %v", err)
+	}
+	defer os.RemoveAll(snapshots.tmpDir)
+	copy := snapshots.snapshots[0].copy
+	if _, err := os.Stat(filepath.Join(copy, "config", "creds.json")); !os.IsNotExist(err) {
+		t.Fatalf("Expected the masked file not to be copied, got %v", err)
+	}
+	if len(snapshots.mounts) != 2 || !snapshots.mounts[1].masked || snapshots.mounts[1].SandboxPath != "/src/config/creds.json" {
+		t.Fatalf("Expected the snapshot to keep its mask, got %+v", snapshots.mounts)
+	}
+
+	// The container engine creates the mask's mount point in the copy, and the tool changes a file
+	if err := os.WriteFile(filepath.Join(copy, "config", "creds.json"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(copy, "app.txt"), []byte("after"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var stderr bytes.Buffer
+	if err := snapshots.review(strings.NewReader(""), &stderr); err != nil {
+		t.Fatalf("review failed: %v", err)
+	}
+	if strings.Contains(stderr.String(), "creds.json") || strings.Contains(stderr.String(), "secret") {
+		t.Errorf("Expected the masked file not to be reviewed, got %q", stderr.String())
+	}
+
+	// Deleting the directory which contains the mask leaves the masked file
+	if err := os.RemoveAll(filepath.Join(copy, "config")); err != nil {
+		t.Fatal(err)
+	}
+	masked := snapshots.snapshots[0].masked
+	changes, err := diffTrees(original, copy, masked)
+	if err != nil {
+		t.Fatalf("diffTrees failed: %v", err)
+	}
+	expected := []treeChange{{Path: "app.txt", Kind: 'M'}, {Path: "config", Kind: 'D'}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("diffTrees = %v, want %v", changes, expected)
+	}
+	if err := applyChanges(copy, original, changes, masked); err != nil {
+		t.Fatalf("applyChanges failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(original, "config", "creds.json")); string(data) != "secret" {
+		t.Errorf("Expected the masked file to be untouched, got %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(original, "app.txt")); string(data) != "after" {
+		t.Errorf("Expected the change to be applied, got %q", data)
+	}
+}
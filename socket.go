@@ -0,0 +1,61 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sandboxDockerSocket is where the container engine socket is mounted in the sandbox.
+const sandboxDockerSocket = "/var/run/docker.sock"
+
+// mountDockerSocket mounts the container engine socket into the sandbox, for scripts with dockerSocket: true.
+// Access to the socket is root-equivalent on the host, so the user must allow it in their configuration.
+func mountDockerSocket(stderr io.Writer, script *Script, config *UserConfig) error {
+	if !script.DockerSocket {
+		return nil
+	}
+	if !config.AllowDockerSocket {
+		return fmt.Errorf("script requests access to the docker socket, which gives it control of the host; set allowDockerSocket: true in the clix configuration to allow it")
+	}
+
+	socket := hostDockerSocket(selectedSandbox(*script))
+	if _, err := os.Stat(socket); err != nil {
+		return fmt.Errorf("docker socket not found: %w", err)
+	}
+	fmt.Fprintf(stderr, "Warning: giving the tool access to the docker socket %s; it can control the host as root\n", socket)
+
+	script.Mounts = append(script.Mounts, Mount{HostPath: socket, SandboxPath: sandboxDockerSocket})
+	script.Env = mergeEnvVar(script.Env, EnvVar{Name: "DOCKER_HOST", Value: "unix://" + sandboxDockerSocket})
+	return nil
+}
+
+// hostDockerSocket returns the path of the socket of the sandbox's container engine.
+func hostDockerSocket(sandbox string) string {
+	if sandbox == "podman" {
+		if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" && os.Geteuid() != 0 {
+			return filepath.Join(dir, "podman", "podman.sock")
+		}
+		return "/run/podman/podman.sock"
+	}
+	if socket, ok := strings.CutPrefix(os.Getenv("DOCKER_HOST"), "unix://"); ok {
+		return socket
+	}
+	return "/var/run/docker.sock"
+}
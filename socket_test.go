@@ -0,0 +1,75 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMountDockerSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	if err := os.WriteFile(socket, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCKER_HOST", "unix://"+socket)
+	t.Setenv("CLIX_SANDBOX", "docker")
+
+	script := Script{Image: "kindest/kind", DockerSocket: true}
+	if err := mountDockerSocket(&bytes.Buffer{}, &script, &UserConfig{}); err == nil || !strings.Contains(err.Error(), "allowDockerSocket") {
+		t.Errorf("Expected the socket to need allowing, got %v", err)
+	}
+
+	var stderr bytes.Buffer
+	if err := mountDockerSocket(&stderr, &script, &UserConfig{AllowDockerSocket: true}); err != nil {
+		t.Fatalf("mountDockerSocket failed: %v", err)
+	}
+	if len(script.Mounts) != 1 || !reflect.DeepEqual(script.Mounts[0], Mount{HostPath: socket, SandboxPath: "/var/run/docker.sock"}) {
+		t.Errorf("Unexpected mounts %+v", script.Mounts)
+	}
+	if len(script.Env) != 1 || script.Env[0].Value != "unix:///var/run/docker.sock" {
+		t.Errorf("Unexpected env %+v", script.Env)
+	}
+	if !strings.Contains(stderr.String(), "Warning:") {
+		t.Errorf("Expected a warning, got %q", stderr.String())
+	}
+
+	t.Setenv("DOCKER_HOST", "unix://"+filepath.Join(t.TempDir(), "missing.sock"))
+	script = Script{Image: "kindest/kind", DockerSocket: true}
+	if err := mountDockerSocket(&bytes.Buffer{}, &script, &UserConfig{AllowDockerSocket: true}); err == nil {
+		t.Errorf("Expected an error for a missing socket")
+	}
+}
+
+func TestHostDockerSocket(t *testing.T) {
+	t.Setenv("DOCKER_HOST", "")
+	if got := hostDockerSocket("docker"); got != "/var/run/docker.sock" {
+		t.Errorf("hostDockerSocket(docker) = %q", got)
+	}
+	t.Setenv("DOCKER_HOST", "tcp://build-host:2376")
+	if got := hostDockerSocket("docker"); got != "/var/run/docker.sock" {
+		t.Errorf("hostDockerSocket(docker) with a tcp DOCKER_HOST = %q", got)
+	}
+	if os.Geteuid() != 0 {
+		t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+		if got := hostDockerSocket("podman"); got != "/run/user/1000/podman/podman.sock" {
+			t.Errorf("hostDockerSocket(podman) = %q", got)
+		}
+	}
+}
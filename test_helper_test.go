@@ -15,10 +15,15 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 // fakeExecCommand mocks exec.Command for testing.
@@ -49,21 +54,211 @@ func TestHelperProcess(t *testing.T) {
 
 	cmd, cmdArgs := args[0], args[1:]
 
+	if calls := os.Getenv("MOCK_CALLS"); calls != "" {
+		// Record the command line, for tests which check the sequence of commands
+		f, err := os.OpenFile(calls, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintln(f, strings.Join(args, " "))
+			f.Close()
+		}
+	}
+
 	behavior := os.Getenv("MOCK_BEHAVIOR")
+	if behavior == "exit_3" {
+		os.Exit(3)
+	}
+	if behavior == "echo_env" {
+		fmt.Println(strings.Join(os.Environ(), "\n"))
+		os.Exit(0)
+	}
+	if behavior == "echo_args" {
+		// Echo the arguments, failing if any of them is "fail"
+		fmt.Printf("args: %v\n", cmdArgs)
+		for _, arg := range cmdArgs {
+			if arg == "fail" {
+				os.Exit(4)
+			}
+		}
+		os.Exit(0)
+	}
 
 	switch cmd {
+	case "clix-sandbox-test":
+		// Echo the plugin request
+		request, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil || behavior == "plugin_failure" {
+			os.Exit(pluginFailureCode)
+		}
+		fmt.Printf("request: %s", request)
+		os.Exit(0)
 	case "git":
+		if len(cmdArgs) >= 3 && cmdArgs[0] == "ls-remote" && cmdArgs[1] == "--tags" {
+			fmt.Printf("1111111111111111\trefs/tags/v1.2.0\n2222222222222222\trefs/tags/v1.10.0\n3333333333333333\trefs/tags/v2.0.0-rc1\n")
+			os.Exit(0)
+		}
 		if len(cmdArgs) >= 2 && cmdArgs[0] == "ls-remote" {
 			// Mock ls-remote: return a dummy hash
 			fmt.Printf("abcdef1234567890\trefs/heads/main\n")
 			os.Exit(0)
 		}
+		if len(cmdArgs) >= 2 && cmdArgs[0] == "rev-parse" && cmdArgs[1] == "HEAD" {
+			fmt.Printf("0123456789abcdef0123456789abcdef01234567\n")
+			os.Exit(0)
+		}
+		if len(cmdArgs) >= 2 && cmdArgs[0] == "branch" && cmdArgs[1] == "--show-current" {
+			fmt.Printf("main\n")
+			os.Exit(0)
+		}
 		if len(cmdArgs) >= 1 && cmdArgs[0] == "clone" {
 			// Mock clone: success
 			fmt.Fprintf(os.Stderr, "Mock cloning...\n")
 			os.Exit(0)
 		}
+	case "go":
+		if len(cmdArgs) >= 5 && cmdArgs[0] == "list" && cmdArgs[1] == "-m" {
+			// Mock the module proxy, which only has example.com/tool
+			if cmdArgs[4] != "example.com/tool@latest" {
+				fmt.Fprintf(os.Stderr, "go: module %s: not found\n", cmdArgs[4])
+				os.Exit(1)
+			}
+			fmt.Printf("v1.4.2\n")
+			os.Exit(0)
+		}
+		if len(cmdArgs) >= 2 && cmdArgs[0] == "install" && os.Getenv("GOBIN") != "" {
+			// Mock go install: write the binary, named after the package
+			target, _, _ := strings.Cut(cmdArgs[1], "@")
+			os.WriteFile(filepath.Join(os.Getenv("GOBIN"), filepath.Base(target)), []byte("#!/bin/sh\n"), 0755)
+			os.Exit(0)
+		}
+	case "sops":
+		if len(cmdArgs) >= 1 && cmdArgs[0] == "--decrypt" {
+			if behavior == "sops_no_key" {
+				fmt.Fprintf(os.Stderr, "Failed to get the data key required to decrypt the SOPS file.\n")
+				os.Exit(128)
+			}
+			fmt.Printf(`{"API_TOKEN":"decrypted-token","PORT":8080,"DEBUG":false}`)
+			os.Exit(0)
+		}
+	case "op":
+		if len(cmdArgs) >= 1 && cmdArgs[0] == "read" {
+			ref := cmdArgs[len(cmdArgs)-1]
+			if ref != "op://dev/github/token" {
+				fmt.Fprintf(os.Stderr, "[ERROR] could not read secret '%s': could not find item\n", ref)
+				os.Exit(1)
+			}
+			fmt.Printf("ghp_mock")
+			os.Exit(0)
+		}
+	case "gh":
+		if len(cmdArgs) >= 2 && cmdArgs[0] == "auth" && cmdArgs[1] == "token" {
+			if behavior == "gh_hangs" {
+				time.Sleep(time.Minute)
+			}
+			if behavior == "gh_logged_out" {
+				fmt.Fprintf(os.Stderr, "no oauth token found for github.com\n")
+				os.Exit(1)
+			}
+			fmt.Printf("gho_mock\n")
+			os.Exit(0)
+		}
+	case "aws":
+		if len(cmdArgs) >= 2 && (cmdArgs[0] == "secretsmanager" || cmdArgs[0] == "ssm") {
+			if behavior == "aws_no_credentials" {
+				fmt.Fprintf(os.Stderr, "Unable to locate credentials. You can configure credentials by running \"aws configure\".\n")
+				os.Exit(253)
+			}
+			fmt.Printf("aws-mock-secret\n")
+			os.Exit(0)
+		}
+	case "resolvectl":
+		if len(cmdArgs) == 1 && cmdArgs[0] == "dns" {
+			fmt.Printf("Global:\nLink 2 (eth0): 192.168.1.1\nLink 5 (tun0): 10.8.0.1 10.8.0.2\n")
+		} else if len(cmdArgs) == 1 && cmdArgs[0] == "domain" {
+			fmt.Printf("Global:\nLink 2 (eth0): lan\nLink 5 (tun0): corp.example.com ~example.internal ~.\n")
+		}
+		os.Exit(0)
+	case "secret-tool":
+		// Mock the Secret Service with a file per account in MOCK_SECRETS
+		if len(cmdArgs) >= 1 {
+			path := filepath.Join(os.Getenv("MOCK_SECRETS"), cmdArgs[len(cmdArgs)-1])
+			switch cmdArgs[0] {
+			case "store":
+				value, _ := io.ReadAll(os.Stdin)
+				if err := os.WriteFile(path, value, 0600); err != nil {
+					os.Exit(1)
+				}
+			case "lookup":
+				value, err := os.ReadFile(path)
+				if err != nil {
+					os.Exit(1)
+				}
+				os.Stdout.Write(value)
+			case "clear":
+				os.Remove(path)
+			}
+			os.Exit(0)
+		}
+	case "gcloud":
+		if len(cmdArgs) >= 3 && cmdArgs[0] == "auth" && cmdArgs[1] == "application-default" && cmdArgs[2] == "print-access-token" {
+			fmt.Printf("ya29.mock-adc-token\n")
+			os.Exit(0)
+		}
+		if len(cmdArgs) >= 2 && cmdArgs[0] == "auth" && cmdArgs[1] == "print-access-token" {
+			if behavior == "gcloud_logged_out" {
+				fmt.Fprintf(os.Stderr, "ERROR: (gcloud.auth.print-access-token) You do not currently have an active account selected.\n")
+				os.Exit(1)
+			}
+			fmt.Printf("ya29.mock-token\n")
+			os.Exit(0)
+		}
 	case "docker":
+		if len(cmdArgs) == 1 && cmdArgs[0] == "--version" {
+			fmt.Printf("Docker version 27.3.1, build ce12230\n")
+			os.Exit(0)
+		}
+		if len(cmdArgs) >= 1 && cmdArgs[0] == "version" {
+			if behavior == "daemon_down" {
+				fmt.Printf("Cannot connect to the Docker daemon at unix:///var/run/docker.sock. Is the docker daemon running?\n")
+				os.Exit(1)
+			}
+			fmt.Printf("27.3.1\n")
+			os.Exit(0)
+		}
+		if len(cmdArgs) >= 1 && cmdArgs[0] == "info" {
+			if behavior == "kata_installed" {
+				fmt.Printf(`{"io.containerd.kata.v2":{"path":"containerd-shim-kata-v2"},"runc":{"path":"runc"}}`)
+			} else if behavior == "nvidia_installed" {
+				fmt.Printf(`{"nvidia":{"path":"nvidia-container-runtime"},"runc":{"path":"runc"}}`)
+			} else {
+				fmt.Printf(`{"runc":{"path":"runc"}}`)
+			}
+			os.Exit(0)
+		}
+		if len(cmdArgs) >= 3 && cmdArgs[0] == "ps" && strings.HasPrefix(cmdArgs[2], "label=clix.dev/script=") {
+			if behavior != "no_containers" {
+				fmt.Printf("c0ffee\nbadbad\n")
+			}
+			os.Exit(0)
+		}
+		if len(cmdArgs) >= 1 && cmdArgs[0] == "logs" {
+			// Mock the logs of the egress proxy
+			if behavior != "proxy_not_ready" {
+				fmt.Printf("Accepting HTTP Socket connections at conn1 local=[::]:3128 remote=[::] FD 12 flags=9\n")
+			}
+			os.Exit(0)
+		}
+		if len(cmdArgs) >= 1 && cmdArgs[0] == "stats" {
+			// Mock streaming stats: frames start with escape sequences which clear the screen, and the
+			// stream stays open until it is killed
+			fmt.Printf("\x1b[2J\x1b[H12.5MiB / 1GiB\t0.00%%\n")
+			fmt.Printf("\x1b[2J\x1b[H40MiB / 1GiB\t50.00%%\n")
+			time.Sleep(time.Minute)
+			os.Exit(0)
+		}
+		if len(cmdArgs) >= 1 && cmdArgs[0] == "create" {
+			fmt.Printf("mockcontainer\n")
+			os.Exit(0)
+		}
 		if len(cmdArgs) >= 2 && cmdArgs[0] == "images" && cmdArgs[1] == "-q" {
 			if behavior == "image_exists" {
 				fmt.Printf("image-id\n")
@@ -71,6 +266,34 @@ func TestHelperProcess(t *testing.T) {
 			// else empty output
 			os.Exit(0)
 		}
+		if len(cmdArgs) >= 3 && cmdArgs[0] == "images" && cmdArgs[2] == "reference=clix-*" {
+			// Newest first, as the engine lists them
+			fmt.Printf("clix-lint-0a1b2c3d-4e5f6a7b:c2\t2026-10-02 09:00:00 +0000 UTC\t1.2GB\n")
+			fmt.Printf("clix-lint-0a1b2c3d-4e5f6a7b:c1\t2026-10-01 09:00:00 +0000 UTC\t1.1GB\n")
+			fmt.Printf("clix-fmt-1a2b3c4d-5e6f7a8b:c1\t2026-09-30 09:00:00 +0000 UTC\t85.3MB\n")
+			os.Exit(0)
+		}
+		if len(cmdArgs) >= 2 && cmdArgs[0] == "compose" && cmdArgs[1] == "ls" {
+			fmt.Printf(`[{"Name":"shop","Status":"running(2)","ConfigFiles":"%s/compose.yaml"}]`, os.Getenv("MOCK_COMPOSE_DIR"))
+			os.Exit(0)
+		}
+		if len(cmdArgs) >= 4 && cmdArgs[0] == "network" && cmdArgs[1] == "ls" {
+			switch cmdArgs[3] {
+			case "label=com.docker.compose.project=shop":
+				fmt.Printf("shop_backend\nshop_default\n")
+			case "label=com.docker.compose.project=blog":
+				fmt.Printf("blog_web\n")
+			}
+			os.Exit(0)
+		}
+		if len(cmdArgs) >= 2 && cmdArgs[0] == "volume" && cmdArgs[1] == "ls" {
+			fmt.Printf("clix-terraform-plugins\nclix-go-mod\n")
+			os.Exit(0)
+		}
+		if len(cmdArgs) >= 3 && cmdArgs[0] == "volume" && cmdArgs[1] == "rm" && cmdArgs[2] == "clix-go-mod" {
+			fmt.Printf("volume is in use\n")
+			os.Exit(1)
+		}
 		if len(cmdArgs) >= 2 && cmdArgs[0] == "buildx" {
 			// Mock build: success
 			fmt.Fprintf(os.Stderr, "Mock building...\n")
@@ -0,0 +1,37 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// wasm-tool is a WASI module for the wasm sandbox tests. It prints its args and TOOL_MODE, and with
+// "write PATH" writes to PATH, exiting 3 if it can't.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) == 3 && os.Args[1] == "write" {
+		if err := os.WriteFile(os.Args[2], []byte("written by wasm-tool\n"), 0644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(3)
+		}
+		return
+	}
+	fmt.Println("Hello from wasm-tool")
+	for i, arg := range os.Args[1:] {
+		fmt.Printf("Arg %d: %s\n", i, arg)
+	}
+	fmt.Printf("TOOL_MODE=%s\n", os.Getenv("TOOL_MODE"))
+}
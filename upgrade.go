@@ -0,0 +1,307 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	yamlv3 "go.yaml.in/yaml/v3"
+)
+
+// scriptUpgrade is a newer version found for a field of a script.
+type scriptUpgrade struct {
+	// path is the field in the script, such as go.version
+	path []string
+	// from and to are the field's value before and after the upgrade
+	from, to string
+}
+
+// listTagsFn returns the tags of an image repository in its registry.
+var listTagsFn = func(repo string) ([]string, error) {
+	return crane.ListTags(repo)
+}
+
+// runUpgrade implements clix upgrade, which bumps the versions pinned by scripts to the latest ones, and
+// updates their locks.
+func runUpgrade(stdout, stderr io.Writer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: clix upgrade <script>...")
+	}
+	for _, scriptPath := range args {
+		if err := upgradeScript(stdout, stderr, scriptPath); err != nil {
+			return fmt.Errorf("upgrading %s: %w", scriptPath, err)
+		}
+	}
+	return nil
+}
+
+// upgradeScript rewrites the script with the upgrades found for it, leaving the rest of the file as it
+// is, and prints what changed. In a dry run the script is not written.
+func upgradeScript(stdout, stderr io.Writer, scriptPath string) error {
+	data, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return fmt.Errorf("error reading script file: %w", err)
+	}
+	script, err := readScript(scriptPath)
+	if err != nil {
+		return err
+	}
+	upgrades, err := findUpgrades(script)
+	if err != nil {
+		return err
+	}
+	if len(upgrades) == 0 {
+		fmt.Fprintf(stdout, "%s: up to date\n", scriptPath)
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "%s:\n", scriptPath)
+	changed := false
+	for _, u := range upgrades {
+		field := strings.Join(u.path, ".")
+		updated, err := replaceYAMLValue(data, u.path, u.from, u.to)
+		if err != nil {
+			fmt.Fprintf(stderr, "Warning: not upgrading %s in %s: %v\n", field, scriptPath, err)
+			continue
+		}
+		data, changed = updated, true
+		fmt.Fprintf(stdout, "  %s: %s -> %s\n", field, u.from, u.to)
+	}
+	if !changed || dryRunEnabled() {
+		return nil
+	}
+	if err := os.WriteFile(scriptPath, data, 0644); err != nil {
+		return err
+	}
+	if _, err := os.Stat(signaturePath(scriptPath)); err == nil {
+		fmt.Fprintf(stderr, "Warning: the signature of %s is no longer valid; sign it again with clix sign\n", scriptPath)
+	}
+
+	lock, err := readLock(scriptPath)
+	if lock == nil || err != nil {
+		return err
+	}
+	if script, err = readScript(scriptPath); err != nil {
+		return err
+	}
+	if lock, err = resolveLock(script); err != nil {
+		return err
+	}
+	if err := writeLock(lockPath(scriptPath), scriptPath, lock); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "  updated %s\n", lockPath(scriptPath))
+	return nil
+}
+
+// findUpgrades finds newer versions for the script's image tag, go version and build tag. Only versions
+// are upgraded: tags such as latest and branches are left to clix lock, which pins what they resolve to.
+func findUpgrades(script Script) ([]scriptUpgrade, error) {
+	var upgrades []scriptUpgrade
+	if script.Image != "" && script.Build == nil {
+		image, err := latestImage(script.Image)
+		if err != nil {
+			return nil, err
+		}
+		if image != script.Image {
+			upgrades = append(upgrades, scriptUpgrade{[]string{"image"}, script.Image, image})
+		}
+	}
+	if config := script.Go; config != nil {
+		pkg, version, inRun := strings.Cut(config.Run, "@")
+		if !inRun {
+			version = config.Version
+		}
+		if _, ok := parseVersion(version); ok {
+			_, latest, err := resolveGoVersion(pkg, "latest")
+			if err != nil {
+				return nil, err
+			}
+			if newerVersion(version, latest) {
+				if inRun {
+					upgrades = append(upgrades, scriptUpgrade{[]string{"go", "run"}, config.Run, pkg + "@" + latest})
+				} else {
+					upgrades = append(upgrades, scriptUpgrade{[]string{"go", "version"}, version, latest})
+				}
+			}
+		}
+	}
+	if build := script.Build; build != nil && build.Commit == "" {
+		if _, ok := parseVersion(build.Branch); ok {
+			tags, err := listGitTags(build.Git)
+			if err != nil {
+				return nil, err
+			}
+			if tag := latestVersion(build.Branch, tags); tag != build.Branch {
+				upgrades = append(upgrades, scriptUpgrade{[]string{"build", "branch"}, build.Branch, tag})
+			}
+		}
+	}
+	return upgrades, nil
+}
+
+// latestImage returns the image with its tag upgraded to the latest version of the same form (so that
+// 3.20-alpine is upgraded to 3.21-alpine, not 3.21). An image pinned by digest is pinned to the digest
+// of the new tag.
+func latestImage(image string) (string, error) {
+	ref, digest, pinned := strings.Cut(image, "@")
+	repo, tag := ref, ""
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		repo, tag = ref[:i], ref[i+1:]
+	}
+	if _, ok := parseVersion(tag); !ok {
+		return image, nil
+	}
+	if err := checkOnline("the tags of image " + repo); err != nil {
+		return "", err
+	}
+	tags, err := listTagsFn(repo)
+	if err != nil {
+		return "", fmt.Errorf("listing the tags of %s: %w", repo, err)
+	}
+	latest := latestVersion(tag, tags)
+	if latest == tag {
+		return image, nil
+	}
+	upgraded := repo + ":" + latest
+	if pinned {
+		if digest, err = imageDigestFn(upgraded); err != nil {
+			return "", fmt.Errorf("resolving the digest of image %s: %w", upgraded, err)
+		}
+		upgraded += "@" + digest
+	}
+	return upgraded, nil
+}
+
+// listGitTags returns the tags of a git repository.
+func listGitTags(repo string) ([]string, error) {
+	if err := checkOnline("the tags of " + repo); err != nil {
+		return nil, err
+	}
+	out, err := execCommand("git", "ls-remote", "--tags", "--refs", repo).Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing the tags of %s: %w", repo, err)
+	}
+	var tags []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			tags = append(tags, strings.TrimPrefix(fields[1], "refs/tags/"))
+		}
+	}
+	return tags, nil
+}
+
+// versionPattern matches versions such as v1.2.3, 3.20 and 1.22-alpine: a prefix, dot separated numbers
+// and a suffix.
+var versionPattern = regexp.MustCompile(`^(v?)(\d+(?:\.\d+)*)(-.*)?$`)
+
+// parsedVersion is a version split by versionPattern.
+type parsedVersion struct {
+	prefix, suffix string
+	numbers        []int
+}
+
+func parseVersion(s string) (parsedVersion, bool) {
+	m := versionPattern.FindStringSubmatch(s)
+	if m == nil {
+		return parsedVersion{}, false
+	}
+	v := parsedVersion{prefix: m[1], suffix: m[3]}
+	for _, part := range strings.Split(m[2], ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return parsedVersion{}, false
+		}
+		v.numbers = append(v.numbers, n)
+	}
+	return v, true
+}
+
+// newerVersion reports whether version b is newer than a. Versions of different forms are not compared.
+func newerVersion(a, b string) bool {
+	va, ok := parseVersion(a)
+	if !ok {
+		return false
+	}
+	vb, ok := parseVersion(b)
+	if !ok || va.prefix != vb.prefix || va.suffix != vb.suffix || len(va.numbers) != len(vb.numbers) {
+		return false
+	}
+	for i := range va.numbers {
+		if va.numbers[i] != vb.numbers[i] {
+			return vb.numbers[i] > va.numbers[i]
+		}
+	}
+	return false
+}
+
+// latestVersion returns the newest of the candidates of the same form as current, or current.
+func latestVersion(current string, candidates []string) string {
+	latest := current
+	for _, c := range candidates {
+		if newerVersion(latest, c) {
+			latest = c
+		}
+	}
+	return latest
+}
+
+// replaceYAMLValue replaces from with to in the scalar value at path in the yaml document, editing the
+// line it is on so that the rest of the document, comments and quoting included, is unchanged.
+func replaceYAMLValue(data []byte, path []string, from, to string) ([]byte, error) {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, fmt.Errorf("script is empty")
+	}
+	node := doc.Content[0]
+	for _, key := range path {
+		var value *yamlv3.Node
+		if node.Kind == yamlv3.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					value = node.Content[i+1]
+				}
+			}
+		}
+		if value == nil {
+			return nil, fmt.Errorf("%s is not set in the script itself, and may come from a script it extends", strings.Join(path, "."))
+		}
+		node = value
+	}
+	if node.Kind != yamlv3.ScalarNode || node.Value != from || strings.Contains(node.Value, "\n") {
+		return nil, fmt.Errorf("%s is not %q", strings.Join(path, "."), from)
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	line := lines[node.Line-1]
+	// The value starts at its column, or after its opening quote
+	start := min(node.Column-1, len(line))
+	i := strings.Index(line[start:], from)
+	if i < 0 {
+		return nil, fmt.Errorf("%s is written in a form which can't be upgraded in place", strings.Join(path, "."))
+	}
+	start += i
+	lines[node.Line-1] = line[:start] + to + line[start+len(from):]
+	return []byte(strings.Join(lines, "")), nil
+}
@@ -0,0 +1,110 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpgrade(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	defer func(fn func(string) ([]string, error)) { listTagsFn = fn }(listTagsFn)
+	listTagsFn = func(repo string) ([]string, error) {
+		return []string{"3.19", "3.20", "3.21", "3.21.1", "3.22-alpine", "latest", "edge"}, nil
+	}
+	defer func(fn func(string) (string, error)) { imageDigestFn = fn }(imageDigestFn)
+	imageDigestFn = func(ref string) (string, error) {
+		return "sha256:" + ref[strings.LastIndex(ref, ":")+1:], nil
+	}
+
+	dir := t.TempDir()
+	image := filepath.Join(dir, "image")
+	os.WriteFile(image, []byte("apiVersion: clix.dev/v1alpha1\nkind: Script\n# The tool's image\nimage: \"alpine:3.20\" # pinned\n"), 0644)
+	goScript := filepath.Join(dir, "go")
+	os.WriteFile(goScript, []byte("apiVersion: clix.dev/v1alpha1\nkind: Script\ngo:\n  run: example.com/tool/cmd/tool\n  version: v1.4.0\n"), 0644)
+	build := filepath.Join(dir, "build")
+	os.WriteFile(build, []byte("apiVersion: clix.dev/v1alpha1\nkind: Script\nbuild:\n  git: https://github.com/example/tool\n  branch: v1.2.0\n"), 0644)
+	var stdout, stderr bytes.Buffer
+	if err := runLock(&stdout, &stderr, []string{image}); err != nil {
+		t.Fatalf("lock failed: %v", err)
+	}
+
+	stdout.Reset()
+	if err := run(nil, &stdout, &stderr, []string{"clix", "upgrade", image, goScript, build}); err != nil {
+		t.Fatalf("upgrade failed: %v: %s", err, stderr.String())
+	}
+	want := image + ":\n  image: alpine:3.20 -> alpine:3.21\n  updated " + image + ".lock\n" +
+		goScript + ":\n  go.version: v1.4.0 -> v1.4.2\n" +
+		build + ":\n  build.branch: v1.2.0 -> v1.10.0\n"
+	if stdout.String() != want {
+		t.Errorf("Unexpected summary:\n%s\nwant:\n%s", stdout.String(), want)
+	}
+	if data, _ := os.ReadFile(image); !strings.HasSuffix(string(data), "# The tool's image\nimage: \"alpine:3.21\" # pinned\n") {
+		t.Errorf("Expected the tag upgraded in place, got:\n%s", data)
+	}
+	if lock, err := readLock(image); err != nil || lock.Image.Ref != "alpine:3.21" || lock.Image.Digest != "sha256:3.21" {
+		t.Errorf("Expected the lock updated, got %+v, %v", lock.Image, err)
+	}
+	if data, _ := os.ReadFile(goScript); !strings.Contains(string(data), "  version: v1.4.2\n") {
+		t.Errorf("Expected the go version upgraded, got:\n%s", data)
+	}
+
+	stdout.Reset()
+	if err := runUpgrade(&stdout, &stderr, []string{image}); err != nil || stdout.String() != image+": up to date\n" {
+		t.Errorf("Expected the script up to date, got %v: %s", err, stdout.String())
+	}
+}
+
+func TestLatestImage(t *testing.T) {
+	defer func(fn func(string) ([]string, error)) { listTagsFn = fn }(listTagsFn)
+	listTagsFn = func(repo string) ([]string, error) {
+		if repo != "registry.example.com:5000/tool" {
+			t.Errorf("Unexpected repository %s", repo)
+		}
+		return []string{"1.22-alpine", "1.23-alpine", "1.24", "v1.25-alpine"}, nil
+	}
+	defer func(fn func(string) (string, error)) { imageDigestFn = fn }(imageDigestFn)
+	imageDigestFn = func(ref string) (string, error) {
+		return "sha256:new", nil
+	}
+
+	for image, want := range map[string]string{
+		"registry.example.com:5000/tool:1.22-alpine":            "registry.example.com:5000/tool:1.23-alpine",
+		"registry.example.com:5000/tool:1.22-alpine@sha256:old": "registry.example.com:5000/tool:1.23-alpine@sha256:new",
+		"registry.example.com:5000/tool:latest":                 "registry.example.com:5000/tool:latest",
+		"registry.example.com:5000/tool":                        "registry.example.com:5000/tool",
+	} {
+		if got, err := latestImage(image); err != nil || got != want {
+			t.Errorf("latestImage(%s) = %q, %v, want %q", image, got, err, want)
+		}
+	}
+}
+
+func TestReplaceYAMLValue(t *testing.T) {
+	data := []byte("go:\n  run: example.com/tool # the tool\n  version: 'v1.0.0'\n")
+	got, err := replaceYAMLValue(data, []string{"go", "version"}, "v1.0.0", "v1.1.0")
+	if want := "go:\n  run: example.com/tool # the tool\n  version: 'v1.1.0'\n"; err != nil || string(got) != want {
+		t.Errorf("replaceYAMLValue = %q, %v, want %q", got, err, want)
+	}
+	if _, err := replaceYAMLValue(data, []string{"image"}, "alpine", "alpine:3"); err == nil || !strings.Contains(err.Error(), "not set in the script") {
+		t.Errorf("Expected an error for a missing field, got %v", err)
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// exitError reports that the tool exited with a non-zero exit code, which clix should exit with too.
+type exitError struct {
+	code int
+}
+
+func (e *exitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.code)
+}
+
+// resourceUsage is the resource usage of the tool's process (or container).
+type resourceUsage struct {
+	Wall       time.Duration
+	User       time.Duration
+	System     time.Duration
+	PeakMemory int64 // bytes
+	// Sampled is set when the CPU and memory figures were sampled (e.g. from docker stats), and are approximate
+	Sampled bool
+}
+
+// usage accumulates the resource usage of the tool commands run by this invocation of clix.
+var usage resourceUsage
+
+func timingsEnabled() bool {
+	return os.Getenv("CLIX_TIMINGS") != ""
+}
+
+// runTool runs the command of the tool, recording its resource usage, or prints it for --dry-run. For
+// clix shell, only a shell in a container sandbox is run, and for clix build nothing is. A non-zero exit
+// code is returned as an *exitError.
+func runTool(cmd *exec.Cmd) error {
+	if err := checkShellSandbox(); err != nil {
+		return err
+	}
+	if prebuildOnly {
+		log(1, "Not running %s for clix build", cmd.Path)
+		return nil
+	}
+	if dryRunEnabled() {
+		return printDryRunCommand(cmd)
+	}
+	start := time.Now()
+	err := cmd.Run()
+	usage.Wall += time.Since(start)
+	if cmd.ProcessState != nil {
+		usage.addProcessState(cmd.ProcessState)
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		return &exitError{code: exitErr.ExitCode()}
+	}
+	return err
+}
+
+func (u *resourceUsage) addProcessState(ps *os.ProcessState) {
+	u.User += ps.UserTime()
+	u.System += ps.SystemTime()
+	if rusage, ok := ps.SysUsage().(*syscall.Rusage); ok {
+		maxRSS := int64(rusage.Maxrss)
+		// Maxrss is reported in bytes on macOS, kilobytes elsewhere
+		if runtime.GOOS != "darwin" {
+			maxRSS *= 1024
+		}
+		if maxRSS > u.PeakMemory {
+			u.PeakMemory = maxRSS
+		}
+	}
+}
+
+func (u *resourceUsage) String() string {
+	approx := ""
+	if u.Sampled {
+		approx = "~"
+	}
+	return fmt.Sprintf("wall %s, cpu %s%s (user %s, sys %s), peak memory %s%s",
+		u.Wall.Round(time.Millisecond), approx, (u.User + u.System).Round(time.Millisecond),
+		u.User.Round(time.Millisecond), u.System.Round(time.Millisecond), approx, formatBytes(u.PeakMemory))
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "clix: %s\n", usage.String())
+}
+
+// auditRecord is appended to the audit log in the clix state dir after each run of a tool, so that
+// resources: limits can be sized from the usage of past runs.
+type auditRecord struct {
+	Time     time.Time `json:"time"`
+	Args     []string  `json:"args"`
+	Sandbox  string    `json:"sandbox,omitempty"`
+	Image    string    `json:"image,omitempty"`
+	ExitCode int       `json:"exitCode"`
+	Error    string    `json:"error,omitempty"`
+	WallMs   int64     `json:"wallMs"`
+	CPUMs    int64     `json:"cpuMs"`
+	// PeakMemory is in bytes
+	PeakMemory int64 `json:"peakMemory"`
+	Sampled    bool  `json:"sampled,omitempty"`
+}
+
+// writeAuditRecord appends the usage of the run to the audit log, as a line of JSON.
+func writeAuditRecord(args []string, runErr error) error {
+	record := auditRecord{
+		Time:       time.Now().UTC(),
+		Args:       args,
+		Sandbox:    diagnostics.Sandbox,
+		WallMs:     usage.Wall.Milliseconds(),
+		CPUMs:      (usage.User + usage.System).Milliseconds(),
+		PeakMemory: usage.PeakMemory,
+		Sampled:    usage.Sampled,
+	}
+	if diagnostics.Script != nil {
+		record.Image = diagnostics.Script.Image
+	}
+	var exitErr *exitError
+	if errors.As(runErr, &exitErr) {
+		record.ExitCode = exitErr.code
+	} else if runErr != nil {
+		record.ExitCode = 1
+		record.Error = runErr.Error()
+	}
+
+	dir, err := stateDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create state dir: %w", err)
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	// The arguments and the error may contain secrets
+	data = append([]byte(redactSecrets(string(data))), '\n')
+
+	f, err := os.OpenFile(filepath.Join(dir, "audit.log"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return f.Close()
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// dockerStatsSampler samples `docker stats` for a running container, because the resource usage of the
+// docker CLI process does not include the container.
+type dockerStatsSampler struct {
+	cli       []string
+	container string
+	// retry is how long to wait before streaming the stats again, when the container hasn't started yet
+	retry time.Duration
+	// before is the usage before the container ran, which the container's usage is added to
+	before resourceUsage
+
+	mutex      sync.Mutex
+	peakMemory int64
+	cpu        time.Duration
+	stop       chan struct{}
+	done       chan struct{}
+}
+
+func startDockerStatsSampler(cli []string, container string) *dockerStatsSampler {
+	s := &dockerStatsSampler{
+		cli:       cli,
+		container: container,
+		retry:     100 * time.Millisecond,
+		before:    usage,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+func (s *dockerStatsSampler) loop() {
+	defer close(s.done)
+	for {
+		s.stream()
+		select {
+		case <-s.stop:
+			return
+		case <-time.After(s.retry):
+		}
+	}
+}
+
+// stream records the samples of a streaming `docker stats`, until it exits (e.g. because the container
+// hasn't started yet) or sampling is stopped. Streaming gets the first sample as soon as the container
+// starts, which polling may miss for short runs.
+func (s *dockerStatsSampler) stream() {
+	args := append(s.cli[1:], "stats", "--format", "{{.MemUsage}}\t{{.CPUPerc}}", s.container)
+	cmd := execCommand(s.cli[0], args...)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		log(2, "docker stats failed: %v", err)
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		log(2, "docker stats failed: %v", err)
+		return
+	}
+	streaming := make(chan struct{})
+	defer close(streaming)
+	go func() {
+		select {
+		case <-s.stop:
+			cmd.Process.Kill()
+		case <-streaming:
+		}
+	}()
+
+	scanner := bufio.NewScanner(out)
+	last := time.Now()
+	for scanner.Scan() {
+		now := time.Now()
+		memory, cpuPercent, ok := parseDockerStats(ansiEscape.ReplaceAllString(scanner.Text(), ""))
+		if !ok {
+			continue
+		}
+		s.mutex.Lock()
+		s.peakMemory = max(s.peakMemory, memory)
+		s.cpu += time.Duration(cpuPercent / 100 * float64(now.Sub(last)))
+		s.mutex.Unlock()
+		last = now
+	}
+	if err := cmd.Wait(); err != nil {
+		// The container may not have started yet, or already exited
+		log(2, "docker stats failed: %v", err)
+	}
+}
+
+// ansiEscape matches the escape sequences which streaming `docker stats` clears the screen with.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// Stop stops sampling, and records the sampled usage.
+func (s *dockerStatsSampler) Stop() {
+	close(s.stop)
+	<-s.done
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	// The client process usage is not interesting, replace it with the container usage, keeping the
+	// usage of earlier runs (e.g. hooks)
+	usage.User = s.before.User + s.cpu
+	usage.System = s.before.System
+	usage.PeakMemory = max(s.before.PeakMemory, s.peakMemory)
+	usage.Sampled = true
+}
+
+// parseDockerStats parses a line like "12.5MiB / 1.944GiB\t0.53%".
+func parseDockerStats(line string) (int64, float64, bool) {
+	fields := strings.Split(strings.TrimSpace(line), "\t")
+	if len(fields) != 2 {
+		return 0, 0, false
+	}
+	memStr, _, _ := strings.Cut(fields[0], "/")
+	memory, ok := parseDockerSize(strings.TrimSpace(memStr))
+	if !ok {
+		return 0, 0, false
+	}
+	cpu, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(fields[1]), "%"), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return memory, cpu, true
+}
+
+// parseDockerSize parses sizes as formatted by docker, e.g. 12.5MiB or 1.2GB.
+func parseDockerSize(s string) (int64, bool) {
+	units := []struct {
+		suffix     string
+		multiplier float64
+	}{
+		{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+		{"kB", 1e3}, {"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+		{"B", 1},
+	}
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			v, err := strconv.ParseFloat(strings.TrimSuffix(s, u.suffix), 64)
+			if err != nil {
+				return 0, false
+			}
+			return int64(v * u.multiplier), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDockerStats(t *testing.T) {
+	tests := []struct {
+		line   string
+		memory int64
+		cpu    float64
+		ok     bool
+	}{
+		{line: "12.5MiB / 1.944GiB\t0.53%", memory: 12.5 * (1 << 20), cpu: 0.53, ok: true},
+		{line: "512KiB / 1GiB\t105.00%\n", memory: 512 * (1 << 10), cpu: 105, ok: true},
+		{line: "2GB / 8GB\t1%", memory: 2e9, cpu: 1, ok: true},
+		{line: "--\t--", ok: false},
+		{line: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		memory, cpu, ok := parseDockerStats(tt.line)
+		if ok != tt.ok {
+			t.Errorf("parseDockerStats(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			continue
+		}
+		if memory != tt.memory || cpu != tt.cpu {
+			t.Errorf("parseDockerStats(%q) = %d, %v; want %d, %v", tt.line, memory, cpu, tt.memory, tt.cpu)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		100:               "100B",
+		2048:              "2.0KiB",
+		45 * (1 << 20):    "45.0MiB",
+		3 * (1 << 30) / 2: "1.5GiB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestRunToolExitCode(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	os.Setenv("MOCK_BEHAVIOR", "exit_3")
+	defer os.Unsetenv("MOCK_BEHAVIOR")
+
+	usage = resourceUsage{}
+	err := runTool(execCommand("some-tool"))
+
+	var exitErr *exitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("Expected exitError, got %v", err)
+	}
+	if exitErr.code != 3 {
+		t.Errorf("Expected exit code 3, got %d", exitErr.code)
+	}
+	if usage.Wall == 0 {
+		t.Errorf("Expected wall time to be recorded")
+	}
+}
+
+func TestDockerSandboxTimingsRemovesContainer(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("MOCK_CALLS", calls)
+	t.Setenv("CLIX_TIMINGS", "1")
+
+	usage = resourceUsage{}
+	script := Script{Image: "alpine"}
+	if err := (&DockerSandbox{}).Run(strings.NewReader(""), io.Discard, io.Discard, script, []string{"ls"}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	data, err := os.ReadFile(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// docker stats is streamed alongside the run
+	var run string
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "docker run ") {
+			run = line
+		}
+	}
+	// A failed run must not leave a named container behind, which the next run would conflict with
+	if !strings.HasPrefix(run, "docker run --rm --name clix-") {
+		t.Errorf("Expected the named container to be removed when it exits, got %q", run)
+	}
+}
+
+func TestWriteAuditRecord(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	defer func() { usage, diagnostics = resourceUsage{}, diagnosticsBundle{} }()
+
+	recordRun("docker", Script{Image: "alpine"}, []string{"ls"})
+	usage = resourceUsage{Wall: 1500 * time.Millisecond, User: 200 * time.Millisecond, PeakMemory: 1 << 20, Sampled: true}
+	if err := writeAuditRecord([]string{"clix", "tool.yaml"}, nil); err != nil {
+		t.Fatalf("writeAuditRecord failed: %v", err)
+	}
+	if err := writeAuditRecord([]string{"clix", "tool.yaml"}, &exitError{code: 2}); err != nil {
+		t.Fatalf("writeAuditRecord failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(os.Getenv("XDG_STATE_HOME"), "clix", "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 records, got %q", lines)
+	}
+	var record auditRecord
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Sandbox != "docker" || record.Image != "alpine" || record.WallMs != 1500 || record.CPUMs != 200 ||
+		record.PeakMemory != 1<<20 || !record.Sampled || record.ExitCode != 0 {
+		t.Errorf("Unexpected record %+v", record)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.ExitCode != 2 || record.Error != "" {
+		t.Errorf("Expected exit code 2 without an error, got %+v", record)
+	}
+}
+
+func TestDockerStatsSampler(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+
+	// The usage of an earlier run, such as a hook
+	usage = resourceUsage{User: time.Second, System: 500 * time.Millisecond, PeakMemory: 20 << 20}
+	defer func() { usage = resourceUsage{} }()
+	sampler := startDockerStatsSampler([]string{"docker"}, "clix-test")
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		sampler.mutex.Lock()
+		sampled := sampler.peakMemory
+		sampler.mutex.Unlock()
+		if sampled == 40<<20 {
+			break
+		}
+	}
+	sampler.Stop()
+
+	if usage.PeakMemory != 40<<20 {
+		t.Errorf("Expected the peak memory of the streamed samples, got %d", usage.PeakMemory)
+	}
+	if usage.User < time.Second || usage.System != 500*time.Millisecond || !usage.Sampled {
+		t.Errorf("Expected the container usage to be added to the earlier usage, got %+v", usage)
+	}
+}
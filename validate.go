@@ -0,0 +1,314 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	yamlv3 "go.yaml.in/yaml/v3"
+)
+
+//go:generate sh -c "go run . validate --schema > docs/schema/script.schema.json"
+
+// jsonSchema is the subset of JSON Schema used to describe scripts.
+type jsonSchema struct {
+	Schema     string                 `json:"$schema,omitempty"`
+	Title      string                 `json:"title,omitempty"`
+	Type       string                 `json:"type,omitempty"`
+	Properties map[string]*jsonSchema `json:"properties,omitempty"`
+	Items      *jsonSchema            `json:"items,omitempty"`
+	OneOf      []*jsonSchema          `json:"oneOf,omitempty"`
+	Enum       []string               `json:"enum,omitempty"`
+	// AdditionalProperties is false for structs, or the schema of the values of maps
+	AdditionalProperties any `json:"additionalProperties,omitempty"`
+}
+
+// schemaOverrides are the schemas of types with custom unmarshalling.
+var schemaOverrides = map[reflect.Type]*jsonSchema{
+	reflect.TypeOf(SandboxList{}): {OneOf: []*jsonSchema{{Type: "string"}, {Type: "array", Items: &jsonSchema{Type: "string"}}}},
+	reflect.TypeOf(CwdMount("")):  {OneOf: []*jsonSchema{{Type: "boolean"}, {Type: "string", Enum: []string{"true", "false", "repoRoot"}}}},
+	reflect.TypeOf(CommandLine{}): {OneOf: []*jsonSchema{{Type: "string"}, {Type: "array", Items: &jsonSchema{Type: "string"}}}},
+	reflect.TypeOf(NetworkPolicy{}): {OneOf: []*jsonSchema{{Type: "string"}, {Type: "object", Properties: map[string]*jsonSchema{
+		"mode":  {Type: "string"},
+		"allow": {Type: "array", Items: &jsonSchema{Type: "string"}},
+	}, AdditionalProperties: false}}},
+	reflect.TypeOf(Checksums{}): {OneOf: []*jsonSchema{{Type: "string"}, {Type: "object", AdditionalProperties: &jsonSchema{Type: "string"}}}},
+}
+
+// scriptSchema generates the JSON Schema of scripts from the Script type.
+func scriptSchema() *jsonSchema {
+	schema := typeSchema(reflect.TypeOf(Script{}))
+	schema.Schema = "https://json-schema.org/draft/2020-12/schema"
+	schema.Title = "clix script"
+	// extends is expanded before the script is parsed, so it is not a field of Script
+	schema.Properties["extends"] = &jsonSchema{OneOf: []*jsonSchema{{Type: "string"}, {Type: "array", Items: &jsonSchema{Type: "string"}}}}
+	schema.Properties["kind"].Enum = []string{"Script"}
+	schema.Properties["apiVersion"].Enum = []string{scriptAPIVersion}
+	return schema
+}
+
+func typeSchema(t reflect.Type) *jsonSchema {
+	if s, ok := schemaOverrides[t]; ok {
+		return s
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Slice:
+		return &jsonSchema{Type: "array", Items: typeSchema(t.Elem())}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: typeSchema(t.Elem())}
+	case reflect.Struct:
+		schema := &jsonSchema{Type: "object", Properties: map[string]*jsonSchema{}, AdditionalProperties: false}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" && field.Anonymous {
+				// Embedded structs are flattened, as encoding/json does
+				for n, p := range typeSchema(field.Type).Properties {
+					schema.Properties[n] = p
+				}
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			schema.Properties[name] = typeSchema(field.Type)
+		}
+		return schema
+	}
+	panic(fmt.Sprintf("no JSON schema for %v", t))
+}
+
+// scriptSections are the ways of running a tool, of which a script uses exactly one.
+var scriptSections = []string{"image", "build", "wasm", "go", "python", "node", "rust", "java", "deno", "bun", "dotnet", "shell", "binary", "kubectl-plugin"}
+
+// validationError is a problem in a script, at a line and column of the file.
+type validationError struct {
+	Line, Column int
+	Message      string
+}
+
+// runValidate implements `clix validate [--schema] <script>...`
+func runValidate(stdout, stderr io.Writer, args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	printSchema := flags.Bool("schema", false, "print the JSON Schema of scripts, for editor integration")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *printSchema {
+		out, err := json.MarshalIndent(scriptSchema(), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "%s\n", out)
+		return nil
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: clix validate [--schema] <script>...")
+	}
+
+	problems := 0
+	for _, scriptPath := range flags.Args() {
+		data, err := os.ReadFile(scriptPath)
+		if err != nil {
+			return fmt.Errorf("error reading script file: %w", err)
+		}
+		errs := validateScript(data)
+		for _, e := range errs {
+			fmt.Fprintf(stdout, "%s:%d:%d: %s\n", scriptPath, e.Line, e.Column, e.Message)
+		}
+		problems += len(errs)
+	}
+	if problems > 0 {
+		return &exitError{code: 1}
+	}
+	return nil
+}
+
+// validateScript checks the script against the schema, and for conflicting sections.
+func validateScript(data []byte) []validationError {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &doc); err != nil {
+		line := 0
+		fmt.Sscanf(err.Error(), "yaml: line %d:", &line)
+		return []validationError{{Line: line, Message: err.Error()}}
+	}
+	if len(doc.Content) == 0 {
+		return []validationError{{Line: 1, Column: 1, Message: "script is empty"}}
+	}
+	root := doc.Content[0]
+	errs := validateNode(root, scriptSchema(), "")
+
+	if root.Kind == yamlv3.MappingNode {
+		var first *yamlv3.Node
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			key := root.Content[i]
+			if !contains(scriptSections, key.Value) {
+				continue
+			}
+			if first == nil {
+				first = key
+				continue
+			}
+			errs = append(errs, validationError{key.Line, key.Column, fmt.Sprintf("conflicting sections %q (line %d) and %q: a script uses only one of %s", first.Value, first.Line, key.Value, strings.Join(scriptSections, ", "))})
+		}
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Line < errs[j].Line || errs[i].Line == errs[j].Line && errs[i].Column < errs[j].Column
+	})
+	return errs
+}
+
+func validateNode(node *yamlv3.Node, schema *jsonSchema, path string) []validationError {
+	if node.Kind == yamlv3.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yamlv3.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+	if len(schema.OneOf) > 0 {
+		var types []string
+		for _, alternative := range schema.OneOf {
+			if len(validateNode(node, alternative, path)) == 0 {
+				return nil
+			}
+			types = append(types, alternative.Type)
+		}
+		return []validationError{{node.Line, node.Column, fmt.Sprintf("%s: expected %s", fieldName(path), strings.Join(types, " or "))}}
+	}
+
+	mismatch := func() []validationError {
+		return []validationError{{node.Line, node.Column, fmt.Sprintf("%s: expected %s, got %s", fieldName(path), schema.Type, yamlKind(node))}}
+	}
+	switch schema.Type {
+	case "object":
+		if node.Kind != yamlv3.MappingNode {
+			return mismatch()
+		}
+		var errs []validationError
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fieldPath := key.Value
+			if path != "" {
+				fieldPath = path + "." + key.Value
+			}
+			if propSchema, ok := schema.Properties[key.Value]; ok {
+				errs = append(errs, validateNode(value, propSchema, fieldPath)...)
+			} else if valueSchema, ok := schema.AdditionalProperties.(*jsonSchema); ok {
+				errs = append(errs, validateNode(value, valueSchema, fieldPath)...)
+			} else {
+				msg := fmt.Sprintf("unknown field %q", fieldPath)
+				if suggestion := closestField(key.Value, schema.Properties); suggestion != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", suggestion)
+				}
+				errs = append(errs, validationError{key.Line, key.Column, msg})
+			}
+		}
+		return errs
+	case "array":
+		if node.Kind != yamlv3.SequenceNode {
+			return mismatch()
+		}
+		var errs []validationError
+		for i, item := range node.Content {
+			errs = append(errs, validateNode(item, schema.Items, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+	case "string", "boolean", "integer":
+		want := map[string]string{"string": "!!str", "boolean": "!!bool", "integer": "!!int"}[schema.Type]
+		if node.Kind != yamlv3.ScalarNode || node.Tag != want {
+			return mismatch()
+		}
+		if len(schema.Enum) > 0 && !contains(schema.Enum, node.Value) {
+			return []validationError{{node.Line, node.Column, fmt.Sprintf("%s: expected %s, got %q", fieldName(path), strings.Join(schema.Enum, " or "), node.Value)}}
+		}
+	}
+	return nil
+}
+
+func fieldName(path string) string {
+	if path == "" {
+		return "script"
+	}
+	return path
+}
+
+// yamlKind describes the node, for type errors.
+func yamlKind(node *yamlv3.Node) string {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		return "object"
+	case yamlv3.SequenceNode:
+		return "array"
+	}
+	switch node.Tag {
+	case "!!bool":
+		return "boolean"
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	}
+	return "string"
+}
+
+// closestField suggests the known field nearest to a misspelt name, within an edit distance of two.
+func closestField(name string, properties map[string]*jsonSchema) string {
+	best, bestDistance := "", 3
+	for candidate := range properties {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(candidate)); d < bestDistance || d == bestDistance && candidate < best {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateScript(t *testing.T) {
+	data := `#!/usr/bin/env clix
+image: alpine
+entrypont: sh
+go:
+  run: example.com/tool
+mounts:
+- hostPath: 3
+sandbox: [podman, namespace]
+binary:
+  sha256: {linux/amd64: abc}
+`
+	var got []string
+	for _, e := range validateScript([]byte(data)) {
+		got = append(got, fmt.Sprintf("%d:%d:%s", e.Line, e.Column, e.Message))
+	}
+	want := []string{
+		`3:1:unknown field "entrypont" (did you mean "entrypoint"?)`,
+		`4:1:conflicting sections "image" (line 2) and "go"`,
+		`7:13:mounts[0].hostPath: expected string, got integer`,
+		`9:1:conflicting sections "image" (line 2) and "binary"`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("validateScript() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("validateScript()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if errs := validateScript([]byte("python:\n  run: black\nsandbox: podman\nprofiles:\n  ci:\n    image: x\n")); len(errs) != 0 {
+		t.Errorf("Expected a valid script, got %+v", errs)
+	}
+}
+
+// TestPublishedSchema checks that the published schema is up to date; run go generate to update it.
+func TestPublishedSchema(t *testing.T) {
+	published, err := os.ReadFile("docs/schema/script.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	if err := runValidate(&stdout, &bytes.Buffer{}, []string{"--schema"}); err != nil {
+		t.Fatalf("runValidate failed: %v", err)
+	}
+	if stdout.String() != string(published) {
+		t.Errorf("docs/schema/script.schema.json is out of date; run go generate")
+	}
+}
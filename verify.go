@@ -0,0 +1,195 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// runVerify implements clix verify, which checks that the script still resolves to its lock, that the
+// locked downloads match their checksums, and that the script is signed when signatures are required or
+// it has a signature. It fails with a diff of what drifted, for use as a CI gate.
+func runVerify(stdout io.Writer, args []string) error {
+	if len(args) != 1 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("usage: clix verify <script>")
+	}
+	scriptPath := args[0]
+	data, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return fmt.Errorf("error reading script file: %w", err)
+	}
+	if _, err := os.Stat(signaturePath(scriptPath)); signatureRequired() || err == nil {
+		if err := verifyScriptSignature(scriptPath, data); err != nil {
+			return err
+		}
+		log(1, "Verified the signature of %s", scriptPath)
+	}
+
+	lock, err := readLock(scriptPath)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return fmt.Errorf("%s has no lockfile; run clix lock %s", scriptPath, scriptPath)
+	}
+	script, err := readScript(scriptPath)
+	if err != nil {
+		return err
+	}
+	resolved, err := resolveLock(script)
+	if err != nil {
+		return err
+	}
+
+	diff, err := diffLock(lock, resolved)
+	if err != nil {
+		return err
+	}
+	problems := verifyDownloads(script, lock)
+	if diff == "" && len(problems) == 0 {
+		fmt.Fprintf(stdout, "%s matches %s\n", scriptPath, lockPath(scriptPath))
+		return nil
+	}
+	fmt.Fprint(stdout, diff)
+	for _, problem := range problems {
+		fmt.Fprintf(stdout, "download: %s\n", problem)
+	}
+	return fmt.Errorf("%s has drifted from %s", scriptPath, lockPath(scriptPath))
+}
+
+// diffLock returns a diff of each part of the lock which differs from what the script resolves to now.
+func diffLock(lock, resolved *ScriptLock) (string, error) {
+	parts := []struct {
+		name             string
+		locked, resolved any
+	}{
+		{"image", lock.Image, resolved.Image},
+		{"build", lock.Build, resolved.Build},
+		{"go", lock.Go, resolved.Go},
+		{"binary", lock.Binary, resolved.Binary},
+		{"kubectl-plugin", lock.KubectlPlugin, resolved.KubectlPlugin},
+	}
+	var diff strings.Builder
+	for _, part := range parts {
+		locked, err := yamlLines(part.locked)
+		if err != nil {
+			return "", err
+		}
+		current, err := yamlLines(part.resolved)
+		if err != nil {
+			return "", err
+		}
+		if slices.Equal(locked, current) {
+			continue
+		}
+		fmt.Fprintf(&diff, "--- %s (locked)\n+++ %s (resolved)\n", part.name, part.name)
+		for _, line := range diffLines(locked, current) {
+			fmt.Fprintln(&diff, line)
+		}
+	}
+	return diff.String(), nil
+}
+
+// yamlLines returns the lines of v as yaml, or none for a nil part.
+func yamlLines(v any) ([]string, error) {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if s := strings.TrimSpace(string(data)); s != "null" {
+		return strings.Split(s, "\n"), nil
+	}
+	return nil, nil
+}
+
+// diffLines returns the lines of a and b prefixed with "-" for removed lines, "+" for added lines and a
+// space for common lines, from their longest common subsequence.
+func diffLines(a, b []string) []string {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, " "+a[i])
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "-"+a[i])
+			i++
+		default:
+			lines = append(lines, "+"+b[j])
+			j++
+		}
+	}
+	return lines
+}
+
+// verifyDownloads downloads the locked binaries, returning the ones which fail to match their checksums.
+// The binary is checked for each platform it has a checksum for, and a kubectl plugin for the platforms
+// it runs on from this host: natively, and in a linux container.
+func verifyDownloads(script Script, lock *ScriptLock) []string {
+	var problems []string
+	host := runtime.GOOS + "/" + runtime.GOARCH
+	if l := lock.Binary; l != nil && script.Binary != nil {
+		config := *script.Binary
+		config.URL, config.Version, config.SHA256 = l.URL, l.Version, l.SHA256
+		for platform := range l.SHA256 {
+			if platform == "" {
+				platform = host
+			}
+			goos, goarch, _ := strings.Cut(platform, "/")
+			if _, err := fetchBinary(&config, goos, goarch); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+	if l := lock.KubectlPlugin; l != nil {
+		manifest := &krewManifest{}
+		manifest.Spec.Platforms = l.Platforms
+		for _, platform := range slices.Compact([]string{host, "linux/" + runtime.GOARCH}) {
+			goos, goarch, _ := strings.Cut(platform, "/")
+			p, err := manifest.platform(goos, goarch)
+			if err != nil {
+				log(1, "Not verifying kubectl plugin %s for %s: %v", l.Name, platform, err)
+				continue
+			}
+			if _, err := fetchArtifact(p.URI, p.SHA256, p.archivePath(), platform); err != nil {
+				problems = append(problems, err.Error())
+			}
+		}
+	}
+	slices.Sort(problems)
+	return problems
+}
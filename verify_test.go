@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer func(fn func(string) (string, error)) { imageDigestFn = fn }(imageDigestFn)
+	digest := "sha256:aaaa"
+	imageDigestFn = func(ref string) (string, error) {
+		return digest, nil
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "tool")
+	os.WriteFile(script, []byte("apiVersion: clix.dev/v1alpha1\nkind: Script\nimage: alpine:3.20\n"), 0644)
+	var stdout bytes.Buffer
+	if err := runVerify(&stdout, []string{script}); err == nil || !strings.Contains(err.Error(), "has no lockfile") {
+		t.Errorf("Expected an error without a lock, got %v", err)
+	}
+	if err := runLock(&stdout, &stdout, []string{script}); err != nil {
+		t.Fatalf("lock failed: %v", err)
+	}
+	stdout.Reset()
+	if err := runVerify(&stdout, []string{script}); err != nil || !strings.Contains(stdout.String(), "matches") {
+		t.Errorf("Expected the script to match its lock, got %v: %s", err, stdout.String())
+	}
+
+	// A tag which moved is reported with a diff
+	digest = "sha256:bbbb"
+	stdout.Reset()
+	err := runVerify(&stdout, []string{script})
+	want := "--- image (locked)\n+++ image (resolved)\n-digest: sha256:aaaa\n+digest: sha256:bbbb\n ref: alpine:3.20\n"
+	if err == nil || stdout.String() != want {
+		t.Errorf("Expected the drift to be reported, got %v:\n%s", err, stdout.String())
+	}
+
+	// Scripts must be signed when signatures are required
+	t.Setenv("CLIX_VERIFY_SIGNATURES", "1")
+	if err := runVerify(&stdout, []string{script}); err == nil || !strings.Contains(err.Error(), "is not signed") {
+		t.Errorf("Expected an error for an unsigned script, got %v", err)
+	}
+}
+
+func TestVerifyDownloads(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	content := []byte("binary")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	sum := sha256.Sum256(content)
+	config := &BinaryConfig{URL: server.URL + "/tool-{os}-{arch}", SHA256: Checksums{"linux/amd64": hex.EncodeToString(sum[:])}}
+	lock := &ScriptLock{Binary: &BinaryLock{URL: config.URL, SHA256: config.SHA256}}
+	if problems := verifyDownloads(Script{Binary: config}, lock); len(problems) != 0 {
+		t.Errorf("Expected the download to match, got %v", problems)
+	}
+
+	content = []byte("replaced")
+	lock.Binary.SHA256 = Checksums{"linux/arm64": hex.EncodeToString(sum[:])}
+	if problems := verifyDownloads(Script{Binary: config}, lock); len(problems) != 1 || !strings.Contains(problems[0], "sha256 mismatch") {
+		t.Errorf("Expected a sha256 mismatch, got %v", problems)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	got := strings.Join(diffLines([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"}), "\n")
+	if want := " a\n-b\n+x\n c\n+d"; got != want {
+		t.Errorf("diffLines = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// volumeLabel marks the docker volumes which clix manages, so that clix cache can list and remove them.
+const volumeLabel = "clix.dev/managed"
+
+// splitVolumeMounts separates named volume mounts from host path mounts.
+func splitVolumeMounts(mounts []Mount) (volumes, hostMounts []Mount) {
+	for _, m := range mounts {
+		if m.Volume != "" {
+			volumes = append(volumes, m)
+		} else {
+			hostMounts = append(hostMounts, m)
+		}
+	}
+	return volumes, hostMounts
+}
+
+// ensureVolumeFn creates the named volume with the clix label, if it does not exist.
+var ensureVolumeFn = func(cli []string, name string) error {
+	inspect := execCommand(cli[0], append(cli[1:], "volume", "inspect", name)...)
+	if err := inspect.Run(); err == nil {
+		return nil
+	}
+	log(1, "Creating volume %s", name)
+	create := execCommand(cli[0], append(cli[1:], "volume", "create", "--label", volumeLabel+"=true", name)...)
+	create.Stderr = os.Stderr
+	if err := create.Run(); err != nil {
+		return fmt.Errorf("error creating volume %s: %w", name, err)
+	}
+	return nil
+}
+
+// namedVolumeArgs returns the -v arguments for the volume mounts, creating the volumes if needed.
+func namedVolumeArgs(cli []string, volumes []Mount) ([]string, error) {
+	var args []string
+	for _, m := range volumes {
+		if m.SandboxPath == "" {
+			return nil, fmt.Errorf("volume %s needs a sandboxPath", m.Volume)
+		}
+		if err := ensureVolumeFn(cli, m.Volume); err != nil {
+			return nil, err
+		}
+		args = append(args, "-v", volumeArg(m.Volume, m.SandboxPath, m.ReadOnly))
+	}
+	return args, nil
+}
+
+// listVolumes returns the names of the volumes managed by clix.
+func listVolumes(cli []string) ([]string, error) {
+	cmd := execCommand(cli[0], append(cli[1:], "volume", "ls", "--quiet", "--filter", "label="+volumeLabel)...)
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error listing volumes: %w", err)
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// containerCLI returns the command line of the container engine used by the cache subcommands.
+func containerCLI() []string {
+	switch sandbox := selectedSandbox(Script{}); sandbox {
+	case "podman", "nerdctl":
+		return []string{sandbox}
+	}
+	return dockerCLI(Script{DockerContext: os.Getenv("CLIX_DOCKER_CONTEXT")})
+}
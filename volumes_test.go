@@ -0,0 +1,78 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVolumeMounts(t *testing.T) {
+	var created []string
+	origEnsure := ensureVolumeFn
+	ensureVolumeFn = func(cli []string, name string) error {
+		created = append(created, name)
+		return nil
+	}
+	defer func() { ensureVolumeFn = origEnsure }()
+
+	script := Script{
+		Image: "hashicorp/terraform",
+		Mounts: []Mount{
+			{Volume: "clix-terraform-plugins", SandboxPath: "/root/.terraform.d"},
+			{HostPath: "/src", SandboxPath: "/workspace"},
+		},
+	}
+	cmdArgs, err := buildDockerArgs(script, nil, false)
+	if err != nil {
+		t.Fatalf("buildDockerArgs failed: %v", err)
+	}
+	joined := strings.Join(cmdArgs, " ")
+	if !strings.Contains(joined, "-v clix-terraform-plugins:/root/.terraform.d") || !strings.Contains(joined, "-v /src:/workspace") {
+		t.Errorf("Expected volume and bind mounts, got %v", cmdArgs)
+	}
+	if len(created) != 1 || created[0] != "clix-terraform-plugins" {
+		t.Errorf("Expected the volume to be created, got %v", created)
+	}
+
+	script.Mounts = []Mount{{Volume: "clix-terraform-plugins"}}
+	if _, err := buildDockerArgs(script, nil, false); err == nil {
+		t.Errorf("Expected an error for a volume without a sandboxPath")
+	}
+
+	// Other sandboxes can't mount volumes
+	if _, err := resolveMounts([]Mount{{Volume: "clix-terraform-plugins", SandboxPath: "/root/.terraform.d"}}, ""); err == nil || !strings.Contains(err.Error(), "docker-compatible") {
+		t.Errorf("Expected an error resolving a volume mount, got %v", err)
+	}
+}
+
+func TestEnsureVolume(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() { execCommand = exec.Command }()
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("MOCK_CALLS", calls)
+
+	// The fake docker reports that the volume exists
+	if err := ensureVolumeFn([]string{"docker"}, "clix-terraform-plugins"); err != nil {
+		t.Fatalf("ensureVolume failed: %v", err)
+	}
+	data, _ := os.ReadFile(calls)
+	if got := strings.TrimSpace(string(data)); got != "docker volume inspect clix-terraform-plugins" {
+		t.Errorf("Unexpected commands %q", got)
+	}
+}
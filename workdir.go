@@ -0,0 +1,95 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// resolveWorkdir resolves the script's workdir, which may be an expression such as git.repoRoot(cwd)
+// or start with ~, to an absolute host path.
+func resolveWorkdir(script *Script) error {
+	if script.Workdir == "" {
+		return nil
+	}
+	dir, _, err := evalExpression(script.Workdir)
+	if err != nil {
+		return fmt.Errorf("workdir: %w", err)
+	}
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get user home dir: %w", err)
+		}
+		dir = filepath.Join(home, strings.TrimPrefix(dir[1:], "/"))
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("workdir: %w", err)
+	}
+	script.Workdir = dir
+	return nil
+}
+
+// sandboxWorkdir returns the working directory of the tool in a sandbox with the resolved mounts.
+// Without a workdir, this is the current directory, at the same path. A workdir is mapped to its path
+// in the sandbox through the mount which contains it, and must be mounted, as the tool would otherwise
+// see an empty directory.
+func sandboxWorkdir(script Script, mounts []Mount) (string, error) {
+	if script.Workdir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("error getting current working directory: %w", err)
+		}
+		return cwd, nil
+	}
+
+	var best *Mount
+	for i, m := range mounts {
+		if isWithin(m.HostPath, script.Workdir) && (best == nil || len(m.HostPath) > len(best.HostPath)) {
+			best = &mounts[i]
+		}
+	}
+	if best == nil {
+		return "", fmt.Errorf("workdir %s is not mounted in the sandbox; add a mount which contains it", script.Workdir)
+	}
+	rel, err := filepath.Rel(best.HostPath, script.Workdir)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(best.SandboxPath, filepath.ToSlash(rel)), nil
+}
+
+// rootfsWorkdir returns the working directory of the tool in the sandboxes which run an extracted image
+// rootfs (nsjail, proot, namespace), which, unlike container engines, don't create it. Without a
+// workdir, this is the current directory if it is mounted (as mountCwd does), and / otherwise.
+func rootfsWorkdir(script Script, mounts []Mount) (string, error) {
+	if script.Workdir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("error getting current working directory: %w", err)
+		}
+		script.Workdir = cwd
+		if dir, err := sandboxWorkdir(script, mounts); err == nil {
+			return dir, nil
+		}
+		return "/", nil
+	}
+	return sandboxWorkdir(script, mounts)
+}
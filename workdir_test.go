@@ -0,0 +1,100 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveWorkdir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cwd, _ := os.Getwd()
+
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"~", home},
+		{"~/src", filepath.Join(home, "src")},
+		{"home() + '/work'", filepath.Join(home, "work")},
+		{"sub", filepath.Join(cwd, "sub")},
+	} {
+		script := Script{Workdir: tc.in}
+		if err := resolveWorkdir(&script); err != nil || script.Workdir != tc.want {
+			t.Errorf("resolveWorkdir(%q) = %q, %v; want %q", tc.in, script.Workdir, err, tc.want)
+		}
+	}
+}
+
+func TestSandboxWorkdir(t *testing.T) {
+	mounts := []Mount{
+		{HostPath: "/home/user/src", SandboxPath: "/src"},
+		{HostPath: "/home/user/src/project", SandboxPath: "/workspace"},
+	}
+
+	for _, tc := range []struct {
+		workdir, want string
+	}{
+		{"/home/user/src/project/pkg", "/workspace/pkg"},
+		{"/home/user/src", "/src"},
+		{"/home/user/src/other", "/src/other"},
+	} {
+		got, err := sandboxWorkdir(Script{Workdir: tc.workdir}, mounts)
+		if err != nil || got != tc.want {
+			t.Errorf("sandboxWorkdir(%q) = %q, %v; want %q", tc.workdir, got, err, tc.want)
+		}
+	}
+
+	if _, err := sandboxWorkdir(Script{Workdir: "/home/user/docs"}, mounts); err == nil || !strings.Contains(err.Error(), "not mounted") {
+		t.Errorf("Expected an error for an unmounted workdir, got %v", err)
+	}
+
+	cwd, _ := os.Getwd()
+	if got, err := sandboxWorkdir(Script{}, nil); err != nil || got != cwd {
+		t.Errorf("sandboxWorkdir() = %q, %v; want the current directory", got, err)
+	}
+
+	dir := t.TempDir()
+	cmdArgs, err := buildDockerArgs(Script{Image: "alpine", Workdir: filepath.Join(dir, "pkg"), Mounts: []Mount{{HostPath: dir, SandboxPath: "/workspace"}}}, nil, false)
+	if err != nil {
+		t.Fatalf("buildDockerArgs failed: %v", err)
+	}
+	if !strings.Contains(strings.Join(cmdArgs, " "), "-w /workspace/pkg") {
+		t.Errorf("Expected the workdir in the sandbox, got %v", cmdArgs)
+	}
+}
+
+func TestRootfsWorkdir(t *testing.T) {
+	cwd, _ := os.Getwd()
+	for _, tc := range []struct {
+		name    string
+		workdir string
+		mounts  []Mount
+		want    string
+	}{
+		{name: "cwd mounted", mounts: []Mount{{HostPath: cwd, SandboxPath: cwd}}, want: cwd},
+		{name: "cwd not mounted", want: "/"},
+		{name: "workdir", workdir: filepath.Join(cwd, "pkg"), mounts: []Mount{{HostPath: cwd, SandboxPath: "/src"}}, want: "/src/pkg"},
+	} {
+		got, err := rootfsWorkdir(Script{Workdir: tc.workdir}, tc.mounts)
+		if err != nil || got != tc.want {
+			t.Errorf("%s: rootfsWorkdir() = %q, %v; want %q", tc.name, got, err, tc.want)
+		}
+	}
+}